@@ -0,0 +1,281 @@
+package ibe
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/pairing"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// HIBEIDTag is the domain separation tag used to map the components of a
+// hierarchical identity to scalars.
+func HIBEIDTag() []byte {
+	return []byte("IBE-HIBE-ID")
+}
+
+// HIBEParams are the public parameters of the Boneh-Boyen-Goh hierarchical
+// IBE scheme from https://eprint.iacr.org/2005/015.pdf, adapted to
+// asymmetric pairings:
+// - P is the base point of G1 and Ppub = alpha*P the master public key
+// - G2, G3 and H[0..MaxDepth-1] are independent random points on G2
+// Identities are paths of at most len(H) components, e.g. org/unit/user.
+type HIBEParams struct {
+	// master public key alpha*P on G1
+	Ppub kyber.Point
+	// random points on G2
+	G2 kyber.Point
+	G3 kyber.Point
+	H  []kyber.Point
+}
+
+// HIBEPrivateKey is the private key of a hierarchical identity. It can be
+// used to decrypt messages sent to ID, and to derive the keys of all the
+// identities below ID with HIBEDelegate.
+type HIBEPrivateKey struct {
+	// ID is the path of this key in the hierarchy
+	ID [][]byte
+	// D0 = alpha*G2 + r*(G3 + sum I_i*H_i) on G2
+	D0 kyber.Point
+	// D1 = r*P on G1
+	D1 kyber.Point
+	// B[j] = r*H[len(ID)+j] on G2, used for delegation
+	B []kyber.Point
+}
+
+// HIBECiphertext is a CCA ciphertext towards a hierarchical identity.
+type HIBECiphertext struct {
+	// Random point rP on G1
+	U kyber.Point
+	// Random point r*(G3 + sum I_i*H_i) on G2
+	C kyber.Point
+	// Sigma attached to the ID: sigma XOR H2(e(Ppub, G2)^r)
+	V []byte
+	// ciphertext of the message M XOR H4(sigma)
+	W []byte
+}
+
+// HIBESetup generates the public parameters of a hierarchy of at most
+// maxDepth levels, together with the master secret key alpha*G2 on G2 that
+// is needed to extract the keys of the top level identities.
+func HIBESetup(s pairing.Suite, maxDepth int) (*HIBEParams, kyber.Point, error) {
+	if maxDepth < 1 {
+		return nil, nil, errors.New("hierarchy depth must be at least 1")
+	}
+	stream := random.New()
+	alpha := s.G1().Scalar().Pick(stream)
+	params := &HIBEParams{
+		Ppub: s.G1().Point().Mul(alpha, nil),
+		G2:   s.G2().Point().Pick(stream),
+		G3:   s.G2().Point().Pick(stream),
+		H:    make([]kyber.Point, maxDepth),
+	}
+	for i := range params.H {
+		params.H[i] = s.G2().Point().Pick(stream)
+	}
+	master := s.G2().Point().Mul(alpha, params.G2)
+	return params, master, nil
+}
+
+// MaxDepth returns the maximum number of components of an identity.
+func (p *HIBEParams) MaxDepth() int {
+	return len(p.H)
+}
+
+// HIBEExtract derives the private key of the identity ID from the master
+// secret key returned by HIBESetup.
+func HIBEExtract(s pairing.Suite, params *HIBEParams, master kyber.Point, ID [][]byte) (*HIBEPrivateKey, error) {
+	ids, err := params.hashID(s, ID)
+	if err != nil {
+		return nil, err
+	}
+	r := s.G1().Scalar().Pick(random.New())
+	D0 := params.idPoint(s, ids)
+	D0 = D0.Mul(r, D0)
+	D0 = D0.Add(D0, master)
+	B := make([]kyber.Point, 0, params.MaxDepth()-len(ids))
+	for _, h := range params.H[len(ids):] {
+		B = append(B, s.G2().Point().Mul(r, h))
+	}
+	return &HIBEPrivateKey{
+		ID: copyID(ID),
+		D0: D0,
+		D1: s.G1().Point().Mul(r, nil),
+		B:  B,
+	}, nil
+}
+
+// HIBEDelegate derives the private key of the identity parent.ID/child from
+// the parent private key. The derived key is re-randomized, so that it is
+// distributed exactly as if it had been extracted from the master key.
+func HIBEDelegate(s pairing.Suite, params *HIBEParams, parent *HIBEPrivateKey, child []byte) (*HIBEPrivateKey, error) {
+	if len(parent.B) == 0 || len(parent.ID) >= params.MaxDepth() {
+		return nil, errors.New("maximum hierarchy depth reached")
+	}
+	ID := append(copyID(parent.ID), append([]byte{}, child...))
+	ids, err := params.hashID(s, ID)
+	if err != nil {
+		return nil, err
+	}
+	k := len(parent.ID)
+	t := s.G1().Scalar().Pick(random.New())
+
+	// D0' = D0 + I_k*B_0 + t*(G3 + sum I_i*H_i)
+	D0 := params.idPoint(s, ids)
+	D0 = D0.Mul(t, D0)
+	D0 = D0.Add(D0, parent.D0)
+	D0 = D0.Add(D0, s.G2().Point().Mul(ids[k], parent.B[0]))
+	// D1' = D1 + t*P
+	D1 := s.G1().Point().Mul(t, nil)
+	D1 = D1.Add(D1, parent.D1)
+	// B_j' = B_j + t*H_j
+	B := make([]kyber.Point, 0, len(parent.B)-1)
+	for j, b := range parent.B[1:] {
+		tH := s.G2().Point().Mul(t, params.H[k+1+j])
+		B = append(B, tH.Add(tH, b))
+	}
+	return &HIBEPrivateKey{
+		ID: ID,
+		D0: D0,
+		D1: D1,
+		B:  B,
+	}, nil
+}
+
+// HIBEEncrypt encrypts msg towards the hierarchical identity ID. It is the
+// BBG encryption of a random sigma, made CCA secure the same way as
+// EncryptCCAonG1, using the H2, H3 and H4 hash functions.
+func HIBEEncrypt(s pairing.Suite, params *HIBEParams, ID [][]byte, msg []byte) (*HIBECiphertext, error) {
+	if len(msg) > s.Hash().Size() {
+		return nil, errors.New("plaintext too long for the hash function provided")
+	}
+	ids, err := params.hashID(s, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Derive random sigma
+	sigma := make([]byte, len(msg))
+	if _, err := rand.Read(sigma); err != nil {
+		return nil, fmt.Errorf("err reading rand sigma: %w", err)
+	}
+	// 2. Derive r from sigma and msg
+	r, err := h3(s, sigma, msg)
+	if err != nil {
+		return nil, err
+	}
+	// 3. Compute U = rP and C = r*(G3 + sum I_i*H_i)
+	U := s.G1().Point().Mul(r, nil)
+	C := params.idPoint(s, ids)
+	C = C.Mul(r, C)
+
+	// 4. Compute V = sigma XOR H2(r*e(Ppub, G2))
+	rGid := s.Pair(params.Ppub, params.G2)
+	rGid = rGid.Mul(r, rGid)
+	hrGid, err := gtToHash(s, rGid, len(msg))
+	if err != nil {
+		return nil, err
+	}
+	V := xor(sigma, hrGid)
+
+	// 5. Compute M XOR H(sigma)
+	hsigma, err := h4(s, sigma, len(msg))
+	if err != nil {
+		return nil, err
+	}
+	W := xor(msg, hsigma)
+
+	return &HIBECiphertext{
+		U: U,
+		C: C,
+		V: V,
+		W: W,
+	}, nil
+}
+
+// HIBEDecrypt decrypts a ciphertext produced by HIBEEncrypt with the private
+// key of the identity it was encrypted to.
+func HIBEDecrypt(s pairing.Suite, params *HIBEParams, private *HIBEPrivateKey, c *HIBECiphertext) ([]byte, error) {
+	if len(c.W) > s.Hash().Size() {
+		return nil, errors.New("ciphertext too long for the hash function provided")
+	}
+	ids, err := params.hashID(s, private.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Compute sigma = V XOR H2(e(U, D0) - e(D1, C))
+	rGid := s.Pair(c.U, private.D0)
+	rGid = rGid.Sub(rGid, s.Pair(private.D1, c.C))
+	hrGid, err := gtToHash(s, rGid, len(c.W))
+	if err != nil {
+		return nil, err
+	}
+	if len(hrGid) != len(c.V) {
+		return nil, fmt.Errorf("XorSigma is of invalid length: exp %d vs got %d", len(hrGid), len(c.V))
+	}
+	sigma := xor(hrGid, c.V)
+
+	// 2. Compute M = W XOR H4(sigma)
+	hsigma, err := h4(s, sigma, len(c.W))
+	if err != nil {
+		return nil, err
+	}
+	msg := xor(hsigma, c.W)
+
+	// 3. Check U = rP and C = r*(G3 + sum I_i*H_i)
+	r, err := h3(s, sigma, msg)
+	if err != nil {
+		return nil, err
+	}
+	rP := s.G1().Point().Mul(r, nil)
+	if !rP.Equal(c.U) {
+		return nil, fmt.Errorf("invalid proof: rP check failed")
+	}
+	rC := params.idPoint(s, ids)
+	rC = rC.Mul(r, rC)
+	if !rC.Equal(c.C) {
+		return nil, fmt.Errorf("invalid proof: rC check failed")
+	}
+	return msg, nil
+}
+
+// hashID maps every component of the identity to a scalar. The level of the
+// component is part of the hash, so that the same name at different levels
+// gives different scalars.
+func (p *HIBEParams) hashID(s pairing.Suite, ID [][]byte) ([]kyber.Scalar, error) {
+	if len(ID) == 0 {
+		return nil, errors.New("empty identity")
+	}
+	if len(ID) > p.MaxDepth() {
+		return nil, fmt.Errorf("identity has %d components, maximum is %d", len(ID), p.MaxDepth())
+	}
+	ids := make([]kyber.Scalar, len(ID))
+	for i, component := range ID {
+		var level [4]byte
+		binary.BigEndian.PutUint32(level[:], uint32(i))
+		seed := append(append(HIBEIDTag(), level[:]...), component...)
+		ids[i] = s.G1().Scalar().Pick(s.XOF(seed))
+	}
+	return ids, nil
+}
+
+// idPoint returns G3 + sum I_i*H_i.
+func (p *HIBEParams) idPoint(s pairing.Suite, ids []kyber.Scalar) kyber.Point {
+	res := s.G2().Point().Set(p.G3)
+	for i, id := range ids {
+		res = res.Add(res, s.G2().Point().Mul(id, p.H[i]))
+	}
+	return res
+}
+
+func copyID(ID [][]byte) [][]byte {
+	res := make([][]byte, len(ID))
+	for i, c := range ID {
+		res[i] = append([]byte{}, c...)
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+package ibe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	circl "go.dedis.ch/kyber/v4/pairing/bls12381/circl"
+)
+
+func TestHIBEDelegatedKeysDecrypt(t *testing.T) {
+	suite := circl.NewSuiteBLS12381()
+	params, master, err := HIBESetup(suite, 3)
+	require.NoError(t, err)
+
+	org := [][]byte{[]byte("dedis")}
+	unit := append(org, []byte("crypto"))
+	user := append(unit, []byte("alice"))
+	msg := []byte("Hello World\n")
+
+	orgKey, err := HIBEExtract(suite, params, master, org)
+	require.NoError(t, err)
+	unitKey, err := HIBEDelegate(suite, params, orgKey, unit[1])
+	require.NoError(t, err)
+	userKey, err := HIBEDelegate(suite, params, unitKey, user[2])
+	require.NoError(t, err)
+	extractedUserKey, err := HIBEExtract(suite, params, master, user)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		ID  [][]byte
+		key *HIBEPrivateKey
+	}{
+		{org, orgKey},
+		{unit, unitKey},
+		{user, userKey},
+		{user, extractedUserKey},
+	} {
+		c, err := HIBEEncrypt(suite, params, tc.ID, msg)
+		require.NoError(t, err)
+		msg2, err := HIBEDecrypt(suite, params, tc.key, c)
+		require.NoError(t, err)
+		require.Equal(t, msg, msg2)
+	}
+
+	_, err = HIBEDelegate(suite, params, userKey, []byte("too deep"))
+	require.Error(t, err)
+}
+
+func TestHIBEWrongIdentityFailsDecryption(t *testing.T) {
+	suite := circl.NewSuiteBLS12381()
+	params, master, err := HIBESetup(suite, 2)
+	require.NoError(t, err)
+
+	alice := [][]byte{[]byte("dedis"), []byte("alice")}
+	bob := [][]byte{[]byte("dedis"), []byte("bob")}
+	msg := []byte("Hello World\n")
+
+	orgKey, err := HIBEExtract(suite, params, master, alice[:1])
+	require.NoError(t, err)
+	bobKey, err := HIBEDelegate(suite, params, orgKey, bob[1])
+	require.NoError(t, err)
+
+	c, err := HIBEEncrypt(suite, params, alice, msg)
+	require.NoError(t, err)
+
+	_, err = HIBEDecrypt(suite, params, bobKey, c)
+	require.ErrorContains(t, err, "invalid proof")
+	_, err = HIBEDecrypt(suite, params, orgKey, c)
+	require.ErrorContains(t, err, "invalid proof")
+}
+
+func TestHIBEInvalidCiphertextFailsDecryption(t *testing.T) {
+	suite := circl.NewSuiteBLS12381()
+	params, master, err := HIBESetup(suite, 2)
+	require.NoError(t, err)
+
+	ID := [][]byte{[]byte("dedis"), []byte("alice")}
+	key, err := HIBEExtract(suite, params, master, ID)
+	require.NoError(t, err)
+	msg := []byte("Hello World\n")
+
+	c, err := HIBEEncrypt(suite, params, ID, msg)
+	require.NoError(t, err)
+	c.C = suite.G2().Point().Add(c.C, params.G3)
+	_, err = HIBEDecrypt(suite, params, key, c)
+	require.ErrorContains(t, err, "invalid proof")
+
+	c, err = HIBEEncrypt(suite, params, ID, msg)
+	require.NoError(t, err)
+	c.W = []byte("somenonsense")
+	_, err = HIBEDecrypt(suite, params, key, c)
+	require.ErrorContains(t, err, "invalid proof")
+
+	_, err = HIBEEncrypt(suite, params, append(ID, []byte("too deep")), msg)
+	require.Error(t, err)
+}
@@ -259,3 +259,57 @@ func TestCPAEncryptOnG1(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, msg, msg2)
 }
+
+func TestMultiRecipientEncryption(t *testing.T) {
+	t.Run("OnG1", func(t *testing.T) {
+		suite := circl.NewSuiteBLS12381()
+		s := suite.G1().Scalar().Pick(random.New())
+		Ppub := suite.G1().Point().Mul(s, nil)
+		IDs := [][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}
+		msg := []byte("Hello World\n")
+
+		c, err := EncryptMultiCCAonG1(suite, Ppub, IDs, msg)
+		require.NoError(t, err)
+		require.Len(t, c.V, len(IDs))
+		for i, ID := range IDs {
+			Qid := suite.G2().Point().(kyber.HashablePoint).Hash(ID)
+			sQid := Qid.Mul(s, Qid)
+			ci, err := c.Ciphertext(i)
+			require.NoError(t, err)
+			msg2, err := DecryptCCAonG1(suite, sQid, ci)
+			require.NoError(t, err)
+			require.Equal(t, msg, msg2)
+
+			// the key of one recipient doesn't open the share of another
+			cj, err := c.Ciphertext((i + 1) % len(IDs))
+			require.NoError(t, err)
+			_, err = DecryptCCAonG1(suite, sQid, cj)
+			require.ErrorContains(t, err, "invalid proof")
+		}
+		_, err = c.Ciphertext(len(IDs))
+		require.Error(t, err)
+	})
+
+	t.Run("OnG2", func(t *testing.T) {
+		suite := circl.NewSuiteBLS12381()
+		s := suite.G2().Scalar().Pick(random.New())
+		Ppub := suite.G2().Point().Mul(s, nil)
+		IDs := [][]byte{[]byte("alice"), []byte("bob")}
+		msg := []byte("Hello World\n")
+
+		c, err := EncryptMultiCCAonG2(suite, Ppub, IDs, msg)
+		require.NoError(t, err)
+		for i, ID := range IDs {
+			Qid := suite.G1().Point().(kyber.HashablePoint).Hash(ID)
+			sQid := Qid.Mul(s, Qid)
+			ci, err := c.Ciphertext(i)
+			require.NoError(t, err)
+			msg2, err := DecryptCCAonG2(suite, sQid, ci)
+			require.NoError(t, err)
+			require.Equal(t, msg, msg2)
+		}
+
+		_, err = EncryptMultiCCAonG2(suite, Ppub, nil, msg)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,123 @@
+package ibe
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/pairing"
+)
+
+// MultiCiphertext is a CCA ciphertext encrypting a single payload towards
+// several identities at once. All recipients share the same random point U
+// and the same masked message W; each of them gets its own V.
+type MultiCiphertext struct {
+	// Random point rP, shared by all recipients
+	U kyber.Point
+	// Sigma attached to each ID: sigma XOR H(rG_id), in the order of the
+	// identities given at encryption time
+	V [][]byte
+	// ciphertext of the message M XOR H(sigma)
+	W []byte
+}
+
+// Ciphertext returns the single-recipient view of the ciphertext for the i-th
+// identity. The result can be decrypted with DecryptCCAonG1 (resp.
+// DecryptCCAonG2) when the multi-recipient ciphertext was produced by
+// EncryptMultiCCAonG1 (resp. EncryptMultiCCAonG2).
+func (c *MultiCiphertext) Ciphertext(i int) (*Ciphertext, error) {
+	if i < 0 || i >= len(c.V) {
+		return nil, fmt.Errorf("recipient index %d out of range [0, %d)", i, len(c.V))
+	}
+	return &Ciphertext{
+		U: c.U,
+		V: c.V[i],
+		W: c.W,
+	}, nil
+}
+
+// EncryptMultiCCAonG1 encrypts msg towards all the given identities using a
+// single random value r, so that the ciphertext only carries one U = rP
+// point no matter how many recipients there are. It uses the same settings
+// and the same hash functions as EncryptCCAonG1:
+// - master is the master key on G1
+// - identities are on G2
+// - the MultiCiphertext.U point will be on G1
+// Each recipient decrypts its share of the ciphertext, obtained with
+// MultiCiphertext.Ciphertext, using DecryptCCAonG1.
+func EncryptMultiCCAonG1(s pairing.Suite, master kyber.Point, IDs [][]byte, msg []byte) (*MultiCiphertext, error) {
+	hG2, ok := s.G2().Point().(kyber.HashablePoint)
+	if !ok {
+		return nil, errors.New("point needs to implement `kyber.HashablePoint`")
+	}
+	pair := func(Qid kyber.Point) kyber.Point {
+		return s.Pair(master, Qid)
+	}
+	return encryptMultiCCA(s, s.G1(), hG2, pair, IDs, msg)
+}
+
+// EncryptMultiCCAonG2 is the counterpart of EncryptMultiCCAonG1 for a master
+// key on G2 and identities on G1. Each recipient decrypts its share of the
+// ciphertext using DecryptCCAonG2.
+func EncryptMultiCCAonG2(s pairing.Suite, master kyber.Point, IDs [][]byte, msg []byte) (*MultiCiphertext, error) {
+	hG1, ok := s.G1().Point().(kyber.HashablePoint)
+	if !ok {
+		return nil, errors.New("point needs to implement `kyber.HashablePoint`")
+	}
+	pair := func(Qid kyber.Point) kyber.Point {
+		return s.Pair(Qid, master)
+	}
+	return encryptMultiCCA(s, s.G2(), hG1, pair, IDs, msg)
+}
+
+// encryptMultiCCA runs the steps of the CCA encryption, once for the shared
+// part and once per identity for the V component. uGroup is the group U
+// lives in, hID hashes identities, and pair computes Gid from Q_id.
+func encryptMultiCCA(s pairing.Suite, uGroup kyber.Group, hID kyber.HashablePoint,
+	pair func(kyber.Point) kyber.Point, IDs [][]byte, msg []byte) (*MultiCiphertext, error) {
+	if len(msg) > s.Hash().Size() {
+		return nil, errors.New("plaintext too long for the hash function provided")
+	}
+	if len(IDs) == 0 {
+		return nil, errors.New("no recipient identity given")
+	}
+
+	// 1. Derive random sigma
+	sigma := make([]byte, len(msg))
+	if _, err := rand.Read(sigma); err != nil {
+		return nil, fmt.Errorf("err reading rand sigma: %w", err)
+	}
+	// 2. Derive r from sigma and msg
+	r, err := h3(s, sigma, msg)
+	if err != nil {
+		return nil, err
+	}
+	// 3. Compute U = rP
+	U := uGroup.Point().Mul(r, uGroup.Point().Base())
+
+	// 4. Compute V_i = sigma XOR H2(rGid_i) for every identity
+	V := make([][]byte, len(IDs))
+	for i, ID := range IDs {
+		Gid := pair(hID.Hash(ID))
+		rGid := Gid.Mul(r, Gid)
+		hrGid, err := gtToHash(s, rGid, len(msg))
+		if err != nil {
+			return nil, err
+		}
+		V[i] = xor(sigma, hrGid)
+	}
+
+	// 5. Compute M XOR H(sigma)
+	hsigma, err := h4(s, sigma, len(msg))
+	if err != nil {
+		return nil, err
+	}
+	W := xor(msg, hsigma)
+
+	return &MultiCiphertext{
+		U: U,
+		V: V,
+		W: W,
+	}, nil
+}
@@ -0,0 +1,253 @@
+// Package paillier implements the Paillier cryptosystem, an additively
+// homomorphic public-key encryption scheme: the product of two ciphertexts
+// decrypts to the sum of the plaintexts, and a ciphertext raised to a power k
+// decrypts to the plaintext multiplied by k. The generator is fixed to N+1.
+//
+// A proof of correctness of the modulus lets the holder of a private key show
+// that gcd(N, φ(N)) = 1, which is required for the homomorphic operations to
+// be sound when the key is used in a multi-party protocol.
+package paillier
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+var (
+	// ErrMessageRange is returned when a plaintext is not in [0, N).
+	ErrMessageRange = errors.New("paillier: message out of range")
+	// ErrCiphertext is returned when a ciphertext is not invertible modulo N².
+	ErrCiphertext = errors.New("paillier: invalid ciphertext")
+	// ErrInvalidKey is returned when the primes don't make a valid key.
+	ErrInvalidKey = errors.New("paillier: invalid key")
+	// ErrInvalidProof is returned when a proof of correctness of the modulus
+	// doesn't verify.
+	ErrInvalidProof = errors.New("paillier: invalid proof")
+)
+
+var one = big.NewInt(1)
+
+// PublicKey is a Paillier public key.
+type PublicKey struct {
+	N *big.Int
+}
+
+// PrivateKey is a Paillier private key.
+type PrivateKey struct {
+	PublicKey
+	P, Q *big.Int
+	// Phi is φ(N) = (P-1)(Q-1)
+	Phi *big.Int
+	// PhiInv is φ(N)⁻¹ mod N
+	PhiInv *big.Int
+}
+
+// GenerateKey generates a private key with a modulus of the given bit length,
+// from two random primes.
+func GenerateKey(rand cipher.Stream, bits int) (*PrivateKey, error) {
+	for {
+		p := prime(rand, (bits+1)/2)
+		q := prime(rand, bits-(bits+1)/2)
+		sk, err := NewPrivateKey(p, q)
+		if errors.Is(err, ErrInvalidKey) {
+			continue
+		}
+		if sk.N.BitLen() != bits {
+			continue
+		}
+		return sk, err
+	}
+}
+
+// NewPrivateKey returns the private key made of the two primes. It returns
+// ErrInvalidKey if they are equal or if gcd(N, φ(N)) ≠ 1.
+func NewPrivateKey(p, q *big.Int) (*PrivateKey, error) {
+	if p.Cmp(q) == 0 || !p.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+		return nil, ErrInvalidKey
+	}
+	n := new(big.Int).Mul(p, q)
+	phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+	phiInv := new(big.Int).ModInverse(phi, n)
+	if phiInv == nil {
+		return nil, ErrInvalidKey
+	}
+	return &PrivateKey{
+		PublicKey: PublicKey{N: n},
+		P:         new(big.Int).Set(p),
+		Q:         new(big.Int).Set(q),
+		Phi:       phi,
+		PhiInv:    phiInv,
+	}, nil
+}
+
+// prime returns a random prime of exactly the given bit length.
+func prime(rand cipher.Stream, bits int) *big.Int {
+	for {
+		b := random.Bits(uint(bits), true, rand)
+		b[len(b)-1] |= 1
+		p := new(big.Int).SetBytes(b)
+		if p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+// N2 returns N².
+func (pk *PublicKey) N2() *big.Int {
+	return new(big.Int).Mul(pk.N, pk.N)
+}
+
+// Encrypt encrypts the message m in [0, N) with a random nonce. It returns
+// the ciphertext and the nonce.
+func (pk *PublicKey) Encrypt(rand cipher.Stream, m *big.Int) (c, r *big.Int, err error) {
+	r = RandomUnit(rand, pk.N)
+	c, err = pk.EncryptWithNonce(m, r)
+	return c, r, err
+}
+
+// EncryptWithNonce encrypts the message m in [0, N) with the nonce r in
+// Z*_N, as (N+1)^m r^N mod N².
+func (pk *PublicKey) EncryptWithNonce(m, r *big.Int) (*big.Int, error) {
+	if m.Sign() < 0 || m.Cmp(pk.N) >= 0 {
+		return nil, ErrMessageRange
+	}
+	n2 := pk.N2()
+	// (N+1)^m = 1 + mN mod N²
+	gm := new(big.Int).Mul(m, pk.N)
+	gm.Add(gm, one)
+	c := new(big.Int).Exp(r, pk.N, n2)
+	c.Mul(c, gm)
+	return c.Mod(c, n2), nil
+}
+
+// Add returns a ciphertext of the sum of the plaintexts of c1 and c2.
+func (pk *PublicKey) Add(c1, c2 *big.Int) *big.Int {
+	c := new(big.Int).Mul(c1, c2)
+	return c.Mod(c, pk.N2())
+}
+
+// Mul returns a ciphertext of the plaintext of c multiplied by k.
+func (pk *PublicKey) Mul(c, k *big.Int) *big.Int {
+	return new(big.Int).Exp(c, k, pk.N2())
+}
+
+// ValidCiphertext tells if c is in Z*_N².
+func (pk *PublicKey) ValidCiphertext(c *big.Int) bool {
+	n2 := pk.N2()
+	return c.Sign() > 0 && c.Cmp(n2) < 0 && new(big.Int).GCD(nil, nil, c, pk.N).Cmp(one) == 0
+}
+
+// Decrypt returns the plaintext of the ciphertext c.
+func (sk *PrivateKey) Decrypt(c *big.Int) (*big.Int, error) {
+	if !sk.ValidCiphertext(c) {
+		return nil, ErrCiphertext
+	}
+	// m = L(c^φ mod N²) φ⁻¹ mod N, with L(u) = (u - 1) / N
+	u := new(big.Int).Exp(c, sk.Phi, sk.N2())
+	u.Sub(u, one)
+	u.Div(u, sk.N)
+	u.Mul(u, sk.PhiInv)
+	return u.Mod(u, sk.N), nil
+}
+
+// RandomUnit returns a random element of Z*_n.
+func RandomUnit(rand cipher.Stream, n *big.Int) *big.Int {
+	gcd := new(big.Int)
+	for {
+		r := random.Int(n, rand)
+		if r.Sign() > 0 && gcd.GCD(nil, nil, r, n).Cmp(one) == 0 {
+			return r
+		}
+	}
+}
+
+// keyProofChallenges is the number of N-th roots in a proof of correctness.
+const keyProofChallenges = 16
+
+// smallPrimesBound bounds the primes the modulus is checked not to be a
+// multiple of.
+const smallPrimesBound = 1 << 12
+
+// ProveKey returns a proof that gcd(N, φ(N)) = 1, bound to the context: the
+// N-th roots of challenges derived from N and the context, which only exist
+// for all the challenges if N-th powering is a bijection of Z*_N.
+func (sk *PrivateKey) ProveKey(context []byte) ([]*big.Int, error) {
+	// the N-th root of y is y^(N⁻¹ mod φ(N))
+	d := new(big.Int).ModInverse(sk.N, sk.Phi)
+	if d == nil {
+		return nil, ErrInvalidKey
+	}
+	proof := make([]*big.Int, keyProofChallenges)
+	for i := range proof {
+		y, err := keyChallenge(&sk.PublicKey, context, i)
+		if err != nil {
+			return nil, err
+		}
+		proof[i] = new(big.Int).Exp(y, d, sk.N)
+	}
+	return proof, nil
+}
+
+// VerifyKey verifies the proof of correctness of N for the context. It also
+// checks that N is odd and has no small prime factor.
+func (pk *PublicKey) VerifyKey(context []byte, proof []*big.Int) error {
+	if pk.N == nil || pk.N.Sign() <= 0 || pk.N.Bit(0) == 0 {
+		return fmt.Errorf("%w: even modulus", ErrInvalidProof)
+	}
+	if pk.N.ProbablyPrime(20) {
+		return fmt.Errorf("%w: prime modulus", ErrInvalidProof)
+	}
+	mod := new(big.Int)
+	for p := int64(3); p < smallPrimesBound; p += 2 {
+		bp := big.NewInt(p)
+		if bp.ProbablyPrime(1) && mod.Mod(pk.N, bp).Sign() == 0 {
+			return fmt.Errorf("%w: small factor %d", ErrInvalidProof, p)
+		}
+	}
+	if len(proof) != keyProofChallenges {
+		return fmt.Errorf("%w: wrong number of roots", ErrInvalidProof)
+	}
+	for i, root := range proof {
+		if root == nil || root.Sign() <= 0 || root.Cmp(pk.N) >= 0 {
+			return fmt.Errorf("%w: root out of range", ErrInvalidProof)
+		}
+		y, err := keyChallenge(pk, context, i)
+		if err != nil {
+			return err
+		}
+		if new(big.Int).Exp(root, pk.N, pk.N).Cmp(y) != 0 {
+			return fmt.Errorf("%w: invalid root", ErrInvalidProof)
+		}
+	}
+	return nil
+}
+
+// keyChallenge derives the i-th challenge of a proof of correctness, in
+// Z*_N.
+func keyChallenge(pk *PublicKey, context []byte, i int) (*big.Int, error) {
+	size := (pk.N.BitLen() + 7) / 8
+	var buf []byte
+	for counter := uint32(0); len(buf) < size+16; counter++ {
+		h := sha256.New()
+		_, _ = h.Write([]byte("paillier key proof"))
+		_ = binary.Write(h, binary.BigEndian, uint32(i))
+		_ = binary.Write(h, binary.BigEndian, counter)
+		_ = binary.Write(h, binary.BigEndian, uint32(len(context)))
+		_, _ = h.Write(context)
+		_, _ = h.Write(pk.N.Bytes())
+		buf = h.Sum(buf)
+	}
+	y := new(big.Int).SetBytes(buf)
+	y.Mod(y, pk.N)
+	if y.Sign() == 0 || new(big.Int).GCD(nil, nil, y, pk.N).Cmp(one) != 0 {
+		// finding such a challenge factors N
+		return nil, fmt.Errorf("%w: challenge not in Z*_N", ErrInvalidProof)
+	}
+	return y, nil
+}
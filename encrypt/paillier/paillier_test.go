@@ -0,0 +1,78 @@
+package paillier
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+func TestPaillier(t *testing.T) {
+	rand := random.New()
+	sk, err := GenerateKey(rand, 1024)
+	require.NoError(t, err)
+	require.Equal(t, 1024, sk.N.BitLen())
+
+	m1 := random.Int(sk.N, rand)
+	m2 := big.NewInt(42)
+	c1, _, err := sk.Encrypt(rand, m1)
+	require.NoError(t, err)
+	c2, r2, err := sk.Encrypt(rand, m2)
+	require.NoError(t, err)
+	c, err := sk.EncryptWithNonce(m2, r2)
+	require.NoError(t, err)
+	require.Equal(t, c2, c)
+
+	m, err := sk.Decrypt(c1)
+	require.NoError(t, err)
+	require.Equal(t, m1, m)
+
+	// homomorphic operations
+	m, err = sk.Decrypt(sk.Add(c1, c2))
+	require.NoError(t, err)
+	sum := new(big.Int).Add(m1, m2)
+	require.Equal(t, sum.Mod(sum, sk.N), m)
+	k := big.NewInt(1000)
+	m, err = sk.Decrypt(sk.Mul(c1, k))
+	require.NoError(t, err)
+	prod := new(big.Int).Mul(m1, k)
+	require.Equal(t, prod.Mod(prod, sk.N), m)
+
+	_, _, err = sk.Encrypt(rand, sk.N)
+	require.ErrorIs(t, err, ErrMessageRange)
+	_, err = sk.Decrypt(sk.N)
+	require.ErrorIs(t, err, ErrCiphertext)
+}
+
+func TestNewPrivateKey(t *testing.T) {
+	p := big.NewInt(1000003)
+	_, err := NewPrivateKey(p, p)
+	require.ErrorIs(t, err, ErrInvalidKey)
+	// 3 divides 7 - 1, so gcd(N, φ(N)) = 3
+	_, err = NewPrivateKey(big.NewInt(3), big.NewInt(7))
+	require.ErrorIs(t, err, ErrInvalidKey)
+	sk, err := NewPrivateKey(big.NewInt(1000003), big.NewInt(1000033))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1000003*1000033), sk.N)
+}
+
+func TestKeyProof(t *testing.T) {
+	sk, err := GenerateKey(random.New(), 1024)
+	require.NoError(t, err)
+	ctx := []byte("session")
+	proof, err := sk.ProveKey(ctx)
+	require.NoError(t, err)
+	require.NoError(t, sk.VerifyKey(ctx, proof))
+
+	require.ErrorIs(t, sk.VerifyKey([]byte("other session"), proof), ErrInvalidProof)
+	require.ErrorIs(t, sk.VerifyKey(ctx, proof[1:]), ErrInvalidProof)
+	proof[0] = new(big.Int).Add(proof[0], one)
+	require.ErrorIs(t, sk.VerifyKey(ctx, proof), ErrInvalidProof)
+
+	// a modulus with a small factor
+	pk := &PublicKey{N: new(big.Int).Mul(sk.P, big.NewInt(101))}
+	require.ErrorIs(t, pk.VerifyKey(ctx, proof), ErrInvalidProof)
+	pk = &PublicKey{N: new(big.Int).Lsh(sk.N, 1)}
+	require.ErrorIs(t, pk.VerifyKey(ctx, proof), ErrInvalidProof)
+}
@@ -50,7 +50,8 @@ func Test_Example_Neff_Shuffle_Simple(t *testing.T) {
 // sequences. There is also a difference when getting the prover. In this
 // variation the Shuffle function doesn't directly return a prover, but a
 // function to get it. This is because the verifier must provide a slice of
-// random numbers to the prover.
+// random numbers to the prover. In a non-interactive setting, they are
+// obtained from SequencesChallenge.
 func Test_Example_Neff_Shuffle_Sequence(t *testing.T) {
 	sequenceLen := 3
 	numSequences := 3
@@ -75,12 +76,10 @@ func Test_Example_Neff_Shuffle_Sequence(t *testing.T) {
 	// shuffle sequences
 	XX, YY, getProver := shuffle.SequencesShuffle(suite, nil, nil, X, Y, suite.RandomStream())
 
-	// compute the proof
-	NQ := len(X)
-	e := make([]kyber.Scalar, NQ)
-	for j := 0; j < NQ; j++ {
-		e[j] = suite.Scalar().Pick(suite.RandomStream())
-	}
+	// compute the proof, with the random numbers derived from the
+	// shuffle inputs and outputs
+	e, err := shuffle.SequencesChallenge(suite, nil, nil, X, Y, XX, YY)
+	require.NoError(t, err)
 
 	prover, err := getProver(e)
 	require.NoError(t, err)
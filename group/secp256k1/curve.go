@@ -0,0 +1,83 @@
+package secp256k1
+
+import (
+	"crypto/elliptic"
+	"math/big"
+
+	gnark "github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fp"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
+)
+
+var params = func() *elliptic.CurveParams {
+	_, g := gnark.Generators()
+	return &elliptic.CurveParams{
+		P:       fp.Modulus(),
+		N:       fr.Modulus(),
+		B:       big.NewInt(7),
+		Gx:      g.X.BigInt(new(big.Int)),
+		Gy:      g.Y.BigInt(new(big.Int)),
+		BitSize: 256,
+		Name:    "secp256k1",
+	}
+}()
+
+// curve implements elliptic.Curve for secp256k1. The generic implementation
+// of elliptic.CurveParams only handles curves with a = -3.
+type curve struct{}
+
+// S256 returns an elliptic.Curve implementing secp256k1.
+func S256() elliptic.Curve {
+	return curve{}
+}
+
+func (curve) Params() *elliptic.CurveParams {
+	return params
+}
+
+func (curve) IsOnCurve(x, y *big.Int) bool {
+	p, ok := toAffine(x, y)
+	return ok && !p.IsInfinity() && p.IsOnCurve()
+}
+
+func (curve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	p1, _ := toAffine(x1, y1)
+	p2, _ := toAffine(x2, y2)
+	return fromAffine(new(gnark.G1Affine).Add(&p1, &p2))
+}
+
+func (curve) Double(x, y *big.Int) (*big.Int, *big.Int) {
+	p, _ := toAffine(x, y)
+	return fromAffine(new(gnark.G1Affine).Double(&p))
+}
+
+// ScalarMult returns k*(x, y). It's not constant time.
+func (curve) ScalarMult(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	p, _ := toAffine(x, y)
+	s := new(big.Int).SetBytes(k)
+	s.Mod(s, params.N)
+	return fromAffine(new(gnark.G1Affine).ScalarMultiplication(&p, s))
+}
+
+// ScalarBaseMult returns k times the base point. It's not constant time.
+func (curve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	s := new(big.Int).SetBytes(k)
+	s.Mod(s, params.N)
+	return fromAffine(new(gnark.G1Affine).ScalarMultiplicationBase(s))
+}
+
+// toAffine converts affine coordinates, (0, 0) being the point at infinity.
+// It returns false if a coordinate is out of the field.
+func toAffine(x, y *big.Int) (gnark.G1Affine, bool) {
+	var p gnark.G1Affine
+	if x.Sign() < 0 || y.Sign() < 0 || x.Cmp(params.P) >= 0 || y.Cmp(params.P) >= 0 {
+		return p, false
+	}
+	p.X.SetBigInt(x)
+	p.Y.SetBigInt(y)
+	return p, true
+}
+
+func fromAffine(p *gnark.G1Affine) (*big.Int, *big.Int) {
+	return p.X.BigInt(new(big.Int)), p.Y.BigInt(new(big.Int))
+}
@@ -0,0 +1,16 @@
+// Package secp256k1 implements the kyber.Group interface for the secp256k1
+// elliptic curve of SEC 2, used by Bitcoin and Ethereum, on top of the field
+// and curve arithmetic of gnark-crypto.
+//
+// The group also implements crypto/elliptic's Curve interface, so that
+// public keys and signatures on secp256k1 can be handled with crypto/ecdsa.
+//
+// The scalar multiplications, Point.Mul and the ScalarMult and ScalarBaseMult
+// methods of the curve, are variable time: they use the big.Int scalars and
+// the windowed algorithms of gnark-crypto, whose running time depends on the
+// scalar. They leak information about secret scalars through timing, so the
+// group should only be used where an attacker can't measure the time of the
+// operations on secrets, for example to verify signatures. The suite is
+// registered in package suites as a prime order group, but not as a constant
+// time one.
+package secp256k1
@@ -0,0 +1,38 @@
+package secp256k1
+
+import (
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/mod"
+)
+
+// group implements the kyber.Group interface for secp256k1. It embeds the
+// elliptic.Curve implementation.
+type group struct {
+	curve
+}
+
+func (g *group) String() string {
+	return "secp256k1"
+}
+
+// ScalarLen returns the number of bytes in the encoding of a Scalar.
+func (g *group) ScalarLen() int {
+	return 32
+}
+
+// Scalar creates a Scalar modulo the order of the curve. As for the NIST
+// curves, its SetBytes method interprets the bytes as a big-endian integer.
+func (g *group) Scalar() kyber.Scalar {
+	return mod.NewInt64(0, params.N)
+}
+
+// PointLen returns the number of bytes in the uncompressed ANSI X9.62
+// encoding of a Point.
+func (g *group) PointLen() int {
+	return 1 + 2*32
+}
+
+// Point creates a Point set to the point at infinity.
+func (g *group) Point() kyber.Point {
+	return new(point)
+}
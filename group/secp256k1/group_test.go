@@ -0,0 +1,89 @@
+package secp256k1
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/util/test"
+)
+
+var testSuite = NewBlakeSHA256Secp256k1()
+
+func TestSecp256k1(t *testing.T) { test.SuiteTest(t, testSuite) }
+
+// multiples of the base point, from
+// https://chuckbatson.wordpress.com/2014/11/26/secp256k1-test-vectors/
+var baseMultiples = []struct{ k, x, y string }{
+	{
+		"1",
+		"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+	},
+	{
+		"2",
+		"c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+		"1ae168fea63dc339a3c58419466ceaeef7f632653266d0e1236431a950cfe52a",
+	},
+	{
+		"20",
+		"4ce119c96e2fa357200b559b2f7dd5a5f02d5290aff74b03f3e471b273211c97",
+		"12ba26dcb10ec1625da61fa10a844c676162948271d96967450288ee9233dc3a",
+	},
+	{
+		"115792089237316195423570985008687907852837564279074904382605163141518161494336",
+		"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		"b7c52588d95c3b9aa25b0403f1eef75702e84bb7597aabe663b82f6f04ef2777",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range baseMultiples {
+		k, ok := new(big.Int).SetString(v.k, 10)
+		require.True(t, ok)
+		s := testSuite.Scalar().SetBytes(k.Bytes())
+		buf, err := testSuite.Point().Mul(s, nil).MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, "04"+v.x+v.y, hex.EncodeToString(buf), "k = %s", v.k)
+
+		x, y := S256().ScalarBaseMult(k.Bytes())
+		require.Equal(t, v.x+v.y, hex.EncodeToString(append(x.FillBytes(make([]byte, 32)), y.FillBytes(make([]byte, 32))...)))
+		require.True(t, S256().IsOnCurve(x, y))
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	buf, err := testSuite.Point().Base().MarshalBinary()
+	require.NoError(t, err)
+	p := testSuite.Point()
+	require.NoError(t, p.UnmarshalBinary(buf))
+	require.True(t, p.Equal(testSuite.Point().Base()))
+
+	buf[len(buf)-1] ^= 1
+	require.Error(t, p.UnmarshalBinary(buf))
+	require.Error(t, p.UnmarshalBinary(buf[:33]))
+
+	null, err := testSuite.Point().Null().MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, p.UnmarshalBinary(null))
+	require.True(t, p.Equal(testSuite.Point().Null()))
+}
+
+func TestECDSA(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(S256(), rand.Reader)
+	require.NoError(t, err)
+	h := sha256.Sum256([]byte("secp256k1"))
+	r, s, err := ecdsa.Sign(rand.Reader, priv, h[:])
+	require.NoError(t, err)
+	require.True(t, ecdsa.Verify(&priv.PublicKey, h[:], r, s))
+
+	// the public key computed with the group matches
+	pub := testSuite.Point().Mul(testSuite.Scalar().SetBytes(priv.D.Bytes()), nil)
+	x, y := fromAffine(&pub.(*point).p)
+	require.Equal(t, priv.X, x)
+	require.Equal(t, priv.Y, y)
+}
@@ -0,0 +1,173 @@
+package secp256k1
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	gnark "github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fp"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/internal/marshalling"
+	"go.dedis.ch/kyber/v4/group/mod"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// point is a point of secp256k1 in affine coordinates, (0, 0) being the
+// point at infinity.
+type point struct {
+	p gnark.G1Affine
+}
+
+func (P *point) String() string {
+	x, y := fromAffine(&P.p)
+	return "(" + x.String() + "," + y.String() + ")"
+}
+
+func (P *point) Equal(P2 kyber.Point) bool {
+	return P.p.Equal(&P2.(*point).p) //nolint:errcheck // Design pattern to emulate generics
+}
+
+func (P *point) Null() kyber.Point {
+	P.p.X.SetZero()
+	P.p.Y.SetZero()
+	return P
+}
+
+func (P *point) Base() kyber.Point {
+	_, P.p = gnark.Generators()
+	return P
+}
+
+func (P *point) Pick(rand cipher.Stream) kyber.Point {
+	return P.Embed(nil, rand)
+}
+
+func (P *point) Set(A kyber.Point) kyber.Point {
+	P.p = A.(*point).p //nolint:errcheck // Design pattern to emulate generics
+	return P
+}
+
+func (P *point) Clone() kyber.Point {
+	return &point{p: P.p}
+}
+
+// EmbedLen reserves the 8 most significant bits of the x-coordinate for
+// randomness, and the 8 least significant bits for the length of the data.
+func (P *point) EmbedLen() int {
+	return (256 - 8 - 8) / 8
+}
+
+// Embed picks a point with the data embedded in its x-coordinate, the other
+// bits being chosen randomly.
+func (P *point) Embed(data []byte, rand cipher.Stream) kyber.Point {
+	dl := P.EmbedLen()
+	if dl > len(data) {
+		dl = len(data)
+	}
+	for {
+		b := random.Bits(256, false, rand)
+		if data != nil {
+			b[fp.Bytes-1] = byte(dl)
+			copy(b[fp.Bytes-dl-1:fp.Bytes-1], data)
+		}
+		var x, y, y2 fp.Element
+		if x.SetBytesCanonical(b) != nil {
+			continue
+		}
+		y2.Square(&x).Mul(&y2, &x).Add(&y2, &bCoeff)
+		if y.Sqrt(&y2) == nil {
+			continue
+		}
+		// pick the sign of y randomly
+		var sign [1]byte
+		rand.XORKeyStream(sign[:], sign[:])
+		if sign[0]&0x80 != 0 {
+			y.Neg(&y)
+		}
+		P.p.X, P.p.Y = x, y
+		return P
+	}
+}
+
+// Data extracts the data embedded in the point.
+func (P *point) Data() ([]byte, error) {
+	b := P.p.X.Bytes()
+	dl := int(b[fp.Bytes-1])
+	if dl > P.EmbedLen() {
+		return nil, errors.New("invalid embedded data length")
+	}
+	return b[fp.Bytes-dl-1 : fp.Bytes-1], nil
+}
+
+func (P *point) Add(A, B kyber.Point) kyber.Point {
+	P.p.Add(&A.(*point).p, &B.(*point).p) //nolint:errcheck // Design pattern to emulate generics
+	return P
+}
+
+func (P *point) Sub(A, B kyber.Point) kyber.Point {
+	P.p.Sub(&A.(*point).p, &B.(*point).p) //nolint:errcheck // Design pattern to emulate generics
+	return P
+}
+
+func (P *point) Neg(A kyber.Point) kyber.Point {
+	P.p.Neg(&A.(*point).p) //nolint:errcheck // Design pattern to emulate generics
+	return P
+}
+
+// Mul sets P to s*B, or to s times the base point if B is nil. It's not
+// constant time.
+func (P *point) Mul(s kyber.Scalar, B kyber.Point) kyber.Point {
+	v := &s.(*mod.Int).V //nolint:errcheck // Design pattern to emulate generics
+	if B == nil {
+		P.p.ScalarMultiplicationBase(v)
+	} else {
+		P.p.ScalarMultiplication(&B.(*point).p, v) //nolint:errcheck // Design pattern to emulate generics
+	}
+	return P
+}
+
+// MarshalSize returns the size of the uncompressed ANSI X9.62 representation.
+func (P *point) MarshalSize() int {
+	return 1 + 2*fp.Bytes
+}
+
+// MarshalBinary returns the uncompressed ANSI X9.62 representation of the
+// point, with both coordinates zero for the point at infinity.
+func (P *point) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, P.MarshalSize())
+	buf[0] = 4
+	x, y := P.p.X.Bytes(), P.p.Y.Bytes()
+	copy(buf[1:], x[:])
+	copy(buf[1+fp.Bytes:], y[:])
+	return buf, nil
+}
+
+func (P *point) UnmarshalBinary(buf []byte) error {
+	if len(buf) != P.MarshalSize() || buf[0] != 4 {
+		return errors.New("invalid elliptic curve point encoding")
+	}
+	var p gnark.G1Affine
+	if p.X.SetBytesCanonical(buf[1:1+fp.Bytes]) != nil ||
+		p.Y.SetBytesCanonical(buf[1+fp.Bytes:]) != nil {
+		return errors.New("invalid elliptic curve point")
+	}
+	if !p.IsInfinity() && !p.IsOnCurve() {
+		return errors.New("invalid elliptic curve point")
+	}
+	P.p = p
+	return nil
+}
+
+func (P *point) MarshalTo(w io.Writer) (int, error) {
+	return marshalling.PointMarshalTo(P, w)
+}
+
+func (P *point) UnmarshalFrom(r io.Reader) (int, error) {
+	return marshalling.PointUnmarshalFrom(P, r)
+}
+
+var bCoeff = func() fp.Element {
+	_, b := gnark.CurveCoefficients()
+	return b
+}()
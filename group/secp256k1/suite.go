@@ -0,0 +1,56 @@
+package secp256k1
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"reflect"
+
+	"go.dedis.ch/fixbuf"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/internal/marshalling"
+	"go.dedis.ch/kyber/v4/util/random"
+	"go.dedis.ch/kyber/v4/xof/blake2xb"
+)
+
+// Suite is the suite for the secp256k1 curve.
+type Suite struct {
+	group
+}
+
+// Hash returns the instance associated with the suite
+func (s *Suite) Hash() hash.Hash {
+	return sha256.New()
+}
+
+// XOF creates the XOF associated with the suite
+func (s *Suite) XOF(key []byte) kyber.XOF {
+	return blake2xb.New(key)
+}
+
+// RandomStream returns a cipher.Stream that returns a key stream
+// from crypto/rand.
+func (s *Suite) RandomStream() cipher.Stream {
+	return random.New()
+}
+
+func (s *Suite) Read(r io.Reader, objs ...interface{}) error {
+	return fixbuf.Read(r, s, objs...)
+}
+
+func (s *Suite) Write(w io.Writer, objs ...interface{}) error {
+	return fixbuf.Write(w, objs...)
+}
+
+// New implements the kyber.encoding interface
+func (s *Suite) New(t reflect.Type) interface{} {
+	return marshalling.GroupNew(s, t)
+}
+
+// NewBlakeSHA256Secp256k1 returns a cipher suite based on package
+// go.dedis.ch/kyber/v4/xof/blake2xb, SHA-256, and the secp256k1 elliptic
+// curve. It returns random streams from Go's crypto/rand.
+func NewBlakeSHA256Secp256k1() *Suite {
+	return new(Suite)
+}
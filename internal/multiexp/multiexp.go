@@ -0,0 +1,109 @@
+// Package multiexp computes multi-scalar multiplications sum s_i*P_i over any
+// kyber.Group, which is the main cost when verifying the vector commitments
+// used by bulletproofs, shuffle and ring signature arguments.
+//
+// It is only meant to be used on public values: the running time depends on
+// the scalars.
+package multiexp
+
+import (
+	"math/big"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+// MultiExp returns sum scalars[i]*points[i], computed with Pippenger's bucket
+// method. It panics if the two slices have different lengths.
+func MultiExp(g kyber.Group, scalars []kyber.Scalar, points []kyber.Point) kyber.Point {
+	if len(scalars) != len(points) {
+		panic("multiexp: scalars and points have different lengths")
+	}
+	res := g.Point().Null()
+	if len(points) == 0 {
+		return res
+	}
+	if len(points) < 4 {
+		for i := range points {
+			res.Add(res, g.Point().Mul(scalars[i], points[i]))
+		}
+		return res
+	}
+
+	ints := make([]*big.Int, len(scalars))
+	maxBits := 0
+	for i, s := range scalars {
+		ints[i] = toBig(s)
+		if l := ints[i].BitLen(); l > maxBits {
+			maxBits = l
+		}
+	}
+
+	c := window(len(points))
+	buckets := make([]kyber.Point, 1<<c)
+	for w := (maxBits + c - 1) / c; w >= 0; w-- {
+		for j := 0; j < c; j++ {
+			res.Add(res, res)
+		}
+		for j := range buckets {
+			buckets[j] = nil
+		}
+		for i, s := range ints {
+			idx := bits(s, w*c, c)
+			if idx == 0 {
+				continue
+			}
+			if buckets[idx] == nil {
+				buckets[idx] = g.Point().Set(points[i])
+			} else {
+				buckets[idx].Add(buckets[idx], points[i])
+			}
+		}
+		// sum_j j*buckets[j] computed as a sum of running sums
+		running := g.Point().Null()
+		acc := g.Point().Null()
+		for j := len(buckets) - 1; j > 0; j-- {
+			if buckets[j] != nil {
+				running.Add(running, buckets[j])
+			}
+			acc.Add(acc, running)
+		}
+		res.Add(res, acc)
+	}
+	return res
+}
+
+// window returns the bucket window size in bits for n points.
+func window(n int) int {
+	switch {
+	case n < 32:
+		return 3
+	case n < 256:
+		return 5
+	case n < 4096:
+		return 7
+	default:
+		return 9
+	}
+}
+
+// bits returns the c bits of s starting at position start.
+func bits(s *big.Int, start, c int) int {
+	res := 0
+	for j := c - 1; j >= 0; j-- {
+		res = res<<1 | int(s.Bit(start+j))
+	}
+	return res
+}
+
+func toBig(s kyber.Scalar) *big.Int {
+	buf, err := s.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	if s.ByteOrder() == kyber.LittleEndian {
+		for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+			buf[i], buf[j] = buf[j], buf[i]
+		}
+	}
+	return new(big.Int).SetBytes(buf)
+}
@@ -0,0 +1,30 @@
+package multiexp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/group/p256"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+func TestMultiExp(t *testing.T) {
+	for _, g := range []kyber.Group{edwards25519.NewBlakeSHA256Ed25519(), p256.NewBlakeSHA256P256()} {
+		for _, n := range []int{0, 1, 3, 17, 100} {
+			scalars := make([]kyber.Scalar, n)
+			points := make([]kyber.Point, n)
+			exp := g.Point().Null()
+			for i := range scalars {
+				scalars[i] = g.Scalar().Pick(random.New())
+				if i%5 == 0 {
+					scalars[i].SetInt64(int64(i))
+				}
+				points[i] = g.Point().Pick(random.New())
+				exp.Add(exp, g.Point().Mul(scalars[i], points[i]))
+			}
+			require.True(t, exp.Equal(MultiExp(g, scalars, points)), "%s n=%d", g, n)
+		}
+	}
+}
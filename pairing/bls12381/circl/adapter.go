@@ -45,5 +45,5 @@ func (s *SuiteBLS12381) ScalarLen() int {
 
 // String returns the name of the suite
 func (s *SuiteBLS12381) String() string {
-	return "circl.adapter"
+	return "bls12381-circl"
 }
@@ -24,5 +24,5 @@ func TestAdapter_SuiteBLS12381(t *testing.T) {
 	err = privhex.UnmarshalBinary(privkey)
 	require.Nil(t, err)
 
-	require.Equal(t, "circl.adapter", suite.String())
+	require.Equal(t, "bls12381-circl", suite.String())
 }
@@ -1,3 +1,12 @@
+// Package circl implements the BLS12-381 pairing suite with the
+// github.com/cloudflare/circl backend, along with SuiteBLS12381, an adapter
+// to the suites.Suite interface.
+//
+// The suite is named "bls12381-circl", in the registry of the pairing package
+// and by the String methods of Suite and SuiteBLS12381, so that the two
+// BLS12-381 backends have distinct names. Suite.String used to return
+// "bls12381" and SuiteBLS12381.String "circl.adapter"; the latter is still
+// accepted as an alias by suites.Find.
 package circl
 
 import (
@@ -15,11 +24,15 @@ import (
 
 var _ pairing.Suite = Suite{}
 
+func init() {
+	pairing.MustRegister("bls12381-circl", func() pairing.Suite { return NewSuite() })
+}
+
 type Suite struct{}
 
 func NewSuite() (s Suite) { return }
 
-func (s Suite) String() string  { return "bls12381" }
+func (s Suite) String() string  { return "bls12381-circl" }
 func (s Suite) G1() kyber.Group { return G1 }
 func (s Suite) G2() kyber.Group { return G2 }
 func (s Suite) GT() kyber.Group { return GT }
@@ -1,3 +1,11 @@
+// Package kilic implements the BLS12-381 pairing suite with the
+// github.com/kilic/bls12-381 backend, along with SuiteBLS12381, an adapter to
+// the suites.Suite interface.
+//
+// The suite is named "bls12381-kilic", in the registry of the pairing package
+// and by the String methods of Suite and SuiteBLS12381, so that the two
+// BLS12-381 backends have distinct names. SuiteBLS12381.String used to return
+// "kilic.adapter", which is still accepted as an alias by suites.Find.
 package kilic
 
 import (
@@ -19,6 +27,10 @@ type Suite struct {
 	domainG2 []byte
 }
 
+func init() {
+	pairing.MustRegister("bls12381-kilic", func() pairing.Suite { return NewBLS12381Suite() })
+}
+
 // NewBLS12381Suite is the same as calling NewBLS12381SuiteWithDST(nil, nil): it uses the default domain separation
 // tags for its Hash To Curve functions.
 func NewBLS12381Suite() pairing.Suite {
@@ -31,6 +43,12 @@ func NewBLS12381SuiteWithDST(DomainG1, DomainG2 []byte) pairing.Suite {
 	return &Suite{domainG1: DomainG1, domainG2: DomainG2}
 }
 
+// String returns the name of the suite in the registry of the pairing
+// package.
+func (s *Suite) String() string {
+	return "bls12381-kilic"
+}
+
 func (s *Suite) SetDomainG1(dst []byte) {
 	s.domainG1 = dst
 }
@@ -24,6 +24,7 @@ import (
 
 	"go.dedis.ch/fixbuf"
 	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/pairing"
 	"go.dedis.ch/kyber/v4/util/random"
 	"go.dedis.ch/kyber/v4/xof/blake2xb"
 	"golang.org/x/crypto/sha3"
@@ -45,6 +46,10 @@ func newDefaultDomainG2() []byte {
 	return []byte("BN254G2_XMD:KECCAK-256_SVDW_RO_")
 }
 
+func init() {
+	pairing.MustRegister("bn254", func() pairing.Suite { return NewSuite() })
+}
+
 // NewSuite generates and returns a new BN254 pairing suite.
 func NewSuite() *Suite {
 	s := &Suite{commonSuite: &commonSuite{}}
@@ -25,6 +25,7 @@ import (
 
 	"go.dedis.ch/fixbuf"
 	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/pairing"
 	"go.dedis.ch/kyber/v4/util/random"
 	"go.dedis.ch/kyber/v4/xof/blake2xb"
 )
@@ -37,6 +38,10 @@ type Suite struct {
 	gt *groupGT
 }
 
+func init() {
+	pairing.MustRegister("bn256", func() pairing.Suite { return NewSuite() })
+}
+
 // NewSuite generates and returns a new BN256 pairing suite.
 func NewSuite() *Suite {
 	s := &Suite{commonSuite: &commonSuite{}}
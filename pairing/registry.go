@@ -0,0 +1,78 @@
+package pairing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The pairing suites of Kyber register themselves under the name returned by
+// their String method when their package is imported, like database/sql
+// drivers: "bn256", "bn254", "bls12381-kilic" and "bls12381-circl". Importing
+// go.dedis.ch/kyber/v4/suites imports all of them.
+
+// Factory creates a pairing suite. It is called by Find for every lookup.
+type Factory func() Suite
+
+var (
+	mu     sync.RWMutex
+	suites = map[string]Factory{}
+)
+
+var (
+	// ErrUnknownSuite is returned by Find when no pairing suite is registered
+	// under the name.
+	ErrUnknownSuite = errors.New("pairing: unknown suite")
+	// ErrDuplicateSuite is returned when registering a pairing suite under a
+	// name which is already registered.
+	ErrDuplicateSuite = errors.New("pairing: suite already registered")
+)
+
+// Register makes the pairing suite created by factory known under the name,
+// which is case-insensitive.
+func Register(name string, factory Factory) error {
+	if name == "" || factory == nil {
+		return errors.New("pairing: empty name or nil factory")
+	}
+	name = strings.ToLower(name)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := suites[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateSuite, name)
+	}
+	suites[name] = factory
+	return nil
+}
+
+// MustRegister is like Register but panics on errors. It is meant for the
+// init functions of the packages of pairing suites.
+func MustRegister(name string, factory Factory) {
+	if err := Register(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// Find looks up a pairing suite by name.
+func Find(name string) (Suite, error) {
+	mu.RLock()
+	factory, ok := suites[strings.ToLower(name)]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSuite, name)
+	}
+	return factory(), nil
+}
+
+// Names returns the sorted names of the registered pairing suites.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
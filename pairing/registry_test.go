@@ -0,0 +1,31 @@
+package pairing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	// the suites of Kyber can't be imported here, their packages importing
+	// this one: a nil suite stands for them
+	factory := func() Suite { return nil }
+	require.NoError(t, Register("Test-Suite", factory))
+	defer func() {
+		mu.Lock()
+		delete(suites, "test-suite")
+		mu.Unlock()
+	}()
+
+	_, err := Find("test-SUITE")
+	require.NoError(t, err)
+	require.Contains(t, Names(), "test-suite")
+
+	require.ErrorIs(t, Register("test-suite", factory), ErrDuplicateSuite)
+	require.Panics(t, func() { MustRegister("test-suite", factory) })
+	require.Error(t, Register("", factory))
+	require.Error(t, Register("nil", nil))
+
+	_, err = Find("unknown")
+	require.ErrorIs(t, err, ErrUnknownSuite)
+}
@@ -0,0 +1,134 @@
+// Package bulletproofs implements the range proofs of Bünz et al.
+// (https://eprint.iacr.org/2017/1066.pdf) over Pedersen commitments
+//
+//	V = v*G + gamma*H
+//
+// A range proof shows that the committed value v lies in [0, 2^n) without
+// revealing it nor gamma. Several commitments can be proven at once with an
+// aggregated proof, whose size grows logarithmically with the total number of
+// bits, and several proofs can be checked together with BatchVerify.
+//
+// The proofs are made non-interactive with the Fiat-Shamir heuristic, using
+// a transcript from the proof/transcript package. They work on any prime-order
+// kyber.Group: the commitment generators are derived from the suite XOF, so
+// that nobody knows their discrete logarithms relative to each other.
+package bulletproofs
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+// Suite wraps the functionalities needed by the bulletproofs package.
+type Suite interface {
+	kyber.Group
+	kyber.XOFFactory
+	kyber.Random
+}
+
+var ErrInvalidProof = errors.New("invalid proof")
+var ErrInvalidParameters = errors.New("invalid parameters")
+
+// Generators holds the group elements the proofs are computed over.
+// G and H are the Pedersen commitment bases, and Gs and Hs are the vector
+// bases of the inner-product argument. A range proof for m values of n bits
+// needs at least n*m vector bases.
+type Generators struct {
+	G  kyber.Point
+	H  kyber.Point
+	Gs []kyber.Point
+	Hs []kyber.Point
+}
+
+// NewGenerators returns generators with capacity vector bases. G is the
+// standard base point of the group and the other points are derived from the
+// suite XOF with domain separated seeds, so they are the same on every call.
+func NewGenerators(suite Suite, capacity int) *Generators {
+	gens := &Generators{
+		G:  suite.Point().Base(),
+		H:  derivePoint(suite, "H", 0),
+		Gs: make([]kyber.Point, capacity),
+		Hs: make([]kyber.Point, capacity),
+	}
+	for i := 0; i < capacity; i++ {
+		gens.Gs[i] = derivePoint(suite, "Gs", i)
+		gens.Hs[i] = derivePoint(suite, "Hs", i)
+	}
+	return gens
+}
+
+func derivePoint(suite Suite, label string, i int) kyber.Point {
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], uint32(i))
+	seed := append([]byte("bulletproofs generator "+label), idx[:]...)
+	return suite.Point().Pick(suite.XOF(seed))
+}
+
+// Commit returns the Pedersen commitment v*G + gamma*H.
+func (gens *Generators) Commit(suite Suite, v uint64, gamma kyber.Scalar) kyber.Point {
+	vG := suite.Point().Mul(scalarFromUint64(suite, v), gens.G)
+	gammaH := suite.Point().Mul(gamma, gens.H)
+	return vG.Add(vG, gammaH)
+}
+
+// scalarFromUint64 converts v to a scalar, including the values that do not
+// fit in an int64.
+func scalarFromUint64(suite Suite, v uint64) kyber.Scalar {
+	s := suite.Scalar().SetInt64(int64(v >> 1))
+	s.Add(s, s)
+	return s.Add(s, suite.Scalar().SetInt64(int64(v&1)))
+}
+
+func innerProduct(suite Suite, a, b []kyber.Scalar) kyber.Scalar {
+	res := suite.Scalar().Zero()
+	tmp := suite.Scalar()
+	for i := range a {
+		res.Add(res, tmp.Mul(a[i], b[i]))
+	}
+	return res
+}
+
+// powers returns [1, x, x^2, ..., x^(n-1)].
+func powers(suite Suite, x kyber.Scalar, n int) []kyber.Scalar {
+	res := make([]kyber.Scalar, n)
+	cur := suite.Scalar().One()
+	for i := range res {
+		res[i] = cur.Clone()
+		cur.Mul(cur, x)
+	}
+	return res
+}
+
+func sum(suite Suite, xs []kyber.Scalar) kyber.Scalar {
+	res := suite.Scalar().Zero()
+	for _, x := range xs {
+		res.Add(res, x)
+	}
+	return res
+}
+
+// commitVector returns sum a[i]*P[i] with constant time multiplications,
+// since a holds secret values of the prover.
+func commitVector(suite Suite, a []kyber.Scalar, P []kyber.Point) kyber.Point {
+	res := suite.Point().Null()
+	tmp := suite.Point()
+	for i := range a {
+		res.Add(res, tmp.Mul(a[i], P[i]))
+	}
+	return res
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+func log2(n int) int {
+	l := 0
+	for n > 1 {
+		n >>= 1
+		l++
+	}
+	return l
+}
@@ -0,0 +1,202 @@
+package bulletproofs
+
+import (
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/internal/multiexp"
+	"go.dedis.ch/kyber/v4/proof/transcript"
+)
+
+// InnerProductProof is a proof of knowledge of two vectors a and b such that
+//
+//	P = <a, Gs> + <b, Hs> + <a, b>*Q
+//
+// for public P, Q, Gs and Hs. Its size is logarithmic in the length of the
+// vectors: one (L, R) pair per halving round plus the two final scalars.
+type InnerProductProof struct {
+	L []kyber.Point
+	R []kyber.Point
+	A kyber.Scalar
+	B kyber.Scalar
+}
+
+// NewInnerProductProof proves the knowledge of a and b for the commitment
+// P = <a, Gs> + <b, Hs> + <a, b>*Q. The length of the vectors must be a power
+// of two.
+func NewInnerProductProof(suite Suite, Q kyber.Point, Gs, Hs []kyber.Point,
+	a, b []kyber.Scalar) (*InnerProductProof, error) {
+	n := len(a)
+	if !isPowerOfTwo(n) || len(b) != n || len(Gs) != n || len(Hs) != n {
+		return nil, fmt.Errorf("vector lengths must be the same power of two: %w", ErrInvalidParameters)
+	}
+	P := commitVector(suite, a, Gs)
+	P.Add(P, commitVector(suite, b, Hs))
+	P.Add(P, suite.Point().Mul(innerProduct(suite, a, b), Q))
+
+	tr := transcript.New(suite, "bulletproofs inner product")
+	if err := tr.AppendPoint("Q", Q); err != nil {
+		return nil, err
+	}
+	if err := tr.AppendPoint("P", P); err != nil {
+		return nil, err
+	}
+	return proveInnerProduct(suite, tr, Q, Gs, Hs, a, b)
+}
+
+// Verify checks the proof against the commitment P.
+func (p *InnerProductProof) Verify(suite Suite, Q kyber.Point, Gs, Hs []kyber.Point, P kyber.Point) error {
+	tr := transcript.New(suite, "bulletproofs inner product")
+	if err := tr.AppendPoint("Q", Q); err != nil {
+		return err
+	}
+	if err := tr.AppendPoint("P", P); err != nil {
+		return err
+	}
+	if len(Hs) != len(Gs) {
+		return fmt.Errorf("vector lengths differ: %w", ErrInvalidParameters)
+	}
+	sc, err := p.verificationScalars(suite, tr, len(Gs))
+	if err != nil {
+		return err
+	}
+
+	scalars := append(append([]kyber.Scalar{}, sc.gs...), sc.hs...)
+	points := append(append([]kyber.Point{}, Gs...), Hs...)
+	scalars = append(append(scalars, sc.l...), sc.r...)
+	points = append(append(points, p.L...), p.R...)
+	scalars = append(scalars, sc.q, suite.Scalar().One())
+	points = append(points, Q, P)
+	if !multiexp.MultiExp(suite, scalars, points).Equal(suite.Point().Null()) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// proveInnerProduct runs the halving rounds of the argument. The transcript
+// must already be bound to the statement.
+func proveInnerProduct(suite Suite, tr *transcript.Transcript, Q kyber.Point, Gs, Hs []kyber.Point,
+	a, b []kyber.Scalar) (*InnerProductProof, error) {
+	n := len(a)
+	G := append([]kyber.Point{}, Gs...)
+	H := append([]kyber.Point{}, Hs...)
+	a = cloneScalars(a)
+	b = cloneScalars(b)
+
+	rounds := log2(n)
+	proof := &InnerProductProof{
+		L: make([]kyber.Point, 0, rounds),
+		R: make([]kyber.Point, 0, rounds),
+	}
+	for ; n > 1; n /= 2 {
+		h := n / 2
+		cL := innerProduct(suite, a[:h], b[h:n])
+		cR := innerProduct(suite, a[h:n], b[:h])
+
+		L := commitVector(suite, a[:h], G[h:n])
+		L.Add(L, commitVector(suite, b[h:n], H[:h]))
+		L.Add(L, suite.Point().Mul(cL, Q))
+		R := commitVector(suite, a[h:n], G[:h])
+		R.Add(R, commitVector(suite, b[:h], H[h:n]))
+		R.Add(R, suite.Point().Mul(cR, Q))
+		proof.L = append(proof.L, L)
+		proof.R = append(proof.R, R)
+
+		if err := tr.AppendPoint("L", L); err != nil {
+			return nil, err
+		}
+		if err := tr.AppendPoint("R", R); err != nil {
+			return nil, err
+		}
+		u := tr.ChallengeScalar("u")
+		uInv := suite.Scalar().Inv(u)
+
+		tmp := suite.Scalar()
+		for i := 0; i < h; i++ {
+			// a' = u*a_lo + u^-1*a_hi and b' = u^-1*b_lo + u*b_hi
+			a[i] = suite.Scalar().Add(tmp.Mul(u, a[i]), suite.Scalar().Mul(uInv, a[h+i]))
+			b[i] = suite.Scalar().Add(tmp.Mul(uInv, b[i]), suite.Scalar().Mul(u, b[h+i]))
+			// G' = u^-1*G_lo + u*G_hi and H' = u*H_lo + u^-1*H_hi
+			G[i] = suite.Point().Add(suite.Point().Mul(uInv, G[i]), suite.Point().Mul(u, G[h+i]))
+			H[i] = suite.Point().Add(suite.Point().Mul(u, H[i]), suite.Point().Mul(uInv, H[h+i]))
+		}
+	}
+	proof.A = a[0]
+	proof.B = b[0]
+	return proof, nil
+}
+
+// ipaScalars are the scalars of the final check of an inner-product proof
+//
+//	P + sum(u_k^2*L_k + u_k^-2*R_k) - <a*s, Gs> - <b/s, Hs> - a*b*Q == 0
+//
+// where s_i is the product of u_k if the k-th bit of i (starting from the
+// most significant one) is set, and of u_k^-1 otherwise.
+type ipaScalars struct {
+	gs []kyber.Scalar // -a*s_i
+	hs []kyber.Scalar // -b/s_i
+	q  kyber.Scalar   // -a*b
+	l  []kyber.Scalar // u_k^2
+	r  []kyber.Scalar // u_k^-2
+}
+
+// verificationScalars replays the transcript of the proof for vectors of
+// length n and returns the scalars of the final check.
+func (p *InnerProductProof) verificationScalars(suite Suite, tr *transcript.Transcript, n int) (*ipaScalars, error) {
+	if !isPowerOfTwo(n) {
+		return nil, fmt.Errorf("vector length must be a power of two: %w", ErrInvalidParameters)
+	}
+	rounds := log2(n)
+	if len(p.L) != rounds || len(p.R) != rounds || p.A == nil || p.B == nil {
+		return nil, fmt.Errorf("malformed inner product proof: %w", ErrInvalidProof)
+	}
+
+	res := &ipaScalars{
+		gs: make([]kyber.Scalar, n),
+		hs: make([]kyber.Scalar, n),
+		l:  make([]kyber.Scalar, rounds),
+		r:  make([]kyber.Scalar, rounds),
+	}
+	u := make([]kyber.Scalar, rounds)
+	uInv := make([]kyber.Scalar, rounds)
+	for k := range u {
+		if p.L[k] == nil || p.R[k] == nil {
+			return nil, fmt.Errorf("malformed inner product proof: %w", ErrInvalidProof)
+		}
+		if err := tr.AppendPoint("L", p.L[k]); err != nil {
+			return nil, err
+		}
+		if err := tr.AppendPoint("R", p.R[k]); err != nil {
+			return nil, err
+		}
+		u[k] = tr.ChallengeScalar("u")
+		uInv[k] = suite.Scalar().Inv(u[k])
+		res.l[k] = suite.Scalar().Mul(u[k], u[k])
+		res.r[k] = suite.Scalar().Mul(uInv[k], uInv[k])
+	}
+
+	negA := suite.Scalar().Neg(p.A)
+	negB := suite.Scalar().Neg(p.B)
+	for i := 0; i < n; i++ {
+		s := suite.Scalar().One()
+		for k := 0; k < rounds; k++ {
+			if (i>>(rounds-1-k))&1 == 1 {
+				s.Mul(s, u[k])
+			} else {
+				s.Mul(s, uInv[k])
+			}
+		}
+		res.gs[i] = suite.Scalar().Mul(negA, s)
+		res.hs[i] = suite.Scalar().Div(negB, s)
+	}
+	res.q = suite.Scalar().Mul(negA, p.B)
+	return res, nil
+}
+
+func cloneScalars(xs []kyber.Scalar) []kyber.Scalar {
+	res := make([]kyber.Scalar, len(xs))
+	for i, x := range xs {
+		res[i] = x.Clone()
+	}
+	return res
+}
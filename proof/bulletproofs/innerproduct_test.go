@@ -0,0 +1,36 @@
+package bulletproofs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+)
+
+func TestInnerProductProof(t *testing.T) {
+	for _, suite := range testSuites {
+		n := 16
+		gens := NewGenerators(suite, n)
+		Q := suite.Point().Pick(suite.RandomStream())
+		a := make([]kyber.Scalar, n)
+		b := make([]kyber.Scalar, n)
+		for i := range a {
+			a[i] = suite.Scalar().Pick(suite.RandomStream())
+			b[i] = suite.Scalar().Pick(suite.RandomStream())
+		}
+		P := commitVector(suite, a, gens.Gs)
+		P.Add(P, commitVector(suite, b, gens.Hs))
+		P.Add(P, suite.Point().Mul(innerProduct(suite, a, b), Q))
+
+		proof, err := NewInnerProductProof(suite, Q, gens.Gs, gens.Hs, a, b)
+		require.NoError(t, err)
+		require.Len(t, proof.L, 4)
+		require.NoError(t, proof.Verify(suite, Q, gens.Gs, gens.Hs, P))
+
+		wrong := suite.Point().Add(P, Q)
+		require.ErrorIs(t, proof.Verify(suite, Q, gens.Gs, gens.Hs, wrong), ErrInvalidProof)
+
+		_, err = NewInnerProductProof(suite, Q, gens.Gs[:3], gens.Hs[:3], a[:3], b[:3])
+		require.ErrorIs(t, err, ErrInvalidParameters)
+	}
+}
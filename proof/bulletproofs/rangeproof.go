@@ -0,0 +1,415 @@
+package bulletproofs
+
+import (
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/internal/multiexp"
+	"go.dedis.ch/kyber/v4/proof/transcript"
+)
+
+// RangeProof proves that one or several Pedersen commitments V_j open to
+// values in [0, 2^n).
+type RangeProof struct {
+	// A commits to the bits of the values, S to the blinding vectors
+	A kyber.Point
+	S kyber.Point
+	// T1 and T2 commit to the coefficients of t(X)
+	T1 kyber.Point
+	T2 kyber.Point
+	// TauX blinds t(x), Mu blinds A and S, and THat = t(x)
+	TauX kyber.Scalar
+	Mu   kyber.Scalar
+	THat kyber.Scalar
+	// IPP proves that THat is the inner product of l(x) and r(x)
+	IPP *InnerProductProof
+}
+
+// ProveRange proves that v lies in [0, 2^n) and returns the proof along with
+// the commitment V = v*G + gamma*H it is bound to. n must be a power of two no
+// larger than 64.
+func ProveRange(suite Suite, gens *Generators, v uint64, gamma kyber.Scalar, n int) (
+	*RangeProof, kyber.Point, error) {
+	proof, V, err := ProveAggregatedRange(suite, gens, []uint64{v}, []kyber.Scalar{gamma}, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, V[0], nil
+}
+
+// VerifyRange checks a proof produced by ProveRange for the commitment V.
+func VerifyRange(suite Suite, gens *Generators, proof *RangeProof, V kyber.Point, n int) error {
+	return proof.Verify(suite, gens, []kyber.Point{V}, n)
+}
+
+// ProveAggregatedRange proves that every value lies in [0, 2^n) with a single
+// proof, and returns the commitments V_j = values[j]*G + gammas[j]*H it is
+// bound to. The number of values must be a power of two and the generators
+// must have at least n*len(values) vector bases.
+//
+//nolint:funlen // follows the steps of the protocol
+func ProveAggregatedRange(suite Suite, gens *Generators, values []uint64, gammas []kyber.Scalar, n int) (
+	*RangeProof, []kyber.Point, error) {
+	m := len(values)
+	if len(gammas) != m {
+		return nil, nil, fmt.Errorf("%d values but %d blinding factors: %w", m, len(gammas), ErrInvalidParameters)
+	}
+	if err := checkParameters(gens, n, m); err != nil {
+		return nil, nil, err
+	}
+	for _, v := range values {
+		if n < 64 && v>>n != 0 {
+			return nil, nil, fmt.Errorf("value %d is out of range: %w", v, ErrInvalidParameters)
+		}
+	}
+	nm := n * m
+	Gs := gens.Gs[:nm]
+	Hs := gens.Hs[:nm]
+	rand := suite.RandomStream()
+
+	V := make([]kyber.Point, m)
+	for j := range values {
+		V[j] = gens.Commit(suite, values[j], gammas[j])
+	}
+	tr, err := newRangeTranscript(suite, V, n)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A = alpha*H + <aL, Gs> + <aR, Hs> where aL are the bits of the values
+	// and aR = aL - 1
+	one := suite.Scalar().One()
+	aL := make([]kyber.Scalar, nm)
+	aR := make([]kyber.Scalar, nm)
+	for i := range aL {
+		bit := (values[i/n] >> (i % n)) & 1
+		aL[i] = suite.Scalar().SetInt64(int64(bit))
+		aR[i] = suite.Scalar().Sub(aL[i], one)
+	}
+	alpha := suite.Scalar().Pick(rand)
+	A := commitVector(suite, aL, Gs)
+	A.Add(A, commitVector(suite, aR, Hs))
+	A.Add(A, suite.Point().Mul(alpha, gens.H))
+
+	// S = rho*H + <sL, Gs> + <sR, Hs> for random sL and sR
+	sL := make([]kyber.Scalar, nm)
+	sR := make([]kyber.Scalar, nm)
+	for i := range sL {
+		sL[i] = suite.Scalar().Pick(rand)
+		sR[i] = suite.Scalar().Pick(rand)
+	}
+	rho := suite.Scalar().Pick(rand)
+	S := commitVector(suite, sL, Gs)
+	S.Add(S, commitVector(suite, sR, Hs))
+	S.Add(S, suite.Point().Mul(rho, gens.H))
+
+	if err := appendPoints(tr, []string{"A", "S"}, A, S); err != nil {
+		return nil, nil, err
+	}
+	y := tr.ChallengeScalar("y")
+	z := tr.ChallengeScalar("z")
+
+	// l(X) = (aL - z) + sL*X
+	// r(X) = y^i o (aR + z + sR*X) + z^(2+j)*2^(i mod n), with j = i / n
+	yPow := powers(suite, y, nm)
+	zz := zTwos(suite, z, n, m)
+	l0 := make([]kyber.Scalar, nm)
+	r0 := make([]kyber.Scalar, nm)
+	r1 := make([]kyber.Scalar, nm)
+	for i := range l0 {
+		l0[i] = suite.Scalar().Sub(aL[i], z)
+		r0[i] = suite.Scalar().Add(aR[i], z)
+		r0[i].Mul(r0[i], yPow[i]).Add(r0[i], zz[i])
+		r1[i] = suite.Scalar().Mul(yPow[i], sR[i])
+	}
+
+	// t(X) = <l(X), r(X)> = t0 + t1*X + t2*X^2
+	t1 := suite.Scalar().Add(innerProduct(suite, l0, r1), innerProduct(suite, sL, r0))
+	t2 := innerProduct(suite, sL, r1)
+	tau1 := suite.Scalar().Pick(rand)
+	tau2 := suite.Scalar().Pick(rand)
+	T1 := suite.Point().Add(suite.Point().Mul(t1, gens.G), suite.Point().Mul(tau1, gens.H))
+	T2 := suite.Point().Add(suite.Point().Mul(t2, gens.G), suite.Point().Mul(tau2, gens.H))
+
+	if err := appendPoints(tr, []string{"T1", "T2"}, T1, T2); err != nil {
+		return nil, nil, err
+	}
+	x := tr.ChallengeScalar("x")
+
+	l := make([]kyber.Scalar, nm)
+	r := make([]kyber.Scalar, nm)
+	for i := range l {
+		l[i] = suite.Scalar().Mul(sL[i], x)
+		l[i].Add(l[i], l0[i])
+		r[i] = suite.Scalar().Mul(r1[i], x)
+		r[i].Add(r[i], r0[i])
+	}
+	tHat := innerProduct(suite, l, r)
+	// taux = tau2*x^2 + tau1*x + sum z^(2+j)*gamma_j
+	tauX := suite.Scalar().Mul(tau2, x)
+	tauX.Add(tauX, tau1).Mul(tauX, x)
+	zPow := suite.Scalar().Mul(z, z)
+	for _, gamma := range gammas {
+		tauX.Add(tauX, suite.Scalar().Mul(zPow, gamma))
+		zPow.Mul(zPow, z)
+	}
+	mu := suite.Scalar().Mul(rho, x)
+	mu.Add(mu, alpha)
+
+	if err := appendScalars(tr, []string{"taux", "mu", "that"}, tauX, mu, tHat); err != nil {
+		return nil, nil, err
+	}
+	w := tr.ChallengeScalar("w")
+	Q := suite.Point().Mul(w, gens.G)
+
+	// The inner product argument is run over Hs' = y^-i*Hs, for which
+	// <l, Gs> + <r, Hs'> commits to l(x) and r(x)
+	yInv := suite.Scalar().Inv(y)
+	yInvPow := powers(suite, yInv, nm)
+	HsPrime := make([]kyber.Point, nm)
+	for i := range HsPrime {
+		HsPrime[i] = suite.Point().Mul(yInvPow[i], Hs[i])
+	}
+	ipp, err := proveInnerProduct(suite, tr, Q, Gs, HsPrime, l, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &RangeProof{
+		A:    A,
+		S:    S,
+		T1:   T1,
+		T2:   T2,
+		TauX: tauX,
+		Mu:   mu,
+		THat: tHat,
+		IPP:  ipp,
+	}, V, nil
+}
+
+// Verify checks that the proof shows every commitment of V opens to a value
+// in [0, 2^n).
+func (p *RangeProof) Verify(suite Suite, gens *Generators, V []kyber.Point, n int) error {
+	return BatchVerify(suite, gens, []*RangeProof{p}, [][]kyber.Point{V}, n)
+}
+
+// BatchVerify checks several range proofs at once, each of them against its
+// own list of commitments. All the verification equations are combined with
+// random weights into a single multi-exponentiation, which is much faster
+// than checking the proofs one by one. If the batch is invalid, the error
+// doesn't tell which proof failed.
+func BatchVerify(suite Suite, gens *Generators, proofs []*RangeProof, commits [][]kyber.Point, n int) error {
+	if len(proofs) != len(commits) {
+		return fmt.Errorf("%d proofs but %d lists of commitments: %w",
+			len(proofs), len(commits), ErrInvalidParameters)
+	}
+	maxNM := 0
+	for _, V := range commits {
+		if err := checkParameters(gens, n, len(V)); err != nil {
+			return err
+		}
+		if n*len(V) > maxNM {
+			maxNM = n * len(V)
+		}
+	}
+
+	v := &batchVerifier{
+		g:  suite.Scalar().Zero(),
+		h:  suite.Scalar().Zero(),
+		gs: make([]kyber.Scalar, maxNM),
+		hs: make([]kyber.Scalar, maxNM),
+	}
+	for i := range v.gs {
+		v.gs[i] = suite.Scalar().Zero()
+		v.hs[i] = suite.Scalar().Zero()
+	}
+	rand := suite.RandomStream()
+	for i, proof := range proofs {
+		weight := suite.Scalar().Pick(rand)
+		if err := proof.addTo(v, suite, gens, commits[i], n, weight); err != nil {
+			return err
+		}
+	}
+
+	scalars := append(v.scalars, v.g, v.h)
+	points := append(v.points, gens.G, gens.H)
+	scalars = append(append(scalars, v.gs...), v.hs...)
+	points = append(append(points, gens.Gs[:maxNM]...), gens.Hs[:maxNM]...)
+	if !multiexp.MultiExp(suite, scalars, points).Equal(suite.Point().Null()) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// batchVerifier accumulates the terms of the multi-exponentiation checking a
+// batch of proofs. The scalars of the generators shared by all proofs are
+// summed, the other terms are kept as separate entries.
+type batchVerifier struct {
+	g, h    kyber.Scalar
+	gs, hs  []kyber.Scalar
+	scalars []kyber.Scalar
+	points  []kyber.Point
+}
+
+func (b *batchVerifier) add(s kyber.Scalar, p kyber.Point) {
+	b.scalars = append(b.scalars, s)
+	b.points = append(b.points, p)
+}
+
+// addTo adds weight times the verification equations of the proof to the
+// batch. The two equations are
+//
+//	THat*G + TauX*H == sum z^(2+j)*V_j + delta(y,z)*G + x*T1 + x^2*T2
+//	A + x*S - z*<1, Gs> + <z + y^-i*z^(2+j)*2^(i mod n), Hs> - Mu*H + THat*Q
+//	  == the commitment checked by the inner product argument
+//
+// where the first one is multiplied by an additional random scalar c so that
+// both can be merged.
+//
+//nolint:funlen // follows the verification equations
+func (p *RangeProof) addTo(b *batchVerifier, suite Suite, gens *Generators, V []kyber.Point, n int,
+	weight kyber.Scalar) error {
+	if p.A == nil || p.S == nil || p.T1 == nil || p.T2 == nil ||
+		p.TauX == nil || p.Mu == nil || p.THat == nil || p.IPP == nil {
+		return fmt.Errorf("malformed range proof: %w", ErrInvalidProof)
+	}
+	m := len(V)
+	nm := n * m
+	tr, err := newRangeTranscript(suite, V, n)
+	if err != nil {
+		return err
+	}
+	if err := appendPoints(tr, []string{"A", "S"}, p.A, p.S); err != nil {
+		return err
+	}
+	y := tr.ChallengeScalar("y")
+	z := tr.ChallengeScalar("z")
+	if err := appendPoints(tr, []string{"T1", "T2"}, p.T1, p.T2); err != nil {
+		return err
+	}
+	x := tr.ChallengeScalar("x")
+	if err := appendScalars(tr, []string{"taux", "mu", "that"}, p.TauX, p.Mu, p.THat); err != nil {
+		return err
+	}
+	w := tr.ChallengeScalar("w")
+	ipa, err := p.IPP.verificationScalars(suite, tr, nm)
+	if err != nil {
+		return err
+	}
+
+	c := suite.Scalar().Pick(suite.RandomStream())
+	cw := suite.Scalar().Mul(c, weight)
+	yInv := suite.Scalar().Inv(y)
+	yInvPow := powers(suite, yInv, nm)
+	zz := zTwos(suite, z, n, m)
+
+	// delta(y,z) = (z - z^2)*<1, y^nm> - sum z^(3+j)*<1, 2^n>
+	z2 := suite.Scalar().Mul(z, z)
+	delta := suite.Scalar().Sub(z, z2)
+	delta.Mul(delta, sum(suite, powers(suite, y, nm)))
+	twoN := sum(suite, powers(suite, suite.Scalar().SetInt64(2), n))
+	zPow := suite.Scalar().Mul(z2, z)
+	for j := 0; j < m; j++ {
+		delta.Sub(delta, suite.Scalar().Mul(zPow, twoN))
+		zPow.Mul(zPow, z)
+	}
+
+	// G: c*(THat - delta) + w*(THat - a*b)
+	gCoef := suite.Scalar().Sub(p.THat, delta)
+	gCoef.Mul(gCoef, c)
+	wCoef := suite.Scalar().Add(p.THat, ipa.q)
+	gCoef.Add(gCoef, wCoef.Mul(wCoef, w))
+	b.g.Add(b.g, gCoef.Mul(gCoef, weight))
+	// H: c*TauX - Mu
+	hCoef := suite.Scalar().Mul(c, p.TauX)
+	hCoef.Sub(hCoef, p.Mu)
+	b.h.Add(b.h, hCoef.Mul(hCoef, weight))
+
+	// Gs: -z - a*s_i and Hs: z + y^-i*(zz_i - b/s_i)
+	negZ := suite.Scalar().Neg(z)
+	for i := 0; i < nm; i++ {
+		gi := suite.Scalar().Add(negZ, ipa.gs[i])
+		b.gs[i].Add(b.gs[i], gi.Mul(gi, weight))
+		hi := suite.Scalar().Add(zz[i], ipa.hs[i])
+		hi.Mul(hi, yInvPow[i]).Add(hi, z)
+		b.hs[i].Add(b.hs[i], hi.Mul(hi, weight))
+	}
+
+	b.add(weight, p.A)
+	b.add(suite.Scalar().Mul(x, weight), p.S)
+	negCW := suite.Scalar().Neg(cw)
+	b.add(suite.Scalar().Mul(negCW, x), p.T1)
+	b.add(suite.Scalar().Mul(negCW, suite.Scalar().Mul(x, x)), p.T2)
+	zPow = suite.Scalar().Mul(z2, negCW)
+	for _, Vj := range V {
+		b.add(zPow.Clone(), Vj)
+		zPow.Mul(zPow, z)
+	}
+	for k := range p.IPP.L {
+		b.add(suite.Scalar().Mul(ipa.l[k], weight), p.IPP.L[k])
+		b.add(suite.Scalar().Mul(ipa.r[k], weight), p.IPP.R[k])
+	}
+	return nil
+}
+
+func checkParameters(gens *Generators, n, m int) error {
+	if !isPowerOfTwo(n) || n > 64 {
+		return fmt.Errorf("bit length %d is not a power of two up to 64: %w", n, ErrInvalidParameters)
+	}
+	if !isPowerOfTwo(m) {
+		return fmt.Errorf("number of values %d is not a power of two: %w", m, ErrInvalidParameters)
+	}
+	if n*m > len(gens.Gs) || n*m > len(gens.Hs) {
+		return fmt.Errorf("generators capacity %d is below %d: %w", len(gens.Gs), n*m, ErrInvalidParameters)
+	}
+	return nil
+}
+
+// appendPoints appends the points with the given labels to the transcript.
+func appendPoints(tr *transcript.Transcript, labels []string, points ...kyber.Point) error {
+	for i, p := range points {
+		if err := tr.AppendPoint(labels[i], p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendScalars appends the scalars with the given labels to the transcript.
+func appendScalars(tr *transcript.Transcript, labels []string, scalars ...kyber.Scalar) error {
+	for i, s := range scalars {
+		if err := tr.AppendScalar(labels[i], s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newRangeTranscript(suite Suite, V []kyber.Point, n int) (*transcript.Transcript, error) {
+	tr := transcript.New(suite, "bulletproofs range proof")
+	tr.AppendUint64("n", uint64(n))
+	tr.AppendUint64("m", uint64(len(V)))
+	for _, Vj := range V {
+		if Vj == nil {
+			return nil, fmt.Errorf("nil commitment: %w", ErrInvalidParameters)
+		}
+		if err := tr.AppendPoint("V", Vj); err != nil {
+			return nil, err
+		}
+	}
+	return tr, nil
+}
+
+// zTwos returns the vector z^(2+j)*2^(i mod n) with j = i / n.
+func zTwos(suite Suite, z kyber.Scalar, n, m int) []kyber.Scalar {
+	twos := powers(suite, suite.Scalar().SetInt64(2), n)
+	res := make([]kyber.Scalar, 0, n*m)
+	zPow := suite.Scalar().Mul(z, z)
+	for j := 0; j < m; j++ {
+		for i := 0; i < n; i++ {
+			res = append(res, suite.Scalar().Mul(zPow, twos[i]))
+		}
+		zPow.Mul(zPow, z)
+	}
+	return res
+}
@@ -0,0 +1,119 @@
+package bulletproofs
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/group/p256"
+)
+
+var testSuites = []Suite{
+	edwards25519.NewBlakeSHA256Ed25519(),
+	p256.NewBlakeSHA256P256(),
+}
+
+func TestRangeProof(t *testing.T) {
+	for _, suite := range testSuites {
+		gens := NewGenerators(suite, 64)
+		for _, v := range []uint64{0, 1, 42, math.MaxUint32, math.MaxUint64} {
+			gamma := suite.Scalar().Pick(suite.RandomStream())
+			proof, V, err := ProveRange(suite, gens, v, gamma, 64)
+			require.NoError(t, err)
+			require.True(t, V.Equal(gens.Commit(suite, v, gamma)))
+			require.NoError(t, VerifyRange(suite, gens, proof, V, 64))
+
+			// the proof is bound to the commitment
+			other := gens.Commit(suite, v, suite.Scalar().Pick(suite.RandomStream()))
+			require.True(t, errors.Is(VerifyRange(suite, gens, proof, other, 64), ErrInvalidProof))
+		}
+	}
+}
+
+func TestRangeProofOutOfRange(t *testing.T) {
+	suite := testSuites[0]
+	gens := NewGenerators(suite, 64)
+	gamma := suite.Scalar().Pick(suite.RandomStream())
+	_, _, err := ProveRange(suite, gens, 1<<8, gamma, 8)
+	require.ErrorIs(t, err, ErrInvalidParameters)
+	_, _, err = ProveRange(suite, gens, 1, gamma, 12)
+	require.ErrorIs(t, err, ErrInvalidParameters)
+	_, _, err = ProveAggregatedRange(suite, gens, []uint64{1, 2}, []kyber.Scalar{gamma, gamma}, 64)
+	require.ErrorIs(t, err, ErrInvalidParameters)
+
+	// A proof for 8 bits doesn't verify as a proof for 16 bits
+	proof, V, err := ProveRange(suite, gens, 200, gamma, 8)
+	require.NoError(t, err)
+	require.NoError(t, VerifyRange(suite, gens, proof, V, 8))
+	require.Error(t, VerifyRange(suite, gens, proof, V, 16))
+}
+
+func TestRangeProofTampered(t *testing.T) {
+	suite := testSuites[0]
+	gens := NewGenerators(suite, 32)
+	gamma := suite.Scalar().Pick(suite.RandomStream())
+	proof, V, err := ProveRange(suite, gens, 12345, gamma, 32)
+	require.NoError(t, err)
+
+	tampered := *proof
+	tampered.THat = suite.Scalar().Add(proof.THat, suite.Scalar().One())
+	require.ErrorIs(t, VerifyRange(suite, gens, &tampered, V, 32), ErrInvalidProof)
+
+	tampered = *proof
+	tampered.A = suite.Point().Add(proof.A, gens.G)
+	require.ErrorIs(t, VerifyRange(suite, gens, &tampered, V, 32), ErrInvalidProof)
+
+	ipp := *proof.IPP
+	ipp.L = ipp.L[1:]
+	tampered = *proof
+	tampered.IPP = &ipp
+	require.ErrorIs(t, VerifyRange(suite, gens, &tampered, V, 32), ErrInvalidProof)
+}
+
+func TestAggregatedRangeProof(t *testing.T) {
+	for _, suite := range testSuites {
+		gens := NewGenerators(suite, 4*32)
+		values := []uint64{7, 0, 1<<32 - 1, 123456}
+		gammas := make([]kyber.Scalar, len(values))
+		for i := range gammas {
+			gammas[i] = suite.Scalar().Pick(suite.RandomStream())
+		}
+		proof, V, err := ProveAggregatedRange(suite, gens, values, gammas, 32)
+		require.NoError(t, err)
+		require.Len(t, proof.IPP.L, 7)
+		require.NoError(t, proof.Verify(suite, gens, V, 32))
+
+		V[0], V[1] = V[1], V[0]
+		require.ErrorIs(t, proof.Verify(suite, gens, V, 32), ErrInvalidProof)
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	suite := testSuites[0]
+	gens := NewGenerators(suite, 2*16)
+	var proofs []*RangeProof
+	var commits [][]kyber.Point
+	for i := 0; i < 4; i++ {
+		gamma := suite.Scalar().Pick(suite.RandomStream())
+		if i%2 == 0 {
+			proof, V, err := ProveRange(suite, gens, uint64(i*1000), gamma, 16)
+			require.NoError(t, err)
+			proofs = append(proofs, proof)
+			commits = append(commits, []kyber.Point{V})
+			continue
+		}
+		proof, V, err := ProveAggregatedRange(suite, gens, []uint64{uint64(i), 1<<16 - 1},
+			[]kyber.Scalar{gamma, gamma}, 16)
+		require.NoError(t, err)
+		proofs = append(proofs, proof)
+		commits = append(commits, V)
+	}
+	require.NoError(t, BatchVerify(suite, gens, proofs, commits, 16))
+
+	commits[2][0] = suite.Point().Add(commits[2][0], gens.G)
+	require.ErrorIs(t, BatchVerify(suite, gens, proofs, commits, 16), ErrInvalidProof)
+	require.ErrorIs(t, BatchVerify(suite, gens, proofs[:1], commits, 16), ErrInvalidParameters)
+}
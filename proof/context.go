@@ -53,3 +53,22 @@ type VerifierContext interface {
 	Get(message interface{}) error        // Receive message from prover
 	PubRand(message ...interface{}) error // Get public randomness
 }
+
+// ContextBinder is implemented by the contexts which derive the challenges
+// from a transcript, such as those of HashProve and HashVerify. Binding the
+// public statement of a proof to the transcript makes the challenges depend
+// on it, so that a proof cannot be reused for another statement.
+type ContextBinder interface {
+	BindContext(label string, data ...interface{}) error
+}
+
+// BindContext binds the data to the challenges of ctx, which can be a
+// ProverContext or a VerifierContext, if it implements ContextBinder.
+// Other contexts are left untouched: in an interactive proof the verifier
+// only picks its challenges after the statement is fixed.
+func BindContext(ctx interface{}, label string, data ...interface{}) error {
+	if b, ok := ctx.(ContextBinder); ok {
+		return b.BindContext(label, data...)
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 
 	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/proof/transcript"
 )
 
 // Suite wraps the functionalities needed by the dleq package.
@@ -35,7 +36,7 @@ type Proof struct {
 
 // NewDLEQProof computes a new NIZK dlog-equality proof for the scalar x with
 // respect to base points G and H. It therefore randomly selects a commitment v
-// and then computes the challenge c = H(xG,xH,vG,vH) and response r = v - cx.
+// and then computes the challenge c = H(G,H,xG,xH,vG,vH) and response r = v - cx.
 // Besides the proof, this function also returns the encrypted base points xG
 // and xH.
 func NewDLEQProof(
@@ -54,30 +55,12 @@ func NewDLEQProof(
 	vH := suite.Point().Mul(v, H)
 
 	// Challenge
-	hSuite := suite.Hash()
-	_, err = xG.MarshalTo(hSuite)
+	c, err := Challenge(suite, []kyber.Point{G}, []kyber.Point{H},
+		[]kyber.Point{xG}, []kyber.Point{xH}, []kyber.Point{vG}, []kyber.Point{vH})
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	_, err = xH.MarshalTo(hSuite)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	_, err = vG.MarshalTo(hSuite)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	_, err = vH.MarshalTo(hSuite)
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	cb := hSuite.Sum(nil)
-	c := suite.Scalar().Pick(suite.XOF(cb))
-
 	// Response
 	r := suite.Scalar()
 	r.Mul(x, c).Sub(v, r)
@@ -118,30 +101,10 @@ func NewDLEQProofBatch(
 	}
 
 	// Collective challenge
-	hSuite := suite.Hash()
-	for _, x := range xG {
-		if _, err := x.MarshalTo(hSuite); err != nil {
-			return nil, nil, nil, err
-		}
-	}
-	for _, x := range xH {
-		if _, err := x.MarshalTo(hSuite); err != nil {
-			return nil, nil, nil, err
-		}
-	}
-	for _, x := range vG {
-		if _, err := x.MarshalTo(hSuite); err != nil {
-			return nil, nil, nil, err
-		}
-	}
-	for _, x := range vH {
-		if _, err := x.MarshalTo(hSuite); err != nil {
-			return nil, nil, nil, err
-		}
+	c, err := Challenge(suite, G, H, xG, xH, vG, vH)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	cb := hSuite.Sum(nil)
-
-	c := suite.Scalar().Pick(suite.XOF(cb))
 
 	// Responses
 	for i, x := range secrets {
@@ -153,6 +116,26 @@ func NewDLEQProofBatch(
 	return proofs, xG, xH, nil
 }
 
+// Challenge returns the challenge of a batch of dlog-equality proofs. It is
+// derived from a transcript (see the proof/transcript package) of all the base
+// points, encrypted base points and commitments, in that order.
+func Challenge(suite Suite, G, H, xG, xH, vG, vH []kyber.Point) (kyber.Scalar, error) {
+	n := len(G)
+	if len(H) != n || len(xG) != n || len(xH) != n || len(vG) != n || len(vH) != n {
+		return nil, fmt.Errorf("invalid: %w", ErrDifferentLengths)
+	}
+	tr := transcript.New(suite, "dleq")
+	for _, in := range []struct {
+		label  string
+		points []kyber.Point
+	}{{"G", G}, {"H", H}, {"xG", xG}, {"xH", xH}, {"vG", vG}, {"vH", vH}} {
+		if err := tr.AppendPoints(in.label, in.points...); err != nil {
+			return nil, err
+		}
+	}
+	return tr.ChallengeScalar("c"), nil
+}
+
 // Verify examines the validity of the NIZK dlog-equality proof.
 // The proof is valid if the following two conditions hold:
 //
@@ -0,0 +1,255 @@
+package proof
+
+import (
+	"sort"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+// Names of the internal variables used to link OR-domains together.
+// They start with a NUL byte so as not to collide with the caller's names.
+const (
+	linkG = "\x00G"
+	linkH = "\x00H"
+)
+
+func linkCommitName(x string) string { return "\x00C:" + x }
+func linkBlindName(x string) string  { return "\x00r:" + x }
+
+// An orDomain is a maximal subtree of Rep and And predicates below an Or
+// branch (or at the root of the predicate), whose variables share the same
+// challenge and responses.
+type orDomain struct {
+	root Predicate
+	path []orBranch      // Or branches leading from the root to this domain
+	vars map[string]bool // Scalar variables used in this domain
+}
+
+type orBranch struct {
+	op     *orPred
+	branch int
+}
+
+// exclusive returns whether the two domains are in different branches of the
+// same Or predicate, in which case only one of them has to hold.
+func (d *orDomain) exclusive(o *orDomain) bool {
+	for k := 0; k < len(d.path) && k < len(o.path); k++ {
+		if d.path[k].op != o.path[k].op {
+			return false
+		}
+		if d.path[k].branch != o.path[k].branch {
+			return true
+		}
+	}
+	return false
+}
+
+// linkDomains finds the Scalar variables that appear in OR-domains that are
+// not mutually exclusive, and creates for each domain using them a predicate
+// C=x*G+r*H proving the knowledge of an opening of a Pedersen commitment C to
+// the variable x. Since the commitment is binding, this proves that x has the
+// same value in all those domains.
+func (prf *proof) linkDomains(pred Predicate) {
+	var domains []*orDomain
+	newDomain := func(root Predicate, path []orBranch) *orDomain {
+		d := &orDomain{root, path, make(map[string]bool)}
+		domains = append(domains, d)
+		return d
+	}
+	var collect func(p Predicate, d *orDomain)
+	collect = func(p Predicate, d *orDomain) {
+		switch p := p.(type) {
+		case *repPred:
+			for _, t := range p.T {
+				d.vars[t.S] = true
+			}
+		case *andPred:
+			for _, sub := range *p {
+				collect(sub, d)
+			}
+		case *orPred:
+			for i, sub := range *p {
+				path := append(append([]orBranch{}, d.path...), orBranch{p, i})
+				collect(sub, newDomain(sub, path))
+			}
+		}
+	}
+	collect(pred, newDomain(pred, nil))
+
+	users := make(map[string][]*orDomain)
+	for _, d := range domains {
+		for x := range d.vars {
+			users[x] = append(users[x], d)
+		}
+	}
+	for x, ds := range users {
+	search:
+		for i := range ds {
+			for j := i + 1; j < len(ds); j++ {
+				if !ds[i].exclusive(ds[j]) {
+					prf.cross = append(prf.cross, x)
+					break search
+				}
+			}
+		}
+	}
+	if len(prf.cross) == 0 {
+		return
+	}
+	sort.Strings(prf.cross)
+
+	prf.link = make(map[Predicate][]Predicate)
+	for _, d := range domains {
+		for _, x := range prf.cross {
+			if d.vars[x] {
+				link := Rep(linkCommitName(x), x, linkG, linkBlindName(x), linkH)
+				link.enumVars(prf)
+				prf.link[d.root] = append(prf.link[d.root], link)
+			}
+		}
+	}
+}
+
+// linkBases returns the Pedersen commitment bases: the standard base point,
+// and a point derived from the suite XOF whose discrete logarithm is unknown.
+func (prf *proof) linkBases() (kyber.Point, kyber.Point) {
+	H := prf.s.Point().Pick(prf.s.XOF([]byte("proof: OR-domain link base")))
+	return prf.s.Point().Base(), H
+}
+
+// prover: commit to the variables crossing OR-domains
+func (prf *proof) commitCrossing() error {
+	if len(prf.cross) == 0 {
+		return nil
+	}
+	sval := make(map[string]kyber.Scalar, len(prf.sval)+len(prf.cross))
+	for k, v := range prf.sval {
+		sval[k] = v
+	}
+	pval := make(map[string]kyber.Point, len(prf.pval)+len(prf.cross)+2)
+	for k, v := range prf.pval {
+		pval[k] = v
+	}
+	G, H := prf.linkBases()
+	pval[linkG] = G
+	pval[linkH] = H
+
+	for _, x := range prf.cross {
+		if sval[x] == nil {
+			// x only appears on non-obligated branches,
+			// so we can commit to any value.
+			sval[x] = prf.s.Scalar()
+			if err := prf.pc.PriRand(sval[x]); err != nil {
+				return err
+			}
+		}
+		r := prf.s.Scalar()
+		if err := prf.pc.PriRand(r); err != nil {
+			return err
+		}
+		sval[linkBlindName(x)] = r
+		C := prf.s.Point().Mul(sval[x], G)
+		C.Add(C, prf.s.Point().Mul(r, H))
+		pval[linkCommitName(x)] = C
+		if err := prf.pc.Put(C); err != nil {
+			return err
+		}
+	}
+	prf.sval = sval
+	prf.pval = pval
+	return nil
+}
+
+// verifier: get the commitments to the variables crossing OR-domains
+func (prf *proof) getCrossing() error {
+	if len(prf.cross) == 0 {
+		return nil
+	}
+	pval := make(map[string]kyber.Point, len(prf.pval)+len(prf.cross)+2)
+	for k, v := range prf.pval {
+		pval[k] = v
+	}
+	pval[linkG], pval[linkH] = prf.linkBases()
+	for _, x := range prf.cross {
+		C := prf.s.Point()
+		if err := prf.vc.Get(C); err != nil {
+			return err
+		}
+		pval[linkCommitName(x)] = C
+	}
+	prf.pval = pval
+	return nil
+}
+
+// prover: produce the commitments of the OR-domain rooted at p
+func (prf *proof) commitDomain(p Predicate, w kyber.Scalar) error {
+	v := make([]kyber.Scalar, prf.nsvars)
+	if e := p.commit(prf, w, v); e != nil {
+		return e
+	}
+	for _, link := range prf.link[p] {
+		if e := link.commit(prf, w, v); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// prover: produce the responses of the OR-domain rooted at p,
+// followed by those of the OR predicates nested within it
+func (prf *proof) respondDomain(p Predicate, c kyber.Scalar) error {
+	r := make([]kyber.Scalar, prf.nsvars)
+	start := len(prf.nested)
+	if e := p.respond(prf, c, r); e != nil {
+		return e
+	}
+	for _, link := range prf.link[p] {
+		if e := link.respond(prf, c, r); e != nil {
+			return e
+		}
+	}
+	if e := prf.sendResponses(nil, r); e != nil {
+		return e
+	}
+
+	nested := append([]nestedOr{}, prf.nested[start:]...)
+	prf.nested = prf.nested[:start]
+	for _, n := range nested {
+		if e := n.op.respond(prf, n.c, nil); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// verifier: get the commitments of the OR-domain rooted at p
+func (prf *proof) getCommitsDomain(p Predicate) error {
+	r := make([]kyber.Scalar, prf.nsvars)
+	if e := p.getCommits(prf, r); e != nil {
+		return e
+	}
+	for _, link := range prf.link[p] {
+		if e := link.getCommits(prf, r); e != nil {
+			return e
+		}
+	}
+	prf.vr[p] = r
+	return nil
+}
+
+// verifier: get the responses of the OR-domain rooted at p and check them
+func (prf *proof) verifyDomain(p Predicate, c kyber.Scalar) error {
+	r := prf.vr[p]
+	if e := prf.getResponses(nil, r); e != nil {
+		return e
+	}
+	if e := p.verify(prf, c, r); e != nil {
+		return e
+	}
+	for _, link := range prf.link[p] {
+		if e := link.verify(prf, c, r); e != nil {
+			return e
+		}
+	}
+	return nil
+}
@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"io"
 
-	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/proof/transcript"
 )
 
 // Hash-based noninteractive Sigma-protocol prover context
@@ -14,7 +14,7 @@ type hashProver struct {
 	suite   Suite
 	proof   bytes.Buffer
 	msg     bytes.Buffer
-	pubrand kyber.XOF
+	pubrand *transcript.Transcript
 	prirand io.Reader
 }
 
@@ -34,7 +34,7 @@ func (s *cipherStreamReader) Read(in []byte) (int, error) {
 func newHashProver(suite Suite, protoName string) *hashProver {
 	var sc hashProver
 	sc.suite = suite
-	sc.pubrand = suite.XOF([]byte(protoName))
+	sc.pubrand = transcript.New(suite, protoName)
 	sc.prirand = &cipherStreamReader{suite.RandomStream()}
 	return &sc
 }
@@ -47,14 +47,10 @@ func (c *hashProver) consumeMsg() error {
 	if c.msg.Len() > 0 {
 		// Stir the message into the public randomness pool
 		buf := c.msg.Bytes()
-		c.pubrand.Reseed()
-		_, err := c.pubrand.Write(buf)
-		if err != nil {
-			return err
-		}
+		c.pubrand.AppendMessage("message", buf)
 
 		// Append the current message data to the proof
-		_, err = c.proof.Write(buf)
+		_, err := c.proof.Write(buf)
 		if err != nil {
 			return err
 		}
@@ -71,7 +67,16 @@ func (c *hashProver) PubRand(data ...interface{}) error {
 		return err
 	}
 
-	return c.suite.Read(c.pubrand, data...)
+	return c.suite.Read(c.pubrand.ChallengeStream("challenge"), data...)
+}
+
+// BindContext absorbs the data into the transcript without adding it to
+// the proof.
+func (c *hashProver) BindContext(label string, data ...interface{}) error {
+	if err := c.consumeMsg(); err != nil {
+		return err
+	}
+	return bindContext(c.suite, c.pubrand, label, data...)
 }
 
 // Get private randomness
@@ -93,7 +98,7 @@ type hashVerifier struct {
 	suite   Suite
 	proof   bytes.Buffer // Buffer with which to read the proof
 	prbuf   []byte       // Byte-slice underlying proof buffer
-	pubrand kyber.XOF
+	pubrand *transcript.Transcript
 }
 
 func newHashVerifier(suite Suite, protoName string,
@@ -104,7 +109,7 @@ func newHashVerifier(suite Suite, protoName string,
 	}
 	c.suite = suite
 	c.prbuf = c.proof.Bytes()
-	c.pubrand = suite.XOF([]byte(protoName))
+	c.pubrand = transcript.New(suite, protoName)
 	return &c, nil
 }
 
@@ -112,13 +117,7 @@ func (c *hashVerifier) consumeMsg() error {
 	l := len(c.prbuf) - c.proof.Len() // How many bytes read?
 	if l > 0 {
 		// Stir consumed bytes into the public randomness pool
-		buf := c.prbuf[:l]
-		c.pubrand.Reseed()
-		_, err := c.pubrand.Write(buf)
-		if err != nil {
-			return err
-		}
-
+		c.pubrand.AppendMessage("message", c.prbuf[:l])
 		c.prbuf = c.proof.Bytes() // Reset to remaining bytes
 	}
 
@@ -138,7 +137,24 @@ func (c *hashVerifier) PubRand(data ...interface{}) error {
 		return err
 	}
 
-	return c.suite.Read(c.pubrand, data...)
+	return c.suite.Read(c.pubrand.ChallengeStream("challenge"), data...)
+}
+
+// BindContext absorbs the data into the transcript, as the prover did.
+func (c *hashVerifier) BindContext(label string, data ...interface{}) error {
+	if err := c.consumeMsg(); err != nil {
+		return err
+	}
+	return bindContext(c.suite, c.pubrand, label, data...)
+}
+
+func bindContext(suite Suite, t *transcript.Transcript, label string, data ...interface{}) error {
+	var buf bytes.Buffer
+	if err := suite.Write(&buf, data...); err != nil {
+		return err
+	}
+	t.AppendMessage(label, buf.Bytes())
+	return nil
 }
 
 // HashProve runs a given Sigma-protocol prover with a ProverContext
@@ -146,9 +162,14 @@ func (c *hashVerifier) PubRand(data ...interface{}) error {
 // Returns a byte-slice containing the noninteractive proof on success,
 // or an error in the case of failure.
 //
+// The messages of the prover are absorbed into a transcript (see the
+// proof/transcript package) from which the challenges are derived.
 // The optional protocolName is fed into the hash function used in the proof,
 // so that a proof generated for a particular protocolName
 // will verify successfully only if the verifier uses the same protocolName.
+// The provers and verifiers of predicates bind their statement, the
+// predicate and its public points, to the challenges; other provers and
+// verifiers can bind theirs with BindContext.
 //
 // The caller must provide a source of random entropy for the proof;
 // this can be random.New() to use fresh random bits, or a
@@ -50,8 +50,8 @@ func Example_hashProve1() {
 	// Signature:
 	// 00000000  e9 a2 da f4 9d 7c e2 25  35 be 0a 15 78 9c ea ca  |.....|.%5...x...|
 	// 00000010  a7 1e 6e d6 26 c3 40 ed  0d 3d 71 d4 a9 ef 55 3b  |..n.&.@..=q...U;|
-	// 00000020  64 76 55 7b 3c 63 20 d8  4b 29 3a 1c 7f 44 59 ad  |dvU{<c .K):..DY.|
-	// 00000030  ff 5d c1 ff 06 1d 97 0c  59 06 3c 4b aa 7b 7c 0c  |.]......Y.<K.{|.|
+	// 00000020  0d 2b 5c 5b 6a 91 16 5e  f9 7a 0f 6f 72 e8 e6 9b  |.+\[j..^.z.or...|
+	// 00000030  1f f0 3a b5 9e 18 41 43  49 76 e5 2b 8e 96 01 08  |..:...ACIv.+....|
 	// Signature verified against correct message M.
 	// Signature verify against wrong message: invalid proof: commit mismatch
 }
@@ -154,13 +154,13 @@ func Example_hashProve2() {
 	// 000000d0  4d 97 a9 bf 1a 28 27 6d  3b 71 04 e1 c0 86 96 08  |M....('m;q......|
 	// 000000e0  8d 0e c0 14 e3 eb 8b e9  16 40 29 60 ab bd e6 1a  |.........@)`....|
 	// 000000f0  68 54 5e 29 c8 85 05 bc  4a 27 83 d9 32 cc 74 0f  |hT^)....J'..2.t.|
-	// 00000100  5e 16 30 25 e2 d6 35 2a  d4 3e b5 07 1f d4 0a eb  |^.0%..5*.>......|
-	// 00000110  5d ef 3b 84 35 39 90 0c  3a 02 bb ee c7 9a e7 09  |].;.59..:.......|
+	// 00000100  44 c6 ed 64 60 5d 0a 8b  08 d9 20 21 d4 6c d2 1d  |D..d`].... !.l..|
+	// 00000110  37 eb f0 4f 4a ab ae 59  cd d3 0a 92 66 8e ab 0c  |7..OJ..Y....f...|
 	// 00000120  d1 cc 1e e1 f4 3b 88 52  e5 99 ed 50 d7 66 b5 76  |.....;.R...P.f.v|
 	// 00000130  59 6c c1 66 98 07 e5 73  e7 b8 fe 48 43 a0 74 09  |Yl.f...s...HC.t.|
 	// 00000140  84 9a 7b ec 21 aa ff c7  fc 79 c6 8f f4 23 82 e7  |..{.!....y...#..|
 	// 00000150  d3 71 69 20 d6 94 27 ef  11 0b 4c a5 79 54 1f 09  |.qi ..'...L.yT..|
-	// 00000160  6b ec 50 c2 1f 98 38 ea  a7 02 da ca aa 1b 6b 39  |k.P...8.......k9|
-	// 00000170  70 b8 35 6c fe 03 1f b0  08 42 e0 5d b2 5e 40 04  |p.5l.....B.].^@.|
+	// 00000160  72 9d b8 4c 44 b0 95 3e  7d 12 d8 84 77 61 d8 a0  |r..LD..>}...wa..|
+	// 00000170  c2 01 50 49 71 43 9d 9f  d3 93 c8 60 66 f6 39 03  |..PIqC.....`f.9.|
 	// Linkable Ring Signature verified.
 }
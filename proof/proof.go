@@ -49,15 +49,18 @@ of the public Point variables that the proof refers to.
 The verifier need not be provided any secrets or branch choices, of course.
 (If the verifier needed those then they wouldn't be secret, would they?)
 
-Currently we require that all Or operators be above all And operators
-in the expression - i.e., Or-of-And combinations are allowed,
-but no And-of-Or predicates.
-We could rewrite expressions into this form as Camenisch/Stadler suggest,
-but that could run a risk of unexpected exponential blowup in the worst case.
-We could avoid this risk by not rewriting the expression tree,
-but instead generating Pedersen commits for variables that need to "cross"
-from one OR-domain to another non-mutually-exclusive one.
-For now we simply require expressions to be in the appropriate form.
+Rep, And and Or predicates compose arbitrarily: Or-of-And as well as
+And-of-Or predicates are supported.
+Each Or sub-predicate gets its own challenge, and thus forms its own
+"OR-domain" in which the responses for the secret variables are computed.
+Rather than rewriting the expression into Or-of-And form,
+as Camenisch/Stadler suggest at the risk of an exponential blowup,
+the prover generates a Pedersen commitment for each secret variable
+that "crosses" from one OR-domain to another non-mutually-exclusive one,
+and proves in each of those OR-domains that it knows an opening
+of that same commitment.
+Using a secret variable in two branches of the same Or predicate
+does not require a commitment, since only one of them needs to be true.
 */
 type Predicate interface {
 
@@ -110,15 +113,26 @@ type proof struct {
 
 	pval map[string]kyber.Point // values of public Point variables
 
+	// Scalar variables crossing non-mutually-exclusive OR-domains,
+	// and per-domain predicates proving the opening of their commitments
+	cross []string
+	link  map[Predicate][]Predicate
+
 	// prover-specific state
 	pc     ProverContext
 	sval   map[string]kyber.Scalar   // values of private Scalar variables
 	choice map[Predicate]int         // OR branch choices set by caller
 	pp     map[Predicate]*proverPred // per-predicate prover state
+	nested []nestedOr                // OR predicates awaiting their domain's responses
 
 	// verifier-specific state
 	vc VerifierContext
-	vp map[Predicate]*verifierPred // per-predicate verifier state
+	vp map[Predicate]*verifierPred  // per-predicate verifier state
+	vr map[Predicate][]kyber.Scalar // per-domain verifier responses
+}
+type nestedOr struct {
+	op *orPred      // OR predicate nested in an AND predicate
+	c  kyber.Scalar // challenge of the enclosing OR-domain
 }
 type proverPred struct {
 	w  kyber.Scalar   // secret pre-challenge
@@ -472,11 +486,8 @@ func (op *orPred) enumVars(prf *proof) {
 	}
 }
 
-func (op *orPred) commit(prf *proof, w kyber.Scalar, pv []kyber.Scalar) error {
+func (op *orPred) commit(prf *proof, w kyber.Scalar, _ []kyber.Scalar) error {
 	sub := []Predicate(*op)
-	if pv != nil { // only happens within an AND expression
-		return errors.New("can't have OR predicates within AND predicates")
-	}
 
 	// Create per-predicate prover state
 	wi := make([]kyber.Scalar, len(sub))
@@ -527,7 +538,7 @@ func commitmentProducer(prf *proof, wi []kyber.Scalar, sub []Predicate) error {
 	// Now recursively choose commitments within each sub
 	for i := 0; i < len(sub); i++ {
 		// Fresh variable-blinding secrets for each pre-commitment
-		if err := sub[i].commit(prf, wi[i], nil); err != nil {
+		if err := prf.commitDomain(sub[i], wi[i]); err != nil {
 			return err
 		}
 	}
@@ -539,7 +550,10 @@ func (op *orPred) respond(prf *proof, c kyber.Scalar, pr []kyber.Scalar) error {
 	sub := []Predicate(*op)
 	pp := prf.pp[op]
 	if pr != nil {
-		return errors.New("OR predicates can't be nested in anything else")
+		// We're within an AND predicate: our sub-challenges and the
+		// responses of our subs come after the responses of our OR-domain.
+		prf.nested = append(prf.nested, nestedOr{op, c})
+		return nil
 	}
 
 	ci := pp.wi
@@ -564,7 +578,7 @@ func (op *orPred) respond(prf *proof, c kyber.Scalar, pr []kyber.Scalar) error {
 
 	// Recursively compute responses in all subtrees
 	for i := range sub {
-		if e := sub[i].respond(prf, ci[i], nil); e != nil {
+		if e := prf.respondDomain(sub[i], ci[i]); e != nil {
 			return e
 		}
 	}
@@ -576,18 +590,15 @@ func (op *orPred) respond(prf *proof, c kyber.Scalar, pr []kyber.Scalar) error {
 func (op *orPred) getCommits(prf *proof, _ []kyber.Scalar) error {
 	sub := []Predicate(*op)
 	for i := range sub {
-		if e := sub[i].getCommits(prf, nil); e != nil {
+		if e := prf.getCommitsDomain(sub[i]); e != nil {
 			return e
 		}
 	}
 	return nil
 }
 
-func (op *orPred) verify(prf *proof, c kyber.Scalar, pr []kyber.Scalar) error {
+func (op *orPred) verify(prf *proof, c kyber.Scalar, _ []kyber.Scalar) error {
 	sub := []Predicate(*op)
-	if pr != nil {
-		return errors.New("OR predicates can't be in anything else")
-	}
 
 	// Get the prover's sub-challenges
 	nsub := len(sub)
@@ -612,7 +623,7 @@ func (op *orPred) verify(prf *proof, c kyber.Scalar, pr []kyber.Scalar) error {
 
 	// Recursively verify all subs
 	for i := range sub {
-		if e := sub[i].verify(prf, ci[i], nil); e != nil {
+		if e := prf.verifyDomain(sub[i], ci[i]); e != nil {
 			return e
 		}
 	}
@@ -656,6 +667,7 @@ func (prf proof) init(suite Suite, pred Predicate) *proof {
 	prf.sidx = make(map[string]int)
 	prf.pidx = make(map[string]int)
 	pred.enumVars(&prf)
+	prf.linkDomains(pred)
 	prf.nsvars = len(prf.svar)
 	prf.npvars = len(prf.pvar)
 
@@ -723,9 +735,20 @@ func (prf *proof) prove(p Predicate, sval map[string]kyber.Scalar,
 	prf.pval = pval
 	prf.choice = choice
 	prf.pp = make(map[Predicate]*proverPred)
+	prf.nested = nil
+
+	// Bind the statement to the challenges
+	if e := prf.bindStatement(p, pc); e != nil {
+		return e
+	}
+
+	// Commit to the variables crossing OR-domains
+	if e := prf.commitCrossing(); e != nil {
+		return e
+	}
 
 	// Generate all commitments
-	if e := p.commit(prf, nil, nil); e != nil {
+	if e := prf.commitDomain(p, nil); e != nil {
 		return e
 	}
 
@@ -736,7 +759,7 @@ func (prf *proof) prove(p Predicate, sval map[string]kyber.Scalar,
 	}
 
 	// Generate all responses based on master challenge
-	return p.respond(prf, c, nil)
+	return prf.respondDomain(p, c)
 }
 
 func (prf *proof) verify(p Predicate, pval map[string]kyber.Point,
@@ -744,10 +767,21 @@ func (prf *proof) verify(p Predicate, pval map[string]kyber.Point,
 	prf.vc = vc
 	prf.pval = pval
 	prf.vp = make(map[Predicate]*verifierPred)
+	prf.vr = make(map[Predicate][]kyber.Scalar)
+
+	// Bind the statement to the challenges
+	if e := prf.bindStatement(p, vc); e != nil {
+		return e
+	}
+
+	// Get the commitments to the variables crossing OR-domains
+	if e := prf.getCrossing(); e != nil {
+		return e
+	}
 
 	// Get the commitments from the verifier,
 	// and calculate the sets of responses we'll need for each OR-domain.
-	if e := p.getCommits(prf, nil); e != nil {
+	if e := prf.getCommitsDomain(p); e != nil {
 		return e
 	}
 
@@ -758,7 +792,28 @@ func (prf *proof) verify(p Predicate, pval map[string]kyber.Point,
 	}
 
 	// Check all the responses and sub-challenges against the commitments.
-	return p.verify(prf, c, nil)
+	return prf.verifyDomain(p, c)
+}
+
+// bindStatement binds the predicate and its public points, in the order of
+// their enumeration, to the challenges of a non-interactive proof, so that
+// the proof doesn't verify against another statement.
+func (prf *proof) bindStatement(p Predicate, ctx interface{}) error {
+	data := []interface{}{[]byte(p.String())}
+	for _, name := range prf.pvar[1:] {
+		if name[0] == 0 {
+			// the internal points linking OR-domains are either fixed or
+			// part of the proof
+			continue
+		}
+		P := prf.pval[name]
+		if P == nil {
+			// nil stands for the standard base point in Point.Mul
+			P = prf.s.Point().Base()
+		}
+		data = append(data, P)
+	}
+	return BindContext(ctx, "predicate", data...)
 }
 
 // Produce a higher-order Prover embodying a given proof predicate.
@@ -69,6 +69,136 @@ func TestRep(t *testing.T) {
 	}
 }
 
+func TestAndOfOr(t *testing.T) {
+	rand := blake2xb.New([]byte("seed"))
+	suite := edwards25519.NewBlakeSHA256Ed25519WithRand(rand)
+
+	a := suite.Scalar().Pick(rand)
+	x := suite.Scalar().Pick(rand)
+	B := suite.Point().Base()
+	H := suite.Point().Pick(rand)
+	A := suite.Point().Mul(a, B)
+	X := suite.Point().Mul(x, B)
+	XH := suite.Point().Mul(x, H)
+	Y := suite.Point().Pick(rand)
+	Z := suite.Point().Pick(rand)
+	pval := map[string]kyber.Point{"B": B, "H": H, "A": A, "X": X, "XH": XH, "Y": Y, "Z": Z}
+
+	prove := func(pred Predicate, sval map[string]kyber.Scalar, choice map[Predicate]int) error {
+		prf, err := HashProve(suite, "TEST", pred.Prover(suite, sval, pval, choice))
+		if err != nil {
+			return err
+		}
+		return HashVerify(suite, "TEST", pred.Verifier(suite, pval), prf)
+	}
+
+	// owns key A AND (knows x OR knows y)
+	or := Or(Rep("X", "x", "B"), Rep("Y", "y", "B"))
+	pred := And(Rep("A", "a", "B"), or)
+	if err := prove(pred, map[string]kyber.Scalar{"a": a, "x": x}, map[Predicate]int{or: 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the secret of A is also the secret of one of X or Y: only true if a == x
+	or = Or(Rep("X", "a", "B"), Rep("Y", "y", "B"))
+	pred = And(Rep("A", "a", "B"), or)
+	if err := prove(pred, map[string]kyber.Scalar{"a": a}, map[Predicate]int{or: 0}); err == nil {
+		t.Fatal("proof with different secrets in linked OR-domains verified")
+	}
+
+	// x*B and x*H, where the equality crosses into an OR-domain
+	or = Or(Rep("XH", "x", "H"), Rep("Z", "z", "B"))
+	pred = And(Rep("X", "x", "B"), or)
+	if err := prove(pred, map[string]kyber.Scalar{"x": x}, map[Predicate]int{or: 0}); err != nil {
+		t.Fatal(err)
+	}
+	// same statement with the wrong point on the obligated branch
+	pval["XH"] = suite.Point().Mul(a, H)
+	if err := prove(pred, map[string]kyber.Scalar{"x": x}, map[Predicate]int{or: 0}); err == nil {
+		t.Fatal("proof of a false statement verified")
+	}
+	pval["XH"] = XH
+
+	// nested: (A && (X || Z) && (XH || Y)) || Z
+	or1 := Or(Rep("X", "x", "B"), Rep("Z", "z", "B"))
+	or2 := Or(Rep("XH", "x", "H"), Rep("Y", "y", "B"))
+	and := And(Rep("A", "a", "B"), or1, or2)
+	pred = Or(and, Rep("Z", "z", "B"))
+	choice := map[Predicate]int{pred: 0, or1: 0, or2: 0}
+	if err := prove(pred, map[string]kyber.Scalar{"a": a, "x": x}, choice); err != nil {
+		t.Fatal(err)
+	}
+	// an unrelated secret is rejected on one of the linked branches
+	pval["XH"] = suite.Point().Mul(a, H)
+	if err := prove(pred, map[string]kyber.Scalar{"a": a, "x": x}, choice); err == nil {
+		t.Fatal("proof of a false statement verified")
+	}
+}
+
+func TestStatementBinding(t *testing.T) {
+	rand := blake2xb.New([]byte("seed"))
+	suite := edwards25519.NewBlakeSHA256Ed25519WithRand(rand)
+
+	x := suite.Scalar().Pick(rand)
+	B := suite.Point().Base()
+	X := suite.Point().Mul(x, B)
+	pval := map[string]kyber.Point{"B": B, "X": X}
+	pred := Rep("X", "x", "B")
+	prf, err := HashProve(suite, "TEST", pred.Prover(suite, map[string]kyber.Scalar{"x": x}, pval, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a proof is V || r with V = r*B + c*X; without the statement in the
+	// transcript, c only depends on V, so anyone can pick V and r and
+	// compute an X for which the proof verifies
+	V := suite.Point().Pick(rand)
+	r := suite.Scalar().Pick(rand)
+	Vb, err := V.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := append(Vb, rb...)
+	var c kyber.Scalar
+	unbound := func(ctx VerifierContext) error {
+		if err := ctx.Get(suite.Point()); err != nil {
+			return err
+		}
+		c = suite.Scalar()
+		return ctx.PubRand(c)
+	}
+	if err := HashVerify(suite, "TEST", unbound, forged); err != nil {
+		t.Fatal(err)
+	}
+	Xf := suite.Point().Sub(V, suite.Point().Mul(r, B))
+	Xf.Mul(suite.Scalar().Inv(c), Xf)
+	if !V.Equal(suite.Point().Add(suite.Point().Mul(r, B), suite.Point().Mul(c, Xf))) {
+		t.Fatal("the forgery doesn't satisfy the verification equation")
+	}
+	forgedPval := map[string]kyber.Point{"B": B, "X": Xf}
+	if err := HashVerify(suite, "TEST", pred.Verifier(suite, forgedPval), forged); err == nil {
+		t.Fatal("forged proof verified")
+	}
+
+	// the honest proof doesn't verify against a changed public point
+	if err := HashVerify(suite, "TEST", pred.Verifier(suite, forgedPval), prf); err == nil {
+		t.Fatal("proof verified against a changed public point")
+	}
+
+	// the predicate is bound too
+	pval["Y"] = X
+	if err := HashVerify(suite, "TEST", Rep("Y", "x", "B").Verifier(suite, pval), prf); err == nil {
+		t.Fatal("proof verified against another predicate")
+	}
+	if err := HashVerify(suite, "TEST", pred.Verifier(suite, pval), prf); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // This code creates a simple discrete logarithm knowledge proof.
 // In particular, that the prover knows a secret x
 // that is the elliptic curve discrete logarithm of a point X
@@ -118,8 +248,8 @@ func Example_rep2() {
 	// Proof:
 	// 00000000  e9 a2 da f4 9d 7c e2 25  35 be 0a 15 78 9c ea ca  |.....|.%5...x...|
 	// 00000010  a7 1e 6e d6 26 c3 40 ed  0d 3d 71 d4 a9 ef 55 3b  |..n.&.@..=q...U;|
-	// 00000020  c1 84 20 a6 b7 79 86 9c  f8 dd 09 82 1e 48 a9 00  |.. ..y.......H..|
-	// 00000030  3e f3 68 66 3f a0 58 f9  88 df b4 35 1b 2f 72 0d  |>.hf?.X....5./r.|
+	// 00000020  2d a6 fe 90 ed c2 5e d3  74 0a 75 e1 2f a4 88 69  |-.....^.t.u./..i|
+	// 00000030  98 5c e8 41 96 00 26 65  39 f1 d1 06 9e b2 d7 01  |.\.A..&e9.......|
 	// Proof verified.
 }
 
@@ -174,6 +304,15 @@ func Example_and2() {
 	// Output: X1=x*B1 && X2=x*B2
 }
 
+// This code creates an And predicate containing an Or predicate,
+// indicating that the prover knows the secret x such that X=x*B,
+// and also knows a secret y such that Y=y*B or a secret z such that Z=z*B.
+func Example_andOr1() {
+	pred := And(Rep("X", "x", "B"), Or(Rep("Y", "y", "B"), Rep("Z", "z", "B")))
+	fmt.Println(pred.String())
+	// Output: X=x*B && (Y=y*B || Z=z*B)
+}
+
 // This code creates an Or predicate indicating that
 // the prover either knows a secret x such that X=x*B,
 // or the prover knows a secret y such that Y=y*B.
@@ -238,12 +377,12 @@ func Example_or2() {
 	// 00000010  4c c8 15 ed b1 eb 50 d3  d9 d2 9b 31 6c d3 0f 6b  |L.....P....1l..k|
 	// 00000020  a2 a9 bc d2 8c 6d d0 5e  9a 8e d1 8e 04 fb 88 af  |.....m.^........|
 	// 00000030  fb 90 8a 2a 71 ac 34 08  f9 bc 07 78 08 44 40 07  |...*q.4....x.D@.|
-	// 00000040  ab 1f 36 7e 7b db 50 7d  49 38 34 75 69 07 67 4b  |..6~{.P}I84ui.gK|
-	// 00000050  55 cb 28 f2 50 ad d1 4b  24 d2 d1 44 fe 44 b0 0e  |U.(.P..K$..D.D..|
+	// 00000040  05 08 08 8b 0d 83 4b 48  25 cc a6 67 70 83 58 ed  |......KH%..gp.X.|
+	// 00000050  b1 14 16 19 49 b7 f7 ef  14 94 21 9d 93 95 8c 07  |....I.....!.....|
 	// 00000060  00 e8 d3 8b 37 76 4f 47  d1 4a 93 0c cd df 20 08  |....7vOG.J.... .|
 	// 00000070  fc 0f ad f9 01 6c 30 c0  02 d4 fa 1b 1f 1c fa 04  |.....l0.........|
-	// 00000080  6d 2a a7 d8 8e 67 72 87  51 0e 16 72 51 87 99 83  |m*...gr.Q..rQ...|
-	// 00000090  2e c9 4e a1 ca 20 7d 64  33 04 f5 66 9b d3 74 03  |..N.. }d3..f..t.|
+	// 00000080  3e 8e f4 30 b0 f8 84 f5  d7 c7 73 2e c6 90 04 df  |>..0......s.....|
+	// 00000090  08 3e 98 42 c0 73 74 ca  29 83 5c 8f 6c 23 e0 0e  |.>.B.st.).\.l#..|
 	// 000000a0  2b e0 be 8d 56 55 1a d1  6e 11 21 fc 20 3e 0f 5f  |+...VU..n.!. >._|
 	// 000000b0  4d 97 a9 bf 1a 28 27 6d  3b 71 04 e1 c0 86 96 08  |M....('m;q......|
 	// Proof verified.
@@ -0,0 +1,129 @@
+// Package transcript implements Fiat-Shamir transcripts in the style of
+// Merlin (https://merlin.cool) on top of a kyber.XOF.
+//
+// A transcript is created for a given protocol, and the prover messages are
+// appended to it with a label. Challenges are then derived from everything
+// that was appended so far, also under a label. Since the protocol name, the
+// labels and the lengths of all messages are absorbed, two different
+// protocols, or two different sequences of messages, never produce the same
+// challenges. This gives domain separation and binds the challenges to the
+// whole context of the proof.
+//
+// The prover and the verifier must perform the same sequence of operations
+// on their transcripts to derive the same challenges.
+package transcript
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+// Suite wraps the functionalities needed by the transcript package.
+type Suite interface {
+	kyber.Group
+	kyber.XOFFactory
+}
+
+// domain separates the transcripts from any other use of the suite XOF.
+const domain = "kyber transcript v1"
+
+// Transcript is a Fiat-Shamir transcript. It must not be used concurrently.
+type Transcript struct {
+	suite Suite
+	xof   kyber.XOF
+}
+
+// New returns a transcript for the protocol with the given name.
+func New(suite Suite, protocol string) *Transcript {
+	t := &Transcript{
+		suite: suite,
+		xof:   suite.XOF([]byte(domain)),
+	}
+	t.AppendMessage("protocol", []byte(protocol))
+	return t
+}
+
+// AppendMessage appends the message with the given label.
+func (t *Transcript) AppendMessage(label string, msg []byte) {
+	t.write([]byte(label))
+	t.write(msg)
+}
+
+// AppendUint64 appends the integer with the given label.
+func (t *Transcript) AppendUint64(label string, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	t.AppendMessage(label, b[:])
+}
+
+// AppendPoint appends the encoding of the point with the given label.
+func (t *Transcript) AppendPoint(label string, p kyber.Point) error {
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("marshaling point %s: %w", label, err)
+	}
+	t.AppendMessage(label, buf)
+	return nil
+}
+
+// AppendPoints appends the encodings of the points, all with the same label.
+func (t *Transcript) AppendPoints(label string, ps ...kyber.Point) error {
+	t.AppendUint64(label, uint64(len(ps)))
+	for _, p := range ps {
+		if err := t.AppendPoint(label, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendScalar appends the encoding of the scalar with the given label.
+func (t *Transcript) AppendScalar(label string, s kyber.Scalar) error {
+	buf, err := s.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("marshaling scalar %s: %w", label, err)
+	}
+	t.AppendMessage(label, buf)
+	return nil
+}
+
+// ChallengeScalar returns a challenge scalar that depends on everything
+// appended so far. The label is appended to the transcript, so that the
+// next challenges are different even if nothing else is appended.
+func (t *Transcript) ChallengeScalar(label string) kyber.Scalar {
+	return t.suite.Scalar().Pick(t.ChallengeStream(label))
+}
+
+// ChallengeBytes returns n challenge bytes, see ChallengeScalar.
+func (t *Transcript) ChallengeBytes(label string, n int) []byte {
+	buf := make([]byte, n)
+	t.ChallengeStream(label).XORKeyStream(buf, buf)
+	return buf
+}
+
+// ChallengeStream returns a stream of public randomness that depends on
+// everything appended so far, from which any number of challenges can be
+// read. The label is appended to the transcript as with ChallengeScalar.
+func (t *Transcript) ChallengeStream(label string) kyber.XOF {
+	t.AppendMessage("challenge", []byte(label))
+	return t.xof.Clone()
+}
+
+// Clone returns an independent copy of the transcript in its current state.
+func (t *Transcript) Clone() *Transcript {
+	return &Transcript{
+		suite: t.suite,
+		xof:   t.xof.Clone(),
+	}
+}
+
+// write absorbs the length-prefixed data.
+func (t *Transcript) write(data []byte) {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(data)))
+	// Writing to an XOF which was never read from doesn't fail
+	_, _ = t.xof.Write(l[:])
+	_, _ = t.xof.Write(data)
+}
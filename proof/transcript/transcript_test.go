@@ -0,0 +1,55 @@
+package transcript
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+)
+
+func TestTranscriptDeterministic(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	P := suite.Point().Base()
+	s := suite.Scalar().SetInt64(42)
+
+	run := func(protocol string, label string) *Transcript {
+		tr := New(suite, protocol)
+		require.NoError(t, tr.AppendPoint(label, P))
+		require.NoError(t, tr.AppendScalar("s", s))
+		return tr
+	}
+
+	c1 := run("test", "P").ChallengeScalar("c")
+	c2 := run("test", "P").ChallengeScalar("c")
+	require.True(t, c1.Equal(c2))
+
+	// domain separation on the protocol name, labels and challenge labels
+	require.False(t, c1.Equal(run("other", "P").ChallengeScalar("c")))
+	require.False(t, c1.Equal(run("test", "Q").ChallengeScalar("c")))
+	require.False(t, c1.Equal(run("test", "P").ChallengeScalar("d")))
+
+	// consecutive challenges differ
+	tr := run("test", "P")
+	require.False(t, tr.ChallengeScalar("c").Equal(tr.ChallengeScalar("c")))
+}
+
+func TestTranscriptFraming(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	tr1 := New(suite, "test")
+	tr1.AppendMessage("ab", []byte("c"))
+	tr2 := New(suite, "test")
+	tr2.AppendMessage("a", []byte("bc"))
+	require.NotEqual(t, tr1.ChallengeBytes("c", 32), tr2.ChallengeBytes("c", 32))
+}
+
+func TestTranscriptClone(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	tr := New(suite, "test")
+	tr.AppendUint64("n", 3)
+	clone := tr.Clone()
+	require.Equal(t, tr.ChallengeBytes("c", 16), clone.ChallengeBytes("c", 16))
+
+	clone.AppendUint64("n", 4)
+	require.NotEqual(t, tr.ChallengeBytes("c", 16), clone.ChallengeBytes("c", 16))
+}
@@ -0,0 +1,214 @@
+package async
+
+import (
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+)
+
+// maxRoundsAhead bounds the rounds for which messages are kept before this
+// node reaches them.
+const maxRoundsAhead = 64
+
+// aba is the binary agreement of Mostéfaoui, Moumen and Raynal, with the
+// confirmation phase of Tholoniat and Gramoli, and a termination gadget: a
+// node that decides broadcasts its decision, adopts a decision announced by
+// f+1 nodes and stops once 2f+1 nodes announced it.
+type aba struct {
+	n, f int
+	// send broadcasts a message of this agreement
+	send func(k Kind, round uint32, value byte)
+	// coin returns the coin of a round, and false if it isn't known yet, in
+	// which case step is called again once it is
+	coin func(round uint32) (byte, bool)
+
+	round  uint32
+	est    int
+	rounds map[uint32]*abaRound
+	// decided is the decided value, or -1
+	decided     int
+	decidedFrom [2]map[dkg.Index]bool
+	decidedSent bool
+	done        bool
+}
+
+type abaRound struct {
+	bvalSent [2]bool
+	bval     [2]map[dkg.Index]bool
+	// bin is the set of values broadcast by 2f+1 nodes
+	bin      byte
+	auxSent  bool
+	aux      map[dkg.Index]byte
+	confSent bool
+	conf     map[dkg.Index]byte
+}
+
+func newABA(n, f int, send func(Kind, uint32, byte), coin func(uint32) (byte, bool)) *aba {
+	return &aba{
+		n:           n,
+		f:           f,
+		send:        send,
+		coin:        coin,
+		est:         -1,
+		decided:     -1,
+		rounds:      make(map[uint32]*abaRound),
+		decidedFrom: [2]map[dkg.Index]bool{make(map[dkg.Index]bool), make(map[dkg.Index]bool)},
+	}
+}
+
+func (a *aba) getRound(r uint32) *abaRound {
+	ar, ok := a.rounds[r]
+	if !ok {
+		ar = &abaRound{
+			bval: [2]map[dkg.Index]bool{make(map[dkg.Index]bool), make(map[dkg.Index]bool)},
+			aux:  make(map[dkg.Index]byte),
+			conf: make(map[dkg.Index]byte),
+		}
+		a.rounds[r] = ar
+	}
+	return ar
+}
+
+// started returns true once the agreement has an input.
+func (a *aba) started() bool {
+	return a.est >= 0
+}
+
+// input starts the agreement with the value v.
+func (a *aba) input(v byte) {
+	if a.started() {
+		return
+	}
+	a.est = int(v)
+	a.step()
+}
+
+// handle processes a message of the agreement.
+func (a *aba) handle(m *Message) {
+	if a.done {
+		return
+	}
+	if m.Kind == KindDecided {
+		a.onDecided(m.Sender, m.Value)
+		return
+	}
+	if m.Round > a.round+maxRoundsAhead {
+		return
+	}
+	r := a.getRound(m.Round)
+	switch m.Kind {
+	case KindBVal:
+		if m.Value > 1 || r.bval[m.Value][m.Sender] {
+			return
+		}
+		r.bval[m.Value][m.Sender] = true
+		if a.started() && len(r.bval[m.Value]) >= a.f+1 && !r.bvalSent[m.Value] {
+			r.bvalSent[m.Value] = true
+			a.send(KindBVal, m.Round, m.Value)
+		}
+		if len(r.bval[m.Value]) >= 2*a.f+1 {
+			r.bin |= 1 << m.Value
+		}
+	case KindAux:
+		if _, ok := r.aux[m.Sender]; ok || m.Value > 1 {
+			return
+		}
+		r.aux[m.Sender] = m.Value
+	case KindConf:
+		if _, ok := r.conf[m.Sender]; ok || m.Value == 0 || m.Value > 3 {
+			return
+		}
+		r.conf[m.Sender] = m.Value
+	}
+	a.step()
+}
+
+// step moves the agreement forward as far as the received messages allow.
+func (a *aba) step() {
+	for a.started() && !a.done {
+		r := a.getRound(a.round)
+		est := byte(a.est)
+		if !r.bvalSent[est] {
+			r.bvalSent[est] = true
+			a.send(KindBVal, a.round, est)
+		}
+		// values broadcast by f+1 nodes before this node started
+		for b := byte(0); b < 2; b++ {
+			if len(r.bval[b]) >= a.f+1 && !r.bvalSent[b] {
+				r.bvalSent[b] = true
+				a.send(KindBVal, a.round, b)
+			}
+		}
+		if r.bin == 0 {
+			return
+		}
+		if !r.auxSent {
+			r.auxSent = true
+			w := est
+			if r.bin&(1<<w) == 0 {
+				w = 1 - w
+			}
+			a.send(KindAux, a.round, w)
+		}
+		if !r.confSent {
+			count := 0
+			for _, v := range r.aux {
+				if r.bin&(1<<v) != 0 {
+					count++
+				}
+			}
+			if count < a.n-a.f {
+				return
+			}
+			r.confSent = true
+			a.send(KindConf, a.round, r.bin)
+		}
+		var vals byte
+		count := 0
+		for _, v := range r.conf {
+			if v&^r.bin == 0 {
+				vals |= v
+				count++
+			}
+		}
+		if count < a.n-a.f {
+			return
+		}
+		s, ok := a.coin(a.round)
+		if !ok {
+			return
+		}
+		switch vals {
+		case 1, 2:
+			v := vals >> 1
+			if v == s {
+				a.decide(v)
+			}
+			a.est = int(v)
+		default:
+			a.est = int(s)
+		}
+		a.round++
+	}
+}
+
+func (a *aba) decide(v byte) {
+	if a.decided < 0 {
+		a.decided = int(v)
+	}
+	if !a.decidedSent {
+		a.decidedSent = true
+		a.send(KindDecided, 0, v)
+	}
+}
+
+func (a *aba) onDecided(from dkg.Index, v byte) {
+	if v > 1 || a.decidedFrom[v][from] {
+		return
+	}
+	a.decidedFrom[v][from] = true
+	if len(a.decidedFrom[v]) >= a.f+1 {
+		a.decide(v)
+	}
+	if len(a.decidedFrom[v]) >= 2*a.f+1 {
+		a.done = true
+	}
+}
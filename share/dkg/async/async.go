@@ -0,0 +1,528 @@
+// Package async implements a distributed key generation that makes progress
+// as soon as messages arrive, without the synchronous phases of package
+// pedersen. A slow or partitioned node delays the protocol, it doesn't make
+// it fail: nodes only wait for messages that honest nodes are guaranteed to
+// eventually send.
+//
+// The protocol tolerates f = (n-1)/3 byzantine nodes and proceeds as follows:
+//
+//   - Each dealer shares a secret with a symmetric bivariate polynomial f,
+//     as in the asynchronous verifiable secret sharing of Cachin et al. It
+//     commits to the coefficients of f and encrypts the row polynomial f(i, y)
+//     to each node i, and sends it all with Bracha's reliable broadcast, so all
+//     the honest nodes get the same deal.
+//   - A node whose row is valid broadcasts an OK message with the
+//     evaluations f(i, j) encrypted to each node j. A node whose row is
+//     invalid or missing recovers it from Threshold of these evaluations, so
+//     there is no complaint phase.
+//   - The nodes agree on the set of dealers with one binary agreement per
+//     dealer. A node votes for a dealer once it delivered its deal and got
+//     Threshold+f OK messages for it, and votes against the remaining dealers
+//     once n-f dealers have been accepted.
+//
+// The final share of a node is the sum of its shares from the accepted
+// dealers, and it's output as a pedersen Result so it can be used anywhere a
+// share of the pedersen DKG is, for example with package tbls.
+//
+// The binary agreements need a common coin that the adversary can't predict.
+// It's the threshold coin of Cachin, Kursawe and Shoup, computed with a key
+// shared among the nodes beforehand, see Config.Coin. Without this key, the
+// coin is derived from the nonce and everyone can predict it: the protocol
+// stays safe against any network, but an adversary controlling the schedule
+// of the messages can prevent the agreements from terminating, so it only
+// terminates under partial synchrony.
+package async
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/encrypt/ecies"
+	"go.dedis.ch/kyber/v4/share"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/sign"
+)
+
+// ErrInvalidConfig is returned when the config can't be used to run the
+// protocol.
+var ErrInvalidConfig = errors.New("async: invalid config")
+
+// Config holds the parameters of the protocol.
+type Config struct {
+	Suite dkg.Suite
+	// Longterm is the longterm secret key of this node. Its public key must
+	// be in Nodes.
+	Longterm kyber.Scalar
+	// Nodes lists all the nodes, including this one.
+	Nodes []dkg.Node
+	// Threshold is the number of shares needed to recover the distributed
+	// key. It must be between f+1 and n-2f, and defaults to f+1.
+	Threshold int
+	// Nonce identifies the session, see dkg.GetNonce.
+	Nonce []byte
+	// Auth is the scheme used to sign the messages.
+	Auth sign.Scheme
+	// Coin is the share of this node of a key distributed among the nodes
+	// beforehand, for example by a previous run of this protocol or of the
+	// pedersen DKG with the same node indices. It's used for the common coin
+	// of the agreements. Its threshold must be between f+1 and n-f. If nil,
+	// the coin is predictable and the protocol only terminates under partial
+	// synchrony.
+	Coin *dkg.DistKeyShare
+	// Log is an optional logger.
+	Log dkg.Logger
+}
+
+// Protocol runs the asynchronous DKG for one node. It keeps answering the
+// other nodes after its own result is ready, until Stop is called.
+type Protocol struct {
+	c     *Config
+	net   Network
+	index dkg.Index
+	nodes map[dkg.Index]dkg.Node
+	n     int
+	f     int
+	t     int
+
+	rbcs map[dkg.Index]*rbc
+	// deals delivered by the reliable broadcasts, nil if invalid
+	deals map[dkg.Index]*Deal
+	// shares of this node from each dealer
+	shares map[dkg.Index]kyber.Scalar
+	oks    map[dkg.Index]map[dkg.Index]bool
+	// points received to recover the share from a dealer
+	points map[dkg.Index][]*share.PriShare
+	// OK messages received before the deal was delivered
+	pending map[dkg.Index][]*Message
+	abas    map[dkg.Index]*aba
+	coins   map[coinID]*coinState
+	// coinPub is the public polynomial of the coin key
+	coinPub *share.PubPoly
+	// number of dealers accepted by the agreements
+	accepted int
+	finished bool
+
+	res      chan dkg.OptionResult
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewProtocol checks the config and starts the protocol, which sends its
+// messages through the network.
+func NewProtocol(c *Config, net Network) (*Protocol, error) {
+	if c.Suite == nil || c.Longterm == nil || c.Auth == nil {
+		return nil, fmt.Errorf("%w: needs a suite, a longterm key and an auth scheme", ErrInvalidConfig)
+	}
+	if len(c.Nonce) != dkg.NonceLength {
+		return nil, fmt.Errorf("%w: nonce must be %d bytes", ErrInvalidConfig, dkg.NonceLength)
+	}
+	n := len(c.Nodes)
+	f := (n - 1) / 3
+	t := c.Threshold
+	if t == 0 {
+		t = f + 1
+	}
+	if n == 0 || t < f+1 || t > n-2*f {
+		return nil, fmt.Errorf("%w: threshold %d out of [%d, %d]", ErrInvalidConfig, t, f+1, n-2*f)
+	}
+	p := &Protocol{
+		c:       c,
+		net:     net,
+		nodes:   make(map[dkg.Index]dkg.Node, n),
+		n:       n,
+		f:       f,
+		t:       t,
+		rbcs:    make(map[dkg.Index]*rbc, n),
+		deals:   make(map[dkg.Index]*Deal, n),
+		shares:  make(map[dkg.Index]kyber.Scalar, n),
+		oks:     make(map[dkg.Index]map[dkg.Index]bool, n),
+		points:  make(map[dkg.Index][]*share.PriShare, n),
+		pending: make(map[dkg.Index][]*Message, n),
+		abas:    make(map[dkg.Index]*aba, n),
+		coins:   make(map[coinID]*coinState),
+		res:     make(chan dkg.OptionResult, 1),
+		stop:    make(chan struct{}),
+	}
+	pub := c.Suite.Point().Mul(c.Longterm, nil)
+	self := false
+	for _, node := range c.Nodes {
+		if node.Public == nil {
+			return nil, fmt.Errorf("%w: node %d has no public key", ErrInvalidConfig, node.Index)
+		}
+		if _, ok := p.nodes[node.Index]; ok {
+			return nil, fmt.Errorf("%w: duplicate index %d", ErrInvalidConfig, node.Index)
+		}
+		p.nodes[node.Index] = node
+		if node.Public.Equal(pub) {
+			p.index = node.Index
+			self = true
+		}
+	}
+	if !self {
+		return nil, fmt.Errorf("%w: public key not found in nodes", ErrInvalidConfig)
+	}
+	if err := p.checkCoin(); err != nil {
+		return nil, err
+	}
+	for _, node := range c.Nodes {
+		dealer := node.Index
+		p.rbcs[dealer] = newRBC(n, f)
+		p.oks[dealer] = make(map[dkg.Index]bool)
+		p.abas[dealer] = newABA(n, f, func(k Kind, round uint32, v byte) {
+			p.broadcast(&Message{Kind: k, Dealer: dealer, Round: round, Value: v})
+		}, func(round uint32) (byte, bool) {
+			return p.coin(dealer, round)
+		})
+	}
+	deal, err := p.deal()
+	if err != nil {
+		return nil, err
+	}
+	go p.run(deal)
+	return p, nil
+}
+
+// WaitEnd returns the channel on which the result is sent.
+func (p *Protocol) WaitEnd() <-chan dkg.OptionResult {
+	return p.res
+}
+
+// Stop stops processing the messages.
+func (p *Protocol) Stop() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+func (p *Protocol) run(deal *Deal) {
+	p.broadcast(&Message{Kind: KindSend, Dealer: p.index, Deal: deal})
+	for {
+		select {
+		case <-p.stop:
+			return
+		case m := <-p.net.Incoming():
+			if err := p.process(&m); err != nil {
+				p.error("message", m.Kind, "from", m.Sender, "dealer", m.Dealer, err)
+			}
+		}
+	}
+}
+
+// deal creates the deal of this node.
+func (p *Protocol) deal() (*Deal, error) {
+	g := p.c.Suite
+	secret := g.Scalar().Pick(g.RandomStream())
+	f := newBivariate(g, p.t, secret, g.RandomStream())
+	deal := &Deal{Commits: f.commit()}
+	for _, node := range p.c.Nodes {
+		buf, err := marshalScalars(f.row(node.Index))
+		if err != nil {
+			return nil, err
+		}
+		cipher, err := ecies.Encrypt(g, node.Public, buf, sha256.New)
+		if err != nil {
+			return nil, err
+		}
+		deal.Rows = append(deal.Rows, dkg.Deal{ShareIndex: node.Index, EncryptedShare: cipher})
+	}
+	return deal, nil
+}
+
+func (p *Protocol) broadcast(m *Message) {
+	m.Sender = p.index
+	m.SessionID = p.c.Nonce
+	h, err := m.Hash()
+	if err != nil {
+		p.error("hashing message", err)
+		return
+	}
+	if m.Signature, err = p.c.Auth.Sign(p.c.Longterm, h); err != nil {
+		p.error("signing message", err)
+		return
+	}
+	p.net.Broadcast(m)
+}
+
+// checkCoin checks that the coin key is a valid share for this node.
+func (p *Protocol) checkCoin() error {
+	k := p.c.Coin
+	if k == nil {
+		return nil
+	}
+	t := len(k.Commits)
+	if t < p.f+1 || t > p.n-p.f {
+		return fmt.Errorf("%w: coin threshold %d out of [%d, %d]", ErrInvalidConfig, t, p.f+1, p.n-p.f)
+	}
+	for _, c := range k.Commits {
+		if c == nil {
+			return fmt.Errorf("%w: nil coin commitment", ErrInvalidConfig)
+		}
+	}
+	if k.Share == nil || k.Share.V == nil || k.Share.I != p.index {
+		return fmt.Errorf("%w: coin share must have the index of this node", ErrInvalidConfig)
+	}
+	p.coinPub = share.NewPubPoly(p.c.Suite, nil, k.Commits)
+	if !p.coinPub.Eval(p.index).V.Equal(p.c.Suite.Point().Mul(k.Share.V, nil)) {
+		return fmt.Errorf("%w: coin share doesn't match its commitments", ErrInvalidConfig)
+	}
+	return nil
+}
+
+func (p *Protocol) verify(m *Message) error {
+	sender, ok := p.nodes[m.Sender]
+	if !ok {
+		return fmt.Errorf("async: unknown sender %d", m.Sender)
+	}
+	if _, ok := p.nodes[m.Dealer]; !ok {
+		return fmt.Errorf("async: unknown dealer %d", m.Dealer)
+	}
+	if string(m.SessionID) != string(p.c.Nonce) {
+		return errors.New("async: invalid session id")
+	}
+	h, err := m.Hash()
+	if err != nil {
+		return err
+	}
+	return p.c.Auth.Verify(sender.Public, h, m.Signature)
+}
+
+func (p *Protocol) process(m *Message) error {
+	if err := p.verify(m); err != nil {
+		return err
+	}
+	switch m.Kind {
+	case KindSend, KindEcho:
+		if m.Deal == nil {
+			return errInvalidDeal
+		}
+		p.onDeal(m)
+	case KindReady:
+		r := p.rbcs[m.Dealer]
+		if r.onReady(m.Sender, m.DealHash) {
+			p.broadcast(&Message{Kind: KindReady, Dealer: m.Dealer, DealHash: m.DealHash})
+		}
+		p.deliver(m.Dealer)
+	case KindOK:
+		p.onOK(m)
+	case KindBVal, KindAux, KindConf, KindDecided:
+		p.abas[m.Dealer].handle(m)
+	case KindCoin:
+		if err := p.onCoin(m); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("async: unknown message kind %d", m.Kind)
+	}
+	p.vote()
+	p.finish()
+	return nil
+}
+
+func (p *Protocol) onDeal(m *Message) {
+	r := p.rbcs[m.Dealer]
+	if m.Kind == KindSend {
+		if m.Sender == m.Dealer && r.onSend() {
+			p.broadcast(&Message{Kind: KindEcho, Dealer: m.Dealer, Deal: m.Deal})
+		}
+		return
+	}
+	h, err := m.Deal.Hash()
+	if err != nil {
+		return
+	}
+	if hash := r.onEcho(m.Sender, m.Deal, h); hash != nil {
+		p.broadcast(&Message{Kind: KindReady, Dealer: m.Dealer, DealHash: hash})
+	}
+	p.deliver(m.Dealer)
+}
+
+// deliver checks the deal of the dealer once the reliable broadcast delivers
+// it, and sends the OK message if the row of this node is valid.
+func (p *Protocol) deliver(dealer dkg.Index) {
+	deal := p.rbcs[dealer].deliver()
+	if deal == nil {
+		return
+	}
+	if err := checkCommits(deal.Commits, p.t); err != nil {
+		p.error("deal from", dealer, err)
+		p.deals[dealer] = nil
+		return
+	}
+	p.deals[dealer] = deal
+	defer func() {
+		for _, m := range p.pending[dealer] {
+			p.onOK(m)
+		}
+		delete(p.pending, dealer)
+	}()
+
+	row, err := p.row(deal)
+	if err != nil {
+		p.info("invalid row from", dealer, err)
+		return
+	}
+	p.shares[dealer] = row[0]
+	ok := &Message{Kind: KindOK, Dealer: dealer}
+	for _, node := range p.c.Nodes {
+		buf, err := evalRow(p.c.Suite, row, node.Index).MarshalBinary()
+		if err != nil {
+			p.error("marshalling point", err)
+			return
+		}
+		cipher, err := ecies.Encrypt(p.c.Suite, node.Public, buf, sha256.New)
+		if err != nil {
+			p.error("encrypting point", err)
+			return
+		}
+		ok.Points = append(ok.Points, dkg.Deal{ShareIndex: node.Index, EncryptedShare: cipher})
+	}
+	p.broadcast(ok)
+}
+
+// row decrypts and checks the row of this node in the deal.
+func (p *Protocol) row(deal *Deal) ([]kyber.Scalar, error) {
+	buf, err := p.decrypt(deal.Rows)
+	if err != nil {
+		return nil, err
+	}
+	row, err := unmarshalScalars(p.c.Suite, buf, p.t)
+	if err != nil {
+		return nil, err
+	}
+	if !verifyRow(p.c.Suite, deal.Commits, p.t, p.index, row) {
+		return nil, errInvalidDeal
+	}
+	return row, nil
+}
+
+// decrypt decrypts the entry for this node.
+func (p *Protocol) decrypt(entries []dkg.Deal) ([]byte, error) {
+	for _, e := range entries {
+		if e.ShareIndex == p.index {
+			return ecies.Decrypt(p.c.Suite, p.c.Longterm, e.EncryptedShare, sha256.New)
+		}
+	}
+	return nil, errors.New("async: no entry for this node")
+}
+
+// onOK counts the OK messages of the nodes, and recovers the share of this
+// node from their points if needed.
+func (p *Protocol) onOK(m *Message) {
+	dealer := m.Dealer
+	deal, delivered := p.deals[dealer]
+	if !delivered {
+		if !p.oks[dealer][m.Sender] {
+			p.oks[dealer][m.Sender] = true
+			p.pending[dealer] = append(p.pending[dealer], m)
+		}
+		return
+	}
+	p.oks[dealer][m.Sender] = true
+	if deal == nil || p.shares[dealer] != nil {
+		return
+	}
+	for _, s := range p.points[dealer] {
+		if s.I == m.Sender {
+			return
+		}
+	}
+	buf, err := p.decrypt(m.Points)
+	if err != nil {
+		p.info("invalid point from", m.Sender, err)
+		return
+	}
+	v := p.c.Suite.Scalar()
+	if err := v.UnmarshalBinary(buf); err != nil ||
+		!verifyPoint(p.c.Suite, deal.Commits, p.t, m.Sender, p.index, v) {
+		p.info("invalid point from", m.Sender)
+		return
+	}
+	p.points[dealer] = append(p.points[dealer], &share.PriShare{I: m.Sender, V: v})
+	if len(p.points[dealer]) < p.t {
+		return
+	}
+	// f(j, i) = f(i, j) so the points are on the row of this node
+	s, err := share.RecoverSecret(p.c.Suite, p.points[dealer], p.t, p.n)
+	if err != nil {
+		p.error("recovering share", err)
+		return
+	}
+	p.shares[dealer] = s
+	p.info("recovered share from", dealer)
+}
+
+// vote gives their input to the agreements on the dealers.
+func (p *Protocol) vote() {
+	p.accepted = 0
+	for _, a := range p.abas {
+		if a.decided == 1 {
+			p.accepted++
+		}
+	}
+	for dealer, a := range p.abas {
+		if a.started() {
+			continue
+		}
+		if p.deals[dealer] != nil && len(p.oks[dealer]) >= p.t+p.f {
+			a.input(1)
+		} else if p.accepted >= p.n-p.f {
+			a.input(0)
+		}
+	}
+}
+
+// finish computes the result once all the agreements decided and this node
+// has its share from all the accepted dealers.
+func (p *Protocol) finish() {
+	if p.finished {
+		return
+	}
+	var qual []dkg.Node
+	for _, node := range p.c.Nodes {
+		a := p.abas[node.Index]
+		if a.decided < 0 {
+			return
+		}
+		if a.decided == 0 {
+			continue
+		}
+		if p.deals[node.Index] == nil || p.shares[node.Index] == nil {
+			return
+		}
+		qual = append(qual, node)
+	}
+	p.finished = true
+
+	g := p.c.Suite
+	v := g.Scalar().Zero()
+	commits := make([]kyber.Point, p.t)
+	for k := range commits {
+		commits[k] = g.Point().Null()
+	}
+	for _, node := range qual {
+		v.Add(v, p.shares[node.Index])
+		deal := p.deals[node.Index]
+		for k := range commits {
+			commits[k].Add(commits[k], deal.Commits[k*p.t])
+		}
+	}
+	key := &dkg.DistKeyShare{
+		Commits: commits,
+		Share:   &share.PriShare{I: p.index, V: v},
+	}
+	p.info("finished with", len(qual), "dealers")
+	p.res <- dkg.OptionResult{Result: &dkg.Result{QUAL: qual, Key: key}}
+}
+
+func (p *Protocol) info(keyvals ...interface{}) {
+	if p.c.Log != nil {
+		p.c.Log.Info(append([]interface{}{"async", p.index}, keyvals...)...)
+	}
+}
+
+func (p *Protocol) error(keyvals ...interface{}) {
+	if p.c.Log != nil {
+		p.c.Log.Error(append([]interface{}{"async", p.index}, keyvals...)...)
+	}
+}
@@ -0,0 +1,438 @@
+package async
+
+import (
+	"crypto/sha256"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/encrypt/ecies"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/proof/dleq"
+	"go.dedis.ch/kyber/v4/share"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+var suite = edwards25519.NewBlakeSHA256Ed25519()
+
+// testNet delivers the messages to each node after a random delay. The links
+// of the nodes in a partition are cut until heal is called, and their
+// messages are delivered afterwards.
+type testNet struct {
+	mu        sync.Mutex
+	rand      *rand.Rand
+	queues    []*queue
+	partition map[int]bool
+	held      []func()
+}
+
+// queue is an unbounded queue of messages for one node.
+type queue struct {
+	mu   sync.Mutex
+	msgs []Message
+	wake chan struct{}
+	out  chan Message
+}
+
+func newQueue() *queue {
+	q := &queue{wake: make(chan struct{}, 1), out: make(chan Message)}
+	go func() {
+		for range q.wake {
+			for {
+				q.mu.Lock()
+				if len(q.msgs) == 0 {
+					q.mu.Unlock()
+					break
+				}
+				m := q.msgs[0]
+				q.msgs = q.msgs[1:]
+				q.mu.Unlock()
+				q.out <- m
+			}
+		}
+	}()
+	return q
+}
+
+func (q *queue) push(m Message) {
+	q.mu.Lock()
+	q.msgs = append(q.msgs, m)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func newTestNet(n int) *testNet {
+	tn := &testNet{rand: rand.New(rand.NewSource(42)), partition: make(map[int]bool)}
+	for i := 0; i < n; i++ {
+		tn.queues = append(tn.queues, newQueue())
+	}
+	return tn
+}
+
+func (tn *testNet) delay() time.Duration {
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+	return time.Duration(tn.rand.Intn(2000)) * time.Microsecond
+}
+
+// send delivers the message to node i.
+func (tn *testNet) send(from, i int, m Message) {
+	deliver := func() {
+		time.AfterFunc(tn.delay(), func() { tn.queues[i].push(m) })
+	}
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+	if from != i && (tn.partition[from] || tn.partition[i]) {
+		tn.held = append(tn.held, deliver)
+		return
+	}
+	go deliver()
+}
+
+func (tn *testNet) heal() {
+	tn.mu.Lock()
+	held := tn.held
+	tn.held = nil
+	tn.partition = make(map[int]bool)
+	tn.mu.Unlock()
+	for _, deliver := range held {
+		deliver()
+	}
+}
+
+func (tn *testNet) node(i int) Network {
+	return &testNode{tn: tn, i: i}
+}
+
+type testNode struct {
+	tn *testNet
+	i  int
+}
+
+func (n *testNode) Broadcast(m *Message) {
+	for j := range n.tn.queues {
+		n.tn.send(n.i, j, *m)
+	}
+}
+
+func (n *testNode) Incoming() <-chan Message {
+	return n.tn.queues[n.i].out
+}
+
+type testSetup struct {
+	keys  []kyber.Scalar
+	nodes []dkg.Node
+	nonce []byte
+	net   *testNet
+}
+
+func newTestSetup(n int) *testSetup {
+	ts := &testSetup{nonce: dkg.GetNonce(), net: newTestNet(n)}
+	for i := 0; i < n; i++ {
+		key := suite.Scalar().Pick(random.New())
+		ts.keys = append(ts.keys, key)
+		ts.nodes = append(ts.nodes, dkg.Node{Index: uint32(i), Public: suite.Point().Mul(key, nil)})
+	}
+	return ts
+}
+
+func (ts *testSetup) config(i int) *Config {
+	return &Config{
+		Suite:    suite,
+		Longterm: ts.keys[i],
+		Nodes:    ts.nodes,
+		Nonce:    ts.nonce,
+		Auth:     schnorr.NewScheme(suite),
+	}
+}
+
+func (ts *testSetup) start(t *testing.T, indices ...int) []*Protocol {
+	var protos []*Protocol
+	for _, i := range indices {
+		p, err := NewProtocol(ts.config(i), ts.net.node(i))
+		require.NoError(t, err)
+		t.Cleanup(p.Stop)
+		protos = append(protos, p)
+	}
+	return protos
+}
+
+func waitResults(t *testing.T, protos []*Protocol) []*dkg.Result {
+	var results []*dkg.Result
+	for _, p := range protos {
+		select {
+		case res := <-p.WaitEnd():
+			require.NoError(t, res.Error)
+			results = append(results, res.Result)
+		case <-time.After(30 * time.Second):
+			t.Fatal("dkg did not finish")
+		}
+	}
+	return results
+}
+
+// checkResults verifies that the nodes agree on the distributed key, and
+// that their shares are consistent with it.
+func checkResults(t *testing.T, results []*dkg.Result, threshold int) {
+	var shares []*share.PriShare
+	for _, r := range results {
+		require.True(t, results[0].PublicEqual(r))
+		require.Len(t, r.Key.Commits, threshold)
+		pub := share.NewPubPoly(suite, nil, r.Key.Commits)
+		require.True(t, pub.Eval(r.Key.Share.I).V.Equal(suite.Point().Mul(r.Key.Share.V, nil)))
+		shares = append(shares, r.Key.Share)
+	}
+	secret, err := share.RecoverSecret(suite, shares, threshold, len(shares))
+	require.NoError(t, err)
+	require.True(t, results[0].Key.Public().Equal(suite.Point().Mul(secret, nil)))
+}
+
+func TestAsyncDKG(t *testing.T) {
+	for _, n := range []int{4, 7} {
+		ts := newTestSetup(n)
+		protos := ts.start(t, allIndices(n)...)
+		results := waitResults(t, protos)
+		require.GreaterOrEqual(t, len(results[0].QUAL), n-(n-1)/3)
+		checkResults(t, results, (n-1)/3+1)
+	}
+}
+
+func allIndices(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+func TestAsyncDKGThreshold(t *testing.T) {
+	ts := newTestSetup(7)
+	var protos []*Protocol
+	for i := 0; i < 7; i++ {
+		c := ts.config(i)
+		c.Threshold = 3
+		p, err := NewProtocol(c, ts.net.node(i))
+		require.NoError(t, err)
+		t.Cleanup(p.Stop)
+		protos = append(protos, p)
+	}
+	checkResults(t, waitResults(t, protos), 3)
+}
+
+func TestAsyncDKGCrash(t *testing.T) {
+	// the last node never starts
+	ts := newTestSetup(4)
+	protos := ts.start(t, 0, 1, 2)
+	results := waitResults(t, protos)
+	require.Len(t, results[0].QUAL, 3)
+	checkResults(t, results, 2)
+}
+
+func TestAsyncDKGPartition(t *testing.T) {
+	// the last node is cut from the others: they finish without it, and it
+	// finishes with the same key once the partition heals
+	ts := newTestSetup(4)
+	ts.net.partition[3] = true
+	protos := ts.start(t, allIndices(4)...)
+	results := waitResults(t, protos[:3])
+	select {
+	case <-protos[3].WaitEnd():
+		t.Fatal("partitioned node finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+	ts.net.heal()
+	results = append(results, waitResults(t, protos[3:])...)
+	checkResults(t, results, 2)
+}
+
+func TestAsyncDKGRecovery(t *testing.T) {
+	// the first node is a byzantine dealer giving an invalid row to the
+	// second node, which recovers its share from the other nodes
+	n, threshold := 7, 3
+	ts := newTestSetup(n)
+	f := newBivariate(suite, threshold, suite.Scalar().Pick(random.New()), random.New())
+	deal := &Deal{Commits: f.commit()}
+	for _, node := range ts.nodes {
+		row := f.row(node.Index)
+		if node.Index == 1 {
+			row[0] = suite.Scalar().Pick(random.New())
+		}
+		buf, err := marshalScalars(row)
+		require.NoError(t, err)
+		cipher, err := ecies.Encrypt(suite, node.Public, buf, sha256.New)
+		require.NoError(t, err)
+		deal.Rows = append(deal.Rows, dkg.Deal{ShareIndex: node.Index, EncryptedShare: cipher})
+	}
+	m := &Message{Kind: KindSend, Sender: 0, Dealer: 0, Deal: deal, SessionID: ts.nonce}
+	h, err := m.Hash()
+	require.NoError(t, err)
+	m.Signature, err = schnorr.Sign(suite, ts.keys[0], h)
+	require.NoError(t, err)
+	ts.net.node(0).Broadcast(m)
+
+	var protos []*Protocol
+	for i := 1; i < n; i++ {
+		c := ts.config(i)
+		c.Threshold = threshold
+		p, err := NewProtocol(c, ts.net.node(i))
+		require.NoError(t, err)
+		t.Cleanup(p.Stop)
+		protos = append(protos, p)
+	}
+	// when the byzantine dealer is accepted, the share of the second node
+	// checked against the public polynomial includes the recovered share
+	checkResults(t, waitResults(t, protos), threshold)
+}
+
+// coinKeys returns the shares of a key used for the common coin.
+func coinKeys(n, threshold int) []*dkg.DistKeyShare {
+	poly := share.NewPriPoly(suite, threshold, nil, random.New())
+	_, commits := poly.Commit(nil).Info()
+	var keys []*dkg.DistKeyShare
+	for _, s := range poly.Shares(n) {
+		keys = append(keys, &dkg.DistKeyShare{Commits: commits, Share: s})
+	}
+	return keys
+}
+
+// countingNode counts the coin shares broadcast by a node.
+type countingNode struct {
+	Network
+	coins *int64
+}
+
+func (n *countingNode) Broadcast(m *Message) {
+	if m.Kind == KindCoin {
+		atomic.AddInt64(n.coins, 1)
+	}
+	n.Network.Broadcast(m)
+}
+
+func TestAsyncDKGCoin(t *testing.T) {
+	n := 4
+	ts := newTestSetup(n)
+	keys := coinKeys(n, 2)
+	var coins int64
+	var protos []*Protocol
+	for i := 0; i < n; i++ {
+		c := ts.config(i)
+		c.Coin = keys[i]
+		p, err := NewProtocol(c, &countingNode{Network: ts.net.node(i), coins: &coins})
+		require.NoError(t, err)
+		t.Cleanup(p.Stop)
+		protos = append(protos, p)
+	}
+	checkResults(t, waitResults(t, protos), 2)
+	require.NotZero(t, atomic.LoadInt64(&coins))
+}
+
+func TestAsyncCoinShare(t *testing.T) {
+	n := 4
+	ts := newTestSetup(n)
+	keys := coinKeys(n, 2)
+	var protos []*Protocol
+	for i := 0; i < 2; i++ {
+		c := ts.config(i)
+		c.Coin = keys[i]
+		protos = append(protos, &Protocol{c: c, net: ts.net.node(i), index: dkg.Index(i), n: n, f: 1,
+			abas:  map[dkg.Index]*aba{0: newABA(n, 1, nil, nil)},
+			coins: make(map[coinID]*coinState)})
+		require.NoError(t, protos[i].checkCoin())
+	}
+	coinShare := func(i int) *Message {
+		H := protos[i].coinBase(0, 3)
+		proof, _, xH, err := dleq.NewDLEQProof(suite, suite.Point().Base(), H, keys[i].Share.V)
+		require.NoError(t, err)
+		return &Message{Kind: KindCoin, Sender: dkg.Index(i), Dealer: 0, Round: 3, CoinShare: xH, CoinProof: proof}
+	}
+	p := protos[0]
+
+	// a share computed with another key is rejected
+	m := coinShare(1)
+	m.Sender = 2
+	require.ErrorIs(t, p.onCoin(m), errInvalidCoin)
+	m = coinShare(1)
+	m.CoinShare = suite.Point().Pick(random.New())
+	require.ErrorIs(t, p.onCoin(m), errInvalidCoin)
+
+	// the coin is known once threshold shares are received
+	_, ok := p.coin(0, 3)
+	require.False(t, ok)
+	require.NoError(t, p.onCoin(coinShare(0)))
+	require.NoError(t, p.onCoin(coinShare(0)))
+	_, ok = p.coin(0, 3)
+	require.False(t, ok)
+	require.NoError(t, p.onCoin(coinShare(1)))
+	v, ok := p.coin(0, 3)
+	require.True(t, ok)
+
+	// any threshold shares give the same coin
+	q := protos[1]
+	require.NoError(t, q.onCoin(coinShare(1)))
+	require.NoError(t, q.onCoin(coinShare(0)))
+	w, ok := q.coin(0, 3)
+	require.True(t, ok)
+	require.Equal(t, v, w)
+}
+
+func TestAsyncConfig(t *testing.T) {
+	ts := newTestSetup(4)
+	c := ts.config(0)
+	c.Threshold = 3
+	_, err := NewProtocol(c, ts.net.node(0))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	c = ts.config(0)
+	c.Nonce = []byte("short")
+	_, err = NewProtocol(c, ts.net.node(0))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	c = ts.config(0)
+	c.Longterm = suite.Scalar().Pick(random.New())
+	_, err = NewProtocol(c, ts.net.node(0))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	// the coin key must have a threshold in [f+1, n-f] and the index and a
+	// valid share of this node
+	keys := coinKeys(4, 2)
+	for _, k := range []*dkg.DistKeyShare{
+		coinKeys(4, 1)[0],
+		coinKeys(4, 4)[0],
+		keys[1],
+		{Commits: keys[0].Commits, Share: &share.PriShare{I: 0, V: suite.Scalar().Pick(random.New())}},
+		{Commits: keys[0].Commits},
+	} {
+		c = ts.config(0)
+		c.Coin = k
+		_, err = NewProtocol(c, ts.net.node(0))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	}
+}
+
+func TestBivariate(t *testing.T) {
+	threshold := 3
+	f := newBivariate(suite, threshold, suite.Scalar().Pick(random.New()), random.New())
+	commits := f.commit()
+	require.NoError(t, checkCommits(commits, threshold))
+	for i := uint32(0); i < 4; i++ {
+		row := f.row(i)
+		require.True(t, verifyRow(suite, commits, threshold, i, row))
+		for j := uint32(0); j < 4; j++ {
+			v := evalRow(suite, row, j)
+			require.True(t, v.Equal(evalRow(suite, f.row(j), i)))
+			require.True(t, verifyPoint(suite, commits, threshold, i, j, v))
+		}
+	}
+	bad := f.row(0)
+	bad[1] = suite.Scalar().Pick(random.New())
+	require.False(t, verifyRow(suite, commits, threshold, 0, bad))
+	commits[1] = suite.Point().Pick(random.New())
+	require.Error(t, checkCommits(commits, threshold))
+}
@@ -0,0 +1,138 @@
+package async
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/proof/dleq"
+	"go.dedis.ch/kyber/v4/share"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+)
+
+var errInvalidCoin = errors.New("async: invalid coin share")
+
+type coinID struct {
+	dealer dkg.Index
+	round  uint32
+}
+
+// coinState collects the shares of the threshold coin of a round.
+type coinState struct {
+	sent   bool
+	shares []*share.PubShare
+	// value is the coin, or -1 until enough shares are received
+	value int
+}
+
+// coin returns the common coin of a round of the agreement on a dealer, and
+// false if it isn't known yet.
+//
+// With a coin key, the coin is derived from the threshold signature
+// x·H(nonce, dealer, round) of the key: no coalition of fewer than Threshold
+// nodes of the coin key learns it before an honest node reaches the round and
+// sends its share. Without a coin key, it's a hash of the nonce, which
+// everyone can predict.
+func (p *Protocol) coin(dealer dkg.Index, round uint32) (byte, bool) {
+	if p.c.Coin == nil {
+		h := sha256.New()
+		h.Write([]byte(domain))
+		h.Write(p.c.Nonce)
+		_ = binary.Write(h, binary.BigEndian, dealer)
+		_ = binary.Write(h, binary.BigEndian, round)
+		return h.Sum(nil)[0] & 1, true
+	}
+	st := p.coinState(dealer, round)
+	if st.value >= 0 {
+		return byte(st.value), true
+	}
+	if !st.sent {
+		st.sent = true
+		g := p.c.Suite
+		proof, _, xH, err := dleq.NewDLEQProof(g, g.Point().Base(), p.coinBase(dealer, round), p.c.Coin.Share.V)
+		if err != nil {
+			p.error("coin proof", err)
+			return 0, false
+		}
+		p.broadcast(&Message{Kind: KindCoin, Dealer: dealer, Round: round, CoinShare: xH, CoinProof: proof})
+	}
+	return 0, false
+}
+
+func (p *Protocol) coinState(dealer dkg.Index, round uint32) *coinState {
+	id := coinID{dealer, round}
+	st, ok := p.coins[id]
+	if !ok {
+		st = &coinState{value: -1}
+		p.coins[id] = st
+	}
+	return st
+}
+
+// coinBase returns the point signed by the coin key for a round.
+func (p *Protocol) coinBase(dealer dkg.Index, round uint32) kyber.Point {
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:4], dealer)
+	binary.BigEndian.PutUint32(b[4:], round)
+	xof := p.c.Suite.XOF([]byte(domain + " coin"))
+	_, _ = xof.Write(p.c.Nonce)
+	_, _ = xof.Write(b[:])
+	return p.c.Suite.Point().Pick(xof)
+}
+
+// onCoin checks a share of the coin of a round, and computes the coin once
+// Threshold of them are received.
+func (p *Protocol) onCoin(m *Message) error {
+	if p.c.Coin == nil {
+		return nil
+	}
+	a := p.abas[m.Dealer]
+	if m.Round > a.round+maxRoundsAhead {
+		return nil
+	}
+	st := p.coinState(m.Dealer, m.Round)
+	if st.value >= 0 {
+		return nil
+	}
+	for _, s := range st.shares {
+		if s.I == m.Sender {
+			return nil
+		}
+	}
+	if m.CoinShare == nil || m.CoinProof == nil {
+		return errInvalidCoin
+	}
+	g := p.c.Suite
+	H := p.coinBase(m.Dealer, m.Round)
+	X := p.coinPub.Eval(m.Sender).V
+	pr := m.CoinProof
+	c, err := dleq.Challenge(g, []kyber.Point{g.Point().Base()}, []kyber.Point{H},
+		[]kyber.Point{X}, []kyber.Point{m.CoinShare}, []kyber.Point{pr.VG}, []kyber.Point{pr.VH})
+	if err != nil {
+		return err
+	}
+	if !c.Equal(pr.C) || pr.Verify(g, g.Point().Base(), H, X, m.CoinShare) != nil {
+		return errInvalidCoin
+	}
+	st.shares = append(st.shares, &share.PubShare{I: m.Sender, V: m.CoinShare})
+	t := len(p.c.Coin.Commits)
+	if len(st.shares) < t {
+		return nil
+	}
+	sig, err := share.RecoverCommit(g, st.shares, t, p.n)
+	if err != nil {
+		return err
+	}
+	buf, err := sig.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	h.Write([]byte(domain))
+	h.Write(buf)
+	st.value = int(h.Sum(nil)[0] & 1)
+	st.shares = nil
+	a.step()
+	return nil
+}
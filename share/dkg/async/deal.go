@@ -0,0 +1,165 @@
+package async
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/internal/multiexp"
+)
+
+var errInvalidDeal = errors.New("async: invalid deal")
+
+// bivariate is a symmetric bivariate polynomial f(x, y) = sum a[k][l] x^k y^l
+// of degree t-1 in each variable. Node i holds the row polynomial f(i, y),
+// so that f(i, 0) is its share of f(x, 0), and f(i, j) = f(j, i) is a point
+// on the row of node j, which lets j recover its row from t other nodes.
+//
+// As in package share, node i is evaluated at x = i+1.
+type bivariate struct {
+	g kyber.Group
+	a [][]kyber.Scalar
+}
+
+func newBivariate(g kyber.Group, t int, secret kyber.Scalar, rand cipher.Stream) *bivariate {
+	a := make([][]kyber.Scalar, t)
+	for k := range a {
+		a[k] = make([]kyber.Scalar, t)
+	}
+	for k := 0; k < t; k++ {
+		for l := k; l < t; l++ {
+			if k == 0 && l == 0 {
+				a[0][0] = secret
+				continue
+			}
+			a[k][l] = g.Scalar().Pick(rand)
+			a[l][k] = a[k][l]
+		}
+	}
+	return &bivariate{g: g, a: a}
+}
+
+// commit returns the commitments to the coefficients, row by row.
+func (b *bivariate) commit() []kyber.Point {
+	t := len(b.a)
+	commits := make([]kyber.Point, 0, t*t)
+	for k := 0; k < t; k++ {
+		for l := 0; l < t; l++ {
+			commits = append(commits, b.g.Point().Mul(b.a[k][l], nil))
+		}
+	}
+	return commits
+}
+
+// row returns the coefficients of f(i, y).
+func (b *bivariate) row(i uint32) []kyber.Scalar {
+	t := len(b.a)
+	powers := powers(b.g, i, t)
+	row := make([]kyber.Scalar, t)
+	for l := 0; l < t; l++ {
+		row[l] = b.g.Scalar().Zero()
+		for k := 0; k < t; k++ {
+			row[l].Add(row[l], b.g.Scalar().Mul(powers[k], b.a[k][l]))
+		}
+	}
+	return row
+}
+
+// powers returns (i+1)^0, ..., (i+1)^(t-1).
+func powers(g kyber.Group, i uint32, t int) []kyber.Scalar {
+	x := g.Scalar().SetInt64(int64(i) + 1)
+	p := make([]kyber.Scalar, t)
+	p[0] = g.Scalar().One()
+	for k := 1; k < t; k++ {
+		p[k] = g.Scalar().Mul(p[k-1], x)
+	}
+	return p
+}
+
+// evalRow evaluates the row polynomial at node j.
+func evalRow(g kyber.Group, row []kyber.Scalar, j uint32) kyber.Scalar {
+	x := g.Scalar().SetInt64(int64(j) + 1)
+	v := g.Scalar().Zero()
+	for l := len(row) - 1; l >= 0; l-- {
+		v.Mul(v, x)
+		v.Add(v, row[l])
+	}
+	return v
+}
+
+// checkCommits verifies that the commitments form a symmetric t*t matrix.
+func checkCommits(commits []kyber.Point, t int) error {
+	if len(commits) != t*t {
+		return errInvalidDeal
+	}
+	for _, c := range commits {
+		if c == nil {
+			return errInvalidDeal
+		}
+	}
+	for k := 0; k < t; k++ {
+		for l := k + 1; l < t; l++ {
+			if !commits[k*t+l].Equal(commits[l*t+k]) {
+				return errInvalidDeal
+			}
+		}
+	}
+	return nil
+}
+
+// verifyRow checks the row polynomial of node i against the commitments.
+func verifyRow(g kyber.Group, commits []kyber.Point, t int, i uint32, row []kyber.Scalar) bool {
+	if len(row) != t {
+		return false
+	}
+	powers := powers(g, i, t)
+	col := make([]kyber.Point, t)
+	for l := 0; l < t; l++ {
+		for k := 0; k < t; k++ {
+			col[k] = commits[k*t+l]
+		}
+		if !multiexp.MultiExp(g, powers, col).Equal(g.Point().Mul(row[l], nil)) {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyPoint checks that v = f(i, j) against the commitments.
+func verifyPoint(g kyber.Group, commits []kyber.Point, t int, i, j uint32, v kyber.Scalar) bool {
+	pi, pj := powers(g, i, t), powers(g, j, t)
+	scalars := make([]kyber.Scalar, 0, t*t)
+	for k := 0; k < t; k++ {
+		for l := 0; l < t; l++ {
+			scalars = append(scalars, g.Scalar().Mul(pi[k], pj[l]))
+		}
+	}
+	return multiexp.MultiExp(g, scalars, commits).Equal(g.Point().Mul(v, nil))
+}
+
+func marshalScalars(scalars []kyber.Scalar) ([]byte, error) {
+	var buf []byte
+	for _, s := range scalars {
+		b, err := s.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, b...)
+	}
+	return buf, nil
+}
+
+func unmarshalScalars(g kyber.Group, buf []byte, t int) ([]kyber.Scalar, error) {
+	size := g.ScalarLen()
+	if len(buf) != t*size {
+		return nil, errInvalidDeal
+	}
+	scalars := make([]kyber.Scalar, t)
+	for k := range scalars {
+		scalars[k] = g.Scalar()
+		if err := scalars[k].UnmarshalBinary(buf[k*size : (k+1)*size]); err != nil {
+			return nil, err
+		}
+	}
+	return scalars, nil
+}
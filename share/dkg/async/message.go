@@ -0,0 +1,191 @@
+package async
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/proof/dleq"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+)
+
+// domain separates the signatures of the messages from other signatures made
+// with the longterm keys.
+const domain = "kyber async dkg v1"
+
+// Kind is the type of a message.
+type Kind byte
+
+const (
+	// KindSend is the deal broadcast by its dealer.
+	KindSend Kind = iota + 1
+	// KindEcho relays the deal of a dealer.
+	KindEcho
+	// KindReady carries the hash of the deal a node is ready to deliver.
+	KindReady
+	// KindOK tells that the share from a dealer is valid, and carries the
+	// points other nodes use to recover their own share.
+	KindOK
+	// KindBVal is a value broadcast in a round of a binary agreement.
+	KindBVal
+	// KindAux is an auxiliary value of a round of a binary agreement.
+	KindAux
+	// KindConf is the set of values a node confirms in a round of a binary
+	// agreement.
+	KindConf
+	// KindDecided tells the value decided by a binary agreement.
+	KindDecided
+	// KindCoin carries the share of a node of the threshold coin of a round
+	// of a binary agreement.
+	KindCoin
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindSend:
+		return "send"
+	case KindEcho:
+		return "echo"
+	case KindReady:
+		return "ready"
+	case KindOK:
+		return "ok"
+	case KindBVal:
+		return "bval"
+	case KindAux:
+		return "aux"
+	case KindConf:
+		return "conf"
+	case KindDecided:
+		return "decided"
+	case KindCoin:
+		return "coin"
+	default:
+		return fmt.Sprintf("kind(%d)", byte(k))
+	}
+}
+
+// Deal is what a dealer broadcasts: the commitments to the coefficients of a
+// symmetric bivariate polynomial f, and for each node i the row polynomial
+// f(i, y), encrypted to the longterm key of i.
+type Deal struct {
+	// Commits is the matrix of the commitments to the coefficients of f, row
+	// by row. It has Threshold*Threshold entries.
+	Commits []kyber.Point
+	// Rows holds the encrypted coefficients of the row polynomial of each
+	// node.
+	Rows []dkg.Deal
+}
+
+// Message is exchanged between the nodes. All the messages of a node are
+// signed with its longterm key.
+type Message struct {
+	Kind   Kind
+	Sender dkg.Index
+	// Dealer is the dealer whose deal or binary agreement the message is
+	// about.
+	Dealer dkg.Index
+	// Round of the binary agreement.
+	Round uint32
+	// Value is the binary value of a KindBVal, KindAux or KindDecided
+	// message, or the set of values of a KindConf message, where bit b is set
+	// when b is in the set.
+	Value byte
+	// Hash of the deal for a KindReady message.
+	DealHash []byte
+	// Deal for KindSend and KindEcho messages.
+	Deal *Deal
+	// Points for a KindOK message: the evaluations of the row polynomial of
+	// the sender, each encrypted to the node it's meant for.
+	Points []dkg.Deal
+	// CoinShare and CoinProof for a KindCoin message: the share of the coin
+	// and the proof that it's computed with the share of the coin key of the
+	// sender.
+	CoinShare kyber.Point
+	CoinProof *dleq.Proof
+	SessionID []byte
+	Signature []byte
+}
+
+// Hash returns the hash of the deal.
+func (d *Deal) Hash() ([]byte, error) {
+	h := sha256.New()
+	_ = binary.Write(h, binary.BigEndian, uint32(len(d.Commits)))
+	for _, c := range d.Commits {
+		if c == nil {
+			return nil, errInvalidDeal
+		}
+		if _, err := c.MarshalTo(h); err != nil {
+			return nil, err
+		}
+	}
+	writeDeals(h, d.Rows)
+	return h.Sum(nil), nil
+}
+
+func writeDeals(b interface{ Write([]byte) (int, error) }, deals []dkg.Deal) {
+	_ = binary.Write(b, binary.BigEndian, uint32(len(deals)))
+	for _, d := range deals {
+		_ = binary.Write(b, binary.BigEndian, d.ShareIndex)
+		_ = binary.Write(b, binary.BigEndian, uint32(len(d.EncryptedShare)))
+		_, _ = b.Write(d.EncryptedShare)
+	}
+}
+
+// Hash returns the hash signed by the sender of the message.
+func (m *Message) Hash() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(domain)
+	b.WriteByte(byte(m.Kind))
+	_ = binary.Write(&b, binary.BigEndian, m.Sender)
+	_ = binary.Write(&b, binary.BigEndian, m.Dealer)
+	_ = binary.Write(&b, binary.BigEndian, m.Round)
+	b.WriteByte(m.Value)
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(m.DealHash)))
+	b.Write(m.DealHash)
+	if m.Deal != nil {
+		b.WriteByte(1)
+		h, err := m.Deal.Hash()
+		if err != nil {
+			return nil, err
+		}
+		b.Write(h)
+	} else {
+		b.WriteByte(0)
+	}
+	writeDeals(&b, m.Points)
+	if m.CoinShare != nil || m.CoinProof != nil {
+		if m.CoinShare == nil || m.CoinProof == nil {
+			return nil, errInvalidCoin
+		}
+		b.WriteByte(1)
+		pr := m.CoinProof
+		for _, e := range []kyber.Marshaling{m.CoinShare, pr.C, pr.R, pr.VG, pr.VH} {
+			if e == nil {
+				return nil, errInvalidCoin
+			}
+			if _, err := e.MarshalTo(&b); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		b.WriteByte(0)
+	}
+	_ = binary.Write(&b, binary.BigEndian, uint32(len(m.SessionID)))
+	b.Write(m.SessionID)
+	h := sha256.Sum256(b.Bytes())
+	return h[:], nil
+}
+
+// Network delivers the messages between the nodes. The network may delay and
+// reorder the messages arbitrarily, but a message broadcast by an honest node
+// must eventually reach all the honest nodes.
+type Network interface {
+	// Broadcast sends the message to all the nodes, including the sender. It
+	// must not block on the delivery of the message.
+	Broadcast(m *Message)
+	// Incoming returns the messages received from the other nodes.
+	Incoming() <-chan Message
+}
@@ -0,0 +1,95 @@
+package async
+
+import (
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+)
+
+// rbc is Bracha's reliable broadcast of the deal of one dealer. If an honest
+// node delivers a deal, all the honest nodes eventually deliver the same
+// deal, even if the dealer sends different deals to different nodes.
+type rbc struct {
+	n, f int
+	// deals received in the echoes, by hash
+	deals   map[string]*Deal
+	echoes  map[string]int
+	readies map[string]int
+	// only the first echo and ready of each node are counted
+	echoFrom  map[dkg.Index]bool
+	readyFrom map[dkg.Index]bool
+	echoed    bool
+	readied   bool
+	delivered *Deal
+}
+
+func newRBC(n, f int) *rbc {
+	return &rbc{
+		n:         n,
+		f:         f,
+		deals:     make(map[string]*Deal),
+		echoes:    make(map[string]int),
+		readies:   make(map[string]int),
+		echoFrom:  make(map[dkg.Index]bool),
+		readyFrom: make(map[dkg.Index]bool),
+	}
+}
+
+// onSend returns true if the deal sent by the dealer must be echoed.
+func (r *rbc) onSend() bool {
+	if r.echoed {
+		return false
+	}
+	r.echoed = true
+	return true
+}
+
+// onEcho records the echo and returns the hash of the deal if this node must
+// send its ready message.
+func (r *rbc) onEcho(from dkg.Index, d *Deal, hash []byte) []byte {
+	if r.echoFrom[from] {
+		return nil
+	}
+	r.echoFrom[from] = true
+	h := string(hash)
+	if _, ok := r.deals[h]; !ok {
+		r.deals[h] = d
+	}
+	r.echoes[h]++
+	if !r.readied && r.echoes[h] >= (r.n+r.f)/2+1 {
+		r.readied = true
+		return hash
+	}
+	return nil
+}
+
+// onReady records the ready message and returns true if this node must send
+// its ready message for the same hash.
+func (r *rbc) onReady(from dkg.Index, hash []byte) bool {
+	if r.readyFrom[from] {
+		return false
+	}
+	r.readyFrom[from] = true
+	r.readies[string(hash)]++
+	if !r.readied && r.readies[string(hash)] >= r.f+1 {
+		r.readied = true
+		return true
+	}
+	return false
+}
+
+// deliver returns the deal the first time it can be delivered, that is when
+// 2f+1 nodes are ready to deliver it and its content is known.
+func (r *rbc) deliver() *Deal {
+	if r.delivered != nil {
+		return nil
+	}
+	for h, c := range r.readies {
+		if c < 2*r.f+1 {
+			continue
+		}
+		if d, ok := r.deals[h]; ok {
+			r.delivered = d
+			return d
+		}
+	}
+	return nil
+}
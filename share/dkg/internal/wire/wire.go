@@ -0,0 +1,258 @@
+// Package wire implements the framing shared by the binary encodings of the
+// DKG packages. An encoding starts with the version of the format, the type
+// of the object and the name of its suite, and continues with big-endian
+// integers, length-prefixed byte strings and fixed-size group elements.
+package wire
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+// Format holds the parameters of the encodings of a package.
+type Format struct {
+	// Version is the first byte of the encodings.
+	Version byte
+	// MaxEntries bounds the number of entries of a list.
+	MaxEntries int
+	// Err is wrapped by the errors of the encoders and decoders.
+	Err error
+}
+
+// Encoder writes the fields of an encoding. The first error is kept and
+// returned by Finish.
+type Encoder struct {
+	f   *Format
+	buf []byte
+	err error
+}
+
+// NewEncoder writes the header of an object of type tag encoded with the
+// suite of this name.
+func (f *Format) NewEncoder(tag byte, suite string) *Encoder {
+	e := &Encoder{f: f, buf: []byte{f.Version, tag}}
+	if len(suite) > math.MaxUint8 {
+		e.Fail("suite name too long")
+	}
+	e.buf = append(e.buf, byte(len(suite)))
+	e.buf = append(e.buf, suite...)
+	return e
+}
+
+// Fail records an error, unless there is already one.
+func (e *Encoder) Fail(msg string) {
+	if e.err == nil {
+		e.err = fmt.Errorf("%w: %s", e.f.Err, msg)
+	}
+}
+
+// Byte writes a single byte.
+func (e *Encoder) Byte(b byte) {
+	e.buf = append(e.buf, b)
+}
+
+// Uint32 writes a 4-byte integer.
+func (e *Encoder) Uint32(v uint32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+// Count writes a number of entries, which must not exceed MaxEntries.
+func (e *Encoder) Count(n int) {
+	if n > e.f.MaxEntries {
+		e.Fail("too many entries")
+	}
+	e.Uint32(uint32(n))
+}
+
+// Bytes16 writes a byte string preceded by its 2-byte length.
+func (e *Encoder) Bytes16(b []byte) {
+	if len(b) > math.MaxUint16 {
+		e.Fail("field too long")
+		return
+	}
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// Bytes32 writes a byte string preceded by its 4-byte length.
+func (e *Encoder) Bytes32(b []byte) {
+	e.Uint32(uint32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// Marshal writes the encodings of group elements, without length prefix.
+func (e *Encoder) Marshal(ms ...kyber.Marshaling) {
+	for _, m := range ms {
+		if m == nil {
+			e.Fail("nil element")
+			return
+		}
+		b, err := m.MarshalBinary()
+		if err != nil {
+			if e.err == nil {
+				e.err = err
+			}
+			return
+		}
+		e.buf = append(e.buf, b...)
+	}
+}
+
+// Points writes a list of points preceded by its length.
+func (e *Encoder) Points(ps []kyber.Point) {
+	e.Count(len(ps))
+	for _, p := range ps {
+		e.Marshal(p)
+	}
+}
+
+// Finish returns the encoding, or the first error.
+func (e *Encoder) Finish() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// Decoder reads the fields of an encoding. The first error is kept and the
+// following reads return zero values.
+type Decoder struct {
+	f   *Format
+	buf []byte
+	err error
+}
+
+// NewDecoder reads the header and checks that it's the one of an object of
+// type tag encoded with the suite of this name.
+func (f *Format) NewDecoder(buf []byte, tag byte, suite string) (*Decoder, error) {
+	r := &Decoder{f: f, buf: buf}
+	if r.Byte() != f.Version {
+		return nil, fmt.Errorf("%w: unsupported version", f.Err)
+	}
+	if r.Byte() != tag {
+		return nil, fmt.Errorf("%w: wrong object type", f.Err)
+	}
+	name := string(r.next(int(r.Byte())))
+	if r.err != nil {
+		return nil, r.err
+	}
+	if name != suite {
+		return nil, fmt.Errorf("%w: suite %q instead of %q", f.Err, name, suite)
+	}
+	return r, nil
+}
+
+// Fail records an error, unless there is already one, and drops the
+// remaining data.
+func (r *Decoder) Fail(msg string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: %s", r.f.Err, msg)
+	}
+	r.buf = nil
+}
+
+// Len returns the number of bytes left.
+func (r *Decoder) Len() int {
+	return len(r.buf)
+}
+
+func (r *Decoder) next(n int) []byte {
+	if r.err != nil || n > len(r.buf) {
+		r.Fail("truncated data")
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+// Byte reads a single byte.
+func (r *Decoder) Byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// Uint32 reads a 4-byte integer.
+func (r *Decoder) Uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// Count reads a number of entries, each of at least minSize bytes, and checks
+// it against MaxEntries and the remaining data.
+func (r *Decoder) Count(minSize int) int {
+	n := r.Uint32()
+	if uint64(n) > uint64(r.f.MaxEntries) || uint64(n)*uint64(minSize) > uint64(len(r.buf)) {
+		r.Fail("too many entries")
+		return 0
+	}
+	return int(n)
+}
+
+// Bytes16 reads a byte string written by Encoder.Bytes16.
+func (r *Decoder) Bytes16() []byte {
+	b := r.next(2)
+	if b == nil {
+		return nil
+	}
+	return r.copy(int(binary.BigEndian.Uint16(b)))
+}
+
+// Bytes32 reads a byte string written by Encoder.Bytes32, of at most max
+// bytes.
+func (r *Decoder) Bytes32(max int) []byte {
+	n := r.Uint32()
+	if uint64(n) > uint64(max) {
+		r.Fail("field too long")
+		return nil
+	}
+	return r.copy(int(n))
+}
+
+// copy returns a copy of the next n bytes, or nil if n is 0.
+func (r *Decoder) copy(n int) []byte {
+	b := r.next(n)
+	if len(b) == 0 {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// Unmarshal reads group elements written by Encoder.Marshal.
+func (r *Decoder) Unmarshal(ms ...kyber.Marshaling) {
+	for _, m := range ms {
+		b := r.next(m.MarshalSize())
+		if b == nil {
+			return
+		}
+		if err := m.UnmarshalBinary(b); err != nil {
+			r.Fail(err.Error())
+			return
+		}
+	}
+}
+
+// Points reads a list of points of the group g written by Encoder.Points.
+func (r *Decoder) Points(g kyber.Group) []kyber.Point {
+	ps := make([]kyber.Point, r.Count(g.PointLen()))
+	for i := range ps {
+		ps[i] = g.Point()
+		r.Unmarshal(ps[i])
+	}
+	return ps
+}
+
+// Finish checks that all the data was read and returns the first error.
+func (r *Decoder) Finish() error {
+	if r.err == nil && len(r.buf) != 0 {
+		r.Fail("trailing data")
+	}
+	return r.err
+}
@@ -1,3 +1,11 @@
+// Package dkg implements a distributed key generation in the style of
+// Pedersen, run in three phases of deals, responses and justifications
+// exchanged over a Board.
+//
+// The bundles, DistKeyShare and Evidence are encoded with pairs of functions
+// such as MarshalDealBundle and UnmarshalDealBundle, which take the suite.
+// They don't implement encoding.BinaryMarshaler since decoding needs the
+// suite, which the objects don't hold.
 package dkg
 
 import (
@@ -7,10 +15,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
+	clock "github.com/jonboulle/clockwork"
 	"go.dedis.ch/kyber/v4"
 	"go.dedis.ch/kyber/v4/encrypt/ecies"
 	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/share/hook"
 	"go.dedis.ch/kyber/v4/sign"
 	"go.dedis.ch/kyber/v4/util/random"
 )
@@ -115,6 +126,15 @@ type Config struct {
 	// stopped, so logging is the best way to communicate information to the
 	// application layer. It can be nil.
 	Log Logger
+
+	// Hook receives the events of the DKG: phase timings, validity of the
+	// deals, complaints, outcome of the justifications and size of the QUAL
+	// set. It can be nil.
+	Hook hook.Hook
+
+	// Clock measures the duration of the phases reported to the Hook. It can
+	// be nil, in which case the real clock is used.
+	Clock clock.Clock
 }
 
 // Phase is a type that represents the different stages of the DKG protocol.
@@ -164,7 +184,11 @@ type DistKeyGenerator struct {
 	evicted []uint32
 	// list of share holders that misbehaved during the response phase
 	evictedHolders []Index
-	state          Phase
+	// evidence gathered against misbehaving nodes
+	evidence []*Evidence
+	state    Phase
+	// when the dkg moved to the current state
+	phaseStart time.Time
 	// index in the old list of nodes
 	oidx Index
 	// index in the new list of nodes
@@ -185,6 +209,15 @@ type DistKeyGenerator struct {
 	oldPresent bool
 	// public polynomial of the old group
 	olddpub *share.PubPoly
+	// hash of the config given to NewDistKeyHandler, checked on restore
+	configHash []byte
+	// bundles received and processed so far
+	receivedDeals   []*DealBundle
+	receivedJustifs []*JustificationBundle
+	// bundles produced so far, pushed again when restarting from a snapshot
+	sentDeals     *DealBundle
+	sentResponses *ResponseBundle
+	sentJustifs   *JustificationBundle
 }
 
 // NewDistKeyHandler takes a Config and returns a DistKeyGenerator that is able
@@ -199,6 +232,11 @@ func NewDistKeyHandler(c *Config) (*DistKeyGenerator, error) {
 	if c.Auth == nil {
 		return nil, errors.New("dkg: need authentication scheme")
 	}
+	// the hash is computed before the config gets modified below
+	configHash, err := hashConfig(c)
+	if err != nil {
+		return nil, err
+	}
 
 	var isResharing bool
 	if c.Share != nil || c.PublicCoeffs != nil {
@@ -234,7 +272,6 @@ func NewDistKeyHandler(c *Config) (*DistKeyGenerator, error) {
 		canReceive = false
 	}
 
-	var err error
 	var canIssue bool
 	var secretCoeff kyber.Scalar
 	var dpriv *share.PriPoly
@@ -304,6 +341,7 @@ func NewDistKeyHandler(c *Config) (*DistKeyGenerator, error) {
 	}
 	dkg := &DistKeyGenerator{
 		state:       InitPhase,
+		phaseStart:  c.now(),
 		suite:       c.Suite,
 		long:        c.Longterm,
 		pub:         pub,
@@ -323,6 +361,7 @@ func NewDistKeyHandler(c *Config) (*DistKeyGenerator, error) {
 		statuses:    statuses,
 		validShares: make(map[uint32]kyber.Scalar),
 		allPublics:  make(map[uint32]*share.PubPoly),
+		configHash:  configHash,
 	}
 	return dkg, err
 }
@@ -357,7 +396,7 @@ func (d *DistKeyGenerator) Deals() (*DealBundle, error) {
 			EncryptedShare: cipher,
 		})
 	}
-	d.state = DealPhase
+	d.moveTo(DealPhase)
 	_, commits := d.dpub.Info()
 	bundle := &DealBundle{
 		DealerIndex: uint32(d.oidx),
@@ -367,7 +406,11 @@ func (d *DistKeyGenerator) Deals() (*DealBundle, error) {
 	}
 	var err error
 	bundle.Signature, err = d.sign(bundle)
-	return bundle, err
+	if err != nil {
+		return nil, err
+	}
+	d.sentDeals = bundle
+	return bundle, nil
 }
 
 // ProcessDeals process the deals from all the nodes. Each deal for this node is
@@ -388,13 +431,13 @@ func (d *DistKeyGenerator) ProcessDeals(bundles []*DealBundle) (*ResponseBundle,
 	}
 	if !d.canReceive {
 		// a node that is only in the old group should not process deals
-		d.state = ResponsePhase // he moves on to the next phase silently
+		d.moveTo(ResponsePhase) // he moves on to the next phase silently
 
 		//nolint:nilnil // protocol defined this way
 		return nil, nil
 	}
 
-	seenIndex := make(map[uint32]bool)
+	seen := make(map[uint32]*DealBundle)
 	for _, bundle := range bundles {
 		if bundle == nil {
 			d.c.Error("found nil Deal bundle")
@@ -423,18 +466,20 @@ func (d *DistKeyGenerator) ProcessDeals(bundles []*DealBundle) (*ResponseBundle,
 			// since we assume broadcast channel, every honest player will evict
 			// this party as well
 			d.evicted = append(d.evicted, bundle.DealerIndex)
+			d.addEvidence(&Evidence{Kind: EvidenceInvalidDeal, Accused: bundle.DealerIndex, Deal: bundle})
 			d.c.Error("Deal with nil public key or invalid threshold")
 			continue
 		}
 		pubPoly := share.NewPubPoly(d.c.Suite, d.c.Suite.Point().Base(), bundle.Public)
-		if seenIndex[bundle.DealerIndex] {
+		if prev, ok := seen[bundle.DealerIndex]; ok {
 			// already saw a bundle from the same dealer - clear sign of
 			// cheating so we evict him from the list
 			d.evicted = append(d.evicted, bundle.DealerIndex)
+			d.addDoubleSigningEvidence(prev, bundle)
 			d.c.Error("Deal bundle already seen")
 			continue
 		}
-		seenIndex[bundle.DealerIndex] = true
+		seen[bundle.DealerIndex] = bundle
 		d.allPublics[bundle.DealerIndex] = pubPoly
 		for _, deal := range bundle.Deals {
 			if !isIndexIncluded(d.c.NewNodes, deal.ShareIndex) {
@@ -442,6 +487,7 @@ func (d *DistKeyGenerator) ProcessDeals(bundles []*DealBundle) (*ResponseBundle,
 				// so we evict him from the list
 				// and we don't even need to look at the rest
 				d.evicted = append(d.evicted, bundle.DealerIndex)
+				d.addEvidence(&Evidence{Kind: EvidenceInvalidDeal, Accused: bundle.DealerIndex, Deal: bundle})
 				d.c.Error("Deal share holder evicted normally")
 				break
 			}
@@ -475,6 +521,7 @@ func (d *DistKeyGenerator) ProcessDeals(bundles []*DealBundle) (*ResponseBundle,
 				publicCommit := pubPoly.Commit()
 				if !oldShareCommit.Equal(publicCommit) {
 					// inconsistent share from old member
+					d.addEvidence(&Evidence{Kind: EvidenceInvalidDeal, Accused: bundle.DealerIndex, Deal: bundle})
 					continue
 				}
 			}
@@ -504,8 +551,10 @@ func (d *DistKeyGenerator) ProcessDeals(bundles []*DealBundle) (*ResponseBundle,
 		// response since every honest node evicts him as well.
 		// XXX Is that always true ? Should we send a complaint still ?
 		if contains(d.evicted, node.Index) {
+			d.c.report(hook.Event{Kind: hook.KindDeal, Dealer: node.Index, Holder: d.nidx, Reason: "dealer evicted"})
 			continue
 		}
+		d.hookDeal(node.Index, myshares[node.Index] == Success, seen)
 
 		if myshares[node.Index] == Success {
 			if d.c.FastSync {
@@ -537,7 +586,9 @@ func (d *DistKeyGenerator) ProcessDeals(bundles []*DealBundle) (*ResponseBundle,
 		}
 		bundle.Signature = sig
 	}
-	d.state = ResponsePhase
+	d.receivedDeals = bundles
+	d.sentResponses = bundle
+	d.moveTo(ResponsePhase)
 	d.c.Info(fmt.Sprintf("sending back %d responses", len(responses)))
 	return bundle, nil
 }
@@ -546,6 +597,19 @@ func (d *DistKeyGenerator) ExpectedResponsesFastSync() int {
 	return len(d.c.NewNodes)
 }
 
+// hookDeal emits the hook event of the validity of the deal of the dealer for
+// this node.
+func (d *DistKeyGenerator) hookDeal(dealer Index, valid bool, seen map[uint32]*DealBundle) {
+	e := hook.Event{Kind: hook.KindDeal, Dealer: dealer, Holder: d.nidx, Valid: valid}
+	if !valid {
+		e.Reason = "missing deal"
+		if _, ok := seen[dealer]; ok {
+			e.Reason = "invalid share"
+		}
+	}
+	d.c.report(e)
+}
+
 // ProcessResponses takes the response from all nodes if any and returns a
 // triplet:
 // - the result if there is no complaint. If not nil, the DKG is finished.
@@ -619,6 +683,7 @@ func (d *DistKeyGenerator) ProcessResponses(bundles []*ResponseBundle) (
 			d.statuses.Set(response.DealerIndex, bundle.ShareIndex, response.Status)
 			if response.Status == Complaint {
 				foundComplaint = true
+				d.c.report(hook.Event{Kind: hook.KindComplaint, Dealer: response.DealerIndex, Holder: bundle.ShareIndex})
 			}
 
 			validAuthors = append(validAuthors, bundle.ShareIndex)
@@ -650,7 +715,7 @@ func (d *DistKeyGenerator) ProcessResponses(bundles []*ResponseBundle) (
 	// regardless of the mode chosen (fast sync or not).
 	if !foundComplaint && d.statuses.CompleteSuccess() {
 		d.c.Info("msg", "DKG successful")
-		d.state = FinishPhase
+		d.moveTo(FinishPhase)
 		if d.canReceive {
 			res, err := d.computeResult()
 			return res, nil, err
@@ -671,7 +736,7 @@ func (d *DistKeyGenerator) ProcessResponses(bundles []*ResponseBundle) (
 		}
 	}
 
-	d.state = JustifPhase
+	d.moveTo(JustifPhase)
 
 	if !d.canIssue {
 		// new node that is expecting some justifications
@@ -713,10 +778,26 @@ func (d *DistKeyGenerator) ProcessResponses(bundles []*ResponseBundle) (
 		return nil, nil, err
 	}
 	bundle.Signature = signature
+	d.sentJustifs = bundle
 	d.c.Info(fmt.Sprintf("%d justifications returned", len(justifications)))
 	return nil, bundle, nil
 }
 
+// expectedJustifications returns the number of dealers which must send a
+// justification after the responses were processed.
+func (d *DistKeyGenerator) expectedJustifications() int {
+	var n int
+	for _, node := range d.c.OldNodes {
+		if contains(d.evicted, node.Index) {
+			continue
+		}
+		if d.statuses.StatusesOfDealer(node.Index).LengthComplaints() > 0 {
+			n++
+		}
+	}
+	return n
+}
+
 // ProcessJustifications takes the justifications of the nodes and returns the
 // results if there is enough QUALified nodes, or an error otherwise. Note that
 // this method returns "nil,nil" if this node is a node only present in the old
@@ -736,15 +817,17 @@ func (d *DistKeyGenerator) ProcessJustifications(bundles []*JustificationBundle)
 			"after processing responses - current state %s", d.state.String())
 	}
 
-	seen := make(map[uint32]bool)
+	d.receivedJustifs = bundles
+	seen := make(map[uint32]*JustificationBundle)
 	for _, bundle := range bundles {
 		if bundle == nil {
 			continue
 		}
-		if seen[bundle.DealerIndex] {
+		if prev, ok := seen[bundle.DealerIndex]; ok {
 			// bundle contains duplicate - clear violation
 			// so we evict
 			d.evicted = append(d.evicted, bundle.DealerIndex)
+			d.addDoubleSigningEvidence(prev, bundle)
 			d.c.Error("Justification bundle contains duplicate - evicting dealer", bundle.DealerIndex)
 			continue
 		}
@@ -770,12 +853,14 @@ func (d *DistKeyGenerator) ProcessJustifications(bundles []*JustificationBundle)
 		}
 		d.c.Info("ProcessJustifications - basic sanity checks done", true)
 
-		seen[bundle.DealerIndex] = true
+		seen[bundle.DealerIndex] = bundle
 		for _, justif := range bundle.Justifications {
 			if !isIndexIncluded(d.c.NewNodes, justif.ShareIndex) {
 				// invalid index - clear violation
 				// so we evict
 				d.evicted = append(d.evicted, bundle.DealerIndex)
+				d.addJustificationEvidence(bundle)
+				d.hookJustification(bundle.DealerIndex, justif.ShareIndex, "unknown share holder")
 				d.c.Error("Invalid index in justifications - evicting dealer", bundle.DealerIndex)
 				continue
 			}
@@ -784,6 +869,7 @@ func (d *DistKeyGenerator) ProcessJustifications(bundles []*JustificationBundle)
 				// dealer hasn't given any public polynomial at the first phase
 				// so we evict directly - no need to look at its justifications
 				d.evicted = append(d.evicted, bundle.DealerIndex)
+				d.hookJustification(bundle.DealerIndex, justif.ShareIndex, "missing public polynomial")
 				d.c.Error("Public polynomial missing - evicting dealer", bundle.DealerIndex)
 				break
 			}
@@ -793,6 +879,8 @@ func (d *DistKeyGenerator) ProcessJustifications(bundles []*JustificationBundle)
 			if !commit.Equal(expected) {
 				// invalid justification - evict
 				d.evicted = append(d.evicted, bundle.DealerIndex)
+				d.addJustificationEvidence(bundle)
+				d.hookJustification(bundle.DealerIndex, justif.ShareIndex, "share not matching the public polynomial")
 				d.c.Error("New share commit invalid - evicting dealer", bundle.DealerIndex)
 				continue
 			}
@@ -804,7 +892,10 @@ func (d *DistKeyGenerator) ProcessJustifications(bundles []*JustificationBundle)
 				if !oldShareCommit.Equal(publicCommit) {
 					// inconsistent share from old member
 					d.evicted = append(d.evicted, bundle.DealerIndex)
-
+					if deal := d.dealOf(bundle.DealerIndex); deal != nil {
+						d.addEvidence(&Evidence{Kind: EvidenceInvalidDeal, Accused: bundle.DealerIndex, Deal: deal})
+					}
+					d.hookJustification(bundle.DealerIndex, justif.ShareIndex, "public polynomial not committing to the previous share")
 					d.c.Error("Old share commit not equal to public commit - evicting dealer", bundle.DealerIndex)
 					continue
 				}
@@ -812,6 +903,7 @@ func (d *DistKeyGenerator) ProcessJustifications(bundles []*JustificationBundle)
 			}
 			// valid share -> mark OK
 			d.statuses.Set(bundle.DealerIndex, justif.ShareIndex, Success)
+			d.hookJustification(bundle.DealerIndex, justif.ShareIndex, "")
 			if justif.ShareIndex == uint32(d.nidx) {
 				// store the share if it's for us
 				d.c.Info("Saving our key share for", justif.ShareIndex)
@@ -846,7 +938,7 @@ func (d *DistKeyGenerator) ProcessJustifications(bundles []*JustificationBundle)
 	if allGood < targetThreshold {
 		// that should not happen in the threat model but we still returns the
 		// fatal error here so DKG do not finish
-		d.state = FinishPhase
+		d.moveTo(FinishPhase)
 		return nil, fmt.Errorf("process-justifications: only %d/%d valid deals - dkg abort", allGood, targetThreshold)
 	}
 
@@ -854,20 +946,32 @@ func (d *DistKeyGenerator) ProcessJustifications(bundles []*JustificationBundle)
 	return d.computeResult()
 }
 
+// hookJustification emits the hook event of the outcome of a justification,
+// valid if there is no reason.
+func (d *DistKeyGenerator) hookJustification(dealer, holder Index, reason string) {
+	d.c.report(hook.Event{Kind: hook.KindJustification, Dealer: dealer, Holder: holder, Valid: reason == "", Reason: reason})
+}
+
 func (d *DistKeyGenerator) computeResult() (*Result, error) {
-	d.state = FinishPhase
+	d.moveTo(FinishPhase)
 	// add a full complaint row on the nodes that are evicted
 	for _, index := range d.evicted {
 		d.statuses.SetAll(index, Complaint)
 	}
 	// add all the shares and public polynomials together for the deals that are
 	// valid ( equivalently or all justified)
+	var res *Result
+	var err error
 	if d.isResharing {
 		// instead of adding, in this case, we interpolate all shares
-		return d.computeResharingResult()
+		res, err = d.computeResharingResult()
+	} else {
+		res, err = d.computeDKGResult()
 	}
-
-	return d.computeDKGResult()
+	if err == nil {
+		d.c.report(hook.Event{Kind: hook.KindQUAL, Size: len(res.QUAL)})
+	}
+	return res, err
 }
 
 func (d *DistKeyGenerator) computeResharingResult() (*Result, error) {
@@ -1147,6 +1251,34 @@ func (d *DistKeyGenerator) Error(keyvals ...interface{}) {
 	d.c.Info("generator", keyvals)
 }
 
+// moveTo moves the dkg to the phase.
+func (d *DistKeyGenerator) moveTo(p Phase) {
+	if d.state == p {
+		return
+	}
+	now := d.c.now()
+	d.hookPhase(now)
+	d.state = p
+	d.phaseStart = now
+}
+
+// hookPhase emits the hook event of the duration of the current phase, which
+// ends now.
+func (d *DistKeyGenerator) hookPhase(now time.Time) {
+	d.c.report(hook.Event{Kind: hook.KindPhase, Phase: d.state.String(), Duration: now.Sub(d.phaseStart)})
+}
+
+func (c *Config) now() time.Time {
+	if c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock.Now()
+}
+
+func (c *Config) report(e hook.Event) {
+	hook.Report(c.Hook, hook.ProtocolPedersenDKG, e)
+}
+
 func (c *Config) Info(keyvals ...interface{}) {
 	if c.Log != nil {
 		c.Log.Info("dkg-log", keyvals)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
 	clock "github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
@@ -12,6 +13,7 @@ import (
 	"go.dedis.ch/kyber/v4/group/edwards25519"
 	"go.dedis.ch/kyber/v4/pairing/bn256"
 	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/share/hook"
 	"go.dedis.ch/kyber/v4/sign/schnorr"
 	"go.dedis.ch/kyber/v4/sign/tbls"
 	"go.dedis.ch/kyber/v4/util/random"
@@ -1110,3 +1112,98 @@ func TestMinimumT(t *testing.T) {
 		})
 	}
 }
+
+func TestDKGHook(t *testing.T) {
+	n := 5
+	thr := 4
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	tns := GenerateTestNodes(suite, n)
+	var events []hook.Event
+	conf := Config{
+		Suite:     suite,
+		NewNodes:  NodesFromTest(tns),
+		Threshold: thr,
+		Auth:      schnorr.NewScheme(suite),
+		Hook:      hook.Func(func(e hook.Event) { events = append(events, e) }),
+	}
+	dm := func(deals []*DealBundle) []*DealBundle {
+		// the second dealer creates an invalid share for the third node
+		for i := range deals[1].Deals {
+			if deals[1].Deals[i].ShareIndex == 2 {
+				deals[1].Deals[i].EncryptedShare = []byte("not a share")
+			}
+		}
+		return deals
+	}
+	results := RunDKG(t, tns, conf, dm, nil, nil)
+	testResults(t, suite, thr, n, results)
+
+	var phases []string
+	count := make(map[hook.Kind]int)
+	for _, e := range events {
+		require.Equal(t, hook.ProtocolPedersenDKG, e.Protocol)
+		count[e.Kind]++
+		switch e.Kind {
+		case hook.KindPhase:
+			phases = append(phases, e.Phase)
+		case hook.KindDeal:
+			invalid := e.Dealer == 1 && e.Holder == 2
+			require.Equal(t, !invalid, e.Valid)
+			if invalid {
+				require.Equal(t, "invalid share", e.Reason)
+			}
+		case hook.KindComplaint, hook.KindJustification:
+			require.Equal(t, hook.Event{
+				Kind:     e.Kind,
+				Protocol: hook.ProtocolPedersenDKG,
+				Dealer:   1,
+				Holder:   2,
+				Valid:    e.Kind == hook.KindJustification,
+			}, e)
+		case hook.KindQUAL:
+			require.Equal(t, n, e.Size)
+		}
+	}
+	// the complainer skips its own response and the dealer its own
+	// justification
+	require.Equal(t, map[hook.Kind]int{
+		hook.KindPhase:         4 * n,
+		hook.KindDeal:          n * n,
+		hook.KindComplaint:     n - 1,
+		hook.KindJustification: n - 1,
+		hook.KindQUAL:          n,
+	}, count)
+	require.Subset(t, phases, []string{DealPhase.String(), ResponsePhase.String(), JustifPhase.String()})
+}
+
+func TestDKGHookClock(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	tns := GenerateTestNodes(suite, 3)
+	clk := clock.NewFakeClock()
+	var phases []hook.Event
+	conf := Config{
+		Suite:     suite,
+		NewNodes:  NodesFromTest(tns),
+		Threshold: 2,
+		Auth:      schnorr.NewScheme(suite),
+		Clock:     clk,
+		Hook: hook.Func(func(e hook.Event) {
+			if e.Kind == hook.KindPhase {
+				phases = append(phases, e)
+			}
+		}),
+	}
+	SetupNodes(tns[:1], &conf)
+	clk.Advance(3 * time.Second)
+	deals, err := tns[0].dkg.Deals()
+	require.NoError(t, err)
+	clk.Advance(5 * time.Second)
+	_, err = tns[0].dkg.ProcessDeals([]*DealBundle{deals})
+	require.NoError(t, err)
+
+	require.Len(t, phases, 2)
+	require.Equal(t, InitPhase.String(), phases[0].Phase)
+	require.Equal(t, 3*time.Second, phases[0].Duration)
+	require.Equal(t, DealPhase.String(), phases[1].Phase)
+	require.Equal(t, 5*time.Second, phases[1].Duration)
+}
@@ -0,0 +1,331 @@
+package dkg
+
+import (
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/share/dkg/internal/wire"
+)
+
+// EncodingVersion is the version of the binary encoding of the bundles, of
+// DistKeyShare and of Evidence.
+const EncodingVersion = 1
+
+// MaxEntries bounds the number of deals, commitments, responses and
+// justifications accepted when decoding.
+const MaxEntries = 1 << 16
+
+// MaxEncryptedShareSize bounds the size of an encrypted share accepted when
+// decoding.
+const MaxEncryptedShareSize = 1 << 12
+
+// ErrInvalidEncoding is returned when decoding malformed data.
+var ErrInvalidEncoding = errors.New("dkg: invalid encoding")
+
+// type tags of the encoded objects
+const (
+	tagDealBundle byte = iota + 1
+	tagResponseBundle
+	tagJustificationBundle
+	tagDistKeyShare
+	tagEvidence
+)
+
+var format = &wire.Format{Version: EncodingVersion, MaxEntries: MaxEntries, Err: ErrInvalidEncoding}
+
+// The encodings start with the version, the type of the object and the name
+// of the suite. All integers are big-endian. The suite is given to the
+// functions encoding and decoding the objects, and decoding fails if the
+// encoding names another suite.
+
+// MarshalDealBundle returns the encoding of the bundle with the suite s.
+func MarshalDealBundle(s Suite, d *DealBundle) ([]byte, error) {
+	if len(d.Public) > MaxEntries || len(d.Deals) > MaxEntries {
+		return nil, fmt.Errorf("%w: too many entries", ErrInvalidEncoding)
+	}
+	e := format.NewEncoder(tagDealBundle, s.String())
+	e.Uint32(d.DealerIndex)
+	e.Points(d.Public)
+	e.Uint32(uint32(len(d.Deals)))
+	for _, deal := range d.Deals {
+		if len(deal.EncryptedShare) > MaxEncryptedShareSize {
+			return nil, fmt.Errorf("%w: encrypted share too large", ErrInvalidEncoding)
+		}
+		e.Uint32(deal.ShareIndex)
+		e.Bytes32(deal.EncryptedShare)
+	}
+	e.Bytes16(d.SessionID)
+	e.Bytes16(d.Signature)
+	return e.Finish()
+}
+
+// UnmarshalDealBundle decodes a bundle encoded with MarshalDealBundle and
+// the suite s.
+func UnmarshalDealBundle(s Suite, buf []byte) (*DealBundle, error) {
+	r, err := format.NewDecoder(buf, tagDealBundle, s.String())
+	if err != nil {
+		return nil, err
+	}
+	d := new(DealBundle)
+	d.DealerIndex = r.Uint32()
+	d.Public = r.Points(s)
+	d.Deals = make([]Deal, r.Count(8))
+	for i := range d.Deals {
+		d.Deals[i].ShareIndex = r.Uint32()
+		d.Deals[i].EncryptedShare = r.Bytes32(MaxEncryptedShareSize)
+	}
+	d.SessionID = r.Bytes16()
+	d.Signature = r.Bytes16()
+	if err := r.Finish(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// MarshalResponseBundle returns the encoding of the bundle with the suite s.
+func MarshalResponseBundle(s Suite, b *ResponseBundle) ([]byte, error) {
+	if len(b.Responses) > MaxEntries {
+		return nil, fmt.Errorf("%w: too many entries", ErrInvalidEncoding)
+	}
+	e := format.NewEncoder(tagResponseBundle, s.String())
+	e.Uint32(b.ShareIndex)
+	e.Uint32(uint32(len(b.Responses)))
+	for _, resp := range b.Responses {
+		if resp.Status != Success && resp.Status != Complaint {
+			return nil, fmt.Errorf("%w: invalid status %d", ErrInvalidEncoding, resp.Status)
+		}
+		e.Uint32(resp.DealerIndex)
+		e.Byte(byte(resp.Status))
+	}
+	e.Bytes16(b.SessionID)
+	e.Bytes16(b.Signature)
+	return e.Finish()
+}
+
+// UnmarshalResponseBundle decodes a bundle encoded with MarshalResponseBundle
+// and the suite s.
+func UnmarshalResponseBundle(s Suite, buf []byte) (*ResponseBundle, error) {
+	r, err := format.NewDecoder(buf, tagResponseBundle, s.String())
+	if err != nil {
+		return nil, err
+	}
+	b := new(ResponseBundle)
+	b.ShareIndex = r.Uint32()
+	b.Responses = make([]Response, r.Count(5))
+	for i := range b.Responses {
+		b.Responses[i].DealerIndex = r.Uint32()
+		status := Status(r.Byte())
+		if status != Success && status != Complaint {
+			r.Fail("invalid status")
+		}
+		b.Responses[i].Status = status
+	}
+	b.SessionID = r.Bytes16()
+	b.Signature = r.Bytes16()
+	if err := r.Finish(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// MarshalJustificationBundle returns the encoding of the bundle with the
+// suite s.
+func MarshalJustificationBundle(s Suite, j *JustificationBundle) ([]byte, error) {
+	if len(j.Justifications) > MaxEntries {
+		return nil, fmt.Errorf("%w: too many entries", ErrInvalidEncoding)
+	}
+	e := format.NewEncoder(tagJustificationBundle, s.String())
+	e.Uint32(j.DealerIndex)
+	e.Uint32(uint32(len(j.Justifications)))
+	for _, just := range j.Justifications {
+		e.Uint32(just.ShareIndex)
+		e.Marshal(just.Share)
+	}
+	e.Bytes16(j.SessionID)
+	e.Bytes16(j.Signature)
+	return e.Finish()
+}
+
+// UnmarshalJustificationBundle decodes a bundle encoded with
+// MarshalJustificationBundle and the suite s.
+func UnmarshalJustificationBundle(s Suite, buf []byte) (*JustificationBundle, error) {
+	r, err := format.NewDecoder(buf, tagJustificationBundle, s.String())
+	if err != nil {
+		return nil, err
+	}
+	j := new(JustificationBundle)
+	j.DealerIndex = r.Uint32()
+	j.Justifications = make([]Justification, r.Count(4+s.ScalarLen()))
+	for i := range j.Justifications {
+		j.Justifications[i].ShareIndex = r.Uint32()
+		j.Justifications[i].Share = s.Scalar()
+		r.Unmarshal(j.Justifications[i].Share)
+	}
+	j.SessionID = r.Bytes16()
+	j.Signature = r.Bytes16()
+	if err := r.Finish(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// MarshalDistKeyShare returns the encoding of the share with the suite s. It
+// contains the private share.
+func MarshalDistKeyShare(s Suite, d *DistKeyShare) ([]byte, error) {
+	if len(d.Commits) > MaxEntries || d.Share == nil {
+		return nil, fmt.Errorf("%w: invalid share", ErrInvalidEncoding)
+	}
+	e := format.NewEncoder(tagDistKeyShare, s.String())
+	e.Points(d.Commits)
+	e.Uint32(d.Share.I)
+	e.Marshal(d.Share.V)
+	return e.Finish()
+}
+
+// UnmarshalDistKeyShare decodes a share encoded with MarshalDistKeyShare and
+// the suite s.
+func UnmarshalDistKeyShare(s Suite, buf []byte) (*DistKeyShare, error) {
+	r, err := format.NewDecoder(buf, tagDistKeyShare, s.String())
+	if err != nil {
+		return nil, err
+	}
+	d := new(DistKeyShare)
+	d.Commits = r.Points(s)
+	d.Share = &share.PriShare{I: r.Uint32(), V: s.Scalar()}
+	r.Unmarshal(d.Share.V)
+	if err := r.Finish(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// MarshalEvidence returns the encoding of the evidence with the suite s. The
+// bundles it holds are encoded with the functions above, each preceded by the
+// tag of its type.
+func MarshalEvidence(s Suite, ev *Evidence) ([]byte, error) {
+	if len(ev.Conflicting) > MaxEntries {
+		return nil, fmt.Errorf("%w: too many entries", ErrInvalidEncoding)
+	}
+	e := format.NewEncoder(tagEvidence, s.String())
+	e.Byte(byte(ev.Kind))
+	e.Uint32(ev.Accused)
+	var packets []Packet
+	if ev.Deal != nil {
+		packets = append(packets, ev.Deal)
+	}
+	if ev.Justification != nil {
+		packets = append(packets, ev.Justification)
+	}
+	// the deal and the justification are optional, and have different types
+	e.Uint32(uint32(len(packets)))
+	for _, p := range packets {
+		encodePacket(e, s, p)
+	}
+	e.Uint32(uint32(len(ev.Conflicting)))
+	for _, p := range ev.Conflicting {
+		encodePacket(e, s, p)
+	}
+	return e.Finish()
+}
+
+// UnmarshalEvidence decodes evidence encoded with MarshalEvidence and the
+// suite s.
+func UnmarshalEvidence(s Suite, buf []byte) (*Evidence, error) {
+	r, err := format.NewDecoder(buf, tagEvidence, s.String())
+	if err != nil {
+		return nil, err
+	}
+	ev := new(Evidence)
+	ev.Kind = EvidenceKind(r.Byte())
+	ev.Accused = r.Uint32()
+	n := r.Count(5)
+	for i := 0; i < n; i++ {
+		switch p := decodePacket(r, s).(type) {
+		case *DealBundle:
+			if ev.Deal != nil {
+				r.Fail("duplicate deal")
+			}
+			ev.Deal = p
+		case *JustificationBundle:
+			if ev.Justification != nil {
+				r.Fail("duplicate justification")
+			}
+			ev.Justification = p
+		default:
+			r.Fail("unexpected bundle")
+		}
+	}
+	ev.Conflicting = make([]Packet, r.Count(5))
+	for i := range ev.Conflicting {
+		ev.Conflicting[i] = decodePacket(r, s)
+	}
+	if err := r.Finish(); err != nil {
+		return nil, err
+	}
+	if len(ev.Conflicting) == 0 {
+		ev.Conflicting = nil
+	}
+	return ev, nil
+}
+
+// encodePacket writes the tag of the type of the bundle and its encoding.
+func encodePacket(e *wire.Encoder, s Suite, p Packet) {
+	var tag byte
+	var buf []byte
+	var err error
+	switch b := p.(type) {
+	case *DealBundle:
+		if b == nil {
+			break
+		}
+		tag = tagDealBundle
+		buf, err = MarshalDealBundle(s, b)
+	case *ResponseBundle:
+		if b == nil {
+			break
+		}
+		tag = tagResponseBundle
+		buf, err = MarshalResponseBundle(s, b)
+	case *JustificationBundle:
+		if b == nil {
+			break
+		}
+		tag = tagJustificationBundle
+		buf, err = MarshalJustificationBundle(s, b)
+	}
+	if tag == 0 {
+		e.Fail(fmt.Sprintf("invalid bundle %T", p))
+		return
+	}
+	if err != nil {
+		e.Fail(err.Error())
+		return
+	}
+	e.Byte(tag)
+	e.Bytes32(buf)
+}
+
+// decodePacket reads a bundle written by encodePacket.
+func decodePacket(r *wire.Decoder, s Suite) Packet {
+	tag := r.Byte()
+	buf := r.Bytes32(r.Len())
+	var p Packet
+	var err error
+	switch tag {
+	case tagDealBundle:
+		p, err = UnmarshalDealBundle(s, buf)
+	case tagResponseBundle:
+		p, err = UnmarshalResponseBundle(s, buf)
+	case tagJustificationBundle:
+		p, err = UnmarshalJustificationBundle(s, buf)
+	default:
+		r.Fail("unknown bundle type")
+		return nil
+	}
+	if err != nil {
+		r.Fail(err.Error())
+		return nil
+	}
+	return p
+}
@@ -0,0 +1,331 @@
+package dkg
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/pairing/bn256"
+	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// encodingBundles runs a dkg and returns bundles and a share to encode.
+func encodingBundles(t *testing.T, suite Suite) (*Config, *DealBundle, *ResponseBundle, *JustificationBundle,
+	*DistKeyShare) {
+	n := 3
+	tns := GenerateTestNodes(suite, n)
+	conf := Config{
+		Suite:     suite,
+		NewNodes:  NodesFromTest(tns),
+		Threshold: n,
+		Auth:      schnorr.NewScheme(suite),
+	}
+	results := RunDKG(t, tns, conf, nil, nil, nil)
+	require.Len(t, results, n)
+	c := tns[0].dkg.c
+	d := tns[0].dkg.sentDeals
+
+	// a complaint makes the first dealer produce a justification
+	SetupNodes(tns[:1], &conf)
+	_, err := tns[0].dkg.Deals()
+	require.NoError(t, err)
+	_, err = tns[0].dkg.ProcessDeals(nil)
+	require.NoError(t, err)
+	r := &ResponseBundle{
+		ShareIndex: 1,
+		Responses: []Response{
+			{DealerIndex: 0, Status: Complaint},
+			{DealerIndex: 2, Status: Success},
+		},
+		SessionID: tns[0].dkg.c.Nonce,
+		Signature: []byte("signature"),
+	}
+	_, j, err := tns[0].dkg.ProcessResponses([]*ResponseBundle{r})
+	require.NoError(t, err)
+	require.NotNil(t, j)
+	return c, d, r, j, results[0].Key
+}
+
+// codec encodes an object and decodes encodings into a new object, which is
+// encoded again by the following calls to marshal.
+type codec struct {
+	marshal   func() ([]byte, error)
+	unmarshal func([]byte) error
+}
+
+func dealCodec(s Suite, d *DealBundle) codec {
+	return codec{
+		marshal: func() ([]byte, error) { return MarshalDealBundle(s, d) },
+		unmarshal: func(buf []byte) error {
+			res, err := UnmarshalDealBundle(s, buf)
+			if err == nil {
+				*d = *res
+			}
+			return err
+		},
+	}
+}
+
+func responseCodec(s Suite, r *ResponseBundle) codec {
+	return codec{
+		marshal: func() ([]byte, error) { return MarshalResponseBundle(s, r) },
+		unmarshal: func(buf []byte) error {
+			res, err := UnmarshalResponseBundle(s, buf)
+			if err == nil {
+				*r = *res
+			}
+			return err
+		},
+	}
+}
+
+func justifCodec(s Suite, j *JustificationBundle) codec {
+	return codec{
+		marshal: func() ([]byte, error) { return MarshalJustificationBundle(s, j) },
+		unmarshal: func(buf []byte) error {
+			res, err := UnmarshalJustificationBundle(s, buf)
+			if err == nil {
+				*j = *res
+			}
+			return err
+		},
+	}
+}
+
+func distKeyShareCodec(s Suite, d *DistKeyShare) codec {
+	return codec{
+		marshal: func() ([]byte, error) { return MarshalDistKeyShare(s, d) },
+		unmarshal: func(buf []byte) error {
+			res, err := UnmarshalDistKeyShare(s, buf)
+			if err == nil {
+				*d = *res
+			}
+			return err
+		},
+	}
+}
+
+func evidenceCodec(s Suite, ev *Evidence) codec {
+	return codec{
+		marshal: func() ([]byte, error) { return MarshalEvidence(s, ev) },
+		unmarshal: func(buf []byte) error {
+			res, err := UnmarshalEvidence(s, buf)
+			if err == nil {
+				*ev = *res
+			}
+			return err
+		},
+	}
+}
+
+// roundTrip decodes the encoding of obj into dec and checks that dec has the
+// same encoding. Points can't be compared with require.Equal since their
+// internal representation is not unique.
+func roundTrip(t *testing.T, obj, dec codec) {
+	buf, err := obj.marshal()
+	require.NoError(t, err)
+	require.NoError(t, dec.unmarshal(buf))
+	buf2, err := dec.marshal()
+	require.NoError(t, err)
+	require.Equal(t, buf, buf2)
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	for _, suite := range []Suite{edwards25519.NewBlakeSHA256Ed25519(), bn256.NewSuiteG2()} {
+		c, d, r, j, dks := encodingBundles(t, suite)
+
+		var d2 DealBundle
+		roundTrip(t, dealCodec(suite, d), dealCodec(suite, &d2))
+		require.Equal(t, d.Deals, d2.Deals)
+		require.NoError(t, VerifyPacketSignature(c, &d2))
+
+		var r2 ResponseBundle
+		roundTrip(t, responseCodec(suite, r), responseCodec(suite, &r2))
+		require.Equal(t, r, &r2)
+
+		var j2 JustificationBundle
+		roundTrip(t, justifCodec(suite, j), justifCodec(suite, &j2))
+		h1, err := j.Hash()
+		require.NoError(t, err)
+		h2, err := j2.Hash()
+		require.NoError(t, err)
+		require.Equal(t, h1, h2)
+
+		var dks2 DistKeyShare
+		roundTrip(t, distKeyShareCodec(suite, dks), distKeyShareCodec(suite, &dks2))
+		require.True(t, dks.Share.V.Equal(dks2.Share.V))
+		require.True(t, dks.Public().Equal(dks2.Public()))
+	}
+}
+
+func TestEncodingSuite(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	d := &DealBundle{
+		DealerIndex: 1,
+		Public:      []kyber.Point{suite.Point().Pick(random.New())},
+		SessionID:   []byte("session"),
+	}
+	buf, err := MarshalDealBundle(suite, d)
+	require.NoError(t, err)
+
+	// a share built by the caller is encoded with the suite given
+	dks := &DistKeyShare{Commits: d.Public, Share: &share.PriShare{I: 1, V: suite.Scalar().One()}}
+	_, err = MarshalDistKeyShare(suite, dks)
+	require.NoError(t, err)
+
+	// the suite name must match the one given to decode
+	_, err = UnmarshalDealBundle(bn256.NewSuiteG1(), buf)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+	_, err = UnmarshalDealBundle(suite, buf)
+	require.NoError(t, err)
+}
+
+func TestEncodingInvalid(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	d := &DealBundle{
+		DealerIndex: 1,
+		Public:      []kyber.Point{suite.Point().Pick(random.New())},
+		Deals:       []Deal{{ShareIndex: 2, EncryptedShare: []byte("share")}},
+		SessionID:   []byte("session"),
+	}
+	buf, err := MarshalDealBundle(suite, d)
+	require.NoError(t, err)
+	header := 3 + len(suite.String())
+
+	d2 := dealCodec(suite, new(DealBundle))
+	for i := 0; i < len(buf); i++ {
+		require.ErrorIs(t, d2.unmarshal(buf[:i]), ErrInvalidEncoding, "length %d", i)
+	}
+	require.ErrorIs(t, d2.unmarshal(append(buf, 0)), ErrInvalidEncoding)
+
+	bad := append([]byte{}, buf...)
+	bad[0] = EncodingVersion + 1
+	require.ErrorIs(t, d2.unmarshal(bad), ErrInvalidEncoding)
+	bad = append([]byte{}, buf...)
+	bad[1] = tagJustificationBundle
+	require.ErrorIs(t, d2.unmarshal(bad), ErrInvalidEncoding)
+	_, err = UnmarshalJustificationBundle(suite, buf)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+
+	// the number of commitments is bounded
+	bad = append([]byte{}, buf...)
+	binary.BigEndian.PutUint32(bad[header+4:], MaxEntries+1)
+	require.ErrorIs(t, d2.unmarshal(bad), ErrInvalidEncoding)
+	binary.BigEndian.PutUint32(bad[header+4:], 1000)
+	require.ErrorIs(t, d2.unmarshal(bad), ErrInvalidEncoding)
+
+	// invalid status
+	r := &ResponseBundle{ShareIndex: 1, Responses: []Response{{DealerIndex: 0, Status: 5}}}
+	_, err = MarshalResponseBundle(suite, r)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+	r.Responses[0].Status = Complaint
+	buf, err = MarshalResponseBundle(suite, r)
+	require.NoError(t, err)
+	buf[header+4+4+4] = 5
+	_, err = UnmarshalResponseBundle(suite, buf)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+
+	// too large encrypted shares
+	d.Deals[0].EncryptedShare = make([]byte, MaxEncryptedShareSize+1)
+	_, err = MarshalDealBundle(suite, d)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+
+	// nil elements
+	_, err = MarshalDistKeyShare(suite, &DistKeyShare{Share: &share.PriShare{}})
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+// fuzzEncoding checks that decoding arbitrary data doesn't panic and that
+// decoded objects encode canonically.
+func fuzzEncoding(f *testing.F, seed []byte, obj codec) {
+	f.Add(seed)
+	f.Add([]byte{})
+	f.Add(seed[:len(seed)/2])
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if obj.unmarshal(data) != nil {
+			return
+		}
+		buf, err := obj.marshal()
+		require.NoError(t, err)
+		require.NoError(t, obj.unmarshal(buf))
+		buf2, err := obj.marshal()
+		require.NoError(t, err)
+		require.Equal(t, buf, buf2)
+	})
+}
+
+var fuzzSuite = edwards25519.NewBlakeSHA256Ed25519()
+
+func seedBundles(f *testing.F) (d, r, j, s []byte) {
+	suite := fuzzSuite
+	var err error
+	deal := &DealBundle{
+		DealerIndex: 1,
+		Public:      []kyber.Point{suite.Point().Pick(random.New()), suite.Point().Pick(random.New())},
+		Deals:       []Deal{{ShareIndex: 2, EncryptedShare: []byte("share")}},
+		SessionID:   []byte("session"),
+		Signature:   []byte("signature"),
+	}
+	resp := &ResponseBundle{ShareIndex: 1, Responses: []Response{{DealerIndex: 0, Status: Complaint}}}
+	just := &JustificationBundle{
+		DealerIndex:    1,
+		Justifications: []Justification{{ShareIndex: 2, Share: suite.Scalar().Pick(random.New())}},
+	}
+	dks := &DistKeyShare{
+		Commits: deal.Public,
+		Share:   &share.PriShare{I: 3, V: suite.Scalar().Pick(random.New())},
+	}
+	d, err = MarshalDealBundle(suite, deal)
+	require.NoError(f, err)
+	r, err = MarshalResponseBundle(suite, resp)
+	require.NoError(f, err)
+	j, err = MarshalJustificationBundle(suite, just)
+	require.NoError(f, err)
+	s, err = MarshalDistKeyShare(suite, dks)
+	require.NoError(f, err)
+	return d, r, j, s
+}
+
+func FuzzDealBundle(f *testing.F) {
+	d, _, _, _ := seedBundles(f)
+	fuzzEncoding(f, d, dealCodec(fuzzSuite, new(DealBundle)))
+}
+
+func FuzzResponseBundle(f *testing.F) {
+	_, r, _, _ := seedBundles(f)
+	fuzzEncoding(f, r, responseCodec(fuzzSuite, new(ResponseBundle)))
+}
+
+func FuzzJustificationBundle(f *testing.F) {
+	_, _, j, _ := seedBundles(f)
+	fuzzEncoding(f, j, justifCodec(fuzzSuite, new(JustificationBundle)))
+}
+
+func FuzzDistKeyShare(f *testing.F) {
+	_, _, _, s := seedBundles(f)
+	fuzzEncoding(f, s, distKeyShareCodec(fuzzSuite, new(DistKeyShare)))
+}
+
+func FuzzEvidence(f *testing.F) {
+	d, r, j, _ := seedBundles(f)
+	deal, err := UnmarshalDealBundle(fuzzSuite, d)
+	require.NoError(f, err)
+	just, err := UnmarshalJustificationBundle(fuzzSuite, j)
+	require.NoError(f, err)
+	resp, err := UnmarshalResponseBundle(fuzzSuite, r)
+	require.NoError(f, err)
+	ev := &Evidence{
+		Kind:          EvidenceDoubleSigning,
+		Accused:       1,
+		Deal:          deal,
+		Justification: just,
+		Conflicting:   []Packet{deal, resp},
+	}
+	buf, err := MarshalEvidence(fuzzSuite, ev)
+	require.NoError(f, err)
+	fuzzEncoding(f, buf, evidenceCodec(fuzzSuite, new(Evidence)))
+}
@@ -0,0 +1,261 @@
+package dkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/share"
+)
+
+// ErrInvalidEvidence is returned by VerifyEvidence when the evidence doesn't
+// prove that the accused node misbehaved.
+var ErrInvalidEvidence = errors.New("dkg: invalid evidence")
+
+// EvidenceKind is the type of misbehavior an Evidence proves.
+type EvidenceKind byte
+
+const (
+	// EvidenceInvalidDeal is a signed deal bundle which is malformed: wrong
+	// number of commitments, unknown share holder or, in a resharing, a
+	// public polynomial not committing to the previous share of the dealer.
+	EvidenceInvalidDeal EvidenceKind = iota + 1
+	// EvidenceInvalidJustification is a signed justification revealing a
+	// share which doesn't match the signed deal of the same dealer.
+	EvidenceInvalidJustification
+	// EvidenceDoubleSigning is two different bundles of the same type signed
+	// by the same node for the same session.
+	EvidenceDoubleSigning
+)
+
+func (k EvidenceKind) String() string {
+	switch k {
+	case EvidenceInvalidDeal:
+		return "invalid deal"
+	case EvidenceInvalidJustification:
+		return "invalid justification"
+	case EvidenceDoubleSigning:
+		return "double signing"
+	default:
+		return fmt.Sprintf("evidence(%d)", byte(k))
+	}
+}
+
+// Evidence is a self-contained proof that a node misbehaved during the DKG.
+// It only holds bundles signed by the accused node, so anyone knowing the
+// config of the DKG can check it with VerifyEvidence. It's sent to third
+// parties encoded with MarshalEvidence.
+type Evidence struct {
+	Kind EvidenceKind
+	// Accused is the index of the node which misbehaved.
+	Accused Index
+	// Deal is the invalid deal for EvidenceInvalidDeal, or the deal the
+	// justification is checked against for EvidenceInvalidJustification.
+	Deal *DealBundle
+	// Justification is the invalid justification.
+	Justification *JustificationBundle
+	// Conflicting holds the two bundles signed for EvidenceDoubleSigning.
+	Conflicting []Packet
+}
+
+// Evidence returns the evidence gathered so far against misbehaving nodes.
+func (d *DistKeyGenerator) Evidence() []*Evidence {
+	return append([]*Evidence{}, d.evidence...)
+}
+
+// addEvidence keeps the evidence if it verifies. Bundles are not always
+// verified before being processed, and an unsigned bundle proves nothing.
+func (d *DistKeyGenerator) addEvidence(ev *Evidence) {
+	for _, e := range d.evidence {
+		if e.Kind == ev.Kind && e.Accused == ev.Accused {
+			return
+		}
+	}
+	if err := VerifyEvidence(d.c, ev); err != nil {
+		d.c.Info("evidence", ev.Kind, "against", ev.Accused, "not kept:", err)
+		return
+	}
+	d.evidence = append(d.evidence, ev)
+}
+
+// addDoubleSigningEvidence records two bundles issued by the same node.
+func (d *DistKeyGenerator) addDoubleSigningEvidence(a, b Packet) {
+	d.addEvidence(&Evidence{
+		Kind:        EvidenceDoubleSigning,
+		Accused:     a.Index(),
+		Conflicting: []Packet{a, b},
+	})
+}
+
+// addJustificationEvidence records a justification which doesn't match the
+// deal of its dealer.
+func (d *DistKeyGenerator) addJustificationEvidence(j *JustificationBundle) {
+	deal := d.dealOf(j.DealerIndex)
+	if deal == nil {
+		return
+	}
+	d.addEvidence(&Evidence{
+		Kind:          EvidenceInvalidJustification,
+		Accused:       j.DealerIndex,
+		Deal:          deal,
+		Justification: j,
+	})
+}
+
+// dealOf returns the deal received from the dealer.
+func (d *DistKeyGenerator) dealOf(dealer Index) *DealBundle {
+	for _, b := range d.receivedDeals {
+		if b != nil && b.DealerIndex == dealer {
+			return b
+		}
+	}
+	return nil
+}
+
+// VerifyEvidence returns nil if the evidence proves that the accused node
+// misbehaved in the DKG run with the given config.
+func VerifyEvidence(c *Config, ev *Evidence) error {
+	if ev == nil {
+		return ErrInvalidEvidence
+	}
+	switch ev.Kind {
+	case EvidenceInvalidDeal:
+		if err := checkSigned(c, ev.Accused, ev.Deal); err != nil {
+			return err
+		}
+		if dealFault(c, ev.Deal) == "" {
+			return fmt.Errorf("%w: valid deal", ErrInvalidEvidence)
+		}
+		return nil
+	case EvidenceInvalidJustification:
+		if err := checkSigned(c, ev.Accused, ev.Deal); err != nil {
+			return err
+		}
+		if err := checkSigned(c, ev.Accused, ev.Justification); err != nil {
+			return err
+		}
+		if fault := dealFault(c, ev.Deal); fault != "" {
+			return fmt.Errorf("%w: %s, use %s evidence", ErrInvalidEvidence, fault, EvidenceInvalidDeal)
+		}
+		if !justificationFault(c, ev.Deal, ev.Justification) {
+			return fmt.Errorf("%w: valid justification", ErrInvalidEvidence)
+		}
+		return nil
+	case EvidenceDoubleSigning:
+		if len(ev.Conflicting) != 2 {
+			return fmt.Errorf("%w: needs two bundles", ErrInvalidEvidence)
+		}
+		a, b := ev.Conflicting[0], ev.Conflicting[1]
+		if fmt.Sprintf("%T", a) != fmt.Sprintf("%T", b) {
+			return fmt.Errorf("%w: bundles of different types", ErrInvalidEvidence)
+		}
+		for _, p := range ev.Conflicting {
+			if err := checkSigned(c, ev.Accused, p); err != nil {
+				return err
+			}
+		}
+		ha, err := a.Hash()
+		if err != nil {
+			return err
+		}
+		hb, err := b.Hash()
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(ha, hb) {
+			return fmt.Errorf("%w: identical bundles", ErrInvalidEvidence)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown kind %d", ErrInvalidEvidence, ev.Kind)
+	}
+}
+
+// checkSigned checks that the bundle was signed by the accused node for the
+// session of the config.
+func checkSigned(c *Config, accused Index, p Packet) error {
+	var session []byte
+	switch b := p.(type) {
+	case *DealBundle:
+		if b == nil {
+			return fmt.Errorf("%w: missing bundle", ErrInvalidEvidence)
+		}
+		session = b.SessionID
+	case *ResponseBundle:
+		if b == nil {
+			return fmt.Errorf("%w: missing bundle", ErrInvalidEvidence)
+		}
+		session = b.SessionID
+	case *JustificationBundle:
+		if b == nil {
+			return fmt.Errorf("%w: missing bundle", ErrInvalidEvidence)
+		}
+		session = b.SessionID
+	default:
+		return fmt.Errorf("%w: unknown bundle %T", ErrInvalidEvidence, p)
+	}
+	if p.Index() != accused {
+		return fmt.Errorf("%w: bundle from %d instead of %d", ErrInvalidEvidence, p.Index(), accused)
+	}
+	if !bytes.Equal(session, c.Nonce) {
+		return fmt.Errorf("%w: bundle from another session", ErrInvalidEvidence)
+	}
+	if err := VerifyPacketSignature(c, p); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvidence, err)
+	}
+	return nil
+}
+
+// dealFault returns why the deal is invalid, or an empty string.
+func dealFault(c *Config, deal *DealBundle) string {
+	threshold := c.Threshold
+	if threshold == 0 {
+		threshold = MinimumT(len(c.NewNodes))
+	}
+	if len(deal.Public) != threshold {
+		return "wrong number of commitments"
+	}
+	for _, p := range deal.Public {
+		if p == nil {
+			return "nil commitment"
+		}
+	}
+	for _, d := range deal.Deals {
+		if !isIndexIncluded(c.NewNodes, d.ShareIndex) {
+			return "unknown share holder"
+		}
+	}
+	if commits := oldCommits(c); commits != nil {
+		old := share.NewPubPoly(c.Suite, c.Suite.Point().Base(), commits)
+		if !old.Eval(deal.DealerIndex).V.Equal(deal.Public[0]) {
+			return "public polynomial not committing to the previous share"
+		}
+	}
+	return ""
+}
+
+// oldCommits returns the public polynomial of the previous DKG for a
+// resharing, or nil.
+func oldCommits(c *Config) []kyber.Point {
+	if c.Share != nil {
+		return c.Share.Commits
+	}
+	return c.PublicCoeffs
+}
+
+// justificationFault returns true if the justification reveals a share which
+// doesn't match the deal.
+func justificationFault(c *Config, deal *DealBundle, j *JustificationBundle) bool {
+	pub := share.NewPubPoly(c.Suite, c.Suite.Point().Base(), deal.Public)
+	for _, justif := range j.Justifications {
+		if !isIndexIncluded(c.NewNodes, justif.ShareIndex) || justif.Share == nil {
+			return true
+		}
+		commit := c.Suite.Point().Mul(justif.Share, nil)
+		if !commit.Equal(pub.Eval(justif.ShareIndex).V) {
+			return true
+		}
+	}
+	return false
+}
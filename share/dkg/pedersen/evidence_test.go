@@ -0,0 +1,212 @@
+package dkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// evidenceSetup returns nodes which produced their deals, and the config a
+// third party uses to verify evidence.
+func evidenceSetup(t *testing.T) ([]*TestNode, []*DealBundle, *Config) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	tns := GenerateTestNodes(suite, 4)
+	conf := Config{
+		Suite:     suite,
+		NewNodes:  NodesFromTest(tns),
+		Threshold: 3,
+		Auth:      schnorr.NewScheme(suite),
+	}
+	SetupNodes(tns, &conf)
+	var deals []*DealBundle
+	for _, n := range tns {
+		d, err := n.dkg.Deals()
+		require.NoError(t, err)
+		deals = append(deals, d)
+	}
+	conf.Nonce = tns[0].dkg.c.Nonce
+	return tns, deals, &conf
+}
+
+// resign copies the deal of the node with the given change, and signs it.
+func resign(t *testing.T, n *TestNode, d *DealBundle, change func(*DealBundle)) *DealBundle {
+	c := *d
+	c.Deals = append([]Deal{}, d.Deals...)
+	change(&c)
+	var err error
+	c.Signature, err = n.dkg.sign(&c)
+	require.NoError(t, err)
+	return &c
+}
+
+func evidenceOf(evs []*Evidence, kind EvidenceKind, accused Index) *Evidence {
+	for _, ev := range evs {
+		if ev.Kind == kind && ev.Accused == accused {
+			return ev
+		}
+	}
+	return nil
+}
+
+func TestEvidenceDeals(t *testing.T) {
+	tns, deals, conf := evidenceSetup(t)
+	// the second dealer commits to a polynomial of the wrong degree, the
+	// third one sends two different deals
+	invalid := resign(t, tns[1], deals[1], func(d *DealBundle) { d.Public = d.Public[:2] })
+	double := resign(t, tns[2], deals[2], func(d *DealBundle) {
+		d.Deals[0] = Deal{ShareIndex: d.Deals[0].ShareIndex, EncryptedShare: []byte("other")}
+	})
+	// the last dealer sends a deal signed by someone else
+	forged := resign(t, tns[0], deals[3], func(d *DealBundle) { d.Public = d.Public[:1] })
+	_, err := tns[0].dkg.ProcessDeals([]*DealBundle{deals[0], invalid, deals[2], double, forged})
+	require.NoError(t, err)
+
+	evs := tns[0].dkg.Evidence()
+	require.Len(t, evs, 2)
+	ev := evidenceOf(evs, EvidenceInvalidDeal, 1)
+	require.NotNil(t, ev)
+	require.NoError(t, VerifyEvidence(conf, ev))
+	ev = evidenceOf(evs, EvidenceDoubleSigning, 2)
+	require.NotNil(t, ev)
+	require.NoError(t, VerifyEvidence(conf, ev))
+
+	// evidence is bound to the session
+	other := *conf
+	other.Nonce = GetNonce()
+	require.ErrorIs(t, VerifyEvidence(&other, ev), ErrInvalidEvidence)
+
+	// valid or unsigned bundles prove nothing
+	require.ErrorIs(t, VerifyEvidence(conf, &Evidence{Kind: EvidenceInvalidDeal, Accused: 0, Deal: deals[0]}),
+		ErrInvalidEvidence)
+	require.ErrorIs(t, VerifyEvidence(conf, &Evidence{Kind: EvidenceInvalidDeal, Accused: 3, Deal: forged}),
+		ErrInvalidEvidence)
+	require.ErrorIs(t, VerifyEvidence(conf, &Evidence{Kind: EvidenceInvalidDeal, Accused: 2, Deal: invalid}),
+		ErrInvalidEvidence)
+	require.ErrorIs(t, VerifyEvidence(conf, &Evidence{
+		Kind:        EvidenceDoubleSigning,
+		Accused:     2,
+		Conflicting: []Packet{deals[2], deals[2]},
+	}), ErrInvalidEvidence)
+	require.ErrorIs(t, VerifyEvidence(conf, &Evidence{Kind: EvidenceDoubleSigning, Accused: 2}), ErrInvalidEvidence)
+	require.ErrorIs(t, VerifyEvidence(conf, nil), ErrInvalidEvidence)
+}
+
+func TestEvidenceJustification(t *testing.T) {
+	tns, deals, conf := evidenceSetup(t)
+	for _, n := range tns {
+		_, err := n.dkg.ProcessDeals(deals)
+		require.NoError(t, err)
+	}
+	// the second node complains about the first dealer, which justifies
+	// with a wrong share
+	complaint := &ResponseBundle{
+		ShareIndex: 1,
+		Responses:  []Response{{DealerIndex: 0, Status: Complaint}},
+		SessionID:  conf.Nonce,
+	}
+	var err error
+	complaint.Signature, err = tns[1].dkg.sign(complaint)
+	require.NoError(t, err)
+	_, j, err := tns[0].dkg.ProcessResponses([]*ResponseBundle{complaint})
+	require.NoError(t, err)
+	require.NotNil(t, j)
+	valid := *j
+	j.Justifications = append([]Justification{}, j.Justifications...)
+	j.Justifications[0].Share = conf.Suite.Scalar().Pick(random.New())
+	j.Signature, err = tns[0].dkg.sign(j)
+	require.NoError(t, err)
+
+	_, _, err = tns[2].dkg.ProcessResponses([]*ResponseBundle{complaint})
+	require.NoError(t, err)
+	_, err = tns[2].dkg.ProcessJustifications([]*JustificationBundle{j})
+	require.NoError(t, err)
+	ev := evidenceOf(tns[2].dkg.Evidence(), EvidenceInvalidJustification, 0)
+	require.NotNil(t, ev)
+	require.NoError(t, VerifyEvidence(conf, ev))
+
+	// a valid or missing justification proves nothing
+	other := *ev
+	other.Justification = &valid
+	require.ErrorIs(t, VerifyEvidence(conf, &other), ErrInvalidEvidence)
+	other.Justification = nil
+	require.ErrorIs(t, VerifyEvidence(conf, &other), ErrInvalidEvidence)
+}
+
+func TestEvidenceSet(t *testing.T) {
+	s := newSet()
+	var conflict []Packet
+	s.onConflict = func(a, b Packet) { conflict = []Packet{a, b} }
+	deal := generateDeal(1)
+	s.Push(deal)
+	s.Push(deal)
+	require.Nil(t, conflict)
+	other := generateDeal(1)
+	s.Push(other)
+	require.Equal(t, []Packet{deal, other}, conflict)
+}
+
+func TestEvidenceEncoding(t *testing.T) {
+	tns, deals, conf := evidenceSetup(t)
+	s := conf.Suite
+	invalid := resign(t, tns[1], deals[1], func(d *DealBundle) { d.Public = d.Public[:2] })
+	double := resign(t, tns[2], deals[2], func(d *DealBundle) {
+		d.Deals[0] = Deal{ShareIndex: d.Deals[0].ShareIndex, EncryptedShare: []byte("other")}
+	})
+	justif := &JustificationBundle{
+		DealerIndex:    0,
+		Justifications: []Justification{{ShareIndex: 1, Share: s.Scalar().Pick(random.New())}},
+		SessionID:      conf.Nonce,
+	}
+	var err error
+	justif.Signature, err = tns[0].dkg.sign(justif)
+	require.NoError(t, err)
+	var responses []Packet
+	for _, status := range []Status{Success, Complaint} {
+		r := &ResponseBundle{ShareIndex: 3, Responses: []Response{{DealerIndex: 0, Status: status}},
+			SessionID: conf.Nonce}
+		r.Signature, err = tns[3].dkg.sign(r)
+		require.NoError(t, err)
+		responses = append(responses, r)
+	}
+
+	for _, ev := range []*Evidence{
+		{Kind: EvidenceInvalidDeal, Accused: 1, Deal: invalid},
+		{Kind: EvidenceInvalidJustification, Accused: 0, Deal: deals[0], Justification: justif},
+		{Kind: EvidenceDoubleSigning, Accused: 2, Conflicting: []Packet{deals[2], double}},
+		{Kind: EvidenceDoubleSigning, Accused: 3, Conflicting: responses},
+	} {
+		require.NoError(t, VerifyEvidence(conf, ev))
+		buf, err := MarshalEvidence(s, ev)
+		require.NoError(t, err)
+		dec, err := UnmarshalEvidence(s, buf)
+		require.NoError(t, err)
+		require.NoError(t, VerifyEvidence(conf, dec), ev.Kind)
+		buf2, err := MarshalEvidence(s, dec)
+		require.NoError(t, err)
+		require.Equal(t, buf, buf2)
+
+		for i := 0; i < len(buf); i++ {
+			_, err = UnmarshalEvidence(s, buf[:i])
+			require.ErrorIs(t, err, ErrInvalidEncoding)
+		}
+	}
+
+	// bundles are tagged with their type
+	buf, err := MarshalEvidence(s, &Evidence{Kind: EvidenceInvalidDeal, Accused: 1, Deal: invalid})
+	require.NoError(t, err)
+	header := 3 + len(s.String()) + 1 + 4 + 4
+	buf[header] = tagJustificationBundle
+	_, err = UnmarshalEvidence(s, buf)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+	buf[header] = tagResponseBundle
+	_, err = UnmarshalEvidence(s, buf)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+
+	_, err = MarshalEvidence(s, &Evidence{Kind: EvidenceDoubleSigning, Conflicting: []Packet{nil}})
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+	_, err = MarshalEvidence(s, &Evidence{Kind: EvidenceDoubleSigning, Conflicting: []Packet{(*DealBundle)(nil)}})
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+}
@@ -0,0 +1,125 @@
+package dkg
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	clock "github.com/jonboulle/clockwork"
+)
+
+// EarlyPhaser is a Phaser which can end a phase before its deadline. The
+// protocol calls AllReceived when it received all the packets it expects
+// during the phase, i.e. the deals of all the old nodes in the DealPhase, the
+// responses of all the new nodes in the ResponsePhase, and the justifications
+// of all the dealers with complaints in the JustifPhase. Since nodes only send
+// complaints outside of FastSync, the ResponsePhase rarely ends early.
+// AllReceived must not block, and can be called for a phase the phaser didn't
+// reach yet.
+type EarlyPhaser interface {
+	Phaser
+	AllReceived(Phase)
+}
+
+// PhaseDurations holds the time a ClockPhaser waits in each phase before
+// moving to the next one.
+type PhaseDurations struct {
+	Deal     time.Duration
+	Response time.Duration
+	Justif   time.Duration
+}
+
+// ClockPhaser is an EarlyPhaser waiting for the duration of each phase on a
+// clock. The clock can be a fake clock to run the protocol deterministically
+// in tests.
+type ClockPhaser struct {
+	out       chan Phase
+	durations PhaseDurations
+	clock     clock.Clock
+
+	mu       sync.Mutex
+	received map[Phase]bool
+	notify   chan struct{}
+}
+
+// NewClockPhaser returns a phaser using the durations and the clock. If the
+// clock is nil, it uses the real clock.
+func NewClockPhaser(durations PhaseDurations, c clock.Clock) *ClockPhaser {
+	if c == nil {
+		c = clock.NewRealClock()
+	}
+	return &ClockPhaser{
+		out:       make(chan Phase, 4),
+		durations: durations,
+		clock:     c,
+		received:  make(map[Phase]bool),
+		notify:    make(chan struct{}, 1),
+	}
+}
+
+// Start signals the phases until the FinishPhase, and returns. It returns
+// without signaling the next phases when the context is canceled, in which
+// case the protocol should be stopped too.
+func (t *ClockPhaser) Start(ctx context.Context) {
+	phases := []struct {
+		phase    Phase
+		duration time.Duration
+	}{
+		{DealPhase, t.durations.Deal},
+		{ResponsePhase, t.durations.Response},
+		{JustifPhase, t.durations.Justif},
+	}
+	for _, p := range phases {
+		if ctx.Err() != nil {
+			return
+		}
+		t.out <- p.phase
+		if !t.wait(ctx, p.phase, p.duration) {
+			return
+		}
+	}
+	if ctx.Err() == nil {
+		t.out <- FinishPhase
+	}
+}
+
+// wait returns true when the phase is over, or false if the context is
+// canceled.
+func (t *ClockPhaser) wait(ctx context.Context, phase Phase, d time.Duration) bool {
+	timer := t.clock.NewTimer(d)
+	defer timer.Stop()
+	for !t.isReceived(phase) {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.Chan():
+			return true
+		case <-t.notify:
+		}
+	}
+	return true
+}
+
+func (t *ClockPhaser) isReceived(phase Phase) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.received[phase]
+}
+
+// AllReceived ends the given phase as soon as the phaser reaches it.
+func (t *ClockPhaser) AllReceived(phase Phase) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.received[phase] {
+		return
+	}
+	t.received[phase] = true
+	select {
+	case t.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (t *ClockPhaser) NextPhase() chan Phase {
+	return t.out
+}
@@ -0,0 +1,152 @@
+package dkg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	clock "github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+)
+
+var testDurations = PhaseDurations{Deal: time.Second, Response: 2 * time.Second, Justif: 3 * time.Second}
+
+func requirePhase(t *testing.T, p *ClockPhaser, phase Phase) {
+	select {
+	case got := <-p.NextPhase():
+		require.Equal(t, phase, got)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %s", phase)
+	}
+}
+
+func requireNoPhase(t *testing.T, p *ClockPhaser) {
+	select {
+	case got := <-p.NextPhase():
+		t.Fatalf("unexpected %s", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestClockPhaser(t *testing.T) {
+	clk := clock.NewFakeClock()
+	p := NewClockPhaser(testDurations, clk)
+	end := make(chan struct{})
+	go func() {
+		p.Start(context.Background())
+		close(end)
+	}()
+	requirePhase(t, p, DealPhase)
+	for _, next := range []struct {
+		phase    Phase
+		duration time.Duration
+	}{
+		{ResponsePhase, testDurations.Deal},
+		{JustifPhase, testDurations.Response},
+		{FinishPhase, testDurations.Justif},
+	} {
+		clk.BlockUntil(1)
+		clk.Advance(next.duration - time.Millisecond)
+		requireNoPhase(t, p)
+		clk.Advance(time.Millisecond)
+		requirePhase(t, p, next.phase)
+	}
+	<-end
+}
+
+func TestClockPhaserEarly(t *testing.T) {
+	clk := clock.NewFakeClock()
+	p := NewClockPhaser(testDurations, clk)
+	go p.Start(context.Background())
+	requirePhase(t, p, DealPhase)
+	p.AllReceived(DealPhase)
+	requirePhase(t, p, ResponsePhase)
+
+	// a phase can be over before the phaser reaches it
+	p.AllReceived(JustifPhase)
+	p.AllReceived(JustifPhase)
+	clk.BlockUntil(1)
+	clk.Advance(testDurations.Response)
+	requirePhase(t, p, JustifPhase)
+	requirePhase(t, p, FinishPhase)
+}
+
+func TestClockPhaserCancel(t *testing.T) {
+	clk := clock.NewFakeClock()
+	p := NewClockPhaser(testDurations, clk)
+	ctx, cancel := context.WithCancel(context.Background())
+	end := make(chan struct{})
+	go func() {
+		p.Start(ctx)
+		close(end)
+	}()
+	requirePhase(t, p, DealPhase)
+	clk.BlockUntil(1)
+	cancel()
+	<-end
+	clk.Advance(testDurations.Deal + testDurations.Response + testDurations.Justif)
+	requireNoPhase(t, p)
+
+	// nothing is signaled with a canceled context
+	p = NewClockPhaser(testDurations, clk)
+	p.Start(ctx)
+	requireNoPhase(t, p)
+}
+
+func TestProtoClockPhaser(t *testing.T) {
+	n := 5
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	tns := GenerateTestNodes(suite, n)
+	network := NewTestNetwork(n)
+	conf := Config{
+		Suite:     suite,
+		NewNodes:  NodesFromTest(tns),
+		Threshold: n,
+		Auth:      schnorr.NewScheme(suite),
+	}
+	SetupNodes(tns, &conf)
+	clk := clock.NewFakeClock()
+	var phasers []*ClockPhaser
+	for _, tn := range tns {
+		phaser := NewClockPhaser(testDurations, clk)
+		c := *tn.dkg.c
+		proto, err := NewProtocol(&c, network.BoardFor(tn.Index), phaser, false)
+		require.NoError(t, err)
+		tn.proto = proto
+		phasers = append(phasers, phaser)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, p := range phasers {
+		go p.Start(ctx)
+	}
+
+	// all the deals arrive, so the nodes move to the response phase
+	// without waiting for the end of the deal phase
+	require.Eventually(t, func() bool {
+		for _, tn := range tns {
+			var state Phase
+			tn.proto.locked(func() bool { state = tn.proto.dkg.state; return true })
+			if state != ResponsePhase {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 10*time.Millisecond)
+	clk.BlockUntil(n)
+	clk.Advance(testDurations.Response)
+
+	var results []*Result
+	for _, tn := range tns {
+		select {
+		case res := <-tn.proto.WaitEnd():
+			require.NoError(t, res.Error)
+			results = append(results, res.Result)
+		case <-time.After(10 * time.Second):
+			t.Fatal("no result")
+		}
+	}
+	testResults(t, suite, n, n, results)
+}
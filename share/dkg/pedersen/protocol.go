@@ -2,7 +2,10 @@ package dkg
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -76,6 +79,14 @@ type Protocol struct {
 	canIssue  bool
 	res       chan OptionResult
 	skipVerif bool
+	// packets received and not yet processed by the dkg
+	deals   *set
+	resps   *set
+	justifs *set
+	// mu protects the dkg and the sets while a snapshot is taken
+	mu       sync.Mutex
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 func NewProtocol(c *Config, b Board, phaser Phaser, skipVerification bool) (*Protocol, error) {
@@ -83,6 +94,43 @@ func NewProtocol(c *Config, b Board, phaser Phaser, skipVerification bool) (*Pro
 	if err != nil {
 		return nil, err
 	}
+	p := newProtocol(dkg, b, phaser, skipVerification)
+	go p.Start()
+	return p, nil
+}
+
+// NewProtocolFromSnapshot restarts a protocol from a snapshot returned by
+// Protocol.Snapshot. The config must be the one used for the initial
+// protocol. The packets the node already produced are pushed again on the
+// board, and the phases already done are skipped when the phaser signals
+// them, so the phaser can be started from the beginning.
+func NewProtocolFromSnapshot(c *Config, b Board, phaser Phaser, skipVerification bool,
+	snap []byte) (*Protocol, error) {
+	s, err := decodeSnapshot(snap)
+	if err != nil {
+		return nil, err
+	}
+	dkg, err := restore(c, s)
+	if err != nil {
+		return nil, err
+	}
+	if dkg.state == FinishPhase {
+		return nil, errors.New("dkg: can't restart a finished protocol")
+	}
+	p := newProtocol(dkg, b, phaser, skipVerification)
+	if s.Pending != nil {
+		if err := p.restorePending(s.Pending); err != nil {
+			return nil, err
+		}
+	}
+	go func() {
+		p.republish()
+		p.Start()
+	}()
+	return p, nil
+}
+
+func newProtocol(dkg *DistKeyGenerator, b Board, phaser Phaser, skipVerification bool) *Protocol {
 	p := &Protocol{
 		board:     b,
 		phaser:    phaser,
@@ -90,9 +138,97 @@ func NewProtocol(c *Config, b Board, phaser Phaser, skipVerification bool) (*Pro
 		canIssue:  dkg.canIssue,
 		res:       make(chan OptionResult, 1),
 		skipVerif: skipVerification,
+		deals:     newSet(),
+		resps:     newSet(),
+		justifs:   newSet(),
+		stop:      make(chan struct{}),
+	}
+	// equivocations are evicted by the sets before reaching the dkg, which
+	// keeps the evidence
+	for _, s := range []*set{p.deals, p.resps, p.justifs} {
+		s.onConflict = dkg.addDoubleSigningEvidence
+	}
+	return p
+}
+
+// Snapshot returns the state of the dkg along with the packets received and
+// not yet processed. The protocol can be restarted from it with
+// NewProtocolFromSnapshot.
+func (p *Protocol) Snapshot() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, err := p.dkg.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	pending := &pendingData{
+		BadDeals:          p.deals.bad,
+		BadResponses:      p.resps.bad,
+		BadJustifications: p.justifs.bad,
+		Responses:         p.resps.ToResponses(),
+	}
+	if pending.Deals, err = encodeDeals(p.deals.ToDeals()); err != nil {
+		return nil, err
+	}
+	if pending.Justifications, err = encodeJustifs(p.justifs.ToJustifications()); err != nil {
+		return nil, err
+	}
+	s.Pending = pending
+	return json.Marshal(s)
+}
+
+func (p *Protocol) restorePending(pending *pendingData) error {
+	deals, err := decodeDeals(p.dkg.suite, pending.Deals)
+	if err != nil {
+		return err
+	}
+	justifs, err := decodeJustifs(p.dkg.suite, pending.Justifications)
+	if err != nil {
+		return err
+	}
+	for _, d := range deals {
+		p.deals.Push(d)
+	}
+	for _, r := range pending.Responses {
+		if r == nil {
+			return ErrInvalidSnapshot
+		}
+		p.resps.Push(r)
+	}
+	for _, j := range justifs {
+		p.justifs.Push(j)
+	}
+	p.deals.bad = pending.BadDeals
+	p.resps.bad = pending.BadResponses
+	p.justifs.bad = pending.BadJustifications
+	return nil
+}
+
+// republish pushes again the packets the dkg produced before the snapshot, in
+// case they did not reach the board. Other nodes ignore identical packets.
+func (p *Protocol) republish() {
+	if p.dkg.sentDeals != nil {
+		p.board.PushDeals(p.dkg.sentDeals)
+	}
+	if p.dkg.sentResponses != nil {
+		p.board.PushResponses(p.dkg.sentResponses)
+	}
+	if p.dkg.sentJustifs != nil {
+		p.board.PushJustifications(p.dkg.sentJustifs)
 	}
-	go p.Start()
-	return p, nil
+}
+
+// Stop stops the protocol without sending any result, for example before
+// restarting it from a snapshot.
+func (p *Protocol) Stop() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// locked runs f while holding the protocol lock.
+func (p *Protocol) locked(f func() bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return f()
 }
 
 func (p *Protocol) Info(keyvals ...interface{}) {
@@ -109,50 +245,79 @@ func (p *Protocol) Start() {
 		p.startFast()
 		return
 	}
-	var deals = newSet()
-	var resps = newSet()
-	var justifs = newSet()
 	for {
 		select {
+		case <-p.stop:
+			return
 		case newPhase := <-p.phaser.NextPhase():
-			switch newPhase {
-			case InitPhase:
-			case DealPhase:
-				if !p.sendDeals() {
-					return
-				}
-			case ResponsePhase:
-				if !p.sendResponses(deals.ToDeals()) {
-					return
-				}
-			case JustifPhase:
-				if !p.sendJustifications(resps.ToResponses()) {
-					return
-				}
-			case FinishPhase:
-				p.finish(justifs.ToJustifications())
+			if !p.locked(func() bool { return p.nextPhase(newPhase) }) {
 				return
 			}
 		case newDeal := <-p.board.IncomingDeal():
 			if err := p.verify(&newDeal); err == nil {
-				deals.Push(&newDeal)
+				p.locked(func() bool { p.deals.Push(&newDeal); p.signalReceived(); return true })
 			}
 		case newResp := <-p.board.IncomingResponse():
 			if err := p.verify(&newResp); err == nil {
-				resps.Push(&newResp)
+				p.locked(func() bool { p.resps.Push(&newResp); p.signalReceived(); return true })
 			}
 		case newJust := <-p.board.IncomingJustification():
 			if err := p.verify(&newJust); err == nil {
-				justifs.Push(&newJust)
+				p.locked(func() bool { p.justifs.Push(&newJust); p.signalReceived(); return true })
 			}
 		}
 	}
 }
 
+// signalReceived tells an EarlyPhaser the phases for which all the expected
+// packets arrived.
+func (p *Protocol) signalReceived() {
+	e, ok := p.phaser.(EarlyPhaser)
+	if !ok {
+		return
+	}
+	if p.deals.Len() == len(p.dkg.c.OldNodes) {
+		e.AllReceived(DealPhase)
+	}
+	if p.resps.Len() == len(p.dkg.c.NewNodes) {
+		e.AllReceived(ResponsePhase)
+	}
+	// the dealers which must justify are only known after the responses
+	if p.dkg.state == JustifPhase && p.justifs.Len() >= p.dkg.expectedJustifications() {
+		e.AllReceived(JustifPhase)
+	}
+}
+
+// nextPhase moves the dkg to the given phase in the normal mode. Phases the
+// dkg already went through, before a restart, are skipped.
+func (p *Protocol) nextPhase(newPhase Phase) bool {
+	switch newPhase {
+	case InitPhase:
+	case DealPhase:
+		return p.sendDeals()
+	case ResponsePhase:
+		if p.dkg.state >= ResponsePhase {
+			return true
+		}
+		return p.sendResponses(p.deals.ToDeals())
+	case JustifPhase:
+		if p.dkg.state >= JustifPhase {
+			return true
+		}
+		if !p.sendJustifications(p.resps.ToResponses()) {
+			return false
+		}
+		p.signalReceived()
+		return true
+	case FinishPhase:
+		p.finish(p.justifs.ToJustifications())
+		return false
+	}
+	return true
+}
+
+//nolint:funlen
 func (p *Protocol) startFast() {
-	var deals = newSet()
-	var resps = newSet()
-	var justifs = newSet()
 	var newN = len(p.dkg.c.NewNodes)
 	var oldN = len(p.dkg.c.OldNodes)
 	// we keep the phase in sync with the dkg phase
@@ -170,14 +335,14 @@ func (p *Protocol) startFast() {
 		if !p.canIssue && phase() != InitPhase {
 			return true
 		}
-		return p.sendResponses(deals.ToDeals())
+		return p.sendResponses(p.deals.ToDeals())
 	}
 
 	toJust := func() bool {
 		if phase() != ResponsePhase {
 			return true
 		}
-		return p.sendJustifications(resps.ToResponses())
+		return p.sendJustifications(p.resps.ToResponses())
 	}
 	// always return false when we are in the finish phase - we quit the
 	// protocol.
@@ -185,32 +350,34 @@ func (p *Protocol) startFast() {
 		if phase() != JustifPhase {
 			return true
 		}
-		p.finish(justifs.ToJustifications())
+		p.finish(p.justifs.ToJustifications())
 		return false
 	}
 	for {
 		select {
+		case <-p.stop:
+			return
 		case newPhase := <-p.phaser.NextPhase():
-			switch newPhase {
-			case InitPhase:
-			case DealPhase:
-				p.Info("phaser", "msg", "moving to sending deals phase")
-				if !p.sendDeals() {
-					return
+			cont := p.locked(func() bool {
+				switch newPhase {
+				case InitPhase:
+				case DealPhase:
+					p.Info("phaser", "msg", "moving to sending deals phase")
+					return p.sendDeals()
+				case ResponsePhase:
+					p.Info("phaser", "msg", fmt.Sprintf("moving to response phase, got %d deals", p.deals.Len()))
+					return toResp()
+				case JustifPhase:
+					p.Info("phaser", "msg", fmt.Sprintf("moving to justifications phase, got %d resps", p.resps.Len()))
+					return toJust()
+				case FinishPhase:
+					// whatever happens here, if phaser says it's finished we finish
+					toFinish()
+					return false
 				}
-			case ResponsePhase:
-				p.Info("phaser", "msg", fmt.Sprintf("moving to response phase, got %d deals", deals.Len()))
-				if !toResp() {
-					return
-				}
-			case JustifPhase:
-				p.Info("phaser", "msg", fmt.Sprintf("moving to justifications phase, got %d resps", resps.Len()))
-				if !toJust() {
-					return
-				}
-			case FinishPhase:
-				// whatever happens here, if phaser says it's finished we finish
-				toFinish()
+				return true
+			})
+			if !cont {
 				return
 			}
 		case newDeal, ok := <-p.board.IncomingDeal():
@@ -218,54 +385,67 @@ func (p *Protocol) startFast() {
 				p.Error("incoming deal channel closed unexpectedly")
 				return
 			}
+			cont := p.locked(func() bool {
+				if err := p.verify(&newDeal); err == nil {
+					p.deals.Push(&newDeal)
+				} else {
+					p.Error("newDeal", "invalid deal signature:", err)
+				}
 
-			if err := p.verify(&newDeal); err == nil {
-				deals.Push(&newDeal)
-			} else {
-				p.Error("newDeal", "invalid deal signature:", err)
-			}
-
-			if deals.Len() == oldN {
-				p.Info("newDeal", "fast moving to response phase", fmt.Sprintf(" got %d deals", oldN))
-				if !toResp() {
-					return
+				if p.deals.Len() == oldN {
+					p.Info("newDeal", "fast moving to response phase", fmt.Sprintf(" got %d deals", oldN))
+					return toResp()
 				}
+				return true
+			})
+			if !cont {
+				return
 			}
 		case newResp, ok := <-p.board.IncomingResponse():
 			if !ok {
 				p.Error("incoming response channel closed unexpectedly")
 				return
 			}
-			if err := p.verify(&newResp); err == nil {
-				resps.Push(&newResp)
-			} else {
-				p.Error("newResp", "Received invalid response signature:", err)
-			}
-			if resps.Len() == newN {
-				p.Info("newResp", "fast moving to justifications phase", fmt.Sprintf("got %d resps", newN))
-				if !toJust() {
-					return
+			cont := p.locked(func() bool {
+				if err := p.verify(&newResp); err == nil {
+					p.resps.Push(&newResp)
+				} else {
+					p.Error("newResp", "Received invalid response signature:", err)
+				}
+				if p.resps.Len() == newN {
+					p.Info("newResp", "fast moving to justifications phase", fmt.Sprintf("got %d resps", newN))
+					return toJust()
 				}
+				return true
+			})
+			if !cont {
+				return
 			}
 		case newJust, ok := <-p.board.IncomingJustification():
 			if !ok {
 				p.Error("incoming justification channel closed unexpectedly")
 				return
 			}
-			if err := p.verify(&newJust); err == nil {
-				justifs.Push(&newJust)
-			} else {
-				p.Error("newJust", "invalid justification signature:", err)
-			}
-			if justifs.Len() == oldN {
-				// we finish only if it's time to do so, maybe we received
-				// justifications but are not in the right phase yet since it
-				// may not be the right time or haven't received enough msg from
-				// previous phase
-				if !toFinish() {
-					p.Info("newJust", "fast moving to finish phase phase", fmt.Sprintf("got %d resps", justifs.Len()))
-					return
+			cont := p.locked(func() bool {
+				if err := p.verify(&newJust); err == nil {
+					p.justifs.Push(&newJust)
+				} else {
+					p.Error("newJust", "invalid justification signature:", err)
 				}
+				if p.justifs.Len() == oldN {
+					// we finish only if it's time to do so, maybe we received
+					// justifications but are not in the right phase yet since it
+					// may not be the right time or haven't received enough msg from
+					// previous phase
+					if !toFinish() {
+						p.Info("newJust", "fast moving to finish phase phase", fmt.Sprintf("got %d resps", p.justifs.Len()))
+						return false
+					}
+				}
+				return true
+			})
+			if !cont {
+				return
 			}
 		}
 	}
@@ -280,7 +460,8 @@ func (p *Protocol) verify(packet Packet) error {
 }
 
 func (p *Protocol) sendDeals() bool {
-	if !p.canIssue {
+	if !p.canIssue || p.dkg.state != InitPhase {
+		// deals are already sent when restarting from a snapshot
 		return true
 	}
 	bundle, err := p.dkg.Deals()
@@ -339,6 +520,14 @@ func (p *Protocol) finish(justifs []*JustificationBundle) {
 	}
 }
 
+// Evidence returns the evidence gathered by the dkg against misbehaving
+// nodes.
+func (p *Protocol) Evidence() []*Evidence {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dkg.Evidence()
+}
+
 func (p *Protocol) WaitEnd() <-chan OptionResult {
 	return p.res
 }
@@ -351,6 +540,8 @@ type OptionResult struct {
 type set struct {
 	vals map[Index]Packet
 	bad  []Index
+	// onConflict is called with the two packets of a misbehaving issuer
+	onConflict func(a, b Packet)
 }
 
 func newSet() *set {
@@ -373,6 +564,9 @@ func (s *set) Push(p Packet) {
 			// bad behavior - we evict
 			delete(s.vals, idx)
 			s.bad = append(s.bad, idx)
+			if s.onConflict != nil {
+				s.onConflict(prev, p)
+			}
 		}
 		// same packet just rebroadcasted - all good
 		return
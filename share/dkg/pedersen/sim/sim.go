@@ -0,0 +1,480 @@
+// Package sim runs the pedersen DKG protocol between in-process nodes over a
+// simulated network dropping, delaying and tampering with packets, and
+// cutting groups of nodes from the others for a while.
+//
+// The nodes share a fake clock only advanced by the simulation, and packets
+// and phases are handed to a protocol one at a time, once it processed the
+// previous ones, so a run only depends on the config and its seed. It's meant
+// for tests of the protocol and of applications built on it.
+package sim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	clock "github.com/jonboulle/clockwork"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// ErrNotFinished is returned by Run when the protocols didn't finish in time.
+var ErrNotFinished = errors.New("sim: protocols not finished")
+
+// Behavior is the way a node deviates from the protocol.
+type Behavior int
+
+const (
+	// Honest follows the protocol.
+	Honest Behavior = iota
+	// Silent never sends anything.
+	Silent
+	// BadShare sends an invalid share to the next node, and has to justify.
+	BadShare
+	// Equivocate sends two different deals to everyone.
+	Equivocate
+)
+
+// Partition cuts a group of nodes from the other nodes until it heals. The
+// packets between the two sides are held and delivered once it heals.
+type Partition struct {
+	Nodes []int
+	// Heal is the time since the start of the simulation at which the
+	// partition heals.
+	Heal time.Duration
+}
+
+// Config holds the parameters of a simulation.
+type Config struct {
+	N         int
+	Threshold int
+	Seed      int64
+	Durations dkg.PhaseDurations
+	// Step is the time the clock advances by between deliveries.
+	Step time.Duration
+	// DropRate is the probability a packet to another node is dropped.
+	DropRate float64
+	// MaxDelay bounds the delay of each packet, a multiple of Step.
+	MaxDelay   time.Duration
+	Behaviors  map[int]Behavior
+	Partitions []Partition
+}
+
+// Default returns the config of 7 honest nodes with a threshold of 4, over a
+// network delaying the packets by up to a second.
+func Default(seed int64) Config {
+	return Config{
+		N:         7,
+		Threshold: 4,
+		Seed:      seed,
+		Durations: dkg.PhaseDurations{Deal: 2 * time.Second, Response: 2 * time.Second, Justif: 2 * time.Second},
+		Step:      100 * time.Millisecond,
+		MaxDelay:  time.Second,
+		Behaviors: map[int]Behavior{},
+	}
+}
+
+type event struct {
+	at     time.Time
+	seq    int
+	to     int
+	packet dkg.Packet
+}
+
+// Simulation runs the protocol between the nodes of a config.
+type Simulation struct {
+	c       Config
+	clock   clock.FakeClock
+	start   time.Time
+	configs []*dkg.Config
+	nodes   []*node
+
+	mu     sync.Mutex
+	rand   *rand.Rand
+	seq    int
+	events []*event
+	trace  []string
+	err    error
+}
+
+// node is the board and the phaser of a protocol. The phases of the
+// ClockPhaser are forwarded by the simulation.
+type node struct {
+	sim       *Simulation
+	index     int
+	phaser    *dkg.ClockPhaser
+	phaserEnd chan struct{}
+	proto     *dkg.Protocol
+	out       chan dkg.Phase
+	deals     chan dkg.DealBundle
+	resps     chan dkg.ResponseBundle
+	justs     chan dkg.JustificationBundle
+	done      chan struct{}
+	res       dkg.OptionResult
+}
+
+// New creates the nodes of the simulation, with the edwards25519 suite.
+func New(c Config) (*Simulation, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	s := &Simulation{
+		c:     c,
+		clock: clock.NewFakeClock(),
+		rand:  rand.New(rand.NewSource(c.Seed)), //nolint:gosec // deterministic on purpose
+	}
+	s.start = s.clock.Now()
+	var keys []kyber.Scalar
+	var nodes []dkg.Node
+	for i := 0; i < c.N; i++ {
+		key := suite.Scalar().Pick(random.New())
+		keys = append(keys, key)
+		nodes = append(nodes, dkg.Node{Index: uint32(i), Public: suite.Point().Mul(key, nil)})
+	}
+	nonce := dkg.GetNonce()
+	for i := 0; i < c.N; i++ {
+		conf := &dkg.Config{
+			Suite:     suite,
+			Longterm:  keys[i],
+			NewNodes:  nodes,
+			Threshold: c.Threshold,
+			Nonce:     nonce,
+			Auth:      schnorr.NewScheme(suite),
+			Clock:     s.clock,
+		}
+		n := &node{
+			sim:       s,
+			index:     i,
+			phaser:    dkg.NewClockPhaser(c.Durations, s.clock),
+			phaserEnd: make(chan struct{}),
+			out:       make(chan dkg.Phase),
+			deals:     make(chan dkg.DealBundle),
+			resps:     make(chan dkg.ResponseBundle),
+			justs:     make(chan dkg.JustificationBundle),
+			done:      make(chan struct{}),
+		}
+		var err error
+		if n.proto, err = dkg.NewProtocol(conf, n, n, false); err != nil {
+			return nil, err
+		}
+		s.configs = append(s.configs, conf)
+		s.nodes = append(s.nodes, n)
+	}
+	return s, nil
+}
+
+// Run runs the protocols until they all finished, and returns the trace of
+// the network.
+func (s *Simulation) Run() ([]string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, n := range s.nodes {
+		go func(n *node) {
+			n.res = <-n.proto.WaitEnd()
+			close(n.done)
+		}(n)
+		go func(n *node) {
+			n.phaser.Start(ctx)
+			close(n.phaserEnd)
+		}(n)
+	}
+	d := s.c.Durations
+	total := d.Deal + d.Response + d.Justif
+	end := total
+	for _, p := range s.c.Partitions {
+		if p.Heal > end {
+			end = p.Heal
+		}
+	}
+	for {
+		// wait until the phasers signaled the phase of the current time
+		if s.clock.Since(s.start) < total {
+			s.clock.BlockUntil(len(s.nodes))
+		} else {
+			for _, n := range s.nodes {
+				<-n.phaserEnd
+			}
+		}
+		s.settle()
+		if err := s.error(); err != nil {
+			return nil, err
+		}
+		if s.finished() {
+			break
+		}
+		if s.clock.Since(s.start) >= end+s.c.MaxDelay+s.c.Step {
+			return nil, ErrNotFinished
+		}
+		s.clock.Advance(s.c.Step)
+	}
+	return s.trace, nil
+}
+
+// Result returns the result of the protocol of node i, once Run returned.
+func (s *Simulation) Result(i int) dkg.OptionResult {
+	return s.nodes[i].res
+}
+
+// Evidence returns the evidence gathered by node i.
+func (s *Simulation) Evidence(i int) []*dkg.Evidence {
+	return s.nodes[i].proto.Evidence()
+}
+
+// Config returns the config of the DKG of node i, for example to verify its
+// evidence.
+func (s *Simulation) Config(i int) *dkg.Config {
+	return s.configs[i]
+}
+
+// Outcome summarizes the results of the nodes, to compare runs.
+func (s *Simulation) Outcome() []string {
+	var res []string
+	for _, n := range s.nodes {
+		if n.res.Error != nil {
+			res = append(res, fmt.Sprintf("%d error %v", n.index, n.res.Error))
+			continue
+		}
+		var qual []uint32
+		for _, q := range n.res.Result.QUAL {
+			qual = append(qual, q.Index)
+		}
+		var kinds []string
+		for _, ev := range n.proto.Evidence() {
+			kinds = append(kinds, fmt.Sprintf("%s by %d", ev.Kind, ev.Accused))
+		}
+		res = append(res, fmt.Sprintf("%d qual %v evidence %v", n.index, qual, kinds))
+	}
+	return res
+}
+
+func (s *Simulation) finished() bool {
+	for _, n := range s.nodes {
+		select {
+		case <-n.done:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Simulation) error() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// settle hands the phases and the packets due to the protocols, node after
+// node, until there is nothing left to deliver at the current time.
+func (s *Simulation) settle() {
+	for {
+		progress := false
+		for _, n := range s.nodes {
+			for {
+				select {
+				case phase := <-n.phaser.NextPhase():
+					n.deliver(phase)
+					progress = true
+					continue
+				default:
+				}
+				break
+			}
+			for _, e := range s.due(n.index) {
+				n.deliver(e.packet)
+				progress = true
+			}
+		}
+		if !progress {
+			return
+		}
+	}
+}
+
+// due removes and returns the events of the node due at the current time.
+func (s *Simulation) due(to int) []*event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	var due, rest []*event
+	for _, e := range s.events {
+		if e.to == to && !e.at.After(now) {
+			due = append(due, e)
+		} else {
+			rest = append(rest, e)
+		}
+	}
+	s.events = rest
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].at.Equal(due[j].at) {
+			return due[i].at.Before(due[j].at)
+		}
+		return due[i].seq < due[j].seq
+	})
+	return due
+}
+
+// deliver hands the phase or the packet to the protocol, and waits until the
+// protocol processed it, or ended.
+func (n *node) deliver(v interface{}) {
+	var sent bool
+	switch b := v.(type) {
+	case dkg.Phase:
+		select {
+		case n.out <- b:
+			sent = true
+		case <-n.done:
+		}
+	case *dkg.DealBundle:
+		select {
+		case n.deals <- *b:
+			sent = true
+		case <-n.done:
+		}
+	case *dkg.ResponseBundle:
+		select {
+		case n.resps <- *b:
+			sent = true
+		case <-n.done:
+		}
+	case *dkg.JustificationBundle:
+		select {
+		case n.justs <- *b:
+			sent = true
+		case <-n.done:
+		}
+	}
+	if !sent {
+		return
+	}
+	// an invalid deal, ignored by the protocol, tells when it is waiting for
+	// the next packet again
+	select {
+	case n.deals <- dkg.DealBundle{}:
+	case <-n.done:
+	}
+}
+
+// heal returns the time at which the link between the nodes heals, or the
+// zero time if they are on the same side of all the partitions.
+func (s *Simulation) heal(from, to int) time.Time {
+	var at time.Time
+	for _, p := range s.c.Partitions {
+		in := map[int]bool{}
+		for _, i := range p.Nodes {
+			in[i] = true
+		}
+		if in[from] != in[to] {
+			if h := s.start.Add(p.Heal); h.After(at) {
+				at = h
+			}
+		}
+	}
+	return at
+}
+
+// broadcast schedules the delivery of the packet to all the nodes.
+func (s *Simulation) broadcast(from int, kind string, p dkg.Packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elapsed := s.clock.Since(s.start)
+	if s.c.Behaviors[from] == Silent {
+		s.trace = append(s.trace, fmt.Sprintf("%v %d silent %s", elapsed, from, kind))
+		return
+	}
+	for to := range s.nodes {
+		if to != from && s.rand.Float64() < s.c.DropRate {
+			s.trace = append(s.trace, fmt.Sprintf("%v %d->%d %s dropped", elapsed, from, to, kind))
+			continue
+		}
+		delay := time.Duration(s.rand.Int63n(int64(s.c.MaxDelay/s.c.Step)+1)) * s.c.Step
+		at := s.clock.Now().Add(delay)
+		msg := fmt.Sprintf("%v %d->%d %s delay %v", elapsed, from, to, kind, delay)
+		if heal := s.heal(from, to); heal.After(s.clock.Now()) {
+			at = heal.Add(delay)
+			msg += fmt.Sprintf(" held until %v", heal.Sub(s.start))
+		}
+		s.seq++
+		s.events = append(s.events, &event{at: at, seq: s.seq, to: to, packet: p})
+		s.trace = append(s.trace, msg)
+	}
+}
+
+// tamper returns a copy of the deal with an invalid share for the next node,
+// signed by the dealer.
+func (s *Simulation) tamper(from int, d *dkg.DealBundle) (*dkg.DealBundle, error) {
+	c := *d
+	c.Deals = append([]dkg.Deal{}, d.Deals...)
+	next := uint32((from + 1) % len(s.nodes))
+	for i := range c.Deals {
+		if c.Deals[i].ShareIndex == next {
+			c.Deals[i].EncryptedShare = []byte("not a share")
+		}
+	}
+	h, err := c.Hash()
+	if err != nil {
+		return nil, err
+	}
+	conf := s.configs[from]
+	if c.Signature, err = conf.Auth.Sign(conf.Longterm, h); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *Simulation) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (n *node) PushDeals(d *dkg.DealBundle) {
+	switch n.sim.c.Behaviors[n.index] {
+	case BadShare:
+		t, err := n.sim.tamper(n.index, d)
+		if err != nil {
+			n.sim.fail(err)
+			return
+		}
+		d = t
+	case Equivocate:
+		t, err := n.sim.tamper(n.index, d)
+		if err != nil {
+			n.sim.fail(err)
+			return
+		}
+		n.sim.broadcast(n.index, "deal", t)
+	}
+	n.sim.broadcast(n.index, "deal", d)
+}
+
+func (n *node) PushResponses(r *dkg.ResponseBundle) {
+	n.sim.broadcast(n.index, "response", r)
+}
+
+func (n *node) PushJustifications(j *dkg.JustificationBundle) {
+	n.sim.broadcast(n.index, "justification", j)
+}
+
+func (n *node) IncomingDeal() <-chan dkg.DealBundle {
+	return n.deals
+}
+
+func (n *node) IncomingResponse() <-chan dkg.ResponseBundle {
+	return n.resps
+}
+
+func (n *node) IncomingJustification() <-chan dkg.JustificationBundle {
+	return n.justs
+}
+
+func (n *node) NextPhase() chan dkg.Phase {
+	return n.out
+}
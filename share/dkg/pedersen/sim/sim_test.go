@@ -0,0 +1,109 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/share"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+)
+
+func run(t *testing.T, c Config) (*Simulation, []string) {
+	s, err := New(c)
+	require.NoError(t, err)
+	trace, err := s.Run()
+	require.NoError(t, err)
+	return s, trace
+}
+
+func qual(res *dkg.Result) []uint32 {
+	var q []uint32
+	for _, n := range res.QUAL {
+		q = append(q, n.Index)
+	}
+	return q
+}
+
+// checkResults verifies that the nodes agree on the distributed key, and
+// that their shares recover it.
+func checkResults(t *testing.T, s *Simulation, threshold int, results []*dkg.Result) {
+	suite := s.Config(0).Suite
+	var shares []*share.PriShare
+	for _, res := range results {
+		require.True(t, results[0].PublicEqual(res))
+		shares = append(shares, res.Key.PriShare())
+	}
+	secret, err := share.RecoverSecret(suite, shares, threshold, len(s.nodes))
+	require.NoError(t, err)
+	require.True(t, results[0].Key.Public().Equal(suite.Point().Mul(secret, nil)))
+}
+
+func TestSimulationByzantine(t *testing.T) {
+	c := Default(1)
+	c.Behaviors[1] = Silent
+	c.Behaviors[2] = BadShare
+	c.Behaviors[3] = Equivocate
+	s, _ := run(t, c)
+
+	var results []*dkg.Result
+	for i := 0; i < c.N; i++ {
+		res := s.Result(i)
+		if c.Behaviors[i] == Silent || c.Behaviors[i] == Equivocate {
+			require.Error(t, res.Error, "node %d", i)
+			continue
+		}
+		require.NoError(t, res.Error, "node %d", i)
+		if c.Behaviors[i] != Honest {
+			continue
+		}
+		results = append(results, res.Result)
+		// the silent and the equivocating dealers are left out, the bad
+		// share is justified
+		require.Equal(t, []uint32{0, 2, 4, 5, 6}, qual(res.Result), "node %d", i)
+		ev := s.Evidence(i)
+		require.Len(t, ev, 1)
+		require.Equal(t, dkg.EvidenceDoubleSigning, ev[0].Kind)
+		require.Equal(t, dkg.Index(3), ev[0].Accused)
+		require.NoError(t, dkg.VerifyEvidence(s.Config(i), ev[0]))
+	}
+	checkResults(t, s, c.Threshold, results)
+}
+
+func TestSimulationPartition(t *testing.T) {
+	// the last node is cut from the others until the response phase: its
+	// deal and the deals for it arrive too late
+	c := Default(7)
+	c.Partitions = []Partition{{Nodes: []int{6}, Heal: 3 * time.Second}}
+	s, trace := run(t, c)
+	require.Contains(t, trace, "0s 6->0 deal delay 300ms held until 3s")
+
+	var results []*dkg.Result
+	for i := 0; i < c.N-1; i++ {
+		res := s.Result(i)
+		require.NoError(t, res.Error, "node %d", i)
+		require.Equal(t, []uint32{0, 1, 2, 3, 4, 5}, qual(res.Result), "node %d", i)
+		results = append(results, res.Result)
+	}
+	checkResults(t, s, c.Threshold, results)
+}
+
+func TestSimulationDeterministic(t *testing.T) {
+	c := Default(42)
+	c.DropRate = 0.1
+	// some packets arrive after the end of their phase
+	c.MaxDelay = 3 * time.Second
+	c.Behaviors[4] = BadShare
+	s, trace := run(t, c)
+	outcome := s.Outcome()
+
+	for i := 0; i < 2; i++ {
+		s2, trace2 := run(t, c)
+		require.Equal(t, trace, trace2)
+		require.Equal(t, outcome, s2.Outcome())
+	}
+
+	c.Seed++
+	_, trace3 := run(t, c)
+	require.NotEqual(t, trace, trace3)
+}
@@ -0,0 +1,540 @@
+package dkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/share"
+)
+
+// snapshotVersion is the version of the snapshot encoding.
+const snapshotVersion = 1
+
+// ErrSnapshotNonce is returned when restoring a snapshot taken during another
+// run of the protocol.
+var ErrSnapshotNonce = errors.New("dkg: snapshot nonce does not match config")
+
+// ErrSnapshotConfig is returned when restoring a snapshot taken with another
+// config.
+var ErrSnapshotConfig = errors.New("dkg: snapshot config hash does not match config")
+
+// ErrInvalidSnapshot is returned when a snapshot can't be decoded.
+var ErrInvalidSnapshot = errors.New("dkg: invalid snapshot")
+
+// snapshot is the encoded state of a DistKeyGenerator. Points and scalars are
+// stored in their binary form.
+type snapshot struct {
+	Version    int
+	Nonce      []byte
+	ConfigHash []byte
+	Phase      Phase
+	// coefficients of the private polynomial of a dealer
+	Coefficients   [][]byte
+	Statuses       StatusMatrix
+	ValidShares    map[uint32][]byte
+	Publics        map[uint32][][]byte
+	Evicted        []uint32
+	EvictedHolders []Index
+
+	Deals          []*dealBundleData
+	Justifications []*justifBundleData
+
+	SentDeals     *dealBundleData
+	SentResponses *ResponseBundle
+	SentJustifs   *justifBundleData
+
+	// Evidence holds the evidence gathered so far, each encoded with
+	// MarshalEvidence.
+	Evidence [][]byte `json:",omitempty"`
+
+	// Pending holds the packets received by a Protocol but not processed yet.
+	Pending *pendingData `json:",omitempty"`
+}
+
+type pendingData struct {
+	Deals             []*dealBundleData
+	BadDeals          []Index
+	Responses         []*ResponseBundle
+	BadResponses      []Index
+	Justifications    []*justifBundleData
+	BadJustifications []Index
+}
+
+type dealBundleData struct {
+	DealerIndex uint32
+	Deals       []Deal
+	Public      [][]byte
+	SessionID   []byte
+	Signature   []byte
+}
+
+type justificationData struct {
+	ShareIndex uint32
+	Share      []byte
+}
+
+type justifBundleData struct {
+	DealerIndex    uint32
+	Justifications []justificationData
+	SessionID      []byte
+	Signature      []byte
+}
+
+// Snapshot returns an encoding of the current state of the generator: the
+// deals and justifications received, the status matrix, the current phase,
+// the valid shares, the evidence gathered against misbehaving nodes and the
+// private polynomial of the dealer. The generator can be recreated from it
+// with RestoreDistKeyGenerator, for example after a crash.
+//
+// The snapshot is plaintext JSON and is NOT encrypted: it holds the
+// coefficients of the secret polynomial of the dealer and the shares it
+// received, from which the share of the node can be computed, and enough of
+// them reveal the distributed secret. It must be encrypted or stored as
+// securely as the longterm key, and deleted once the protocol is over.
+func (d *DistKeyGenerator) Snapshot() ([]byte, error) {
+	s, err := d.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(s)
+}
+
+func (d *DistKeyGenerator) snapshot() (*snapshot, error) {
+	var err error
+	s := &snapshot{
+		Version:        snapshotVersion,
+		Nonce:          d.c.Nonce,
+		ConfigHash:     d.configHash,
+		Phase:          d.state,
+		Statuses:       *d.statuses,
+		ValidShares:    make(map[uint32][]byte, len(d.validShares)),
+		Publics:        make(map[uint32][][]byte, len(d.allPublics)),
+		Evicted:        d.evicted,
+		EvictedHolders: d.evictedHolders,
+		SentResponses:  d.sentResponses,
+	}
+	if d.canIssue {
+		if s.Coefficients, err = marshalScalars(d.dpriv.Coefficients()); err != nil {
+			return nil, err
+		}
+	}
+	for i, v := range d.validShares {
+		if s.ValidShares[i], err = v.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	for i, pub := range d.allPublics {
+		_, commits := pub.Info()
+		if s.Publics[i], err = marshalPoints(commits); err != nil {
+			return nil, err
+		}
+	}
+	if s.Deals, err = encodeDeals(d.receivedDeals); err != nil {
+		return nil, err
+	}
+	if s.Justifications, err = encodeJustifs(d.receivedJustifs); err != nil {
+		return nil, err
+	}
+	if d.sentDeals != nil {
+		if s.SentDeals, err = encodeDeal(d.sentDeals); err != nil {
+			return nil, err
+		}
+	}
+	if d.sentJustifs != nil {
+		if s.SentJustifs, err = encodeJustif(d.sentJustifs); err != nil {
+			return nil, err
+		}
+	}
+	for _, ev := range d.evidence {
+		buf, err := MarshalEvidence(d.suite, ev)
+		if err != nil {
+			return nil, err
+		}
+		s.Evidence = append(s.Evidence, buf)
+	}
+	return s, nil
+}
+
+// RestoreDistKeyGenerator returns a DistKeyGenerator in the state saved by
+// Snapshot. The config must be the same as the one given to
+// NewDistKeyHandler when the snapshot was taken; ErrSnapshotNonce or
+// ErrSnapshotConfig is returned otherwise.
+func RestoreDistKeyGenerator(c *Config, snap []byte) (*DistKeyGenerator, error) {
+	s, err := decodeSnapshot(snap)
+	if err != nil {
+		return nil, err
+	}
+	return restore(c, s)
+}
+
+func decodeSnapshot(snap []byte) (*snapshot, error) {
+	s := new(snapshot)
+	if err := json.Unmarshal(snap, s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, s.Version)
+	}
+	return s, nil
+}
+
+//nolint:funlen
+func restore(c *Config, s *snapshot) (*DistKeyGenerator, error) {
+	if !bytes.Equal(s.Nonce, c.Nonce) {
+		return nil, ErrSnapshotNonce
+	}
+	d, err := NewDistKeyHandler(c)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(s.ConfigHash, d.configHash) {
+		return nil, ErrSnapshotConfig
+	}
+	if s.Phase < InitPhase || s.Phase > FinishPhase || s.Statuses == nil {
+		return nil, ErrInvalidSnapshot
+	}
+
+	if err := checkSnapshotIndices(d.c, s); err != nil {
+		return nil, err
+	}
+
+	d.state = s.Phase
+	if d.canIssue {
+		coeffs, err := unmarshalScalars(d.suite, s.Coefficients)
+		if err != nil {
+			return nil, err
+		}
+		if len(coeffs) != len(d.dpriv.Coefficients()) {
+			return nil, fmt.Errorf("%w: wrong number of coefficients", ErrInvalidSnapshot)
+		}
+		d.dpriv = share.CoefficientsToPriPoly(d.suite, coeffs)
+		d.dpub = d.dpriv.Commit(d.suite.Point().Base())
+	}
+	d.statuses = &s.Statuses
+	for i, buf := range s.ValidShares {
+		v := d.suite.Scalar()
+		if err := v.UnmarshalBinary(buf); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		d.validShares[i] = v
+	}
+	for i, bufs := range s.Publics {
+		commits, err := unmarshalPoints(d.suite, bufs)
+		if err != nil {
+			return nil, err
+		}
+		d.allPublics[i] = share.NewPubPoly(d.suite, d.suite.Point().Base(), commits)
+	}
+	d.evicted = s.Evicted
+	d.evictedHolders = s.EvictedHolders
+
+	if d.receivedDeals, err = decodeDeals(d.suite, s.Deals); err != nil {
+		return nil, err
+	}
+	if d.receivedJustifs, err = decodeJustifs(d.suite, s.Justifications); err != nil {
+		return nil, err
+	}
+	if s.SentDeals != nil {
+		if d.sentDeals, err = decodeDeal(d.suite, s.SentDeals); err != nil {
+			return nil, err
+		}
+	}
+	d.sentResponses = s.SentResponses
+	if s.SentJustifs != nil {
+		if d.sentJustifs, err = decodeJustif(d.suite, s.SentJustifs); err != nil {
+			return nil, err
+		}
+	}
+	for _, buf := range s.Evidence {
+		ev, err := UnmarshalEvidence(d.suite, buf)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		// the snapshot may have been tampered with, and only verified
+		// evidence is ever kept
+		if err := VerifyEvidence(d.c, ev); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		d.evidence = append(d.evidence, ev)
+	}
+	return d, nil
+}
+
+// checkSnapshotIndices checks that the indices of the snapshot are the ones
+// of the nodes of the config, so that the restored generator doesn't index
+// its status matrix out of range.
+func checkSnapshotIndices(c *Config, s *snapshot) error {
+	if len(s.Statuses) != len(c.OldNodes) {
+		return fmt.Errorf("%w: status matrix has %d dealers", ErrInvalidSnapshot, len(s.Statuses))
+	}
+	for dealer, bitset := range s.Statuses {
+		if !isIndexIncluded(c.OldNodes, dealer) {
+			return fmt.Errorf("%w: unknown dealer %d", ErrInvalidSnapshot, dealer)
+		}
+		if len(bitset) != len(c.NewNodes) {
+			return fmt.Errorf("%w: status matrix has %d share holders for dealer %d",
+				ErrInvalidSnapshot, len(bitset), dealer)
+		}
+		for holder, status := range bitset {
+			if !isIndexIncluded(c.NewNodes, holder) {
+				return fmt.Errorf("%w: unknown share holder %d", ErrInvalidSnapshot, holder)
+			}
+			if status != Success && status != Complaint {
+				return fmt.Errorf("%w: invalid status %d", ErrInvalidSnapshot, status)
+			}
+		}
+	}
+	dealers := append([]Index{}, s.Evicted...)
+	for i := range s.ValidShares {
+		dealers = append(dealers, i)
+	}
+	for i := range s.Publics {
+		dealers = append(dealers, i)
+	}
+	for _, b := range s.Deals {
+		if b != nil {
+			dealers = append(dealers, b.DealerIndex)
+		}
+	}
+	for _, b := range s.Justifications {
+		if b != nil {
+			dealers = append(dealers, b.DealerIndex)
+		}
+	}
+	for _, i := range dealers {
+		if !isIndexIncluded(c.OldNodes, i) {
+			return fmt.Errorf("%w: unknown dealer %d", ErrInvalidSnapshot, i)
+		}
+	}
+	for _, i := range s.EvictedHolders {
+		if !isIndexIncluded(c.NewNodes, i) {
+			return fmt.Errorf("%w: unknown share holder %d", ErrInvalidSnapshot, i)
+		}
+	}
+	return nil
+}
+
+// hashConfig returns a hash of the fields of the config that define the run
+// of the protocol. It gives the same hash for the config before and after
+// NewDistKeyHandler fills in OldNodes and PublicCoeffs.
+func hashConfig(c *Config) ([]byte, error) {
+	h := sha256.New()
+	_, _ = h.Write([]byte(c.Suite.String()))
+	_, _ = h.Write(c.Nonce)
+	pub, err := c.Suite.Point().Mul(c.Longterm, nil).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	_, _ = h.Write(pub)
+	for _, v := range []uint32{uint32(c.Threshold), uint32(c.OldThreshold)} {
+		_ = binary.Write(h, binary.BigEndian, v)
+	}
+	if c.FastSync {
+		_, _ = h.Write([]byte{1})
+	} else {
+		_, _ = h.Write([]byte{0})
+	}
+	oldNodes := c.OldNodes
+	if c.Share == nil && c.PublicCoeffs == nil {
+		// a fresh dkg sets the old nodes to the new nodes
+		oldNodes = nil
+	}
+	for _, list := range [][]Node{oldNodes, c.NewNodes} {
+		_ = binary.Write(h, binary.BigEndian, uint32(len(list)))
+		for _, n := range list {
+			_ = binary.Write(h, binary.BigEndian, n.Index)
+			buf, err := n.Public.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			_, _ = h.Write(buf)
+		}
+	}
+	coeffs := c.PublicCoeffs
+	if c.Share != nil {
+		// the public coefficients are taken from the share when resharing
+		coeffs = c.Share.Commits
+	}
+	_ = binary.Write(h, binary.BigEndian, uint32(len(coeffs)))
+	for _, p := range coeffs {
+		buf, err := p.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		_, _ = h.Write(buf)
+	}
+	return h.Sum(nil), nil
+}
+
+func encodeDeal(b *DealBundle) (*dealBundleData, error) {
+	public, err := marshalPoints(b.Public)
+	if err != nil {
+		return nil, err
+	}
+	return &dealBundleData{
+		DealerIndex: b.DealerIndex,
+		Deals:       b.Deals,
+		Public:      public,
+		SessionID:   b.SessionID,
+		Signature:   b.Signature,
+	}, nil
+}
+
+func decodeDeal(g Suite, b *dealBundleData) (*DealBundle, error) {
+	public, err := unmarshalPoints(g, b.Public)
+	if err != nil {
+		return nil, err
+	}
+	return &DealBundle{
+		DealerIndex: b.DealerIndex,
+		Deals:       b.Deals,
+		Public:      public,
+		SessionID:   b.SessionID,
+		Signature:   b.Signature,
+	}, nil
+}
+
+func encodeDeals(bundles []*DealBundle) ([]*dealBundleData, error) {
+	res := make([]*dealBundleData, 0, len(bundles))
+	for _, b := range bundles {
+		if b == nil {
+			continue
+		}
+		data, err := encodeDeal(b)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, data)
+	}
+	return res, nil
+}
+
+func decodeDeals(g Suite, bundles []*dealBundleData) ([]*DealBundle, error) {
+	res := make([]*DealBundle, 0, len(bundles))
+	for _, b := range bundles {
+		if b == nil {
+			return nil, ErrInvalidSnapshot
+		}
+		bundle, err := decodeDeal(g, b)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, bundle)
+	}
+	return res, nil
+}
+
+func encodeJustif(b *JustificationBundle) (*justifBundleData, error) {
+	data := &justifBundleData{
+		DealerIndex:    b.DealerIndex,
+		Justifications: make([]justificationData, len(b.Justifications)),
+		SessionID:      b.SessionID,
+		Signature:      b.Signature,
+	}
+	for i, j := range b.Justifications {
+		buf, err := j.Share.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data.Justifications[i] = justificationData{ShareIndex: j.ShareIndex, Share: buf}
+	}
+	return data, nil
+}
+
+func decodeJustif(g Suite, b *justifBundleData) (*JustificationBundle, error) {
+	bundle := &JustificationBundle{
+		DealerIndex:    b.DealerIndex,
+		Justifications: make([]Justification, len(b.Justifications)),
+		SessionID:      b.SessionID,
+		Signature:      b.Signature,
+	}
+	for i, j := range b.Justifications {
+		v := g.Scalar()
+		if err := v.UnmarshalBinary(j.Share); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		bundle.Justifications[i] = Justification{ShareIndex: j.ShareIndex, Share: v}
+	}
+	return bundle, nil
+}
+
+func encodeJustifs(bundles []*JustificationBundle) ([]*justifBundleData, error) {
+	res := make([]*justifBundleData, 0, len(bundles))
+	for _, b := range bundles {
+		if b == nil {
+			continue
+		}
+		data, err := encodeJustif(b)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, data)
+	}
+	return res, nil
+}
+
+func decodeJustifs(g Suite, bundles []*justifBundleData) ([]*JustificationBundle, error) {
+	res := make([]*JustificationBundle, 0, len(bundles))
+	for _, b := range bundles {
+		if b == nil {
+			return nil, ErrInvalidSnapshot
+		}
+		bundle, err := decodeJustif(g, b)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, bundle)
+	}
+	return res, nil
+}
+
+func marshalPoints(ps []kyber.Point) ([][]byte, error) {
+	res := make([][]byte, len(ps))
+	for i, p := range ps {
+		buf, err := p.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		res[i] = buf
+	}
+	return res, nil
+}
+
+func unmarshalPoints(g kyber.Group, bufs [][]byte) ([]kyber.Point, error) {
+	res := make([]kyber.Point, len(bufs))
+	for i, buf := range bufs {
+		res[i] = g.Point()
+		if err := res[i].UnmarshalBinary(buf); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+	}
+	return res, nil
+}
+
+func marshalScalars(ss []kyber.Scalar) ([][]byte, error) {
+	res := make([][]byte, len(ss))
+	for i, s := range ss {
+		buf, err := s.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		res[i] = buf
+	}
+	return res, nil
+}
+
+func unmarshalScalars(g kyber.Group, bufs [][]byte) ([]kyber.Scalar, error) {
+	res := make([]kyber.Scalar, len(bufs))
+	for i, buf := range bufs {
+		res[i] = g.Scalar()
+		if err := res[i].UnmarshalBinary(buf); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+	}
+	return res, nil
+}
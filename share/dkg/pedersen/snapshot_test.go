@@ -0,0 +1,272 @@
+package dkg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	clock "github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+)
+
+// restoreAll snapshots and restores the generators of all the nodes, using
+// the configs they were created with.
+func restoreAll(t *testing.T, tns []*TestNode, confs []Config) {
+	for i, n := range tns {
+		snap, err := n.dkg.Snapshot()
+		require.NoError(t, err)
+		c := confs[i]
+		n.dkg, err = RestoreDistKeyGenerator(&c, snap)
+		require.NoError(t, err)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	n := 5
+	thr := 4
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	tns := GenerateTestNodes(suite, n)
+	list := NodesFromTest(tns)
+	conf := Config{
+		Suite:     suite,
+		NewNodes:  list,
+		Threshold: thr,
+		Auth:      schnorr.NewScheme(suite),
+	}
+	nonce := GetNonce()
+	confs := make([]Config, n)
+	for i, node := range tns {
+		confs[i] = conf
+		confs[i].Longterm = node.Private
+		confs[i].Nonce = nonce
+		c := confs[i]
+		dkg, err := NewDistKeyHandler(&c)
+		require.NoError(t, err)
+		node.dkg = dkg
+	}
+
+	var deals []*DealBundle
+	for _, node := range tns {
+		d, err := node.dkg.Deals()
+		require.NoError(t, err)
+		deals = append(deals, d)
+	}
+	restoreAll(t, tns, confs)
+	// restored dealers can't deal twice
+	_, err := tns[0].dkg.Deals()
+	require.Error(t, err)
+
+	// the first dealer gives an invalid share to the second node, so it has
+	// to justify it
+	deals[0].Deals[0].EncryptedShare = []byte("invalid")
+	var resps []*ResponseBundle
+	for _, node := range tns {
+		resp, err := node.dkg.ProcessDeals(deals)
+		require.NoError(t, err)
+		if resp != nil {
+			resps = append(resps, resp)
+		}
+	}
+	require.NotEmpty(t, resps)
+	restoreAll(t, tns, confs)
+	require.Len(t, tns[1].dkg.receivedDeals, n)
+	require.Equal(t, ResponsePhase, tns[1].dkg.state)
+
+	var justifs []*JustificationBundle
+	for _, node := range tns {
+		res, just, err := node.dkg.ProcessResponses(resps)
+		require.NoError(t, err)
+		require.Nil(t, res)
+		if just != nil {
+			justifs = append(justifs, just)
+		}
+	}
+	require.Len(t, justifs, 1)
+	restoreAll(t, tns, confs)
+	require.Equal(t, JustifPhase, tns[1].dkg.state)
+
+	var results []*Result
+	for _, node := range tns {
+		res, err := node.dkg.ProcessJustifications(justifs)
+		require.NoError(t, err)
+		results = append(results, res)
+	}
+	testResults(t, suite, thr, n, results)
+}
+
+func TestSnapshotMismatch(t *testing.T) {
+	n := 3
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	tns := GenerateTestNodes(suite, n)
+	conf := Config{
+		Suite:     suite,
+		NewNodes:  NodesFromTest(tns),
+		Threshold: 2,
+		Auth:      schnorr.NewScheme(suite),
+		Longterm:  tns[0].Private,
+		Nonce:     GetNonce(),
+	}
+	c := conf
+	dkg, err := NewDistKeyHandler(&c)
+	require.NoError(t, err)
+	_, err = dkg.Deals()
+	require.NoError(t, err)
+	snap, err := dkg.Snapshot()
+	require.NoError(t, err)
+
+	c = conf
+	_, err = RestoreDistKeyGenerator(&c, snap)
+	require.NoError(t, err)
+
+	c = conf
+	c.Nonce = GetNonce()
+	_, err = RestoreDistKeyGenerator(&c, snap)
+	require.ErrorIs(t, err, ErrSnapshotNonce)
+
+	c = conf
+	c.Threshold = 3
+	_, err = RestoreDistKeyGenerator(&c, snap)
+	require.ErrorIs(t, err, ErrSnapshotConfig)
+
+	c = conf
+	c.Longterm = tns[1].Private
+	_, err = RestoreDistKeyGenerator(&c, snap)
+	require.ErrorIs(t, err, ErrSnapshotConfig)
+
+	c = conf
+	_, err = RestoreDistKeyGenerator(&c, snap[:len(snap)/2])
+	require.ErrorIs(t, err, ErrInvalidSnapshot)
+
+	// indices which aren't the ones of the nodes of the config
+	for _, tamper := range []func(s *snapshot){
+		func(s *snapshot) { s.Statuses[42] = s.Statuses[0] },
+		func(s *snapshot) { delete(s.Statuses, 0) },
+		func(s *snapshot) { delete(s.Statuses[1], 2) },
+		func(s *snapshot) { s.Statuses[1][42] = Success },
+		func(s *snapshot) { s.Statuses[1][2] = 7 },
+		func(s *snapshot) { s.ValidShares[42] = s.ValidShares[0] },
+		func(s *snapshot) { s.Publics[42] = s.Publics[0] },
+		func(s *snapshot) { s.Evicted = []Index{42} },
+		func(s *snapshot) { s.EvictedHolders = []Index{42} },
+	} {
+		s, err := decodeSnapshot(snap)
+		require.NoError(t, err)
+		tamper(s)
+		buf, err := json.Marshal(s)
+		require.NoError(t, err)
+		c = conf
+		_, err = RestoreDistKeyGenerator(&c, buf)
+		require.ErrorIs(t, err, ErrInvalidSnapshot)
+	}
+}
+
+func TestProtoSnapshot(t *testing.T) {
+	n := 5
+	thr := n
+	period := 1 * time.Second
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	tns := GenerateTestNodes(suite, n)
+	list := NodesFromTest(tns)
+	network := NewTestNetwork(n)
+	dkgConf := Config{
+		Suite:     suite,
+		NewNodes:  list,
+		Threshold: thr,
+		Auth:      schnorr.NewScheme(suite),
+	}
+	SetupNodes(tns, &dkgConf)
+	SetupProto(tns, period, network)
+	for _, node := range tns {
+		go node.phaser.Start()
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// the first node crashes after the deals were exchanged
+	crashed := tns[0]
+	snap, err := crashed.proto.Snapshot()
+	require.NoError(t, err)
+	crashed.proto.Stop()
+
+	c := dkgConf
+	c.Longterm = crashed.Private
+	c.Nonce = crashed.dkg.c.Nonce
+	clk := clock.NewFakeClock()
+	crashed.clock = clk
+	crashed.phaser = NewTimePhaserFunc(func(Phase) {
+		clk.Sleep(period)
+	})
+	crashed.proto, err = NewProtocolFromSnapshot(&c, crashed.board, crashed.phaser, false, snap)
+	require.NoError(t, err)
+	go crashed.phaser.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	var resCh = make(chan OptionResult, n)
+	for _, node := range tns {
+		go func(n *TestNode) { resCh <- <-n.proto.WaitEnd() }(node)
+	}
+	for i := 0; i < 2; i++ {
+		moveTime(tns, period)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	var results []*Result
+	for optRes := range resCh {
+		require.NoError(t, optRes.Error)
+		results = append(results, optRes.Result)
+		if len(results) == n {
+			break
+		}
+	}
+	testResults(t, suite, thr, n, results)
+}
+
+func TestSnapshotEvidence(t *testing.T) {
+	tns, deals, conf := evidenceSetup(t)
+	c := *conf
+	c.Longterm = tns[0].Private
+
+	// the second dealer sends an invalid deal, processed by the generator
+	invalid := resign(t, tns[1], deals[1], func(d *DealBundle) { d.Public = d.Public[:2] })
+	p := newProtocol(tns[0].dkg, NewTestNetwork(len(tns)).BoardFor(0), NewTimePhaser(time.Hour), false)
+	_, err := p.dkg.ProcessDeals([]*DealBundle{invalid})
+	require.NoError(t, err)
+	// the third dealer sends two deals, caught by the set of the protocol
+	double := resign(t, tns[2], deals[2], func(d *DealBundle) {
+		d.Deals[0] = Deal{ShareIndex: d.Deals[0].ShareIndex, EncryptedShare: []byte("other")}
+	})
+	p.deals.Push(deals[2])
+	p.deals.Push(double)
+	require.Len(t, p.Evidence(), 2)
+
+	snap, err := p.Snapshot()
+	require.NoError(t, err)
+	restored, err := NewProtocolFromSnapshot(&c, NewTestNetwork(len(tns)).BoardFor(0),
+		NewTimePhaser(time.Hour), false, snap)
+	require.NoError(t, err)
+	defer restored.Stop()
+	evs := restored.Evidence()
+	require.Len(t, evs, 2)
+	for _, ev := range []*Evidence{
+		evidenceOf(evs, EvidenceInvalidDeal, 1),
+		evidenceOf(evs, EvidenceDoubleSigning, 2),
+	} {
+		require.NotNil(t, ev)
+		require.NoError(t, VerifyEvidence(conf, ev))
+	}
+
+	// evidence which doesn't verify is rejected
+	s, err := p.dkg.snapshot()
+	require.NoError(t, err)
+	s.Evidence[0], err = MarshalEvidence(conf.Suite, &Evidence{
+		Kind:    EvidenceInvalidDeal,
+		Accused: 0,
+		Deal:    deals[0],
+	})
+	require.NoError(t, err)
+	buf, err := json.Marshal(s)
+	require.NoError(t, err)
+	_, err = RestoreDistKeyGenerator(&c, buf)
+	require.ErrorIs(t, err, ErrInvalidSnapshot)
+}
@@ -0,0 +1,434 @@
+// Package pvss implements a publicly verifiable distributed key generation
+// built on the PVSS of package share/pvss, in the style of SCRAPE.
+//
+// Each dealer posts a single Transcript: the commitments of its sharing
+// polynomial, the shares encrypted to the nodes with their encryption
+// consistency proofs, a proof of knowledge of its secret bound to the session
+// and to the dealer, and its signature. Anyone can verify a transcript with
+// VerifyTranscript, which relies on pvss.VerifyEncShareBatch, so a whole run
+// can be audited from a public log such as a blockchain.
+//
+// Verified transcripts are aggregated by adding their commitments and their
+// encrypted shares: the result is the commitment of the sum of the
+// polynomials and the encryption of the shares of the sum. As with the
+// underlying PVSS, the shared secret is the point S*G, whose public key is
+// S*H for the second base H of the session, and node i decrypts the point
+// s_i*G of its aggregated share with DecryptShare. Any Threshold of the
+// verified decrypted shares recover the secret with RecoverSecret.
+package pvss
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/proof/dleq"
+	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/share/pvss"
+	"go.dedis.ch/kyber/v4/sign"
+)
+
+// Suite is the suite needed by the DKG.
+type Suite = pvss.Suite
+
+// domain separates the hashes of this package from other uses of the
+// longterm keys and of the nonce.
+const domain = "kyber pvss dkg v1"
+
+// ErrInvalidTranscript is returned when a transcript doesn't verify.
+var ErrInvalidTranscript = errors.New("pvss dkg: invalid transcript")
+
+// ErrInvalidAggregate is returned when an aggregate doesn't match its
+// transcripts.
+var ErrInvalidAggregate = errors.New("pvss dkg: invalid aggregate")
+
+// Config holds the public parameters of a DKG session.
+type Config struct {
+	Suite Suite
+	// Publics are the longterm public keys of the nodes. The i-th node gets
+	// the share of index i, and is dealer i.
+	Publics []kyber.Point
+	// Threshold is the number of shares needed to recover the secret. It's
+	// also the minimum number of transcripts to aggregate.
+	Threshold int
+	// Nonce identifies the session. It must be unique, see dkg.GetNonce.
+	Nonce []byte
+	// Auth is the scheme used by the dealers to sign their transcripts.
+	Auth sign.Scheme
+}
+
+// Base returns the second base H of the session, derived from the nonce so
+// that nobody knows its discrete logarithm with respect to the base point.
+func (c *Config) Base() kyber.Point {
+	return c.Suite.Point().Pick(c.Suite.XOF(append([]byte(domain+" base"), c.Nonce...)))
+}
+
+func (c *Config) check() error {
+	if c.Suite == nil || c.Auth == nil {
+		return errors.New("pvss dkg: config needs a suite and an auth scheme")
+	}
+	if len(c.Nonce) == 0 {
+		return errors.New("pvss dkg: config needs a nonce")
+	}
+	if c.Threshold < 1 || c.Threshold > len(c.Publics) {
+		return fmt.Errorf("pvss dkg: threshold %d out of [1, %d]", c.Threshold, len(c.Publics))
+	}
+	return nil
+}
+
+// Transcript is the public output of a dealer.
+type Transcript struct {
+	Dealer uint32
+	// Commits are the commitments to the coefficients of the sharing
+	// polynomial, with respect to the base H.
+	Commits []kyber.Point
+	// Shares are the encrypted shares, the share i being encrypted to the
+	// i-th node.
+	Shares []*pvss.PubVerShare
+	// R and Z prove the knowledge of the secret committed in Commits[0].
+	R kyber.Point
+	Z kyber.Scalar
+	// Signature of the dealer over the hash of the transcript.
+	Signature []byte
+}
+
+// NewTranscript deals a random secret as the node whose longterm secret key
+// is given, and returns the signed transcript.
+func NewTranscript(c *Config, longterm kyber.Scalar) (*Transcript, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	pub := c.Suite.Point().Mul(longterm, nil)
+	dealer := -1
+	for i, p := range c.Publics {
+		if p.Equal(pub) {
+			dealer = i
+			break
+		}
+	}
+	if dealer < 0 {
+		return nil, errors.New("pvss dkg: public key not found in config")
+	}
+	H := c.Base()
+	secret := c.Suite.Scalar().Pick(c.Suite.RandomStream())
+	shares, poly, err := pvss.EncShares(c.Suite, H, c.Publics, secret, c.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	_, commits := poly.Info()
+	tr := &Transcript{
+		Dealer:  uint32(dealer),
+		Commits: commits,
+		Shares:  shares,
+	}
+	k := c.Suite.Scalar().Pick(c.Suite.RandomStream())
+	tr.R = c.Suite.Point().Mul(k, H)
+	e, err := tr.challenge(c)
+	if err != nil {
+		return nil, err
+	}
+	tr.Z = c.Suite.Scalar().Add(k, e.Mul(e, secret))
+	h, err := tr.Hash(c)
+	if err != nil {
+		return nil, err
+	}
+	if tr.Signature, err = c.Auth.Sign(longterm, h); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+// challenge returns the challenge of the proof of knowledge, which binds it
+// to the session and the dealer so a transcript can't be replayed by another
+// dealer.
+func (t *Transcript) challenge(c *Config) (kyber.Scalar, error) {
+	h := sha256.New()
+	h.Write([]byte(domain + " pok"))
+	h.Write(c.Nonce)
+	_ = binary.Write(h, binary.BigEndian, t.Dealer)
+	for _, p := range []kyber.Point{t.Commits[0], t.R} {
+		if _, err := p.MarshalTo(h); err != nil {
+			return nil, err
+		}
+	}
+	return c.Suite.Scalar().SetBytes(h.Sum(nil)), nil
+}
+
+// Hash returns the hash of the transcript signed by the dealer.
+func (t *Transcript) Hash(c *Config) ([]byte, error) {
+	h := sha256.New()
+	h.Write([]byte(domain))
+	h.Write(c.Nonce)
+	_ = binary.Write(h, binary.BigEndian, t.Dealer)
+	write := func(ms ...kyber.Marshaling) error {
+		for _, m := range ms {
+			if m == nil {
+				return fmt.Errorf("%w: nil element", ErrInvalidTranscript)
+			}
+			if _, err := m.MarshalTo(h); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_ = binary.Write(h, binary.BigEndian, uint32(len(t.Commits)))
+	for _, p := range t.Commits {
+		if err := write(p); err != nil {
+			return nil, err
+		}
+	}
+	_ = binary.Write(h, binary.BigEndian, uint32(len(t.Shares)))
+	for _, s := range t.Shares {
+		if s == nil {
+			return nil, fmt.Errorf("%w: nil share", ErrInvalidTranscript)
+		}
+		_ = binary.Write(h, binary.BigEndian, s.S.I)
+		if err := write(s.S.V, s.P.C, s.P.R, s.P.VG, s.P.VH); err != nil {
+			return nil, err
+		}
+	}
+	if err := write(t.R, t.Z); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// VerifyTranscript checks the signature of the dealer, the proof of
+// knowledge of its secret and that all the encrypted shares are consistent
+// with the commitments.
+func VerifyTranscript(c *Config, t *Transcript) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	n := len(c.Publics)
+	if int(t.Dealer) >= n {
+		return fmt.Errorf("%w: unknown dealer %d", ErrInvalidTranscript, t.Dealer)
+	}
+	if len(t.Commits) != c.Threshold || len(t.Shares) != n {
+		return fmt.Errorf("%w: wrong number of commitments or shares", ErrInvalidTranscript)
+	}
+	points := append([]kyber.Point{t.R}, t.Commits...)
+	scalars := []kyber.Scalar{t.Z}
+	for _, s := range t.Shares {
+		if s == nil {
+			return fmt.Errorf("%w: nil share", ErrInvalidTranscript)
+		}
+		points = append(points, s.S.V, s.P.VG, s.P.VH)
+		scalars = append(scalars, s.P.C, s.P.R)
+	}
+	if !ofSuite(c.Suite, points, scalars...) {
+		return fmt.Errorf("%w: missing element or element of another suite", ErrInvalidTranscript)
+	}
+	h, err := t.Hash(c)
+	if err != nil {
+		return err
+	}
+	if err := c.Auth.Verify(c.Publics[t.Dealer], h, t.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTranscript, err)
+	}
+
+	H := c.Base()
+	e, err := t.challenge(c)
+	if err != nil {
+		return err
+	}
+	zH := c.Suite.Point().Mul(t.Z, H)
+	if !zH.Equal(c.Suite.Point().Add(t.R, c.Suite.Point().Mul(e, t.Commits[0]))) {
+		return fmt.Errorf("%w: invalid proof of knowledge", ErrInvalidTranscript)
+	}
+
+	poly := share.NewPubPoly(c.Suite, H, t.Commits)
+	sH := make([]kyber.Point, n)
+	for i, s := range t.Shares {
+		if s.S.I != uint32(i) {
+			return fmt.Errorf("%w: share %d has index %d", ErrInvalidTranscript, i, s.S.I)
+		}
+		sH[i] = poly.Eval(uint32(i)).V
+	}
+	_, valid, err := pvss.VerifyEncShareBatch(c.Suite, H, c.Publics, sH, poly, t.Shares)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTranscript, err)
+	}
+	if len(valid) != n {
+		return fmt.Errorf("%w: %d invalid encrypted shares", ErrInvalidTranscript, n-len(valid))
+	}
+	return nil
+}
+
+// ofSuite checks that the elements are elements of the suite s: the
+// arithmetic of a suite panics on elements of another one, such as elements
+// decoded with another suite.
+func ofSuite(s Suite, points []kyber.Point, scalars ...kyber.Scalar) bool {
+	pt, st := reflect.TypeOf(s.Point()), reflect.TypeOf(s.Scalar())
+	for _, p := range points {
+		if reflect.TypeOf(p) != pt {
+			return false
+		}
+	}
+	for _, x := range scalars {
+		if reflect.TypeOf(x) != st {
+			return false
+		}
+	}
+	return true
+}
+
+// Aggregate is the combination of the transcripts of several dealers.
+type Aggregate struct {
+	// Dealers whose transcripts were aggregated, in increasing order.
+	Dealers []uint32
+	// Commits are the commitments of the sum of the sharing polynomials.
+	Commits []kyber.Point
+	// EncShares are the encrypted aggregated shares, the share i being
+	// encrypted to the i-th node.
+	EncShares []kyber.Point
+}
+
+// PublicKey returns S*H, where S*G is the shared secret.
+func (a *Aggregate) PublicKey() kyber.Point {
+	return a.Commits[0]
+}
+
+// AggregateTranscripts verifies the transcripts and aggregates them. It
+// needs at least Threshold transcripts from different dealers.
+func AggregateTranscripts(c *Config, ts []*Transcript) (*Aggregate, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	for _, t := range ts {
+		if err := VerifyTranscript(c, t); err != nil {
+			return nil, fmt.Errorf("dealer %d: %w", t.Dealer, err)
+		}
+	}
+	return aggregate(c, ts)
+}
+
+// aggregate adds up verified transcripts.
+func aggregate(c *Config, ts []*Transcript) (*Aggregate, error) {
+	if len(ts) < c.Threshold {
+		return nil, fmt.Errorf("pvss dkg: %d transcripts, need %d", len(ts), c.Threshold)
+	}
+	byDealer := make([]*Transcript, len(c.Publics))
+	for _, t := range ts {
+		if byDealer[t.Dealer] != nil {
+			return nil, fmt.Errorf("pvss dkg: several transcripts from dealer %d", t.Dealer)
+		}
+		byDealer[t.Dealer] = t
+	}
+	a := &Aggregate{
+		Commits:   make([]kyber.Point, c.Threshold),
+		EncShares: make([]kyber.Point, len(c.Publics)),
+	}
+	for i := range a.Commits {
+		a.Commits[i] = c.Suite.Point().Null()
+	}
+	for i := range a.EncShares {
+		a.EncShares[i] = c.Suite.Point().Null()
+	}
+	for dealer, t := range byDealer {
+		if t == nil {
+			continue
+		}
+		a.Dealers = append(a.Dealers, uint32(dealer))
+		for i, p := range t.Commits {
+			a.Commits[i].Add(a.Commits[i], p)
+		}
+		for i, s := range t.Shares {
+			a.EncShares[i].Add(a.EncShares[i], s.S.V)
+		}
+	}
+	return a, nil
+}
+
+// VerifyAggregate checks that the aggregate is the combination of the given
+// transcripts, which must be valid.
+func VerifyAggregate(c *Config, a *Aggregate, ts []*Transcript) error {
+	exp, err := AggregateTranscripts(c, ts)
+	if err != nil {
+		return err
+	}
+	if !ofSuite(c.Suite, append(append([]kyber.Point{}, a.Commits...), a.EncShares...)) {
+		return ErrInvalidAggregate
+	}
+	equal := func(p, q []kyber.Point) bool {
+		if len(p) != len(q) {
+			return false
+		}
+		for i := range p {
+			if p[i] == nil || !p[i].Equal(q[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if len(a.Dealers) != len(exp.Dealers) {
+		return ErrInvalidAggregate
+	}
+	for i := range a.Dealers {
+		if a.Dealers[i] != exp.Dealers[i] {
+			return ErrInvalidAggregate
+		}
+	}
+	if !equal(a.Commits, exp.Commits) || !equal(a.EncShares, exp.EncShares) {
+		return ErrInvalidAggregate
+	}
+	return nil
+}
+
+// DecryptShare decrypts the aggregated share of the node whose longterm
+// secret key is given. The decrypted share comes with a proof that it's the
+// decryption of the encrypted share.
+func DecryptShare(c *Config, a *Aggregate, longterm kyber.Scalar) (*pvss.PubVerShare, error) {
+	pub := c.Suite.Point().Mul(longterm, nil)
+	for i, p := range c.Publics {
+		if !p.Equal(pub) {
+			continue
+		}
+		if i >= len(a.EncShares) {
+			return nil, ErrInvalidAggregate
+		}
+		// decryption: x^{-1} * (x s_i G)
+		v := c.Suite.Point().Mul(c.Suite.Scalar().Inv(longterm), a.EncShares[i])
+		proof, _, _, err := dleq.NewDLEQProof(c.Suite, c.Suite.Point().Base(), v, longterm)
+		if err != nil {
+			return nil, err
+		}
+		return &pvss.PubVerShare{S: share.PubShare{I: uint32(i), V: v}, P: *proof}, nil
+	}
+	return nil, errors.New("pvss dkg: public key not found in config")
+}
+
+// encShare returns the encrypted share i of the aggregate.
+func (a *Aggregate) encShare(i uint32) *pvss.PubVerShare {
+	return &pvss.PubVerShare{S: share.PubShare{I: i, V: a.EncShares[i]}}
+}
+
+// VerifyDecShare checks that the decrypted share is the decryption of the
+// matching encrypted share of the aggregate.
+func VerifyDecShare(c *Config, a *Aggregate, d *pvss.PubVerShare) error {
+	i := d.S.I
+	if int(i) >= len(c.Publics) || int(i) >= len(a.EncShares) {
+		return fmt.Errorf("pvss dkg: unknown share %d", i)
+	}
+	return pvss.VerifyDecShare(c.Suite, c.Suite.Point().Base(), c.Publics[i], a.encShare(i), d)
+}
+
+// RecoverSecret verifies the decrypted shares and recovers the shared secret
+// S*G from Threshold valid ones.
+func RecoverSecret(c *Config, a *Aggregate, ds []*pvss.PubVerShare) (kyber.Point, error) {
+	var X []kyber.Point
+	var enc []*pvss.PubVerShare
+	var dec []*pvss.PubVerShare
+	for _, d := range ds {
+		if d == nil || int(d.S.I) >= len(c.Publics) || int(d.S.I) >= len(a.EncShares) {
+			continue
+		}
+		X = append(X, c.Publics[d.S.I])
+		enc = append(enc, a.encShare(d.S.I))
+		dec = append(dec, d)
+	}
+	return pvss.RecoverSecret(c.Suite, c.Suite.Point().Base(), X, enc, dec, c.Threshold, len(c.Publics))
+}
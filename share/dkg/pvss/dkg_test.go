@@ -0,0 +1,203 @@
+package pvss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/group/p256"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/share/pvss"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+var suite = edwards25519.NewBlakeSHA256Ed25519()
+
+func setup(n, t int) (*Config, []kyber.Scalar) {
+	keys := make([]kyber.Scalar, n)
+	c := &Config{
+		Suite:     suite,
+		Threshold: t,
+		Nonce:     dkg.GetNonce(),
+		Auth:      schnorr.NewScheme(suite),
+	}
+	for i := range keys {
+		keys[i] = suite.Scalar().Pick(random.New())
+		c.Publics = append(c.Publics, suite.Point().Mul(keys[i], nil))
+	}
+	return c, keys
+}
+
+func transcripts(t *testing.T, c *Config, keys []kyber.Scalar) []*Transcript {
+	ts := make([]*Transcript, len(keys))
+	for i, k := range keys {
+		tr, err := NewTranscript(c, k)
+		require.NoError(t, err)
+		require.Equal(t, uint32(i), tr.Dealer)
+		ts[i] = tr
+	}
+	return ts
+}
+
+// publish encodes the transcripts and decodes them as a third party would.
+func publish(t *testing.T, ts []*Transcript) []*Transcript {
+	var res []*Transcript
+	for _, tr := range ts {
+		buf, err := MarshalTranscript(suite, tr)
+		require.NoError(t, err)
+		dec, err := UnmarshalTranscript(suite, buf)
+		require.NoError(t, err)
+		buf2, err := MarshalTranscript(suite, dec)
+		require.NoError(t, err)
+		require.Equal(t, buf, buf2)
+		res = append(res, dec)
+	}
+	return res
+}
+
+func TestPVSSDKG(t *testing.T) {
+	n, threshold := 7, 4
+	c, keys := setup(n, threshold)
+	ts := publish(t, transcripts(t, c, keys))
+	for _, tr := range ts {
+		require.NoError(t, VerifyTranscript(c, tr))
+	}
+
+	// the last dealer didn't post in time
+	agg, err := AggregateTranscripts(c, ts[:n-1])
+	require.NoError(t, err)
+	require.Equal(t, []uint32{0, 1, 2, 3, 4, 5}, agg.Dealers)
+	require.NoError(t, VerifyAggregate(c, agg, ts[:n-1]))
+	require.ErrorIs(t, VerifyAggregate(c, agg, ts), ErrInvalidAggregate)
+
+	buf, err := MarshalAggregate(suite, agg)
+	require.NoError(t, err)
+	agg2, err := UnmarshalAggregate(suite, buf)
+	require.NoError(t, err)
+	require.NoError(t, VerifyAggregate(c, agg2, ts[:n-1]))
+	require.True(t, agg.PublicKey().Equal(agg2.PublicKey()))
+
+	dec := make([]*pvss.PubVerShare, n)
+	for i, k := range keys {
+		dec[i], err = DecryptShare(c, agg2, k)
+		require.NoError(t, err)
+		require.NoError(t, VerifyDecShare(c, agg2, dec[i]))
+	}
+	secret, err := RecoverSecret(c, agg, dec[:threshold])
+	require.NoError(t, err)
+	other, err := RecoverSecret(c, agg, dec[n-threshold:])
+	require.NoError(t, err)
+	require.True(t, secret.Equal(other))
+
+	// invalid decrypted shares are ignored
+	bad := *dec[0]
+	bad.S.V = suite.Point().Pick(random.New())
+	require.Error(t, VerifyDecShare(c, agg, &bad))
+	_, err = RecoverSecret(c, agg, append([]*pvss.PubVerShare{&bad}, dec[1:threshold]...))
+	require.ErrorIs(t, err, pvss.ErrTooFewShares)
+	other, err = RecoverSecret(c, agg, append([]*pvss.PubVerShare{&bad}, dec[1:threshold+1]...))
+	require.NoError(t, err)
+	require.True(t, secret.Equal(other))
+
+	// the secret depends on the aggregated transcripts
+	agg3, err := AggregateTranscripts(c, ts[1:])
+	require.NoError(t, err)
+	for i, k := range keys {
+		dec[i], err = DecryptShare(c, agg3, k)
+		require.NoError(t, err)
+	}
+	other, err = RecoverSecret(c, agg3, dec)
+	require.NoError(t, err)
+	require.False(t, secret.Equal(other))
+}
+
+func TestPVSSDKGInvalid(t *testing.T) {
+	c, keys := setup(4, 3)
+	ts := transcripts(t, c, keys)
+
+	_, err := AggregateTranscripts(c, ts[:2])
+	require.Error(t, err)
+	_, err = AggregateTranscripts(c, []*Transcript{ts[0], ts[1], ts[0]})
+	require.Error(t, err)
+
+	// tampered share
+	tr := *ts[0]
+	tr.Shares = append([]*pvss.PubVerShare{}, ts[0].Shares...)
+	s := *tr.Shares[1]
+	s.S.V = suite.Point().Pick(random.New())
+	tr.Shares[1] = &s
+	require.ErrorIs(t, VerifyTranscript(c, &tr), ErrInvalidTranscript)
+	_, err = AggregateTranscripts(c, []*Transcript{ts[1], ts[2], &tr})
+	require.ErrorIs(t, err, ErrInvalidTranscript)
+
+	// a transcript is bound to its session
+	other := *c
+	other.Nonce = dkg.GetNonce()
+	require.ErrorIs(t, VerifyTranscript(&other, ts[0]), ErrInvalidTranscript)
+
+	// another dealer can't reuse a transcript, even signing it itself
+	tr = *ts[0]
+	tr.Dealer = 1
+	h, err := tr.Hash(c)
+	require.NoError(t, err)
+	tr.Signature, err = c.Auth.Sign(keys[1], h)
+	require.NoError(t, err)
+	require.ErrorIs(t, VerifyTranscript(c, &tr), ErrInvalidTranscript)
+
+	// wrong number of shares
+	tr = *ts[0]
+	tr.Shares = tr.Shares[:3]
+	require.ErrorIs(t, VerifyTranscript(c, &tr), ErrInvalidTranscript)
+
+	_, err = NewTranscript(c, suite.Scalar().Pick(random.New()))
+	require.Error(t, err)
+}
+
+func TestPVSSDKGEncoding(t *testing.T) {
+	c, keys := setup(4, 3)
+	tr, err := NewTranscript(c, keys[0])
+	require.NoError(t, err)
+	buf, err := MarshalTranscript(suite, tr)
+	require.NoError(t, err)
+
+	for i := 0; i < len(buf); i++ {
+		_, err = UnmarshalTranscript(suite, buf[:i])
+		require.ErrorIs(t, err, ErrInvalidEncoding, "length %d", i)
+	}
+	_, err = UnmarshalTranscript(suite, append(buf, 0))
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+	_, err = UnmarshalAggregate(suite, buf)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+
+	_, err = MarshalTranscript(suite, &Transcript{Shares: []*pvss.PubVerShare{nil}})
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+}
+
+func TestPVSSDKGOtherSuite(t *testing.T) {
+	c, keys := setup(4, 3)
+	other := p256.NewBlakeSHA256P256()
+	oc := &Config{Suite: other, Threshold: c.Threshold, Nonce: c.Nonce, Auth: schnorr.NewScheme(other)}
+	okeys := make([]kyber.Scalar, len(keys))
+	for i := range okeys {
+		okeys[i] = other.Scalar().Pick(random.New())
+		oc.Publics = append(oc.Publics, other.Point().Mul(okeys[i], nil))
+	}
+	tr, err := NewTranscript(oc, okeys[0])
+	require.NoError(t, err)
+
+	// a transcript of another suite is rejected when decoding, and when
+	// verifying it without a panic
+	buf, err := MarshalTranscript(other, tr)
+	require.NoError(t, err)
+	_, err = UnmarshalTranscript(suite, buf)
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+	require.ErrorIs(t, VerifyTranscript(c, tr), ErrInvalidTranscript)
+
+	ts := transcripts(t, c, keys)
+	agg, err := AggregateTranscripts(c, ts)
+	require.NoError(t, err)
+	agg.Commits[0] = other.Point().Base()
+	require.ErrorIs(t, VerifyAggregate(c, agg, ts), ErrInvalidAggregate)
+}
@@ -0,0 +1,113 @@
+package pvss
+
+import (
+	"errors"
+
+	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/share/dkg/internal/wire"
+	"go.dedis.ch/kyber/v4/share/pvss"
+)
+
+// EncodingVersion is the version of the binary encoding of the transcripts
+// and aggregates.
+const EncodingVersion = 1
+
+// MaxEntries bounds the number of commitments, shares and dealers accepted
+// when decoding.
+const MaxEntries = 1 << 16
+
+// ErrInvalidEncoding is returned when decoding malformed data.
+var ErrInvalidEncoding = errors.New("pvss dkg: invalid encoding")
+
+// type tags of the encoded objects
+const (
+	tagTranscript byte = iota + 1
+	tagAggregate
+)
+
+var format = &wire.Format{Version: EncodingVersion, MaxEntries: MaxEntries, Err: ErrInvalidEncoding}
+
+// The encodings start with the version, the type of the object and the name
+// of the suite. All integers are big-endian. The suite is given to the
+// functions encoding and decoding the objects, and decoding fails if the
+// encoding names another suite. Transcripts and aggregates don't implement
+// encoding.BinaryMarshaler since decoding needs the suite, which they don't
+// hold.
+
+// MarshalTranscript returns the encoding of the transcript with the suite s.
+func MarshalTranscript(s Suite, t *Transcript) ([]byte, error) {
+	e := format.NewEncoder(tagTranscript, s.String())
+	e.Uint32(t.Dealer)
+	e.Points(t.Commits)
+	e.Count(len(t.Shares))
+	for _, sh := range t.Shares {
+		if sh == nil {
+			e.Fail("nil share")
+			break
+		}
+		e.Uint32(sh.S.I)
+		e.Marshal(sh.S.V, sh.P.C, sh.P.R, sh.P.VG, sh.P.VH)
+	}
+	e.Marshal(t.R, t.Z)
+	e.Bytes16(t.Signature)
+	return e.Finish()
+}
+
+// UnmarshalTranscript decodes a transcript encoded with MarshalTranscript and
+// the suite s.
+func UnmarshalTranscript(s Suite, buf []byte) (*Transcript, error) {
+	r, err := format.NewDecoder(buf, tagTranscript, s.String())
+	if err != nil {
+		return nil, err
+	}
+	t := new(Transcript)
+	t.Dealer = r.Uint32()
+	t.Commits = r.Points(s)
+	pl, sl := s.PointLen(), s.ScalarLen()
+	n := r.Count(4 + 3*pl + 2*sl)
+	for i := 0; i < n; i++ {
+		ps := &pvss.PubVerShare{S: share.PubShare{I: r.Uint32(), V: s.Point()}}
+		ps.P.C, ps.P.R, ps.P.VG, ps.P.VH = s.Scalar(), s.Scalar(), s.Point(), s.Point()
+		r.Unmarshal(ps.S.V, ps.P.C, ps.P.R, ps.P.VG, ps.P.VH)
+		t.Shares = append(t.Shares, ps)
+	}
+	t.R, t.Z = s.Point(), s.Scalar()
+	r.Unmarshal(t.R, t.Z)
+	t.Signature = r.Bytes16()
+	if err := r.Finish(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// MarshalAggregate returns the encoding of the aggregate with the suite s.
+func MarshalAggregate(s Suite, a *Aggregate) ([]byte, error) {
+	e := format.NewEncoder(tagAggregate, s.String())
+	e.Count(len(a.Dealers))
+	for _, d := range a.Dealers {
+		e.Uint32(d)
+	}
+	e.Points(a.Commits)
+	e.Points(a.EncShares)
+	return e.Finish()
+}
+
+// UnmarshalAggregate decodes an aggregate encoded with MarshalAggregate and
+// the suite s.
+func UnmarshalAggregate(s Suite, buf []byte) (*Aggregate, error) {
+	r, err := format.NewDecoder(buf, tagAggregate, s.String())
+	if err != nil {
+		return nil, err
+	}
+	a := new(Aggregate)
+	n := r.Count(4)
+	for i := 0; i < n; i++ {
+		a.Dealers = append(a.Dealers, r.Uint32())
+	}
+	a.Commits = r.Points(s)
+	a.EncShares = r.Points(s)
+	if err := r.Finish(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
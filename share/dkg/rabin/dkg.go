@@ -33,6 +33,15 @@
 //     must be broadcasted to all the QUAL participant.
 //  7. At this point, every QUAL participant can issue the distributed key by
 //     calling `DistKeyShare()`.
+//
+// A distributed key can also be reshared to a new set of participants with a
+// new threshold, see NewReshareDistKeyGenerator. The old participants deal
+// their share instead of a random secret, and the new participants
+// interpolate the deals of the QUAL dealers to get their new share. The steps
+// are the same, but the old participants only deal and the new ones only
+// receive: the Index of Deal, Response, SecretCommits and the DealerIndex of
+// ComplaintCommits and ReconstructCommits refer to the list of old
+// participants, the other indices to the list of new participants.
 package dkg
 
 import (
@@ -160,6 +169,20 @@ type DistKeyGenerator struct {
 
 	t int
 
+	// dealers is the list of participants dealing a secret, the old
+	// participants for a resharing and the participants otherwise.
+	dealers     []kyber.Point
+	dealerIndex uint32
+	isDealer    bool
+	isReceiver  bool
+	// oldT is the number of QUAL dealers required, t if not resharing
+	oldT int
+	// oldPub is the public polynomial of the reshared key, nil if not
+	// resharing
+	oldPub *share.PubPoly
+	// dealers whose secret commitments don't commit to their old share
+	invalid map[uint32]bool
+
 	dealer    *vss.Dealer
 	verifiers map[uint32]*vss.Verifier
 
@@ -218,9 +241,102 @@ func NewDistKeyGenerator(
 		pub:                pub,
 		participants:       participants,
 		index:              index,
+		dealers:            participants,
+		dealerIndex:        index,
+		isDealer:           true,
+		isReceiver:         true,
+		oldT:               t,
+		invalid:            make(map[uint32]bool),
 	}, nil
 }
 
+// ReshareConfig holds the parameters to reshare a distributed key to a new
+// list of participants.
+type ReshareConfig struct {
+	Suite Suite
+	// Longterm is the longterm secret key of this participant.
+	Longterm kyber.Scalar
+	// Share is the current share of the distributed key, it must be set for
+	// the participants in OldNodes.
+	Share *DistKeyShare
+	// PublicCoeffs is the public polynomial of the distributed key. It is
+	// only needed by the new participants not having a Share.
+	PublicCoeffs []kyber.Point
+	// OldNodes is the list of participants holding a share of the key.
+	OldNodes []kyber.Point
+	// NewNodes is the list of participants receiving a share of the key.
+	NewNodes []kyber.Point
+	// Threshold is the threshold of the new shares. If zero, it is set to
+	// vss.MinimumT(len(NewNodes)).
+	Threshold int
+}
+
+// NewReshareDistKeyGenerator returns a DistKeyGenerator resharing the
+// distributed key described by the config. A participant in OldNodes deals
+// its share and must set Share, a participant in NewNodes receives a new
+// share, and a participant can be in both lists. It returns an error if the
+// longterm key is in none of the lists.
+func NewReshareDistKeyGenerator(c *ReshareConfig) (*DistKeyGenerator, error) {
+	suite := c.Suite
+	pub := suite.Point().Mul(c.Longterm, nil)
+	dealerIndex, isDealer := findIndex(c.OldNodes, pub)
+	index, isReceiver := findIndex(c.NewNodes, pub)
+	if !isDealer && !isReceiver {
+		return nil, errors.New("dkg: own public key not found in list of participants")
+	}
+	commits := c.PublicCoeffs
+	if c.Share != nil {
+		commits = c.Share.Commits
+	}
+	if len(commits) == 0 {
+		return nil, errors.New("dkg: resharing needs the public coefficients of the key")
+	}
+	if len(commits) > len(c.OldNodes) {
+		return nil, errors.New("dkg: threshold of the key larger than the list of old participants")
+	}
+	t := c.Threshold
+	if t == 0 {
+		t = vss.MinimumT(len(c.NewNodes))
+	}
+	d := &DistKeyGenerator{
+		verifiers:          make(map[uint32]*vss.Verifier),
+		commitments:        make(map[uint32]*share.PubPoly),
+		pendingReconstruct: make(map[uint32][]*ReconstructCommits),
+		reconstructed:      make(map[uint32]bool),
+		t:                  t,
+		suite:              suite,
+		long:               c.Longterm,
+		pub:                pub,
+		participants:       c.NewNodes,
+		index:              index,
+		dealers:            c.OldNodes,
+		dealerIndex:        dealerIndex,
+		isDealer:           isDealer,
+		isReceiver:         isReceiver,
+		oldT:               len(commits),
+		oldPub:             share.NewPubPoly(suite, suite.Point().Base(), commits),
+		invalid:            make(map[uint32]bool),
+	}
+	if !isDealer {
+		return d, nil
+	}
+	if c.Share == nil || c.Share.Share == nil {
+		return nil, errors.New("dkg: old participant without a share")
+	}
+	if c.Share.Share.I != dealerIndex {
+		return nil, errors.New("dkg: share index not matching the list of old participants")
+	}
+	if !d.oldPub.Check(c.Share.Share) {
+		return nil, errors.New("dkg: share not matching the public coefficients")
+	}
+	var err error
+	d.dealer, err = vss.NewDealer(suite, c.Longterm, c.Share.Share.V, c.NewNodes, t)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
 // Deals returns all the deals that must be broadcasted to all
 // participants. The deal corresponding to this DKG is already added
 // to this DKG and is ommitted from the returned map. To know
@@ -231,8 +347,12 @@ func NewDistKeyGenerator(
 //	   sendTo(participants[i],dd)
 //	}
 //
-// This method panics if it can't process its own deal.
+// This method panics if it can't process its own deal. It returns an error for
+// a new participant of a resharing, which has nothing to deal.
 func (d *DistKeyGenerator) Deals() (map[int]*Deal, error) {
+	if !d.isDealer {
+		return nil, errors.New("dkg: not a dealer")
+	}
 	deals, err := d.dealer.EncryptedDeals()
 	if err != nil {
 		return nil, err
@@ -240,11 +360,11 @@ func (d *DistKeyGenerator) Deals() (map[int]*Deal, error) {
 	dd := make(map[int]*Deal)
 	for i := range d.participants {
 		distd := &Deal{
-			Index: d.index,
+			Index: d.dealerIndex,
 			Deal:  deals[i],
 		}
-		if i == int(d.index) {
-			if _, ok := d.verifiers[d.index]; ok {
+		if d.isReceiver && i == int(d.index) {
+			if _, ok := d.verifiers[d.dealerIndex]; ok {
 				// already processed our own deal
 				continue
 			}
@@ -271,8 +391,11 @@ func (d *DistKeyGenerator) Deals() (map[int]*Deal, error) {
 // error in case the deal has already been stored, or if the deal is incorrect
 // (see `vss.Verifier.ProcessEncryptedDeal()`).
 func (d *DistKeyGenerator) ProcessDeal(dd *Deal) (*Response, error) {
+	if !d.isReceiver {
+		return nil, errors.New("dkg: not receiving deals")
+	}
 	// public key of the dealer
-	pub, ok := findPub(d.participants, dd.Index)
+	pub, ok := findPub(d.dealers, dd.Index)
 	if !ok {
 		return nil, errors.New("dkg: dist deal out of bounds index")
 	}
@@ -294,7 +417,9 @@ func (d *DistKeyGenerator) ProcessDeal(dd *Deal) (*Response, error) {
 
 	// Set StatusApproval for the verifier that represents the participant
 	// that distibuted the Deal
-	ver.UnsafeSetResponseDKG(dd.Index, true)
+	if i, ok := findIndex(d.participants, pub); ok {
+		ver.UnsafeSetResponseDKG(i, true)
+	}
 
 	d.verifiers[dd.Index] = ver
 	return &Response{
@@ -310,15 +435,17 @@ func (d *DistKeyGenerator) ProcessDeal(dd *Deal) (*Response, error) {
 // the response, and returns a justification.
 func (d *DistKeyGenerator) ProcessResponse(resp *Response) (*Justification, error) {
 	v, ok := d.verifiers[resp.Index]
-	if !ok {
+	if !ok && d.isReceiver {
 		return nil, errors.New("dkg: complaint received but no deal for it")
 	}
 
-	if err := v.ProcessResponse(resp.Response); err != nil {
-		return nil, err
+	if ok {
+		if err := v.ProcessResponse(resp.Response); err != nil {
+			return nil, err
+		}
 	}
 
-	if resp.Index != uint32(d.index) {
+	if !d.isDealer || resp.Index != d.dealerIndex {
 		//nolint:nilnil // Expected behavior
 		return nil, nil
 	}
@@ -332,19 +459,25 @@ func (d *DistKeyGenerator) ProcessResponse(resp *Response) (*Justification, erro
 		return nil, nil
 	}
 	// a justification for our own deal, are we cheating !?
-	if err := v.ProcessJustification(j); err != nil {
-		return nil, err
+	if v != nil {
+		if err := v.ProcessJustification(j); err != nil {
+			return nil, err
+		}
 	}
 
 	return &Justification{
-		Index:         d.index,
+		Index:         d.dealerIndex,
 		Justification: j,
 	}, nil
 }
 
 // ProcessJustification takes a justification and validates it. It returns an
-// error in case the justification is wrong.
+// error in case the justification is wrong. An old participant of a resharing
+// which doesn't receive deals ignores the justifications.
 func (d *DistKeyGenerator) ProcessJustification(j *Justification) error {
+	if !d.isReceiver {
+		return nil
+	}
 	v, ok := d.verifiers[j.Index]
 	if !ok {
 		return errors.New("dkg: Justification received but no deal for it")
@@ -358,13 +491,17 @@ func (d *DistKeyGenerator) SetTimeout() {
 	for _, v := range d.verifiers {
 		v.SetTimeout()
 	}
+	if d.dealer != nil && !d.isReceiver {
+		d.dealer.SetTimeout()
+	}
 }
 
 // Certified returns true if at least t deals are certified (see
-// vss.Verifier.DealCertified()). If the distribution is certified, the protocol
-// can continue using d.SecretCommits().
+// vss.Verifier.DealCertified()), or the threshold of the reshared key for a
+// resharing. If the distribution is certified, the protocol can continue using
+// d.SecretCommits().
 func (d *DistKeyGenerator) Certified() bool {
-	return len(d.QUAL()) >= d.t
+	return len(d.QUAL()) >= d.oldT
 }
 
 // QUAL returns the index in the list of participants that forms the QUALIFIED
@@ -410,12 +547,15 @@ func (d *DistKeyGenerator) qualIter(fn func(idx uint32, v *vss.Verifier) bool) {
 // This dkg must have its deal certified, otherwise it returns an error. The
 // SecretCommits returned is already added to this dkg's list of SecretCommits.
 func (d *DistKeyGenerator) SecretCommits() (*SecretCommits, error) {
+	if !d.isDealer {
+		return nil, errors.New("dkg: not a dealer")
+	}
 	if !d.dealer.DealCertified() {
 		return nil, errors.New("dkg: can't give SecretCommits if deal not certified")
 	}
 	sc := &SecretCommits{
 		Commitments: d.dealer.Commits(),
-		Index:       uint32(d.index),
+		Index:       d.dealerIndex,
 		SessionID:   d.dealer.SessionID(),
 	}
 	msg := sc.Hash(d.suite)
@@ -425,7 +565,7 @@ func (d *DistKeyGenerator) SecretCommits() (*SecretCommits, error) {
 	}
 	sc.Signature = sig
 	// adding our own commitments
-	d.commitments[uint32(d.index)] = share.NewPubPoly(d.suite, d.suite.Point().Base(), sc.Commitments)
+	d.commitments[d.dealerIndex] = share.NewPubPoly(d.suite, d.suite.Point().Base(), sc.Commitments)
 	return sc, err
 }
 
@@ -434,8 +574,14 @@ func (d *DistKeyGenerator) SecretCommits() (*SecretCommits, error) {
 // invalid. In case the SecretCommits are valid, but this dkg can't verify its
 // share, it returns a ComplaintCommits that must be broadcasted to every other
 // participant. It returns (nil,nil) otherwise.
+// For a resharing, it returns an error if the commitments don't commit to the
+// share of the dealer, and the dealer is left out of the new key. An old
+// participant which doesn't receive deals ignores the SecretCommits.
 func (d *DistKeyGenerator) ProcessSecretCommits(sc *SecretCommits) (*ComplaintCommits, error) {
-	pub, ok := findPub(d.participants, sc.Index)
+	if !d.isReceiver {
+		return nil, nil //nolint:nilnil // Expected behavior
+	}
+	pub, ok := findPub(d.dealers, sc.Index)
 	if !ok {
 		return nil, errors.New("dkg: secretcommits received with index out of bounds")
 	}
@@ -456,6 +602,14 @@ func (d *DistKeyGenerator) ProcessSecretCommits(sc *SecretCommits) (*ComplaintCo
 		return nil, err
 	}
 
+	if len(sc.Commitments) != d.t {
+		return nil, errors.New("dkg: secretcommits with wrong number of commitments")
+	}
+	if !d.checkOldShare(sc.Index, sc.Commitments[0]) {
+		d.invalid[sc.Index] = true
+		return nil, errors.New("dkg: secretcommits not committing to the share of the dealer")
+	}
+
 	deal := v.Deal()
 	poly := share.NewPubPoly(d.suite, d.suite.Point().Base(), sc.Commitments)
 	if !poly.Check(deal.SecShare) {
@@ -483,6 +637,9 @@ func (d *DistKeyGenerator) ProcessSecretCommits(sc *SecretCommits) (*ComplaintCo
 // ReconstructCommits message that must be  broadcasted to every other participant
 // in QUAL so the polynomial in question can be reconstructed.
 func (d *DistKeyGenerator) ProcessComplaintCommits(cc *ComplaintCommits) (*ReconstructCommits, error) {
+	if !d.isReceiver {
+		return nil, nil //nolint:nilnil // Expected behavior
+	}
 	issuer, ok := findPub(d.participants, cc.Index)
 	if !ok {
 		return nil, errors.New("dkg: commitcomplaint with unknown issuer")
@@ -546,6 +703,9 @@ func (d *DistKeyGenerator) ProcessComplaintCommits(cc *ComplaintCommits) (*Recon
 // the public polynomials of the malicious dealer in question, then the
 // polynomial is recovered.
 func (d *DistKeyGenerator) ProcessReconstructCommits(rs *ReconstructCommits) error {
+	if !d.isReceiver {
+		return nil
+	}
 	if _, ok := d.reconstructed[rs.DealerIndex]; ok {
 		// commitments already reconstructed, no need for other shares
 		return nil
@@ -592,10 +752,22 @@ func (d *DistKeyGenerator) ProcessReconstructCommits(rs *ReconstructCommits) err
 		// note it has been reconstructed.
 		d.reconstructed[rs.DealerIndex] = true
 		delete(d.pendingReconstruct, rs.DealerIndex)
+		if !d.checkOldShare(rs.DealerIndex, d.commitments[rs.DealerIndex].Commit()) {
+			d.invalid[rs.DealerIndex] = true
+		}
 	}
 	return nil
 }
 
+// checkOldShare returns true if the commitment to the secret of the dealer is
+// the commitment to its share of the reshared key, or if not resharing.
+func (d *DistKeyGenerator) checkOldShare(dealer uint32, commit kyber.Point) bool {
+	if d.oldPub == nil {
+		return true
+	}
+	return d.oldPub.Eval(dealer).V.Equal(commit)
+}
+
 // Finished returns true if the DKG has operated the protocol correctly and has
 // all necessary information to generate the DistKeyShare() by itself. It
 // returns false otherwise.
@@ -603,6 +775,10 @@ func (d *DistKeyGenerator) Finished() bool {
 	var ret = true
 	var nb = 0
 	d.qualIter(func(idx uint32, _ *vss.Verifier) bool {
+		if d.invalid[idx] {
+			// left out of a resharing
+			return true
+		}
 		nb++
 		// ALL QUAL members should have their commitments by now either given or
 		// reconstructed.
@@ -612,7 +788,7 @@ func (d *DistKeyGenerator) Finished() bool {
 		}
 		return true
 	})
-	return nb >= d.t && ret
+	return nb >= d.oldT && ret
 }
 
 // DistKeyShare generates the distributed key relative to this receiver
@@ -626,6 +802,9 @@ func (d *DistKeyGenerator) DistKeyShare() (*DistKeyShare, error) {
 	if !d.Certified() {
 		return nil, errors.New("dkg: distributed key not certified")
 	}
+	if d.oldPub != nil {
+		return d.resharedKeyShare()
+	}
 
 	sh := d.suite.Scalar().Zero()
 	var pub *share.PubPoly
@@ -664,6 +843,59 @@ func (d *DistKeyGenerator) DistKeyShare() (*DistKeyShare, error) {
 	}, nil
 }
 
+// resharedKeyShare interpolates the deals and commitments of the QUAL dealers
+// at zero. The dealers' secrets being shares of the old polynomial, the
+// result is a share of a polynomial with the same secret.
+func (d *DistKeyGenerator) resharedKeyShare() (*DistKeyShare, error) {
+	var shares []*share.PriShare
+	coeffs := make([][]*share.PubShare, d.t)
+	var err error
+	d.qualIter(func(i uint32, v *vss.Verifier) bool {
+		if d.invalid[i] {
+			return true
+		}
+		poly, ok := d.commitments[i]
+		if !ok {
+			err = fmt.Errorf("dkg: protocol not finished: %d commitments missing", i)
+			return false
+		}
+		shares = append(shares, &share.PriShare{I: i, V: v.Deal().SecShare.V})
+		_, commits := poly.Info()
+		for k := range coeffs {
+			coeffs[k] = append(coeffs[k], &share.PubShare{I: i, V: commits[k]})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) < d.oldT {
+		return nil, errors.New("dkg: not enough valid dealers")
+	}
+
+	sh, err := share.RecoverSecret(d.suite, shares, d.oldT, len(d.dealers))
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]kyber.Point, d.t)
+	for k := range coeffs {
+		commits[k], err = share.RecoverCommit(d.suite, coeffs[k], d.oldT, len(d.dealers))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !commits[0].Equal(d.oldPub.Commit()) {
+		return nil, errors.New("dkg: reshared key different from the old key")
+	}
+	return &DistKeyShare{
+		Commits: commits,
+		Share: &share.PriShare{
+			I: d.index,
+			V: sh,
+		},
+	}, nil
+}
+
 // Hash returns the hash value of this struct used in the signature process.
 func (sc *SecretCommits) Hash(s Suite) []byte {
 	h := s.Hash()
@@ -696,6 +928,15 @@ func (rc *ReconstructCommits) Hash(s Suite) []byte {
 	return h.Sum(nil)
 }
 
+func findIndex(list []kyber.Point, p kyber.Point) (uint32, bool) {
+	for i, q := range list {
+		if q.Equal(p) {
+			return uint32(i), true
+		}
+	}
+	return 0, false
+}
+
 func findPub(list []kyber.Point, i uint32) (kyber.Point, bool) {
 	if i >= uint32(len(list)) {
 		return nil, false
@@ -690,3 +690,190 @@ func fullExchange(t *testing.T) {
 	}
 
 }
+
+// fullDistKeyShares runs the whole protocol between the generators and
+// returns the shares of the participants receiving one, by index.
+func fullDistKeyShares(t *testing.T, gens []*DistKeyGenerator) map[uint32]*DistKeyShare {
+	receivers := make(map[uint32]*DistKeyGenerator)
+	for _, g := range gens {
+		if g.isReceiver {
+			receivers[g.index] = g
+		}
+	}
+	var resps []*Response
+	for _, g := range gens {
+		if !g.isDealer {
+			continue
+		}
+		deals, err := g.Deals()
+		require.NoError(t, err)
+		for i, d := range deals {
+			resp, err := receivers[uint32(i)].ProcessDeal(d)
+			require.NoError(t, err)
+			require.True(t, resp.Response.Approved)
+			resps = append(resps, resp)
+		}
+	}
+	for _, resp := range resps {
+		for _, g := range gens {
+			if g.isReceiver && resp.Response.Index == g.index {
+				continue
+			}
+			j, err := g.ProcessResponse(resp)
+			require.NoError(t, err)
+			require.Nil(t, j)
+		}
+	}
+	for _, g := range gens {
+		if !g.isDealer {
+			continue
+		}
+		sc, err := g.SecretCommits()
+		require.NoError(t, err)
+		// a dealer not dealing its old share is rejected
+		bad := !g.checkOldShare(sc.Index, sc.Commitments[0])
+		for _, g2 := range gens {
+			if g2 == g {
+				continue
+			}
+			cc, err := g2.ProcessSecretCommits(sc)
+			if bad && g2.isReceiver {
+				require.Error(t, err)
+				continue
+			}
+			require.NoError(t, err)
+			require.Nil(t, cc)
+		}
+	}
+	dkss := make(map[uint32]*DistKeyShare)
+	for i, g := range receivers {
+		require.True(t, g.Finished())
+		dks, err := g.DistKeyShare()
+		require.NoError(t, err)
+		dkss[i] = dks
+	}
+	return dkss
+}
+
+func TestDKGReshare(t *testing.T) {
+	dkss := fullDistKeyShares(t, dkgGen())
+	oldT := nbParticipants/2 + 1
+	var oldShares []*share.PriShare
+	for _, dks := range dkss {
+		oldShares = append(oldShares, dks.Share)
+	}
+	secret, err := share.RecoverSecret(suite, oldShares, oldT, nbParticipants)
+	require.NoError(t, err)
+
+	// the first two participants leave, three join and the threshold grows
+	newPubs := append([]kyber.Point{}, partPubs[2:]...)
+	newSecs := append([]kyber.Scalar{}, partSec[2:]...)
+	for i := 0; i < 3; i++ {
+		sec, pub := genPair()
+		newPubs = append(newPubs, pub)
+		newSecs = append(newSecs, sec)
+	}
+	newT := 6
+	var gens []*DistKeyGenerator
+	for i := 0; i < 2; i++ {
+		g, err := NewReshareDistKeyGenerator(&ReshareConfig{
+			Suite:     suite,
+			Longterm:  partSec[i],
+			Share:     dkss[uint32(i)],
+			OldNodes:  partPubs,
+			NewNodes:  newPubs,
+			Threshold: newT,
+		})
+		require.NoError(t, err)
+		require.True(t, g.isDealer)
+		require.False(t, g.isReceiver)
+		gens = append(gens, g)
+	}
+	for i, sec := range newSecs {
+		c := &ReshareConfig{
+			Suite:     suite,
+			Longterm:  sec,
+			OldNodes:  partPubs,
+			NewNodes:  newPubs,
+			Threshold: newT,
+		}
+		if i+2 < nbParticipants {
+			c.Share = dkss[uint32(i+2)]
+		} else {
+			c.PublicCoeffs = dkss[0].Commits
+		}
+		g, err := NewReshareDistKeyGenerator(c)
+		require.NoError(t, err)
+		gens = append(gens, g)
+	}
+
+	// the second old participant deals another secret and is left out
+	cheater := gens[1]
+	cheater.dealer, err = vss.NewDealer(suite, partSec[1], suite.Scalar().Pick(suite.RandomStream()), newPubs, newT)
+	require.NoError(t, err)
+
+	newDkss := fullDistKeyShares(t, gens)
+	require.Len(t, newDkss, len(newPubs))
+	var newShares []*share.PriShare
+	for i, dks := range newDkss {
+		require.Equal(t, i, dks.Share.I)
+		require.Len(t, dks.Commits, newT)
+		require.True(t, dks.Public().Equal(dkss[0].Public()))
+		require.True(t, checkDks(dks, newDkss[0]))
+		require.True(t, share.NewPubPoly(suite, nil, dks.Commits).Check(dks.Share))
+		newShares = append(newShares, dks.Share)
+	}
+	for _, g := range gens {
+		if g.isReceiver {
+			require.Equal(t, map[uint32]bool{1: true}, g.invalid)
+		}
+	}
+	newSecret, err := share.RecoverSecret(suite, newShares[:newT], newT, len(newPubs))
+	require.NoError(t, err)
+	require.True(t, secret.Equal(newSecret))
+	_, err = share.RecoverSecret(suite, newShares[:newT-1], newT, len(newPubs))
+	require.Error(t, err)
+}
+
+func TestDKGReshareConfig(t *testing.T) {
+	dkss := fullDistKeyShares(t, dkgGen())
+	sec, pub := genPair()
+	c := &ReshareConfig{
+		Suite:    suite,
+		Longterm: partSec[0],
+		OldNodes: partPubs,
+		NewNodes: append([]kyber.Point{pub}, partPubs[1:]...),
+	}
+	// an old participant needs its share
+	_, err := NewReshareDistKeyGenerator(c)
+	require.Error(t, err)
+	c.PublicCoeffs = dkss[0].Commits
+	_, err = NewReshareDistKeyGenerator(c)
+	require.Error(t, err)
+	c.Share = dkss[1]
+	_, err = NewReshareDistKeyGenerator(c)
+	require.Error(t, err)
+	c.Share = dkss[0]
+	g, err := NewReshareDistKeyGenerator(c)
+	require.NoError(t, err)
+	require.Equal(t, vss.MinimumT(nbParticipants), g.t)
+
+	// a new participant only receives
+	c.Longterm = sec
+	c.Share = nil
+	g, err = NewReshareDistKeyGenerator(c)
+	require.NoError(t, err)
+	_, err = g.Deals()
+	require.Error(t, err)
+	_, err = g.SecretCommits()
+	require.Error(t, err)
+
+	other, _ := genPair()
+	c.Longterm = other
+	_, err = NewReshareDistKeyGenerator(c)
+	require.Error(t, err)
+	c.Longterm = sec
+	c.PublicCoeffs = nil
+	_, err = NewReshareDistKeyGenerator(c)
+	require.Error(t, err)
+}
@@ -0,0 +1,503 @@
+// Package transport implements the Board of the pedersen DKG protocol over
+// TCP, optionally secured with TLS.
+//
+// Every participant listens on its address and keeps a connection to every
+// other participant. Bundles are sent in length-prefixed frames signed with
+// the longterm key of the participant relaying them, so a frame can't be
+// injected by someone outside of the group.
+//
+// The first time a participant sees a bundle signed by its issuer for the
+// run of the protocol, it delivers it to the protocol and echoes it to all
+// the other participants. A dealer sending different
+// bundles to different participants is therefore caught: both versions reach
+// every honest participant, which then evicts the dealer. Identical bundles
+// are delivered only once, and all the frames are sent again when a
+// connection is re-established, so a participant restarting, for example
+// from a snapshot, gets the bundles it missed.
+package transport
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.dedis.ch/kyber/v4"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/sign"
+)
+
+// DefaultRetryPeriod is the time waited before dialing a peer again.
+const DefaultRetryPeriod = 200 * time.Millisecond
+
+// maxVersions is the number of different bundles delivered for the same
+// issuer, which is enough for the protocol to detect an equivocation.
+const maxVersions = 2
+
+// ErrInvalidBundle is returned when a bundle isn't signed by its issuer for
+// the run of the protocol.
+var ErrInvalidBundle = errors.New("transport: invalid bundle")
+
+// ErrClosed is returned when using a closed board.
+var ErrClosed = errors.New("transport: board closed")
+
+// Peer is a participant of the protocol.
+type Peer struct {
+	// Address to reach the peer, as given to net.Dial.
+	Address string
+	// Public is the longterm public key of the peer.
+	Public kyber.Point
+}
+
+// Config holds the parameters of a Board.
+type Config struct {
+	Suite dkg.Suite
+	// Longterm is the longterm secret key of this participant. Its public key
+	// must be in Peers.
+	Longterm kyber.Scalar
+	// Auth is the scheme used to sign the frames.
+	Auth sign.Scheme
+	// Peers lists all the participants, including this one.
+	Peers []Peer
+	// DKG is the config of the run of the protocol. Only the bundles of its
+	// nodes, for its nonce and signed with its auth scheme, are delivered and
+	// relayed.
+	DKG *dkg.Config
+	// Listener is an optional listener to use instead of listening on the
+	// address of this participant.
+	Listener net.Listener
+	// TLS is an optional config used both to accept and to dial connections.
+	// It must hold a certificate and the settings to verify the certificates
+	// of the peers.
+	TLS *tls.Config
+	// RetryPeriod is the time waited before dialing a peer again. It defaults
+	// to DefaultRetryPeriod.
+	RetryPeriod time.Duration
+	// Log is an optional logger.
+	Log dkg.Logger
+}
+
+// Board is a dkg.Board sending the bundles over the network.
+type Board struct {
+	c        *Config
+	self     uint32
+	listener net.Listener
+
+	deals   chan dkg.DealBundle
+	resps   chan dkg.ResponseBundle
+	justifs chan dkg.JustificationBundle
+
+	mu sync.Mutex
+	// frames sent so far, sent again on each new connection. There are at
+	// most maxVersions bundles per kind and issuer, so at most maxHistory
+	// frames.
+	history    [][]byte
+	maxHistory int
+	// hashes of the bundles delivered
+	seen map[string]bool
+	// number of different bundles delivered per kind and issuer
+	versions map[kind]map[dkg.Index]int
+	conns    map[net.Conn]bool
+	wake     []chan struct{}
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+var _ dkg.Board = (*Board)(nil)
+
+// NewBoard starts listening for the peers and connecting to them.
+func NewBoard(c *Config) (*Board, error) {
+	if c.Suite == nil || c.Longterm == nil || c.Auth == nil {
+		return nil, errors.New("transport: config needs a suite, a longterm key and an auth scheme")
+	}
+	if c.DKG == nil || c.DKG.Auth == nil || len(c.DKG.NewNodes) == 0 {
+		return nil, errors.New("transport: config needs the DKG config with its nodes and auth scheme")
+	}
+	pub := c.Suite.Point().Mul(c.Longterm, nil)
+	self := -1
+	for i, p := range c.Peers {
+		if p.Public == nil {
+			return nil, fmt.Errorf("transport: peer %d has no public key", i)
+		}
+		if p.Public.Equal(pub) {
+			self = i
+		}
+	}
+	if self < 0 {
+		return nil, errors.New("transport: public key not found in peers")
+	}
+	l := c.Listener
+	if l == nil {
+		var err error
+		if l, err = net.Listen("tcp", c.Peers[self].Address); err != nil {
+			return nil, err
+		}
+	}
+	if c.TLS != nil {
+		l = tls.NewListener(l, c.TLS)
+	}
+
+	n := len(c.Peers)
+	nDealers, nHolders := len(dealers(c.DKG)), len(c.DKG.NewNodes)
+	b := &Board{
+		c:        c,
+		self:     uint32(self),
+		listener: l,
+		// the incoming channels hold all the bundles of the issuers
+		deals:      make(chan dkg.DealBundle, maxVersions*nDealers),
+		resps:      make(chan dkg.ResponseBundle, maxVersions*nHolders),
+		justifs:    make(chan dkg.JustificationBundle, maxVersions*nDealers),
+		maxHistory: maxVersions * (2*nDealers + nHolders),
+		seen:       make(map[string]bool),
+		versions:   make(map[kind]map[dkg.Index]int),
+		conns:      make(map[net.Conn]bool),
+		wake:       make([]chan struct{}, n),
+		closing:    make(chan struct{}),
+	}
+	b.wg.Add(1)
+	go b.accept()
+	for i := range c.Peers {
+		if i == self {
+			continue
+		}
+		b.wake[i] = make(chan struct{}, 1)
+		b.wg.Add(1)
+		go b.send(i)
+	}
+	return b, nil
+}
+
+// Addr returns the address the board listens on.
+func (b *Board) Addr() net.Addr {
+	return b.listener.Addr()
+}
+
+// Close stops the board and closes all its connections.
+func (b *Board) Close() error {
+	var err error
+	b.once.Do(func() {
+		close(b.closing)
+		err = b.listener.Close()
+		b.mu.Lock()
+		for conn := range b.conns {
+			conn.Close()
+		}
+		b.mu.Unlock()
+		b.wg.Wait()
+	})
+	return err
+}
+
+func (b *Board) PushDeals(d *dkg.DealBundle) {
+	b.push(d)
+}
+
+func (b *Board) IncomingDeal() <-chan dkg.DealBundle {
+	return b.deals
+}
+
+func (b *Board) PushResponses(r *dkg.ResponseBundle) {
+	b.push(r)
+}
+
+func (b *Board) IncomingResponse() <-chan dkg.ResponseBundle {
+	return b.resps
+}
+
+func (b *Board) PushJustifications(j *dkg.JustificationBundle) {
+	b.push(j)
+}
+
+func (b *Board) IncomingJustification() <-chan dkg.JustificationBundle {
+	return b.justifs
+}
+
+// push delivers our own bundle and broadcasts it.
+func (b *Board) push(p dkg.Packet) {
+	k, body, err := encodeBundle(b.c.Suite, p)
+	if err != nil {
+		b.error("encoding bundle", err)
+		return
+	}
+	if err := b.handle(k, body); err != nil {
+		b.error("pushing bundle", err)
+	}
+}
+
+// handle delivers the bundle if it's new, and broadcasts it to the peers.
+// The frame only authenticates the peer relaying the bundle, so the bundle
+// is checked to come from its issuer before it's counted as one of its
+// versions: otherwise a peer could forge versions of the bundles of honest
+// issuers, which would be dropped.
+func (b *Board) handle(k kind, body []byte) error {
+	p, err := decodeBundle(b.c.Suite, k, body)
+	if err != nil {
+		return err
+	}
+	hash, err := p.Hash()
+	if err != nil {
+		return err
+	}
+	key := string(append([]byte{byte(k)}, hash...))
+
+	b.mu.Lock()
+	seen := b.seen[key]
+	b.mu.Unlock()
+	if seen {
+		return nil
+	}
+	if err := b.verifyBundle(p); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	if b.seen[key] || b.versions[k][p.Index()] >= maxVersions || len(b.history) >= b.maxHistory {
+		b.mu.Unlock()
+		return nil
+	}
+	b.seen[key] = true
+	if b.versions[k] == nil {
+		b.versions[k] = make(map[dkg.Index]int)
+	}
+	b.versions[k][p.Index()]++
+	if err := b.broadcast(k, body); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	b.mu.Unlock()
+	return b.deliver(p)
+}
+
+// broadcast signs the bundle and queues it for all the peers. It must be
+// called with the lock held.
+func (b *Board) broadcast(k kind, body []byte) error {
+	f := &frame{Kind: k, Sender: b.self, Body: body}
+	sig, err := b.c.Auth.Sign(b.c.Longterm, f.message())
+	if err != nil {
+		return err
+	}
+	f.Signature = sig
+	b.history = append(b.history, f.marshal())
+	for _, w := range b.wake {
+		if w == nil {
+			continue
+		}
+		select {
+		case w <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// verifyBundle checks that the bundle is for the run of the protocol and is
+// signed by its issuer, which is one of the nodes.
+func (b *Board) verifyBundle(p dkg.Packet) error {
+	var session []byte
+	switch v := p.(type) {
+	case *dkg.DealBundle:
+		session = v.SessionID
+	case *dkg.ResponseBundle:
+		session = v.SessionID
+	case *dkg.JustificationBundle:
+		session = v.SessionID
+	}
+	if !bytes.Equal(session, b.c.DKG.Nonce) {
+		return fmt.Errorf("%w: session %x of issuer %d", ErrInvalidBundle, session, p.Index())
+	}
+	if err := dkg.VerifyPacketSignature(b.c.DKG, p); err != nil {
+		return fmt.Errorf("%w: issuer %d: %v", ErrInvalidBundle, p.Index(), err)
+	}
+	return nil
+}
+
+// dealers returns the nodes dealing in the run of the protocol.
+func dealers(c *dkg.Config) []dkg.Node {
+	if c.OldNodes == nil {
+		return c.NewNodes
+	}
+	return c.OldNodes
+}
+
+func (b *Board) deliver(p dkg.Packet) error {
+	switch v := p.(type) {
+	case *dkg.DealBundle:
+		select {
+		case b.deals <- *v:
+		case <-b.closing:
+			return ErrClosed
+		}
+	case *dkg.ResponseBundle:
+		select {
+		case b.resps <- *v:
+		case <-b.closing:
+			return ErrClosed
+		}
+	case *dkg.JustificationBundle:
+		select {
+		case b.justifs <- *v:
+		case <-b.closing:
+			return ErrClosed
+		}
+	}
+	return nil
+}
+
+func (b *Board) accept() {
+	defer b.wg.Done()
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			select {
+			case <-b.closing:
+				return
+			default:
+			}
+			b.error("accepting connection", err)
+			continue
+		}
+		if !b.track(conn) {
+			return
+		}
+		b.wg.Add(1)
+		go b.receive(conn)
+	}
+}
+
+// track registers a connection to close with the board. It returns false if
+// the board is closed.
+func (b *Board) track(conn net.Conn) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.closing:
+		conn.Close()
+		return false
+	default:
+	}
+	b.conns[conn] = true
+	return true
+}
+
+func (b *Board) untrack(conn net.Conn) {
+	b.mu.Lock()
+	delete(b.conns, conn)
+	b.mu.Unlock()
+	conn.Close()
+}
+
+// receive reads the frames of an incoming connection.
+func (b *Board) receive(conn net.Conn) {
+	defer b.wg.Done()
+	defer b.untrack(conn)
+	for {
+		f, err := readFrame(conn)
+		if err != nil {
+			return
+		}
+		if err := b.verify(f); err != nil {
+			// the connection is not from a peer, or the peer misbehaves
+			b.error("invalid frame from", conn.RemoteAddr(), err)
+			return
+		}
+		if err := b.handle(f.Kind, f.Body); err != nil {
+			if errors.Is(err, ErrClosed) {
+				return
+			}
+			b.error("invalid bundle from peer", f.Sender, err)
+		}
+	}
+}
+
+func (b *Board) verify(f *frame) error {
+	if int(f.Sender) >= len(b.c.Peers) || f.Sender == b.self {
+		return fmt.Errorf("%w: unknown sender %d", ErrInvalidFrame, f.Sender)
+	}
+	pub := b.c.Peers[f.Sender].Public
+	if err := b.c.Auth.Verify(pub, f.message(), f.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFrame, err)
+	}
+	return nil
+}
+
+// send keeps a connection to the peer i and writes the frames to it. All the
+// frames are sent again on each new connection.
+func (b *Board) send(i int) {
+	defer b.wg.Done()
+	retry := b.c.RetryPeriod
+	if retry == 0 {
+		retry = DefaultRetryPeriod
+	}
+	for {
+		conn, err := b.dial(b.c.Peers[i].Address)
+		if err == nil && b.track(conn) {
+			err = b.write(conn, i)
+			b.untrack(conn)
+		}
+		if err != nil {
+			b.info("connection to peer", i, err)
+		}
+		select {
+		case <-b.closing:
+			return
+		case <-time.After(retry):
+		}
+	}
+}
+
+func (b *Board) dial(addr string) (net.Conn, error) {
+	d := &net.Dialer{Timeout: 5 * time.Second}
+	if b.c.TLS != nil {
+		return tls.DialWithDialer(d, "tcp", addr, b.c.TLS)
+	}
+	return d.Dial("tcp", addr)
+}
+
+// write sends the history to the peer i until the connection fails or the
+// board is closed.
+func (b *Board) write(conn net.Conn, i int) error {
+	// the peer never writes on this connection, reading only detects when
+	// it's closed
+	dead := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		close(dead)
+	}()
+	var sent int
+	for {
+		b.mu.Lock()
+		pending := b.history[sent:]
+		b.mu.Unlock()
+		for _, buf := range pending {
+			if _, err := conn.Write(buf); err != nil {
+				return err
+			}
+		}
+		sent += len(pending)
+		select {
+		case <-b.closing:
+			return nil
+		case <-dead:
+			return errors.New("transport: connection closed by peer")
+		case <-b.wake[i]:
+		}
+	}
+}
+
+func (b *Board) info(keyvals ...interface{}) {
+	if b.c.Log != nil {
+		b.c.Log.Info(append([]interface{}{"transport"}, keyvals...)...)
+	}
+}
+
+func (b *Board) error(keyvals ...interface{}) {
+	if b.c.Log != nil {
+		b.c.Log.Error(append([]interface{}{"transport"}, keyvals...)...)
+	}
+}
@@ -0,0 +1,380 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+var suite = edwards25519.NewBlakeSHA256Ed25519()
+
+type testNet struct {
+	keys      []kyber.Scalar
+	peers     []Peer
+	listeners []net.Listener
+	nonce     []byte
+}
+
+func newTestNet(t *testing.T, n int) *testNet {
+	tn := &testNet{nonce: dkg.GetNonce()}
+	for i := 0; i < n; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		key := suite.Scalar().Pick(random.New())
+		tn.keys = append(tn.keys, key)
+		tn.listeners = append(tn.listeners, l)
+		tn.peers = append(tn.peers, Peer{
+			Address: l.Addr().String(),
+			Public:  suite.Point().Mul(key, nil),
+		})
+	}
+	return tn
+}
+
+func (tn *testNet) config(i int, tlsConf *tls.Config) *Config {
+	return &Config{
+		Suite:       suite,
+		Longterm:    tn.keys[i],
+		Auth:        schnorr.NewScheme(suite),
+		Peers:       tn.peers,
+		DKG:         tn.dkgConfig(),
+		Listener:    tn.listeners[i],
+		TLS:         tlsConf,
+		RetryPeriod: 20 * time.Millisecond,
+	}
+}
+
+func (tn *testNet) boards(t *testing.T, tlsConf *tls.Config) []*Board {
+	boards := make([]*Board, len(tn.peers))
+	for i := range boards {
+		b, err := NewBoard(tn.config(i, tlsConf))
+		require.NoError(t, err)
+		t.Cleanup(func() { b.Close() })
+		boards[i] = b
+	}
+	return boards
+}
+
+// dkgConfig returns the config of a DKG between all the peers, without
+// longterm key.
+func (tn *testNet) dkgConfig() *dkg.Config {
+	return &dkg.Config{
+		Suite:     suite,
+		NewNodes:  tn.nodes(),
+		Threshold: len(tn.peers) - 1,
+		Nonce:     tn.nonce,
+		Auth:      schnorr.NewScheme(suite),
+	}
+}
+
+func (tn *testNet) nodes() []dkg.Node {
+	nodes := make([]dkg.Node, len(tn.peers))
+	for i, p := range tn.peers {
+		nodes[i] = dkg.Node{Index: uint32(i), Public: p.Public}
+	}
+	return nodes
+}
+
+func selfSignedTLS(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dkg"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS13,
+	}
+}
+
+func runDKG(t *testing.T, tlsConf *tls.Config, fastSync bool) {
+	n := 4
+	tn := newTestNet(t, n)
+	boards := tn.boards(t, tlsConf)
+	nonce := tn.nonce
+	protos := make([]*dkg.Protocol, n)
+	phasers := make([]*dkg.TimePhaser, n)
+	for i := range protos {
+		conf := &dkg.Config{
+			Suite:     suite,
+			Longterm:  tn.keys[i],
+			NewNodes:  tn.nodes(),
+			Threshold: 3,
+			FastSync:  fastSync,
+			Nonce:     nonce,
+			Auth:      schnorr.NewScheme(suite),
+		}
+		phasers[i] = dkg.NewTimePhaser(500 * time.Millisecond)
+		p, err := dkg.NewProtocol(conf, boards[i], phasers[i], false)
+		require.NoError(t, err)
+		protos[i] = p
+	}
+	for _, ph := range phasers {
+		go ph.Start()
+	}
+	var key kyber.Point
+	for _, p := range protos {
+		select {
+		case res := <-p.WaitEnd():
+			require.NoError(t, res.Error)
+			require.Len(t, res.Result.QUAL, n)
+			if key == nil {
+				key = res.Result.Key.Public()
+			}
+			require.True(t, key.Equal(res.Result.Key.Public()))
+		case <-time.After(10 * time.Second):
+			t.Fatal("dkg did not finish")
+		}
+	}
+}
+
+func TestBoardDKG(t *testing.T) {
+	runDKG(t, nil, true)
+}
+
+func TestBoardDKGTLS(t *testing.T) {
+	runDKG(t, selfSignedTLS(t), false)
+}
+
+// dealBundle returns a deal bundle of the dealer, signed with its key.
+func dealBundle(t *testing.T, tn *testNet, dealer uint32, seed string) *dkg.DealBundle {
+	p := suite.Point().Pick(suite.XOF([]byte(seed)))
+	d := &dkg.DealBundle{
+		DealerIndex: dealer,
+		Deals:       []dkg.Deal{{ShareIndex: 1, EncryptedShare: []byte(seed)}},
+		Public:      []kyber.Point{p},
+		SessionID:   tn.nonce,
+	}
+	hash, err := d.Hash()
+	require.NoError(t, err)
+	d.Signature, err = schnorr.Sign(suite, tn.keys[dealer], hash)
+	require.NoError(t, err)
+	return d
+}
+
+func receiveDeal(t *testing.T, b *Board) *dkg.DealBundle {
+	select {
+	case d := <-b.IncomingDeal():
+		return &d
+	case <-time.After(5 * time.Second):
+		t.Fatal("no deal received")
+		return nil
+	}
+}
+
+func TestBoardDedup(t *testing.T) {
+	tn := newTestNet(t, 3)
+	boards := tn.boards(t, nil)
+	d := dealBundle(t, tn, 0, "deal")
+	boards[0].PushDeals(d)
+	boards[0].PushDeals(d)
+	for _, b := range boards {
+		got := receiveDeal(t, b)
+		require.Equal(t, d.Deals, got.Deals)
+		require.True(t, d.Public[0].Equal(got.Public[0]))
+	}
+	time.Sleep(100 * time.Millisecond)
+	for _, b := range boards {
+		require.Len(t, b.IncomingDeal(), 0)
+	}
+}
+
+// sendFrame signs the bundle as the peer i and writes it to addr.
+func sendFrame(t *testing.T, tn *testNet, i int, addr string, p dkg.Packet) {
+	k, body, err := encodeBundle(suite, p)
+	require.NoError(t, err)
+	f := &frame{Kind: k, Sender: uint32(i), Body: body}
+	f.Signature, err = schnorr.Sign(suite, tn.keys[i], f.message())
+	require.NoError(t, err)
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write(f.marshal())
+	require.NoError(t, err)
+}
+
+func TestBoardEquivocation(t *testing.T) {
+	// the first peer is a byzantine dealer sending a different deal to each
+	// of the two other peers
+	tn := newTestNet(t, 3)
+	tn.listeners[0].Close()
+	var boards []*Board
+	for i := 1; i < 3; i++ {
+		b, err := NewBoard(tn.config(i, nil))
+		require.NoError(t, err)
+		t.Cleanup(func() { b.Close() })
+		boards = append(boards, b)
+	}
+	d1, d2 := dealBundle(t, tn, 0, "first"), dealBundle(t, tn, 0, "second")
+	sendFrame(t, tn, 0, tn.peers[1].Address, d1)
+	sendFrame(t, tn, 0, tn.peers[2].Address, d2)
+
+	// thanks to the echo, both peers see both versions
+	for _, b := range boards {
+		got := [][]byte{receiveDeal(t, b).Deals[0].EncryptedShare, receiveDeal(t, b).Deals[0].EncryptedShare}
+		require.ElementsMatch(t, [][]byte{[]byte("first"), []byte("second")}, got)
+	}
+
+	// further versions are dropped
+	sendFrame(t, tn, 0, tn.peers[1].Address, dealBundle(t, tn, 0, "third"))
+	time.Sleep(100 * time.Millisecond)
+	for _, b := range boards {
+		require.Len(t, b.IncomingDeal(), 0)
+	}
+}
+
+func TestBoardForgedBundle(t *testing.T) {
+	// the second peer is byzantine and relays bundles it forged in the name
+	// of the first one
+	tn := newTestNet(t, 3)
+	tn.listeners[1].Close()
+	boards := make([]*Board, 3)
+	for _, i := range []int{0, 2} {
+		b, err := NewBoard(tn.config(i, nil))
+		require.NoError(t, err)
+		t.Cleanup(func() { b.Close() })
+		boards[i] = b
+	}
+
+	forged := func(seed string, sign func(d *dkg.DealBundle)) *dkg.DealBundle {
+		d := dealBundle(t, tn, 0, seed)
+		sign(d)
+		return d
+	}
+	resign := func(d *dkg.DealBundle) {
+		hash, err := d.Hash()
+		require.NoError(t, err)
+		d.Signature, err = schnorr.Sign(suite, tn.keys[1], hash)
+		require.NoError(t, err)
+	}
+	for _, d := range []*dkg.DealBundle{
+		// signed by the relaying peer instead of the dealer
+		forged("forged1", resign),
+		forged("forged2", resign),
+		// signed by the dealer for another run of the protocol
+		forged("other session", func(d *dkg.DealBundle) {
+			d.SessionID = dkg.GetNonce()
+			hash, err := d.Hash()
+			require.NoError(t, err)
+			d.Signature, err = schnorr.Sign(suite, tn.keys[0], hash)
+			require.NoError(t, err)
+		}),
+		// from a node which doesn't exist
+		forged("unknown issuer", func(d *dkg.DealBundle) {
+			d.DealerIndex = 42
+			resign(d)
+		}),
+	} {
+		sendFrame(t, tn, 1, tn.peers[2].Address, d)
+	}
+	time.Sleep(100 * time.Millisecond)
+	require.Len(t, boards[2].IncomingDeal(), 0)
+	require.Empty(t, boards[2].history)
+
+	// the forgeries didn't take the place of the deal of the dealer
+	d := dealBundle(t, tn, 0, "deal")
+	boards[0].PushDeals(d)
+	for _, b := range []*Board{boards[0], boards[2]} {
+		require.Equal(t, d.Deals, receiveDeal(t, b).Deals)
+	}
+}
+
+func TestBoardReconnect(t *testing.T) {
+	tn := newTestNet(t, 2)
+	// the second peer is down when the first one pushes its deal
+	addr := tn.peers[1].Address
+	tn.listeners[1].Close()
+	b0, err := NewBoard(tn.config(0, nil))
+	require.NoError(t, err)
+	defer b0.Close()
+	d := dealBundle(t, tn, 0, "deal")
+	b0.PushDeals(d)
+	receiveDeal(t, b0)
+	time.Sleep(50 * time.Millisecond)
+
+	start := func() *Board {
+		l, err := net.Listen("tcp", addr)
+		require.NoError(t, err)
+		tn.listeners[1] = l
+		b, err := NewBoard(tn.config(1, nil))
+		require.NoError(t, err)
+		return b
+	}
+	b1 := start()
+	require.Equal(t, d.Deals, receiveDeal(t, b1).Deals)
+	// after a restart, the deal is sent again
+	require.NoError(t, b1.Close())
+	b1 = start()
+	defer b1.Close()
+	require.Equal(t, d.Deals, receiveDeal(t, b1).Deals)
+}
+
+func TestBoardInvalidFrames(t *testing.T) {
+	tn := newTestNet(t, 2)
+	boards := tn.boards(t, nil)
+
+	// a frame signed by someone outside the group is dropped
+	stranger := newTestNet(t, 1)
+	stranger.listeners[0].Close()
+	stranger.keys = append(tn.keys[:1:1], stranger.keys...)
+	sendFrame(t, stranger, 1, tn.peers[0].Address, dealBundle(t, tn, 1, "stranger"))
+	time.Sleep(100 * time.Millisecond)
+	require.Len(t, boards[0].IncomingDeal(), 0)
+
+	// the board keeps working afterwards
+	sendFrame(t, tn, 1, tn.peers[0].Address, dealBundle(t, tn, 1, "peer"))
+	require.Equal(t, []byte("peer"), receiveDeal(t, boards[0]).Deals[0].EncryptedShare)
+}
+
+func TestReadFrame(t *testing.T) {
+	f := &frame{Kind: kindResponse, Sender: 3, Body: []byte("body"), Signature: []byte("sig")}
+	got, err := readFrame(bytes.NewReader(f.marshal()))
+	require.NoError(t, err)
+	require.Equal(t, f, got)
+
+	var big [4]byte
+	binary.BigEndian.PutUint32(big[:], MaxFrameSize+1)
+	_, err = readFrame(bytes.NewReader(big[:]))
+	require.ErrorIs(t, err, ErrFrameTooLarge)
+
+	buf := f.marshal()
+	binary.BigEndian.PutUint32(buf[10:], 100)
+	_, err = readFrame(bytes.NewReader(buf))
+	require.ErrorIs(t, err, ErrInvalidFrame)
+	buf = f.marshal()
+	buf[4] = frameVersion + 1
+	_, err = readFrame(bytes.NewReader(buf))
+	require.ErrorIs(t, err, ErrInvalidFrame)
+
+	_, err = decodeBundle(suite, kindDeal, []byte("garbage"))
+	require.ErrorIs(t, err, ErrInvalidFrame)
+}
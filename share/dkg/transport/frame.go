@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+)
+
+// MaxFrameSize is the maximum size of a frame, length prefix excluded.
+const MaxFrameSize = 1 << 24
+
+const frameVersion = 1
+
+// domain separates the signatures of the frames from other signatures made
+// with the longterm keys.
+const domain = "kyber dkg transport v1"
+
+// kind is the type of bundle carried by a frame.
+type kind byte
+
+const (
+	kindDeal kind = iota + 1
+	kindResponse
+	kindJustification
+)
+
+// ErrFrameTooLarge is returned when reading a frame bigger than MaxFrameSize.
+var ErrFrameTooLarge = errors.New("transport: frame too large")
+
+// ErrInvalidFrame is returned when a frame can't be decoded or its signature
+// is invalid.
+var ErrInvalidFrame = errors.New("transport: invalid frame")
+
+// frame is a bundle relayed by the peer Sender, who signs it.
+type frame struct {
+	Kind      kind
+	Sender    uint32
+	Body      []byte
+	Signature []byte
+}
+
+// message returns the bytes signed by the sender of the frame.
+func (f *frame) message() []byte {
+	var b bytes.Buffer
+	b.WriteString(domain)
+	b.WriteByte(byte(f.Kind))
+	_ = binary.Write(&b, binary.BigEndian, f.Sender)
+	b.Write(f.Body)
+	return b.Bytes()
+}
+
+// marshal returns the frame with its length prefix.
+func (f *frame) marshal() []byte {
+	size := 1 + 1 + 4 + 4 + len(f.Body) + len(f.Signature)
+	buf := make([]byte, 4, 4+size)
+	binary.BigEndian.PutUint32(buf, uint32(size))
+	buf = append(buf, frameVersion, byte(f.Kind))
+	buf = binary.BigEndian.AppendUint32(buf, f.Sender)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(f.Body)))
+	buf = append(buf, f.Body...)
+	return append(buf, f.Signature...)
+}
+
+// readFrame reads a length-prefixed frame from r.
+func readFrame(r io.Reader) (*frame, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(prefix[:])
+	if size > MaxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	if len(buf) < 10 || buf[0] != frameVersion {
+		return nil, fmt.Errorf("%w: bad header", ErrInvalidFrame)
+	}
+	f := &frame{
+		Kind:   kind(buf[1]),
+		Sender: binary.BigEndian.Uint32(buf[2:6]),
+	}
+	bodyLen := binary.BigEndian.Uint32(buf[6:10])
+	if uint64(bodyLen) > uint64(len(buf)-10) {
+		return nil, fmt.Errorf("%w: bad body length", ErrInvalidFrame)
+	}
+	f.Body = buf[10 : 10+bodyLen]
+	f.Signature = buf[10+bodyLen:]
+	return f, nil
+}
+
+// encodeBundle returns the kind and the encoding of a bundle.
+func encodeBundle(s dkg.Suite, p dkg.Packet) (kind, []byte, error) {
+	switch v := p.(type) {
+	case *dkg.DealBundle:
+		buf, err := dkg.MarshalDealBundle(s, v)
+		return kindDeal, buf, err
+	case *dkg.ResponseBundle:
+		buf, err := dkg.MarshalResponseBundle(s, v)
+		return kindResponse, buf, err
+	case *dkg.JustificationBundle:
+		buf, err := dkg.MarshalJustificationBundle(s, v)
+		return kindJustification, buf, err
+	default:
+		return 0, nil, fmt.Errorf("transport: unknown packet type %T", p)
+	}
+}
+
+// decodeBundle decodes the bundle carried by a frame of kind k. Bundles of
+// another suite are rejected.
+func decodeBundle(s dkg.Suite, k kind, buf []byte) (dkg.Packet, error) {
+	var p dkg.Packet
+	var err error
+	switch k {
+	case kindDeal:
+		p, err = dkg.UnmarshalDealBundle(s, buf)
+	case kindResponse:
+		p, err = dkg.UnmarshalResponseBundle(s, buf)
+	case kindJustification:
+		p, err = dkg.UnmarshalJustificationBundle(s, buf)
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %d", ErrInvalidFrame, k)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFrame, err)
+	}
+	return p, nil
+}
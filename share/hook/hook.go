@@ -0,0 +1,111 @@
+// Package hook reports the events of the verifiable secret sharing and
+// distributed key generation protocols: phase timings, validity of the deals,
+// complaints, outcome of the justifications and size of the qualified set.
+// Operators can follow a ceremony from logs with NewSlog, or from metrics with
+// NewMetrics, and see where it stalls.
+package hook
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kind is the type of an Event.
+type Kind int
+
+const (
+	// KindPhase is reported when a phase ends, with its duration.
+	KindPhase Kind = iota + 1
+	// KindDeal is reported when a deal is processed, with its validity.
+	KindDeal
+	// KindComplaint is reported for each complaint received against a dealer.
+	KindComplaint
+	// KindJustification is reported for each justification processed, with
+	// its validity.
+	KindJustification
+	// KindQUAL is reported when the qualified set of dealers is known, with
+	// its size.
+	KindQUAL
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindPhase:
+		return "phase"
+	case KindDeal:
+		return "deal"
+	case KindComplaint:
+		return "complaint"
+	case KindJustification:
+		return "justification"
+	case KindQUAL:
+		return "qual"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// Protocol names used in the events of the protocols of kyber.
+const (
+	ProtocolPedersenDKG = "dkg/pedersen"
+	ProtocolPedersenVSS = "vss/pedersen"
+	ProtocolRabinVSS    = "vss/rabin"
+)
+
+// Event is reported by a protocol. Only the fields relevant to its kind are
+// set.
+type Event struct {
+	Kind Kind
+	// Protocol names the protocol reporting the event.
+	Protocol string
+	// Phase is the phase which ended, for KindPhase.
+	Phase string
+	// Duration is the duration of the phase, for KindPhase.
+	Duration time.Duration
+	// Dealer is the index of the dealer of the deal, of the dealer the
+	// complaint is against, or of the dealer justifying. A VSS has a single
+	// dealer, reported with index 0.
+	Dealer uint32
+	// Holder is the index of the share holder which received the deal,
+	// issued the complaint, or whose share is justified.
+	Holder uint32
+	// Valid tells if the deal or the justification is valid.
+	Valid bool
+	// Reason tells why a deal or a justification is invalid.
+	Reason string
+	// Size is the size of the qualified set, for KindQUAL.
+	Size int
+}
+
+// Hook receives the events of a protocol. It is called synchronously while
+// the protocol runs, so it must be quick and must not block.
+type Hook interface {
+	Report(Event)
+}
+
+// Func adapts a function to a Hook.
+type Func func(Event)
+
+// Report calls f.
+func (f Func) Report(e Event) {
+	f(e)
+}
+
+// Multi returns a Hook reporting the events to all the hooks.
+func Multi(hooks ...Hook) Hook {
+	return Func(func(e Event) {
+		for _, h := range hooks {
+			h.Report(e)
+		}
+	})
+}
+
+// Report reports the event to h if it isn't nil, with the protocol name set.
+// It is a helper for the protocols.
+func Report(h Hook, protocol string, e Event) {
+	if h == nil {
+		return
+	}
+	e.Protocol = protocol
+	h.Report(e)
+}
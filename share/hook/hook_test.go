@@ -0,0 +1,104 @@
+package hook
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	var got []Event
+	h := Func(func(e Event) { got = append(got, e) })
+	Report(nil, ProtocolPedersenDKG, Event{Kind: KindQUAL})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindQUAL, Size: 3})
+	require.Equal(t, []Event{{Kind: KindQUAL, Protocol: ProtocolPedersenDKG, Size: 3}}, got)
+
+	got = nil
+	Report(Multi(h, h), ProtocolRabinVSS, Event{Kind: KindComplaint})
+	require.Len(t, got, 2)
+	require.Equal(t, got[0], got[1])
+
+	require.Equal(t, "justification", KindJustification.String())
+	require.Equal(t, "kind(42)", Kind(42).String())
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlog(slog.New(slog.NewTextHandler(&buf, nil)))
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindPhase, Phase: "deal", Duration: time.Second})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindDeal, Dealer: 1, Holder: 2, Valid: true})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindDeal, Dealer: 3, Holder: 2, Reason: "invalid share"})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindComplaint, Dealer: 3, Holder: 2})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindQUAL, Size: 4})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	for i, want := range []string{
+		"level=INFO msg=phase protocol=dkg/pedersen phase=deal duration=1s",
+		"level=INFO msg=deal protocol=dkg/pedersen dealer=1 holder=2 valid=true",
+		`level=WARN msg=deal protocol=dkg/pedersen dealer=3 holder=2 valid=false reason="invalid share"`,
+		"level=WARN msg=complaint protocol=dkg/pedersen dealer=3 holder=2",
+		"level=INFO msg=qual protocol=dkg/pedersen size=4",
+	} {
+		// skip the time
+		_, line, _ := strings.Cut(lines[i], " ")
+		require.Equal(t, want, line)
+	}
+}
+
+type testMetric struct {
+	value float64
+	count int
+}
+
+func (m *testMetric) Add(v float64)     { m.value += v; m.count++ }
+func (m *testMetric) Set(v float64)     { m.value = v; m.count++ }
+func (m *testMetric) Observe(v float64) { m.value += v; m.count++ }
+
+type testRegistry map[string]*testMetric
+
+func (r testRegistry) get(name string, labelValues []string) *testMetric {
+	key := name + "{" + strings.Join(labelValues, ",") + "}"
+	if r[key] == nil {
+		r[key] = &testMetric{}
+	}
+	return r[key]
+}
+
+func (r testRegistry) Counter(name string, labelValues ...string) Counter {
+	return r.get(name, labelValues)
+}
+
+func (r testRegistry) Gauge(name string, labelValues ...string) Gauge {
+	return r.get(name, labelValues)
+}
+
+func (r testRegistry) Histogram(name string, labelValues ...string) Histogram {
+	return r.get(name, labelValues)
+}
+
+func TestMetrics(t *testing.T) {
+	r := testRegistry{}
+	h := NewMetrics(r)
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindPhase, Phase: "deal", Duration: 1500 * time.Millisecond})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindDeal, Dealer: 1, Holder: 2, Valid: true})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindDeal, Dealer: 1, Holder: 3, Valid: true})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindDeal, Dealer: 3, Holder: 2})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindComplaint, Dealer: 3, Holder: 2})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindJustification, Dealer: 3, Holder: 2, Valid: true})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindQUAL, Size: 5})
+	Report(h, ProtocolPedersenDKG, Event{Kind: KindQUAL, Size: 4})
+
+	require.Equal(t, testRegistry{
+		"dkg_phase_duration_seconds{dkg/pedersen,deal}": {1.5, 1},
+		"dkg_deals_total{dkg/pedersen,1,true}":          {2, 2},
+		"dkg_deals_total{dkg/pedersen,3,false}":         {1, 1},
+		"dkg_complaints_total{dkg/pedersen,3}":          {1, 1},
+		"dkg_justifications_total{dkg/pedersen,3,true}": {1, 1},
+		"dkg_qual_size{dkg/pedersen}":                   {4, 2},
+	}, r)
+}
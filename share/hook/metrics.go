@@ -0,0 +1,67 @@
+package hook
+
+import (
+	"strconv"
+)
+
+// Counter is a monotonic counter, as a prometheus.Counter.
+type Counter interface {
+	Add(float64)
+}
+
+// Gauge is a value which can go up and down, as a prometheus.Gauge.
+type Gauge interface {
+	Set(float64)
+}
+
+// Histogram records observations in buckets, as a prometheus.Histogram.
+type Histogram interface {
+	Observe(float64)
+}
+
+// Registry returns the metrics with the given name, for the given label
+// values. With Prometheus, each method wraps the WithLabelValues method of a
+// vector registered with the labels documented for each metric name.
+type Registry interface {
+	Counter(name string, labelValues ...string) Counter
+	Gauge(name string, labelValues ...string) Gauge
+	Histogram(name string, labelValues ...string) Histogram
+}
+
+// Names of the metrics updated by the Hook returned by NewMetrics, and their
+// labels in order.
+const (
+	// MetricPhaseSeconds is a histogram of the phase durations in seconds,
+	// labeled by protocol and phase.
+	MetricPhaseSeconds = "dkg_phase_duration_seconds"
+	// MetricDeals counts the processed deals, labeled by protocol, dealer
+	// and validity ("true" or "false").
+	MetricDeals = "dkg_deals_total"
+	// MetricComplaints counts the complaints, labeled by protocol and dealer.
+	MetricComplaints = "dkg_complaints_total"
+	// MetricJustifications counts the processed justifications, labeled by
+	// protocol, dealer and validity.
+	MetricJustifications = "dkg_justifications_total"
+	// MetricQUALSize is a gauge of the size of the last qualified set,
+	// labeled by protocol.
+	MetricQUALSize = "dkg_qual_size"
+)
+
+// NewMetrics returns a Hook updating the metrics of the registry.
+func NewMetrics(r Registry) Hook {
+	return Func(func(e Event) {
+		dealer := strconv.FormatUint(uint64(e.Dealer), 10)
+		switch e.Kind {
+		case KindPhase:
+			r.Histogram(MetricPhaseSeconds, e.Protocol, e.Phase).Observe(e.Duration.Seconds())
+		case KindDeal:
+			r.Counter(MetricDeals, e.Protocol, dealer, strconv.FormatBool(e.Valid)).Add(1)
+		case KindComplaint:
+			r.Counter(MetricComplaints, e.Protocol, dealer).Add(1)
+		case KindJustification:
+			r.Counter(MetricJustifications, e.Protocol, dealer, strconv.FormatBool(e.Valid)).Add(1)
+		case KindQUAL:
+			r.Gauge(MetricQUALSize, e.Protocol).Set(float64(e.Size))
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package hook
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlog returns a Hook logging the events with the logger. Invalid deals,
+// complaints and invalid justifications are logged at the warning level, the
+// other events at the info level.
+func NewSlog(l *slog.Logger) Hook {
+	return Func(func(e Event) {
+		level := slog.LevelInfo
+		attrs := []slog.Attr{slog.String("protocol", e.Protocol)}
+		switch e.Kind {
+		case KindPhase:
+			attrs = append(attrs, slog.String("phase", e.Phase), slog.Duration("duration", e.Duration))
+		case KindDeal, KindJustification:
+			attrs = append(attrs,
+				slog.Any("dealer", e.Dealer),
+				slog.Any("holder", e.Holder),
+				slog.Bool("valid", e.Valid))
+			if !e.Valid {
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.String("reason", e.Reason))
+			}
+		case KindComplaint:
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.Any("dealer", e.Dealer), slog.Any("holder", e.Holder))
+		case KindQUAL:
+			attrs = append(attrs, slog.Int("size", e.Size))
+		}
+		l.LogAttrs(context.Background(), level, e.Kind.String(), attrs...)
+	})
+}
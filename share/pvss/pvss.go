@@ -113,38 +113,30 @@ func computeCommitments(suite Suite, n int, polyComs []kyber.Point) []kyber.Poin
 	return coms
 }
 
-func computeGlobalChallenge(suite Suite, n int, commit *share.PubPoly, encShares []*PubVerShare) (kyber.Scalar, error) {
+func computeGlobalChallenge(
+	suite Suite,
+	H kyber.Point,
+	X []kyber.Point,
+	commit *share.PubPoly,
+	encShares []*PubVerShare,
+) (kyber.Scalar, error) {
+	n := len(X)
 	_, polyComs := commit.Info()
 	coms := computeCommitments(suite, n, polyComs)
 
-	h := suite.Hash()
-	var err error
-	for _, com := range coms {
-		if _, err = com.MarshalTo(h); err != nil {
-			return nil, err
-		}
-	}
-
-	for _, encShare := range encShares {
-		if _, err = encShare.S.V.MarshalTo(h); err != nil {
-			return nil, err
-		}
-	}
-
-	for _, encShare := range encShares {
-		if _, err = encShare.P.VG.MarshalTo(h); err != nil {
-			return nil, err
-		}
+	HS := make([]kyber.Point, n)
+	sX := make([]kyber.Point, len(encShares))
+	VG := make([]kyber.Point, len(encShares))
+	VH := make([]kyber.Point, len(encShares))
+	for i := range HS {
+		HS[i] = H
 	}
-
-	for _, encShare := range encShares {
-		if _, err = encShare.P.VH.MarshalTo(h); err != nil {
-			return nil, err
-		}
+	for i, encShare := range encShares {
+		sX[i] = encShare.S.V
+		VG[i] = encShare.P.VG
+		VH[i] = encShare.P.VH
 	}
-
-	cb := h.Sum(nil)
-	return suite.Scalar().Pick(suite.XOF(cb)), nil
+	return dleq.Challenge(suite, HS, X, coms, sX, VG, VH)
 }
 
 // VerifyEncShare checks that the encrypted share sX satisfies
@@ -178,7 +170,7 @@ func VerifyEncShareBatch(
 	var E []*PubVerShare // good encrypted shares
 
 	// Need to compute the global challenge and verify the encrypted shares
-	expGlobalChallenge, err := computeGlobalChallenge(suite, len(X), commit, encShares)
+	expGlobalChallenge, err := computeGlobalChallenge(suite, H, X, commit, encShares)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -246,24 +238,14 @@ func DecShareBatch(
 // log_{G}(X) == log_{sG}(sX). Note that X = xG and sX = s(xG) = x(sG).
 func VerifyDecShare(suite Suite, G, X kyber.Point, encShare *PubVerShare, decShare *PubVerShare) error {
 	// Compute challenge for the decShare
-	h := suite.Hash()
-	var err error
-	if _, err = X.MarshalTo(h); err != nil {
-		return err
-	}
-	if _, err = encShare.S.V.MarshalTo(h); err != nil {
-		return err
-	}
-	if _, err = decShare.P.VG.MarshalTo(h); err != nil {
-		return err
-	}
-	if _, err = decShare.P.VH.MarshalTo(h); err != nil {
+	expDecChallenge, err := dleq.Challenge(suite,
+		[]kyber.Point{G}, []kyber.Point{decShare.S.V},
+		[]kyber.Point{X}, []kyber.Point{encShare.S.V},
+		[]kyber.Point{decShare.P.VG}, []kyber.Point{decShare.P.VH})
+	if err != nil {
 		return err
 	}
 
-	cb := h.Sum(nil)
-	expDecChallenge := suite.Scalar().Pick(suite.XOF(cb))
-
 	if !decShare.P.C.Equal(expDecChallenge) {
 		return fmt.Errorf("didn't verify: %w", ErrDecShareChallengeVerification)
 	}
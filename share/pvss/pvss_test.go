@@ -83,7 +83,7 @@ func TestPVSS(test *testing.T) {
 	var E []*PubVerShare // good encrypted shares
 	var D []*PubVerShare // good decrypted shares
 
-	globalChallenge, err := computeGlobalChallenge(suite, n, pubPoly, encShares)
+	globalChallenge, err := computeGlobalChallenge(suite, H, X, pubPoly, encShares)
 	require.NoError(test, err)
 
 	for i := 0; i < n; i++ {
@@ -130,7 +130,7 @@ func TestPVSSDelete(test *testing.T) {
 	var E []*PubVerShare // good encrypted shares
 	var D []*PubVerShare // good decrypted shares
 
-	globalChallenge, err := computeGlobalChallenge(suite, len(X), pubPoly, encShares)
+	globalChallenge, err := computeGlobalChallenge(suite, H, X, pubPoly, encShares)
 	require.NoError(test, err)
 
 	for i := 0; i < n; i++ {
@@ -182,7 +182,7 @@ func TestPVSSDeleteFail(test *testing.T) {
 	var E []*PubVerShare // good encrypted shares
 	var D []*PubVerShare // good decrypted shares
 
-	globalChallenge, err := computeGlobalChallenge(suite, n, pubPoly, encShares)
+	globalChallenge, err := computeGlobalChallenge(suite, H, X, pubPoly, encShares)
 	require.NoError(test, err)
 
 	for i := 0; i < n; i++ {
@@ -266,11 +266,11 @@ func TestPVSSBatch(test *testing.T) {
 	Z3 := []*PubVerShare{E0[3], E1[3], E2[3]}
 
 	globalChallenges := make([]kyber.Scalar, 3)
-	globalChallenges[0], err = computeGlobalChallenge(suite, n, p0, e0)
+	globalChallenges[0], err = computeGlobalChallenge(suite, H, X, p0, e0)
 	require.NoError(test, err)
-	globalChallenges[1], err = computeGlobalChallenge(suite, n, p1, e1)
+	globalChallenges[1], err = computeGlobalChallenge(suite, H, X, p1, e1)
 	require.NoError(test, err)
-	globalChallenges[2], err = computeGlobalChallenge(suite, n, p2, e2)
+	globalChallenges[2], err = computeGlobalChallenge(suite, H, X, p2, e2)
 	require.NoError(test, err)
 
 	// (2) Share batch decryption (trustees)
@@ -13,6 +13,7 @@ import (
 
 	"go.dedis.ch/kyber/v4"
 	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/share/hook"
 	"go.dedis.ch/kyber/v4/sign/schnorr"
 	"go.dedis.ch/protobuf"
 )
@@ -282,6 +283,12 @@ func (d *Dealer) SetTimeout() {
 	d.Aggregator.timeout = true
 }
 
+// SetHook sets the hook receiving the complaints and the outcome of the
+// justifications processed by the dealer.
+func (d *Dealer) SetHook(h hook.Hook) {
+	d.Aggregator.hook = h
+}
+
 // PrivatePoly returns the private polynomial used to generate the deal. This
 // private polynomial can be saved and then later on used to generate new
 // shares.  This information SHOULD STAY PRIVATE and thus MUST never be given
@@ -377,6 +384,7 @@ func (v *Verifier) ProcessEncryptedDeal(e *EncryptedDeal) (*Response, error) {
 	if errors.Is(err, errDealAlreadyProcessed) {
 		return nil, err
 	}
+	v.Aggregator.reportValidity(hook.KindDeal, r.Index, err)
 
 	if r.Signature, err = schnorr.Sign(v.suite, v.longterm, r.Hash(v.suite)); err != nil {
 		return nil, err
@@ -496,6 +504,12 @@ func (v *Verifier) SetTimeout() {
 	v.Aggregator.timeout = true
 }
 
+// SetHook sets the hook receiving the validity of the deal, the complaints and
+// the outcome of the justifications processed by the verifier.
+func (v *Verifier) SetHook(h hook.Hook) {
+	v.Aggregator.hook = h
+}
+
 // UnsafeSetResponseDKG is an UNSAFE bypass method to allow DKG to use VSS
 // that works on basis of approval only.
 func (v *Verifier) UnsafeSetResponseDKG(idx uint32, approval bool) {
@@ -523,6 +537,7 @@ type Aggregator struct {
 	t         int
 	badDealer bool
 	timeout   bool
+	hook      hook.Hook
 }
 
 func newAggregator(
@@ -630,7 +645,13 @@ func (a *Aggregator) verifyResponse(r *Response) error {
 		return err
 	}
 
-	return a.addResponse(r)
+	if err := a.addResponse(r); err != nil {
+		return err
+	}
+	if r.StatusApproved == StatusComplaint {
+		hook.Report(a.hook, hook.ProtocolPedersenVSS, hook.Event{Kind: hook.KindComplaint, Holder: r.Index})
+	}
+	return nil
 }
 
 func (a *Aggregator) verifyJustification(j *Justification) error {
@@ -648,12 +669,24 @@ func (a *Aggregator) verifyJustification(j *Justification) error {
 	if err := a.VerifyDeal(j.Deal, false); err != nil {
 		// if one justification is bad, then flag the dealer as malicious
 		a.badDealer = true
+		a.reportValidity(hook.KindJustification, j.Index, err)
 		return err
 	}
 	r.StatusApproved = StatusApproval
+	a.reportValidity(hook.KindJustification, j.Index, nil)
 	return nil
 }
 
+// reportValidity reports the validity of a deal or a justification for the
+// verifier.
+func (a *Aggregator) reportValidity(kind hook.Kind, holder uint32, err error) {
+	e := hook.Event{Kind: kind, Holder: holder, Valid: err == nil}
+	if err != nil {
+		e.Reason = err.Error()
+	}
+	hook.Report(a.hook, hook.ProtocolPedersenVSS, e)
+}
+
 func (a *Aggregator) addResponse(r *Response) error {
 	if _, ok := findPub(a.verifiers, r.Index); !ok {
 		return errors.New("vss: index out of bounds in Complaint")
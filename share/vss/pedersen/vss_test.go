@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/kyber/v4"
 	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/share/hook"
 	"go.dedis.ch/kyber/v4/sign/schnorr"
 	"go.dedis.ch/kyber/v4/xof/blake2xb"
 	"go.dedis.ch/protobuf"
@@ -629,3 +630,48 @@ func randomBytes(n int) []byte {
 	}
 	return buff
 }
+
+func TestVSSHook(t *testing.T) {
+	dealer, verifiers := genAll()
+	var dealerEvents, verifierEvents []hook.Event
+	dealer.SetHook(hook.Func(func(e hook.Event) { dealerEvents = append(dealerEvents, e) }))
+	v := verifiers[0]
+	v.SetHook(hook.Func(func(e hook.Event) { verifierEvents = append(verifierEvents, e) }))
+
+	// invalid deal, complained about and justified
+	d := dealer.deals[0]
+	goodV := d.SecShare.V
+	d.SecShare.V = suite.Scalar().Pick(rng)
+	encD, _ := dealer.EncryptedDeal(0)
+	resp, err := v.ProcessEncryptedDeal(encD)
+	require.NoError(t, err)
+	d.SecShare.V = goodV
+	j, err := dealer.ProcessResponse(resp)
+	require.NoError(t, err)
+	require.NoError(t, v.ProcessJustification(j))
+
+	require.Equal(t, []hook.Event{
+		{Kind: hook.KindComplaint, Protocol: hook.ProtocolPedersenVSS},
+	}, dealerEvents)
+	require.Len(t, verifierEvents, 2)
+	require.Equal(t, hook.KindDeal, verifierEvents[0].Kind)
+	require.Equal(t, hook.ProtocolPedersenVSS, verifierEvents[0].Protocol)
+	require.False(t, verifierEvents[0].Valid)
+	require.NotEmpty(t, verifierEvents[0].Reason)
+	require.Equal(t, hook.Event{
+		Kind:     hook.KindJustification,
+		Protocol: hook.ProtocolPedersenVSS,
+		Valid:    true,
+	}, verifierEvents[1])
+
+	// valid deal
+	verifierEvents = nil
+	v = verifiers[1]
+	v.SetHook(hook.Func(func(e hook.Event) { verifierEvents = append(verifierEvents, e) }))
+	encD, _ = dealer.EncryptedDeal(1)
+	_, err = v.ProcessEncryptedDeal(encD)
+	require.NoError(t, err)
+	require.Equal(t, []hook.Event{
+		{Kind: hook.KindDeal, Protocol: hook.ProtocolPedersenVSS, Holder: 1, Valid: true},
+	}, verifierEvents)
+}
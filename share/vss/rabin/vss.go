@@ -39,6 +39,7 @@ import (
 
 	"go.dedis.ch/kyber/v4"
 	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/share/hook"
 	"go.dedis.ch/kyber/v4/sign/schnorr"
 	"go.dedis.ch/protobuf"
 )
@@ -312,6 +313,12 @@ func (d *Dealer) SetTimeout() {
 	d.aggregator.cleanVerifiers()
 }
 
+// SetHook sets the hook receiving the complaints and the outcome of the
+// justifications processed by the dealer.
+func (d *Dealer) SetHook(h hook.Hook) {
+	d.aggregator.hook = h
+}
+
 // Verifier receives a Deal from a Dealer, can reply with a Complaint, and can
 // collaborate with other Verifiers to reconstruct a secret.
 type Verifier struct {
@@ -322,6 +329,7 @@ type Verifier struct {
 	index       int
 	verifiers   []kyber.Point
 	hkdfContext []byte
+	hook        hook.Hook
 	*aggregator
 }
 
@@ -393,6 +401,7 @@ func (v *Verifier) ProcessEncryptedDeal(e *EncryptedDeal) (*Response, error) {
 
 	if v.aggregator == nil {
 		v.aggregator = newAggregator(v.suite, v.dealer, v.verifiers, d.Commitments, t, d.SessionID)
+		v.aggregator.hook = v.hook
 	}
 
 	r := &Response{
@@ -407,6 +416,7 @@ func (v *Verifier) ProcessEncryptedDeal(e *EncryptedDeal) (*Response, error) {
 	if errors.Is(err, errDealAlreadyProcessed) {
 		return nil, err
 	}
+	v.aggregator.reportValidity(hook.KindDeal, r.Index, err)
 
 	if r.Signature, err = schnorr.Sign(v.suite, v.longterm, r.Hash(v.suite)); err != nil {
 		return nil, err
@@ -510,6 +520,15 @@ func (v *Verifier) SetTimeout() {
 	v.aggregator.cleanVerifiers()
 }
 
+// SetHook sets the hook receiving the validity of the deal, the complaints and
+// the outcome of the justifications processed by the verifier.
+func (v *Verifier) SetHook(h hook.Hook) {
+	v.hook = h
+	if v.aggregator != nil {
+		v.aggregator.hook = h
+	}
+}
+
 // aggregator is used to collect all deals, and responses for one protocol run.
 // It brings common functionalities for both Dealer and Verifier structs.
 type aggregator struct {
@@ -523,6 +542,7 @@ type aggregator struct {
 	deal      *Deal
 	t         int
 	badDealer bool
+	hook      hook.Hook
 }
 
 func newAggregator(
@@ -620,7 +640,13 @@ func (a *aggregator) verifyResponse(r *Response) error {
 		return err
 	}
 
-	return a.addResponse(r)
+	if err := a.addResponse(r); err != nil {
+		return err
+	}
+	if !r.Approved {
+		hook.Report(a.hook, hook.ProtocolRabinVSS, hook.Event{Kind: hook.KindComplaint, Holder: r.Index})
+	}
+	return nil
 }
 
 func (a *aggregator) verifyJustification(j *Justification) error {
@@ -638,12 +664,24 @@ func (a *aggregator) verifyJustification(j *Justification) error {
 	if err := a.VerifyDeal(j.Deal, false); err != nil {
 		// if one response is bad, flag the dealer as malicious
 		a.badDealer = true
+		a.reportValidity(hook.KindJustification, j.Index, err)
 		return err
 	}
 	r.Approved = true
+	a.reportValidity(hook.KindJustification, j.Index, nil)
 	return nil
 }
 
+// reportValidity reports the validity of a deal or a justification for the
+// verifier.
+func (a *aggregator) reportValidity(kind hook.Kind, holder uint32, err error) {
+	e := hook.Event{Kind: kind, Holder: holder, Valid: err == nil}
+	if err != nil {
+		e.Reason = err.Error()
+	}
+	hook.Report(a.hook, hook.ProtocolRabinVSS, e)
+}
+
 func (a *aggregator) addResponse(r *Response) error {
 	if _, ok := findPub(a.verifiers, r.Index); !ok {
 		return errors.New("vss: index out of bounds in Complaint")
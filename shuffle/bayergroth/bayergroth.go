@@ -0,0 +1,518 @@
+// Package bayergroth implements the shuffle argument of Bayer and Groth,
+// "Efficient Zero-Knowledge Argument for Correctness of a Shuffle"
+// (Eurocrypt 2012, http://www0.cs.ucl.ac.uk/staff/J.Groth/MinimalShuffle.pdf).
+//
+// It proves that a list of ElGamal ciphertexts is a permutation and
+// re-encryption of another one, for the same (G, H, X, Y) inputs as the Neff
+// shuffle of the shuffle package: the i-th ciphertext is the pair
+// (X[i], Y[i]), and re-encrypting it with beta gives
+// (X[i] + beta*G, Y[i] + beta*H).
+//
+// The k ciphertexts are arranged in a m x n matrix with m and n close to
+// sqrt(k), so that the proof holds O(sqrt(k)) group elements and scalars.
+// The verifier mostly computes a few multi-exponentiations of size k. The
+// prover does O(m*k) constant time exponentiations.
+//
+// The argument is made non-interactive with the Fiat-Shamir heuristic, using
+// a transcript of the proof/transcript package that is bound to the whole
+// statement. The sequences variant shuffles ciphertexts made of several
+// ElGamal pairs, as the SequencesShuffle function of the shuffle package.
+package bayergroth
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/proof/transcript"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// Suite wraps the functionalities needed by the bayergroth package.
+type Suite interface {
+	kyber.Group
+	kyber.XOFFactory
+}
+
+var ErrInvalidProof = errors.New("invalid proof")
+var ErrInvalidParameters = errors.New("invalid parameters")
+
+// Proof is a Bayer-Groth shuffle argument.
+type Proof struct {
+	CA       []kyber.Point // commitments to the permutation, one per row
+	CB       []kyber.Point // commitments to the permuted challenge powers
+	Product  *ProductProof
+	MultiExp *MultiExpProof
+}
+
+// Shuffle randomly shuffles and re-encrypts the ElGamal pairs (X[i], Y[i])
+// and proves the correctness of the shuffle. It returns the shuffled pairs
+// (Xbar[i], Ybar[i]) and the proof. If G or H is nil, the standard base
+// point is used.
+func Shuffle(suite Suite, G, H kyber.Point, X, Y []kyber.Point, rand cipher.Stream) (
+	Xbar, Ybar []kyber.Point, prf *Proof, err error) {
+	XX, YY, prf, err := SequencesShuffle(suite, G, H, [][]kyber.Point{X}, [][]kyber.Point{Y}, rand)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return XX[0], YY[0], prf, nil
+}
+
+// Prove proves that (Xbar[i], Ybar[i]) is the re-encryption of the pair
+// (X[pi[i]], Y[pi[i]]) with beta[pi[i]].
+func Prove(suite Suite, G, H kyber.Point, X, Y, Xbar, Ybar []kyber.Point,
+	pi []int, beta []kyber.Scalar, rand cipher.Stream) (*Proof, error) {
+	return SequencesProve(suite, G, H,
+		[][]kyber.Point{X}, [][]kyber.Point{Y}, [][]kyber.Point{Xbar}, [][]kyber.Point{Ybar},
+		pi, [][]kyber.Scalar{beta}, rand)
+}
+
+// Verify checks that (Xbar, Ybar) is a shuffle of (X, Y).
+func Verify(suite Suite, G, H kyber.Point, X, Y, Xbar, Ybar []kyber.Point, prf *Proof) error {
+	return SequencesVerify(suite, G, H,
+		[][]kyber.Point{X}, [][]kyber.Point{Y}, [][]kyber.Point{Xbar}, [][]kyber.Point{Ybar}, prf)
+}
+
+// SequencesShuffle shuffles sequences of ElGamal pairs with the same
+// permutation, and proves the correctness of the shuffle. As for the
+// SequencesShuffle function of the shuffle package, X and Y are indexed by
+// [position in the sequence][sequence]: the i-th ciphertext is made of the
+// pairs (X[j][i], Y[j][i]) for all j, and each pair is re-encrypted with its
+// own randomness.
+func SequencesShuffle(suite Suite, G, H kyber.Point, X, Y [][]kyber.Point, rand cipher.Stream) (
+	Xbar, Ybar [][]kyber.Point, prf *Proof, err error) {
+	if err := checkDimensions(X, Y); err != nil {
+		return nil, nil, nil, err
+	}
+	G, H = bases(suite, G, H)
+	w, k := len(X), len(X[0])
+
+	// Fisher–Yates shuffle
+	pi := make([]int, k)
+	for i := range pi {
+		pi[i] = i
+	}
+	for i := k - 1; i > 0; i-- {
+		j := int(random.Int(big.NewInt(int64(i+1)), rand).Int64())
+		pi[i], pi[j] = pi[j], pi[i]
+	}
+
+	beta := make([][]kyber.Scalar, w)
+	Xbar = make([][]kyber.Point, w)
+	Ybar = make([][]kyber.Point, w)
+	for j := 0; j < w; j++ {
+		beta[j] = randomScalars(suite, k, rand)
+		Xbar[j] = make([]kyber.Point, k)
+		Ybar[j] = make([]kyber.Point, k)
+		for i := 0; i < k; i++ {
+			Xbar[j][i] = suite.Point().Mul(beta[j][pi[i]], G)
+			Xbar[j][i].Add(Xbar[j][i], X[j][pi[i]])
+			Ybar[j][i] = suite.Point().Mul(beta[j][pi[i]], H)
+			Ybar[j][i].Add(Ybar[j][i], Y[j][pi[i]])
+		}
+	}
+
+	prf, err = SequencesProve(suite, G, H, X, Y, Xbar, Ybar, pi, beta, rand)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return Xbar, Ybar, prf, nil
+}
+
+// SequencesProve proves that for all j, (Xbar[j][i], Ybar[j][i]) is the
+// re-encryption of (X[j][pi[i]], Y[j][pi[i]]) with beta[j][pi[i]].
+func SequencesProve(suite Suite, G, H kyber.Point, X, Y, Xbar, Ybar [][]kyber.Point,
+	pi []int, beta [][]kyber.Scalar, rand cipher.Stream) (*Proof, error) {
+	st, err := newStatement(suite, G, H, X, Y, Xbar, Ybar)
+	if err != nil {
+		return nil, err
+	}
+	if len(pi) != st.k || len(beta) != st.w {
+		return nil, fmt.Errorf("permutation or randomness of wrong size: %w", ErrInvalidParameters)
+	}
+	perm := make([]int, st.m*st.n)
+	for i := range perm {
+		perm[i] = i
+	}
+	seen := make([]bool, st.k)
+	for i, p := range pi {
+		if p < 0 || p >= st.k || seen[p] {
+			return nil, fmt.Errorf("pi is not a permutation: %w", ErrInvalidParameters)
+		}
+		seen[p] = true
+		perm[i] = p
+	}
+	for _, b := range beta {
+		if len(b) != st.k {
+			return nil, fmt.Errorf("randomness of wrong size: %w", ErrInvalidParameters)
+		}
+	}
+	return st.prove(suite, perm, beta, rand)
+}
+
+// SequencesVerify checks that (Xbar, Ybar) is a shuffle of the sequences
+// (X, Y).
+func SequencesVerify(suite Suite, G, H kyber.Point, X, Y, Xbar, Ybar [][]kyber.Point, prf *Proof) error {
+	st, err := newStatement(suite, G, H, X, Y, Xbar, Ybar)
+	if err != nil {
+		return err
+	}
+	return st.verify(suite, prf)
+}
+
+// statement holds the input and output ciphertexts of a shuffle, padded with
+// null ciphertexts to fill a m x n matrix.
+type statement struct {
+	G, H       kyber.Point
+	X, Y       [][]kyber.Point
+	Xbar, Ybar [][]kyber.Point
+	C, Cbar    []Ciphertext
+	k, w, m, n int
+	ck         *commitKey
+}
+
+func newStatement(suite Suite, G, H kyber.Point, X, Y, Xbar, Ybar [][]kyber.Point) (*statement, error) {
+	if err := checkDimensions(X, Y); err != nil {
+		return nil, err
+	}
+	if err := checkDimensions(Xbar, Ybar); err != nil {
+		return nil, err
+	}
+	if len(Xbar) != len(X) || len(Xbar[0]) != len(X[0]) {
+		return nil, fmt.Errorf("input and output of different sizes: %w", ErrInvalidParameters)
+	}
+	G, H = bases(suite, G, H)
+	st := &statement{G: G, H: H, X: X, Y: Y, Xbar: Xbar, Ybar: Ybar, w: len(X), k: len(X[0])}
+	st.m, st.n = dimensions(st.k)
+	st.ck = newCommitKey(suite, st.n)
+	st.C = ciphertexts(suite, X, Y, st.m*st.n)
+	st.Cbar = ciphertexts(suite, Xbar, Ybar, st.m*st.n)
+	return st, nil
+}
+
+// dimensions returns the number of rows m and columns n of the matrix of k
+// ciphertexts. There are at least two columns, as required by the single
+// value product argument.
+func dimensions(k int) (m, n int) {
+	n = 2
+	for n*n < k {
+		n++
+	}
+	m = (k + n - 1) / n
+	return m, n
+}
+
+func (st *statement) transcript(suite Suite) (*transcript.Transcript, error) {
+	tr := transcript.New(suite, "bayer-groth shuffle")
+	tr.AppendUint64("w", uint64(st.w))
+	tr.AppendUint64("k", uint64(st.k))
+	if err := tr.AppendPoints("G", st.G); err != nil {
+		return nil, err
+	}
+	if err := tr.AppendPoints("H", st.H); err != nil {
+		return nil, err
+	}
+	for _, in := range []struct {
+		label  string
+		points [][]kyber.Point
+	}{{"X", st.X}, {"Y", st.Y}, {"Xbar", st.Xbar}, {"Ybar", st.Ybar}} {
+		for _, seq := range in.points {
+			if err := tr.AppendPoints(in.label, seq...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tr, nil
+}
+
+// prove runs the shuffle argument of section 5 of the paper. perm is the
+// permutation padded to the matrix size.
+func (st *statement) prove(suite Suite, perm []int, beta [][]kyber.Scalar, rand cipher.Stream) (*Proof, error) {
+	N := st.m * st.n
+	tr, err := st.transcript(suite)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit to the permutation
+	a := make([]kyber.Scalar, N)
+	for i, p := range perm {
+		a[i] = suite.Scalar().SetInt64(int64(p) + 1)
+	}
+	A := matrix(a, st.n)
+	r := randomScalars(suite, st.m, rand)
+	prf := &Proof{CA: st.ck.commitRows(suite, A, r)}
+	if err := tr.AppendPoints("CA", prf.CA...); err != nil {
+		return nil, err
+	}
+	x := tr.ChallengeScalar("x")
+
+	// Commit to the permuted powers of x
+	xPows := powers(suite, x, N+1)
+	b := make([]kyber.Scalar, N)
+	for i, p := range perm {
+		b[i] = xPows[p+1]
+	}
+	B := matrix(b, st.n)
+	s := randomScalars(suite, st.m, rand)
+	prf.CB = st.ck.commitRows(suite, B, s)
+	if err := tr.AppendPoints("CB", prf.CB...); err != nil {
+		return nil, err
+	}
+	y := tr.ChallengeScalar("y")
+	z := tr.ChallengeScalar("z")
+
+	// Prove that the product of y*a_i + b_i - z is the one of y*i + x^i - z,
+	// which implies that a and b are the same permutation of the indices
+	// and the powers of x
+	cD := st.productCommitments(suite, prf.CA, prf.CB, y, z)
+	D := make([][]kyber.Scalar, st.m)
+	t := make([]kyber.Scalar, st.m)
+	for i := range D {
+		D[i] = make([]kyber.Scalar, st.n)
+		for j := range D[i] {
+			D[i][j] = suite.Scalar().Mul(y, A[i][j])
+			D[i][j].Add(D[i][j], B[i][j]).Sub(D[i][j], z)
+		}
+		t[i] = suite.Scalar().Mul(y, r[i])
+		t[i].Add(t[i], s[i])
+	}
+	prf.Product, err = proveProduct(suite, st.ck, tr, cD, D, t, rand)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prove that sum x^i*C_i = sum b_i*Cbar_i - Enc(0; rho), where rho is
+	// the combination of the re-encryption randomness
+	rho := make([]kyber.Scalar, st.w)
+	for l := range rho {
+		rho[l] = suite.Scalar().Zero()
+		for i := 0; i < st.k; i++ {
+			rho[l].Sub(rho[l], suite.Scalar().Mul(b[i], beta[l][perm[i]]))
+		}
+	}
+	prf.MultiExp, err = proveMultiExp(suite, st, tr, st.target(suite, xPows), prf.CB, B, s, rho, rand)
+	if err != nil {
+		return nil, err
+	}
+	return prf, nil
+}
+
+func (st *statement) verify(suite Suite, prf *Proof) error {
+	if prf == nil || prf.Product == nil || prf.MultiExp == nil ||
+		!validPoints(prf.CA, st.m) || !validPoints(prf.CB, st.m) {
+		return fmt.Errorf("malformed proof: %w", ErrInvalidProof)
+	}
+	N := st.m * st.n
+	tr, err := st.transcript(suite)
+	if err != nil {
+		return err
+	}
+	if err := tr.AppendPoints("CA", prf.CA...); err != nil {
+		return err
+	}
+	x := tr.ChallengeScalar("x")
+	xPows := powers(suite, x, N+1)
+	if err := tr.AppendPoints("CB", prf.CB...); err != nil {
+		return err
+	}
+	y := tr.ChallengeScalar("y")
+	z := tr.ChallengeScalar("z")
+
+	cD := st.productCommitments(suite, prf.CA, prf.CB, y, z)
+	prod := suite.Scalar().One()
+	for i := 1; i <= N; i++ {
+		f := suite.Scalar().Mul(y, suite.Scalar().SetInt64(int64(i)))
+		f.Add(f, xPows[i]).Sub(f, z)
+		prod.Mul(prod, f)
+	}
+	if err := prf.Product.verify(suite, st.ck, tr, cD, prod); err != nil {
+		return err
+	}
+	return prf.MultiExp.verify(suite, st, tr, st.target(suite, xPows), prf.CB)
+}
+
+// productCommitments returns the commitments y*CA_i + CB_i + com(-z, ..., -z; 0)
+// to the rows of the matrix y*a + b - z.
+func (st *statement) productCommitments(suite Suite, CA, CB []kyber.Point, y, z kyber.Scalar) []kyber.Point {
+	minusZ := suite.Point().Mul(suite.Scalar().Neg(z), st.ck.sum(suite))
+	cD := make([]kyber.Point, st.m)
+	for i := range cD {
+		cD[i] = suite.Point().Mul(y, CA[i])
+		cD[i].Add(cD[i], CB[i]).Add(cD[i], minusZ)
+	}
+	return cD
+}
+
+// target returns sum x^(i+1)*C_i over the input ciphertexts.
+func (st *statement) target(suite Suite, xPows []kyber.Scalar) Ciphertext {
+	return combinePublic(suite, xPows[1:st.k+1], st.C[:st.k])
+}
+
+// commitKey is the key of the Pedersen vector commitments
+//
+//	com(a; r) = r*H + sum a_i*G_i
+//
+// Its points are derived from the suite XOF, so that their discrete
+// logarithms relative to each other are unknown.
+type commitKey struct {
+	h kyber.Point
+	g []kyber.Point
+}
+
+func newCommitKey(suite Suite, n int) *commitKey {
+	ck := &commitKey{
+		h: suite.Point().Pick(suite.XOF([]byte("bayer-groth commitment key H"))),
+		g: make([]kyber.Point, n),
+	}
+	for i := range ck.g {
+		seed := fmt.Sprintf("bayer-groth commitment key G %d", i)
+		ck.g[i] = suite.Point().Pick(suite.XOF([]byte(seed)))
+	}
+	return ck
+}
+
+// commit returns com(a; r) with constant time multiplications, since a and
+// r are secret. a can be shorter than the key.
+func (ck *commitKey) commit(suite Suite, a []kyber.Scalar, r kyber.Scalar) kyber.Point {
+	res := suite.Point().Mul(r, ck.h)
+	tmp := suite.Point()
+	for i := range a {
+		res.Add(res, tmp.Mul(a[i], ck.g[i]))
+	}
+	return res
+}
+
+func (ck *commitKey) commitRows(suite Suite, A [][]kyber.Scalar, r []kyber.Scalar) []kyber.Point {
+	res := make([]kyber.Point, len(A))
+	for i := range A {
+		res[i] = ck.commit(suite, A[i], r[i])
+	}
+	return res
+}
+
+// sum returns com(1, ..., 1; 0).
+func (ck *commitKey) sum(suite Suite) kyber.Point {
+	res := suite.Point().Null()
+	for _, g := range ck.g {
+		res.Add(res, g)
+	}
+	return res
+}
+
+// opens returns whether sum scalars_i*points_i is com(a; r).
+func (ck *commitKey) opens(suite Suite, scalars []kyber.Scalar, points []kyber.Point,
+	a []kyber.Scalar, r kyber.Scalar) bool {
+	scalars = append([]kyber.Scalar{}, scalars...)
+	points = append([]kyber.Point{}, points...)
+	for i := range a {
+		scalars = append(scalars, suite.Scalar().Neg(a[i]))
+		points = append(points, ck.g[i])
+	}
+	scalars = append(scalars, suite.Scalar().Neg(r))
+	points = append(points, ck.h)
+	return isNull(suite, scalars, points)
+}
+
+func checkDimensions(X, Y [][]kyber.Point) error {
+	if len(X) == 0 || len(X[0]) == 0 {
+		return fmt.Errorf("no ciphertext: %w", ErrInvalidParameters)
+	}
+	if len(Y) != len(X) {
+		return fmt.Errorf("X and Y of different sizes: %w", ErrInvalidParameters)
+	}
+	k := len(X[0])
+	for j := range X {
+		if !validPoints(X[j], k) || !validPoints(Y[j], k) {
+			return fmt.Errorf("sequences of different sizes or nil points: %w", ErrInvalidParameters)
+		}
+	}
+	return nil
+}
+
+func bases(suite Suite, G, H kyber.Point) (kyber.Point, kyber.Point) {
+	if G == nil {
+		G = suite.Point().Base()
+	}
+	if H == nil {
+		H = suite.Point().Base()
+	}
+	return G, H
+}
+
+// validPoints returns whether ps holds n non-nil points.
+func validPoints(ps []kyber.Point, n int) bool {
+	if len(ps) != n {
+		return false
+	}
+	for _, p := range ps {
+		if p == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// validScalars returns whether ss holds n non-nil scalars.
+func validScalars(ss []kyber.Scalar, n int) bool {
+	if len(ss) != n {
+		return false
+	}
+	for _, s := range ss {
+		if s == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func randomScalars(suite Suite, n int, rand cipher.Stream) []kyber.Scalar {
+	res := make([]kyber.Scalar, n)
+	for i := range res {
+		res[i] = suite.Scalar().Pick(rand)
+	}
+	return res
+}
+
+// matrix splits v in rows of n elements.
+func matrix(v []kyber.Scalar, n int) [][]kyber.Scalar {
+	res := make([][]kyber.Scalar, len(v)/n)
+	for i := range res {
+		res[i] = v[i*n : (i+1)*n]
+	}
+	return res
+}
+
+// powers returns [1, x, x^2, ..., x^(n-1)].
+func powers(suite Suite, x kyber.Scalar, n int) []kyber.Scalar {
+	res := make([]kyber.Scalar, n)
+	cur := suite.Scalar().One()
+	for i := range res {
+		res[i] = cur.Clone()
+		cur.Mul(cur, x)
+	}
+	return res
+}
+
+// linear returns sum c_i*v_i over the vectors v_i.
+func linear(suite Suite, c []kyber.Scalar, v [][]kyber.Scalar) []kyber.Scalar {
+	res := make([]kyber.Scalar, len(v[0]))
+	for j := range res {
+		res[j] = suite.Scalar().Zero()
+		for i := range v {
+			res[j].Add(res[j], suite.Scalar().Mul(c[i], v[i][j]))
+		}
+	}
+	return res
+}
+
+// dot returns sum c_i*s_i.
+func dot(suite Suite, c, s []kyber.Scalar) kyber.Scalar {
+	res := suite.Scalar().Zero()
+	for i := range c {
+		res.Add(res, suite.Scalar().Mul(c[i], s[i]))
+	}
+	return res
+}
@@ -0,0 +1,155 @@
+package bayergroth
+
+import (
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/xof/blake2xb"
+)
+
+func encryptPairs(suite Suite, H kyber.Point, k int, rand cipher.Stream) (X, Y []kyber.Point) {
+	X = make([]kyber.Point, k)
+	Y = make([]kyber.Point, k)
+	for i := 0; i < k; i++ {
+		r := suite.Scalar().Pick(rand)
+		X[i] = suite.Point().Mul(r, nil)
+		Y[i] = suite.Point().Mul(r, H)
+		Y[i].Add(Y[i], suite.Point().Pick(rand))
+	}
+	return X, Y
+}
+
+func TestShuffle(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	rand := blake2xb.New([]byte("bayer-groth test"))
+	H := suite.Point().Mul(suite.Scalar().Pick(rand), nil)
+
+	for _, k := range []int{1, 2, 3, 4, 7, 9, 16, 25} {
+		X, Y := encryptPairs(suite, H, k, rand)
+		Xbar, Ybar, prf, err := Shuffle(suite, nil, H, X, Y, rand)
+		require.NoError(t, err)
+		require.NoError(t, Verify(suite, nil, H, X, Y, Xbar, Ybar, prf), "k=%d", k)
+	}
+}
+
+func TestShuffleProofSize(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	rand := blake2xb.New([]byte("bayer-groth size"))
+	H := suite.Point().Mul(suite.Scalar().Pick(rand), nil)
+
+	k := 100
+	X, Y := encryptPairs(suite, H, k, rand)
+	Xbar, Ybar, prf, err := Shuffle(suite, nil, H, X, Y, rand)
+	require.NoError(t, err)
+	require.NoError(t, Verify(suite, nil, H, X, Y, Xbar, Ybar, prf))
+
+	m, n := dimensions(k)
+	require.Equal(t, 10, m)
+	require.Equal(t, 10, n)
+	require.Len(t, prf.CA, m)
+	require.Len(t, prf.MultiExp.A, n)
+	require.Len(t, prf.MultiExp.E, 2*m-1)
+	require.Len(t, prf.Product.Hadamard.Zero.CD, 2*m)
+}
+
+func TestShuffleInvalid(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	rand := blake2xb.New([]byte("bayer-groth invalid"))
+	H := suite.Point().Mul(suite.Scalar().Pick(rand), nil)
+
+	k := 6
+	X, Y := encryptPairs(suite, H, k, rand)
+	Xbar, Ybar, prf, err := Shuffle(suite, nil, H, X, Y, rand)
+	require.NoError(t, err)
+
+	// swapped outputs
+	Xbar[0], Xbar[1] = Xbar[1], Xbar[0]
+	require.ErrorIs(t, Verify(suite, nil, H, X, Y, Xbar, Ybar, prf), ErrInvalidProof)
+	Xbar[0], Xbar[1] = Xbar[1], Xbar[0]
+
+	// other inputs
+	X2, Y2 := encryptPairs(suite, H, k, rand)
+	require.ErrorIs(t, Verify(suite, nil, H, X2, Y2, Xbar, Ybar, prf), ErrInvalidProof)
+
+	// other bases
+	require.ErrorIs(t, Verify(suite, H, nil, X, Y, Xbar, Ybar, prf), ErrInvalidProof)
+
+	// replaced ciphertext: the prover re-encrypts the first input twice
+	pi := []int{0, 0, 2, 3, 4, 5}
+	beta := randomScalars(suite, k, rand)
+	Xbad := make([]kyber.Point, k)
+	Ybad := make([]kyber.Point, k)
+	for i := range Xbad {
+		Xbad[i] = suite.Point().Add(X[pi[i]], suite.Point().Mul(beta[pi[i]], nil))
+		Ybad[i] = suite.Point().Add(Y[pi[i]], suite.Point().Mul(beta[pi[i]], H))
+	}
+	_, err = Prove(suite, nil, H, X, Y, Xbad, Ybad, pi, beta, rand)
+	require.ErrorIs(t, err, ErrInvalidParameters)
+	// a cheating prover skipping the checks on the permutation
+	st, err := newStatement(suite, nil, H, [][]kyber.Point{X}, [][]kyber.Point{Y},
+		[][]kyber.Point{Xbad}, [][]kyber.Point{Ybad})
+	require.NoError(t, err)
+	bad, err := st.prove(suite, pi, [][]kyber.Scalar{beta}, rand)
+	require.NoError(t, err)
+	require.ErrorIs(t, Verify(suite, nil, H, X, Y, Xbad, Ybad, bad), ErrInvalidProof)
+
+	// malformed proofs
+	require.ErrorIs(t, Verify(suite, nil, H, X, Y, Xbar, Ybar, nil), ErrInvalidProof)
+	bad = &Proof{CA: prf.CA[1:], CB: prf.CB, Product: prf.Product, MultiExp: prf.MultiExp}
+	require.ErrorIs(t, Verify(suite, nil, H, X, Y, Xbar, Ybar, bad), ErrInvalidProof)
+	me := *prf.MultiExp
+	me.A = me.A[1:]
+	bad = &Proof{CA: prf.CA, CB: prf.CB, Product: prf.Product, MultiExp: &me}
+	require.ErrorIs(t, Verify(suite, nil, H, X, Y, Xbar, Ybar, bad), ErrInvalidProof)
+	me = *prf.MultiExp
+	me.B = suite.Scalar().Add(me.B, suite.Scalar().One())
+	bad = &Proof{CA: prf.CA, CB: prf.CB, Product: prf.Product, MultiExp: &me}
+	require.ErrorIs(t, Verify(suite, nil, H, X, Y, Xbar, Ybar, bad), ErrInvalidProof)
+}
+
+func TestSequencesShuffle(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	rand := blake2xb.New([]byte("bayer-groth sequences"))
+	H := suite.Point().Mul(suite.Scalar().Pick(rand), nil)
+
+	w, k := 3, 5
+	X := make([][]kyber.Point, w)
+	Y := make([][]kyber.Point, w)
+	for j := range X {
+		X[j], Y[j] = encryptPairs(suite, H, k, rand)
+	}
+	Xbar, Ybar, prf, err := SequencesShuffle(suite, nil, H, X, Y, rand)
+	require.NoError(t, err)
+	require.NoError(t, SequencesVerify(suite, nil, H, X, Y, Xbar, Ybar, prf))
+
+	// the same permutation must be applied to all the sequences
+	Xbar[1][0], Xbar[1][1] = Xbar[1][1], Xbar[1][0]
+	Ybar[1][0], Ybar[1][1] = Ybar[1][1], Ybar[1][0]
+	require.ErrorIs(t, SequencesVerify(suite, nil, H, X, Y, Xbar, Ybar, prf), ErrInvalidProof)
+
+	_, _, _, err = SequencesShuffle(suite, nil, H, X, Y[:2], rand)
+	require.ErrorIs(t, err, ErrInvalidParameters)
+}
+
+func BenchmarkShuffle(b *testing.B) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	rand := blake2xb.New(nil)
+	H := suite.Point().Mul(suite.Scalar().Pick(rand), nil)
+	X, Y := encryptPairs(suite, H, 100, rand)
+	Xbar, Ybar, prf, err := Shuffle(suite, nil, H, X, Y, rand)
+	require.NoError(b, err)
+
+	b.Run("prove", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _, _ = Shuffle(suite, nil, H, X, Y, rand)
+		}
+	})
+	b.Run("verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = Verify(suite, nil, H, X, Y, Xbar, Ybar, prf)
+		}
+	})
+}
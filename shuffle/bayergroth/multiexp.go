@@ -0,0 +1,247 @@
+package bayergroth
+
+import (
+	"crypto/cipher"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/internal/multiexp"
+	"go.dedis.ch/kyber/v4/proof/transcript"
+)
+
+// Ciphertext is a sequence of ElGamal pairs (X[l], Y[l]) that are shuffled
+// together. It holds a single pair for the Shuffle function.
+type Ciphertext struct {
+	X []kyber.Point
+	Y []kyber.Point
+}
+
+// MultiExpProof shows that a ciphertext C is the re-encryption of
+// sum_(i,j) a_ij*Cbar_ij for a committed matrix a (section 4 of the paper).
+type MultiExpProof struct {
+	CA0 kyber.Point
+	CB  []kyber.Point // commitments to the b_k, except b_m = 0
+	E   []Ciphertext  // the E_k, except E_m = C
+	A   []kyber.Scalar
+	R   kyber.Scalar
+	B   kyber.Scalar
+	S   kyber.Scalar
+	Tau []kyber.Scalar
+}
+
+// proveMultiExp runs the multi-exponentiation argument for the rows of the
+// output ciphertexts, the matrix A committed with r, and the randomness rho
+// such that C = Enc(0; rho) + sum_(i,j) A_ij*Cbar_ij.
+//
+//nolint:funlen
+func proveMultiExp(suite Suite, st *statement, tr *transcript.Transcript, C Ciphertext,
+	cA []kyber.Point, A [][]kyber.Scalar, r []kyber.Scalar, rho []kyber.Scalar,
+	rand cipher.Stream) (*MultiExpProof, error) {
+	m, n := st.m, st.n
+	a0 := randomScalars(suite, n, rand)
+	r0 := suite.Scalar().Pick(rand)
+	As := append([][]kyber.Scalar{a0}, A...)
+	rs := append([]kyber.Scalar{r0}, r...)
+
+	// E_k = Enc(b_k*G; tau_k) + sum_(j-i = k-m) A_j*Cbar_i, with b_m = 0 and
+	// tau_m = rho so that E_m = C
+	b := randomScalars(suite, 2*m, rand)
+	s := randomScalars(suite, 2*m, rand)
+	tau := make([][]kyber.Scalar, 2*m)
+	for k := range tau {
+		tau[k] = randomScalars(suite, st.w, rand)
+	}
+	b[m].Zero()
+	s[m].Zero()
+	tau[m] = rho
+
+	prf := &MultiExpProof{
+		CA0: st.ck.commit(suite, a0, r0),
+		CB:  make([]kyber.Point, 0, 2*m-1),
+		E:   make([]Ciphertext, 0, 2*m-1),
+	}
+	for k := 0; k < 2*m; k++ {
+		if k == m {
+			continue
+		}
+		prf.CB = append(prf.CB, st.ck.commit(suite, b[k:k+1], s[k]))
+		E := st.encrypt(suite, b[k], tau[k])
+		for i := 1; i <= m; i++ {
+			if j := k - m + i; j >= 0 && j <= m {
+				E = E.add(suite, combine(suite, As[j], st.Cbar[(i-1)*n:i*n]))
+			}
+		}
+		prf.E = append(prf.E, E)
+	}
+
+	if err := appendMultiExp(tr, prf); err != nil {
+		return nil, err
+	}
+	xPows := powers(suite, tr.ChallengeScalar("multi-exponentiation x"), 2*m)
+
+	prf.A = linear(suite, xPows, As)
+	prf.R = dot(suite, xPows[:m+1], rs)
+	prf.B = dot(suite, xPows, b)
+	prf.S = dot(suite, xPows, s)
+	prf.Tau = make([]kyber.Scalar, st.w)
+	for l := range prf.Tau {
+		prf.Tau[l] = suite.Scalar().Zero()
+		for k := range tau {
+			prf.Tau[l].Add(prf.Tau[l], suite.Scalar().Mul(xPows[k], tau[k][l]))
+		}
+	}
+	return prf, nil
+}
+
+//nolint:funlen
+func (p *MultiExpProof) verify(suite Suite, st *statement, tr *transcript.Transcript, C Ciphertext,
+	cA []kyber.Point) error {
+	m, n := st.m, st.n
+	if p.CA0 == nil || !validPoints(p.CB, 2*m-1) || len(p.E) != 2*m-1 ||
+		!validScalars(p.A, n) || p.R == nil || p.B == nil || p.S == nil || !validScalars(p.Tau, st.w) {
+		return fmt.Errorf("malformed multi-exponentiation argument: %w", ErrInvalidProof)
+	}
+	for _, E := range p.E {
+		if !validPoints(E.X, st.w) || !validPoints(E.Y, st.w) {
+			return fmt.Errorf("malformed multi-exponentiation argument: %w", ErrInvalidProof)
+		}
+	}
+	if err := appendMultiExp(tr, p); err != nil {
+		return err
+	}
+	xPows := powers(suite, tr.ChallengeScalar("multi-exponentiation x"), 2*m)
+
+	cAs := append([]kyber.Point{p.CA0}, cA...)
+	if !st.ck.opens(suite, xPows[:m+1], cAs, p.A, p.R) {
+		return fmt.Errorf("multi-exponentiation argument: %w", ErrInvalidProof)
+	}
+	// the commitment to b_m = 0 is com(0; 0)
+	kPows := append(append([]kyber.Scalar{}, xPows[:m]...), xPows[m+1:]...)
+	if !st.ck.opens(suite, kPows, p.CB, []kyber.Scalar{p.B}, p.S) {
+		return fmt.Errorf("multi-exponentiation argument: %w", ErrInvalidProof)
+	}
+
+	// sum x^k*E_k - Enc(b*G; tau) - sum_(i,j) x^(m-i)*a_j*Cbar_ij = 0
+	cbarScalars := make([]kyber.Scalar, m*n)
+	for i := 1; i <= m; i++ {
+		for j := 0; j < n; j++ {
+			cbarScalars[(i-1)*n+j] = suite.Scalar().Mul(xPows[m-i], p.A[j])
+			cbarScalars[(i-1)*n+j].Neg(cbarScalars[(i-1)*n+j])
+		}
+	}
+	scalars := append(append(append([]kyber.Scalar{}, kPows...), xPows[m]), cbarScalars...)
+	for l := 0; l < st.w; l++ {
+		pointsX := make([]kyber.Point, 0, len(scalars)+1)
+		pointsY := make([]kyber.Point, 0, len(scalars)+2)
+		for _, E := range p.E {
+			pointsX = append(pointsX, E.X[l])
+			pointsY = append(pointsY, E.Y[l])
+		}
+		pointsX = append(pointsX, C.X[l])
+		pointsY = append(pointsY, C.Y[l])
+		for _, c := range st.Cbar {
+			pointsX = append(pointsX, c.X[l])
+			pointsY = append(pointsY, c.Y[l])
+		}
+		negTau := suite.Scalar().Neg(p.Tau[l])
+		if !isNull(suite, append(scalars, negTau), append(pointsX, st.G)) ||
+			!isNull(suite, append(scalars, negTau, suite.Scalar().Neg(p.B)), append(pointsY, st.H, st.G)) {
+			return fmt.Errorf("multi-exponentiation argument: %w", ErrInvalidProof)
+		}
+	}
+	return nil
+}
+
+func appendMultiExp(tr *transcript.Transcript, p *MultiExpProof) error {
+	if err := tr.AppendPoints("multi-exponentiation CA0", p.CA0); err != nil {
+		return err
+	}
+	if err := tr.AppendPoints("multi-exponentiation CB", p.CB...); err != nil {
+		return err
+	}
+	for _, E := range p.E {
+		if err := tr.AppendPoints("multi-exponentiation EX", E.X...); err != nil {
+			return err
+		}
+		if err := tr.AppendPoints("multi-exponentiation EY", E.Y...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ciphertexts returns the ciphertexts made of the pairs (X[l][i], Y[l][i]),
+// padded with null ciphertexts to size n.
+func ciphertexts(suite Suite, X, Y [][]kyber.Point, n int) []Ciphertext {
+	res := make([]Ciphertext, n)
+	for i := range res {
+		res[i] = Ciphertext{X: make([]kyber.Point, len(X)), Y: make([]kyber.Point, len(X))}
+		for l := range X {
+			if i < len(X[l]) {
+				res[i].X[l], res[i].Y[l] = X[l][i], Y[l][i]
+			} else {
+				res[i].X[l], res[i].Y[l] = suite.Point().Null(), suite.Point().Null()
+			}
+		}
+	}
+	return res
+}
+
+// encrypt returns the encryption of b*G with randomness tau.
+func (st *statement) encrypt(suite Suite, b kyber.Scalar, tau []kyber.Scalar) Ciphertext {
+	c := Ciphertext{X: make([]kyber.Point, len(tau)), Y: make([]kyber.Point, len(tau))}
+	bG := suite.Point().Mul(b, st.G)
+	for l := range tau {
+		c.X[l] = suite.Point().Mul(tau[l], st.G)
+		c.Y[l] = suite.Point().Mul(tau[l], st.H)
+		c.Y[l].Add(c.Y[l], bG)
+	}
+	return c
+}
+
+func (c Ciphertext) add(suite Suite, o Ciphertext) Ciphertext {
+	res := Ciphertext{X: make([]kyber.Point, len(c.X)), Y: make([]kyber.Point, len(c.Y))}
+	for l := range c.X {
+		res.X[l] = suite.Point().Add(c.X[l], o.X[l])
+		res.Y[l] = suite.Point().Add(c.Y[l], o.Y[l])
+	}
+	return res
+}
+
+// combine returns sum a_i*cs_i with constant time multiplications, since a
+// is secret.
+func combine(suite Suite, a []kyber.Scalar, cs []Ciphertext) Ciphertext {
+	w := len(cs[0].X)
+	res := Ciphertext{X: make([]kyber.Point, w), Y: make([]kyber.Point, w)}
+	tmp := suite.Point()
+	for l := 0; l < w; l++ {
+		res.X[l] = suite.Point().Null()
+		res.Y[l] = suite.Point().Null()
+		for i := range a {
+			res.X[l].Add(res.X[l], tmp.Mul(a[i], cs[i].X[l]))
+			res.Y[l].Add(res.Y[l], tmp.Mul(a[i], cs[i].Y[l]))
+		}
+	}
+	return res
+}
+
+// combinePublic returns sum a_i*cs_i for public a.
+func combinePublic(suite Suite, a []kyber.Scalar, cs []Ciphertext) Ciphertext {
+	w := len(cs[0].X)
+	res := Ciphertext{X: make([]kyber.Point, w), Y: make([]kyber.Point, w)}
+	X := make([]kyber.Point, len(cs))
+	Y := make([]kyber.Point, len(cs))
+	for l := 0; l < w; l++ {
+		for i := range cs {
+			X[i], Y[i] = cs[i].X[l], cs[i].Y[l]
+		}
+		res.X[l] = multiexp.MultiExp(suite, a, X)
+		res.Y[l] = multiexp.MultiExp(suite, a, Y)
+	}
+	return res
+}
+
+// isNull returns whether sum scalars_i*points_i is the null point.
+func isNull(suite Suite, scalars []kyber.Scalar, points []kyber.Point) bool {
+	return multiexp.MultiExp(suite, scalars, points).Equal(suite.Point().Null())
+}
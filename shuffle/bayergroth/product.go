@@ -0,0 +1,403 @@
+package bayergroth
+
+import (
+	"crypto/cipher"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/proof/transcript"
+)
+
+// ProductProof shows that the product of all the entries of a matrix
+// committed row by row is a given value (section 5.3 of the paper).
+type ProductProof struct {
+	Cb          kyber.Point    // commitment to the product of the rows, nil for a single row
+	Hadamard    *HadamardProof // nil for a single row
+	SingleValue *SingleValueProof
+}
+
+// HadamardProof shows that a commitment opens to the entry-wise product of
+// the rows of a committed matrix (section 5.1 of the paper).
+type HadamardProof struct {
+	CB   []kyber.Point // commitments to the partial products
+	Zero *ZeroProof
+}
+
+// ZeroProof shows that sum a_i *_y b_i = 0 for committed vectors a_i and b_i,
+// where a *_y b = sum a_j*b_j*y^j (section 5.2 of the paper).
+type ZeroProof struct {
+	CA0 kyber.Point
+	CBm kyber.Point
+	CD  []kyber.Point
+	A   []kyber.Scalar
+	B   []kyber.Scalar
+	R   kyber.Scalar
+	S   kyber.Scalar
+	T   kyber.Scalar
+}
+
+// SingleValueProof shows that the product of the entries of a committed
+// vector is a given value (section 5.3 of the paper).
+type SingleValueProof struct {
+	Cd     kyber.Point
+	CDelta kyber.Point // commitment to the -delta_i*d_(i+1)
+	CX     kyber.Point // commitment to the delta_(i+1) - a_(i+1)*delta_i - b_i*d_(i+1)
+	A      []kyber.Scalar
+	B      []kyber.Scalar
+	R      kyber.Scalar
+	S      kyber.Scalar
+}
+
+func proveProduct(suite Suite, ck *commitKey, tr *transcript.Transcript,
+	cA []kyber.Point, A [][]kyber.Scalar, r []kyber.Scalar, rand cipher.Stream) (*ProductProof, error) {
+	var err error
+	prf := &ProductProof{}
+	if len(A) == 1 {
+		prf.SingleValue, err = proveSingleValue(suite, ck, tr, A[0], r[0], rand)
+		return prf, err
+	}
+
+	b := make([]kyber.Scalar, len(A[0]))
+	for j := range b {
+		b[j] = A[0][j].Clone()
+		for i := 1; i < len(A); i++ {
+			b[j].Mul(b[j], A[i][j])
+		}
+	}
+	s := suite.Scalar().Pick(rand)
+	prf.Cb = ck.commit(suite, b, s)
+	if err := tr.AppendPoint("Cb", prf.Cb); err != nil {
+		return nil, err
+	}
+	prf.Hadamard, err = proveHadamard(suite, ck, tr, cA, A, r, prf.Cb, b, s, rand)
+	if err != nil {
+		return nil, err
+	}
+	prf.SingleValue, err = proveSingleValue(suite, ck, tr, b, s, rand)
+	if err != nil {
+		return nil, err
+	}
+	return prf, nil
+}
+
+func (p *ProductProof) verify(suite Suite, ck *commitKey, tr *transcript.Transcript,
+	cA []kyber.Point, b kyber.Scalar) error {
+	if p.SingleValue == nil {
+		return fmt.Errorf("malformed product proof: %w", ErrInvalidProof)
+	}
+	if len(cA) == 1 {
+		if p.Cb != nil || p.Hadamard != nil {
+			return fmt.Errorf("malformed product proof: %w", ErrInvalidProof)
+		}
+		return p.SingleValue.verify(suite, ck, tr, cA[0], b)
+	}
+
+	if p.Cb == nil || p.Hadamard == nil {
+		return fmt.Errorf("malformed product proof: %w", ErrInvalidProof)
+	}
+	if err := tr.AppendPoint("Cb", p.Cb); err != nil {
+		return err
+	}
+	if err := p.Hadamard.verify(suite, ck, tr, cA, p.Cb); err != nil {
+		return err
+	}
+	return p.SingleValue.verify(suite, ck, tr, p.Cb, b)
+}
+
+func proveHadamard(suite Suite, ck *commitKey, tr *transcript.Transcript,
+	cA []kyber.Point, A [][]kyber.Scalar, r []kyber.Scalar,
+	cb kyber.Point, b []kyber.Scalar, s kyber.Scalar, rand cipher.Stream) (*HadamardProof, error) {
+	m := len(A)
+
+	// B_i is the product of the first i+1 rows
+	B := make([][]kyber.Scalar, m)
+	sB := make([]kyber.Scalar, m)
+	cB := make([]kyber.Point, m)
+	B[0], sB[0], cB[0] = A[0], r[0], cA[0]
+	for i := 1; i < m-1; i++ {
+		B[i] = make([]kyber.Scalar, len(b))
+		for j := range b {
+			B[i][j] = suite.Scalar().Mul(B[i-1][j], A[i][j])
+		}
+		sB[i] = suite.Scalar().Pick(rand)
+		cB[i] = ck.commit(suite, B[i], sB[i])
+	}
+	B[m-1], sB[m-1], cB[m-1] = b, s, cb
+
+	prf := &HadamardProof{CB: cB[1 : m-1]}
+	if err := tr.AppendPoints("Hadamard CB", prf.CB...); err != nil {
+		return nil, err
+	}
+	x := tr.ChallengeScalar("Hadamard x")
+	y := tr.ChallengeScalar("Hadamard y")
+
+	// Since A_(i+1) o B_i = B_(i+1), we have
+	// sum x^(i+1) * (A_(i+1) *_y B_i) + (-1, ..., -1) *_y sum x^(i+1)*B_(i+1) = 0
+	xPows := powers(suite, x, m)
+	left := append(append([][]kyber.Scalar{}, A[1:]...), constant(suite, len(b), -1))
+	leftR := append(append([]kyber.Scalar{}, r[1:]...), suite.Scalar().Zero())
+	right := make([][]kyber.Scalar, m)
+	rightR := make([]kyber.Scalar, m)
+	for i := 0; i < m-1; i++ {
+		right[i] = linear(suite, xPows[i+1:i+2], B[i:i+1])
+		rightR[i] = suite.Scalar().Mul(xPows[i+1], sB[i])
+	}
+	right[m-1] = linear(suite, xPows[1:], B[1:])
+	rightR[m-1] = dot(suite, xPows[1:], sB[1:])
+
+	var err error
+	prf.Zero, err = proveZero(suite, ck, tr, y, left, leftR, right, rightR, rand)
+	if err != nil {
+		return nil, err
+	}
+	return prf, nil
+}
+
+func (p *HadamardProof) verify(suite Suite, ck *commitKey, tr *transcript.Transcript,
+	cA []kyber.Point, cb kyber.Point) error {
+	m := len(cA)
+	if !validPoints(p.CB, m-2) || p.Zero == nil {
+		return fmt.Errorf("malformed Hadamard product proof: %w", ErrInvalidProof)
+	}
+	if err := tr.AppendPoints("Hadamard CB", p.CB...); err != nil {
+		return err
+	}
+	x := tr.ChallengeScalar("Hadamard x")
+	y := tr.ChallengeScalar("Hadamard y")
+
+	cB := append(append([]kyber.Point{cA[0]}, p.CB...), cb)
+	cLeft, cRight := hadamardCommitments(suite, ck, cA, cB, powers(suite, x, m))
+	return p.Zero.verify(suite, ck, tr, y, cLeft, cRight)
+}
+
+// hadamardCommitments returns the commitments of the statement of the zero
+// argument used by the Hadamard product argument.
+func hadamardCommitments(suite Suite, ck *commitKey, cA, cB []kyber.Point,
+	xPows []kyber.Scalar) (cLeft, cRight []kyber.Point) {
+	m := len(cA)
+	cLeft = append(append([]kyber.Point{}, cA[1:]...), suite.Point().Neg(ck.sum(suite)))
+	cRight = make([]kyber.Point, m)
+	cD := suite.Point().Null()
+	for i := 0; i < m-1; i++ {
+		cRight[i] = suite.Point().Mul(xPows[i+1], cB[i])
+		cD.Add(cD, suite.Point().Mul(xPows[i+1], cB[i+1]))
+	}
+	cRight[m-1] = cD
+	return cLeft, cRight
+}
+
+// proveZero runs the zero argument for the vectors A committed with r and B
+// committed with s, whose commitments are already bound to the transcript.
+// With vectors a_0 and b_(m+1) picked at random, the polynomials
+//
+//	a(x) = sum_(i=0..m) x^i*a_i and b(x) = sum_(j=1..m+1) x^(m+1-j)*b_j
+//
+// have a product a(x) *_y b(x) whose coefficient for x^(m+1) is
+// sum a_i *_y b_i = 0. The prover commits to the other coefficients d_k before learning x.
+//
+//nolint:funlen
+func proveZero(suite Suite, ck *commitKey, tr *transcript.Transcript, y kyber.Scalar,
+	A [][]kyber.Scalar, r []kyber.Scalar, B [][]kyber.Scalar, s []kyber.Scalar,
+	rand cipher.Stream) (*ZeroProof, error) {
+	m := len(A)
+	n := len(A[0])
+	yPows := powers(suite, y, n+1)[1:]
+
+	a0 := randomScalars(suite, n, rand)
+	r0 := suite.Scalar().Pick(rand)
+	bm := randomScalars(suite, n, rand)
+	sm := suite.Scalar().Pick(rand)
+	As := append([][]kyber.Scalar{a0}, A...)
+	rs := append([]kyber.Scalar{r0}, r...)
+	Bs := append(append([][]kyber.Scalar{}, B...), bm) // Bs[j-1] = b_j
+	ss := append(append([]kyber.Scalar{}, s...), sm)
+
+	prf := &ZeroProof{
+		CA0: ck.commit(suite, a0, r0),
+		CBm: ck.commit(suite, bm, sm),
+		CD:  make([]kyber.Point, 0, 2*m),
+	}
+	t := make([]kyber.Scalar, 2*m+1)
+	for k := 0; k <= 2*m; k++ {
+		t[k] = suite.Scalar().Zero()
+		if k == m+1 {
+			continue
+		}
+		d := suite.Scalar().Zero()
+		for i := 0; i <= m; i++ {
+			if j := i + m + 1 - k; j >= 1 && j <= m+1 {
+				d.Add(d, bilinear(suite, As[i], Bs[j-1], yPows))
+			}
+		}
+		t[k].Pick(rand)
+		prf.CD = append(prf.CD, ck.commit(suite, []kyber.Scalar{d}, t[k]))
+	}
+
+	if err := appendZero(tr, prf); err != nil {
+		return nil, err
+	}
+	xPows := powers(suite, tr.ChallengeScalar("zero x"), 2*m+1)
+	rev := reverse(xPows[:m+1])
+	prf.A = linear(suite, xPows, As)
+	prf.R = dot(suite, xPows[:m+1], rs)
+	prf.B = linear(suite, rev, Bs)
+	prf.S = dot(suite, rev, ss)
+	prf.T = dot(suite, xPows, t)
+	return prf, nil
+}
+
+func (p *ZeroProof) verify(suite Suite, ck *commitKey, tr *transcript.Transcript, y kyber.Scalar,
+	cA, cB []kyber.Point) error {
+	m := len(cA)
+	n := len(ck.g)
+	if p.CA0 == nil || p.CBm == nil || !validPoints(p.CD, 2*m) ||
+		!validScalars(p.A, n) || !validScalars(p.B, n) || p.R == nil || p.S == nil || p.T == nil {
+		return fmt.Errorf("malformed zero argument: %w", ErrInvalidProof)
+	}
+	if err := appendZero(tr, p); err != nil {
+		return err
+	}
+	xPows := powers(suite, tr.ChallengeScalar("zero x"), 2*m+1)
+
+	cAs := append([]kyber.Point{p.CA0}, cA...)
+	if !ck.opens(suite, xPows[:m+1], cAs, p.A, p.R) {
+		return fmt.Errorf("zero argument: %w", ErrInvalidProof)
+	}
+	cBs := append(append([]kyber.Point{}, cB...), p.CBm)
+	if !ck.opens(suite, reverse(xPows[:m+1]), cBs, p.B, p.S) {
+		return fmt.Errorf("zero argument: %w", ErrInvalidProof)
+	}
+	// the commitment to d_(m+1) = 0 is com(0; 0)
+	dPows := append(append([]kyber.Scalar{}, xPows[:m+1]...), xPows[m+2:]...)
+	yPows := powers(suite, y, n+1)[1:]
+	if !ck.opens(suite, dPows, p.CD, []kyber.Scalar{bilinear(suite, p.A, p.B, yPows)}, p.T) {
+		return fmt.Errorf("zero argument: %w", ErrInvalidProof)
+	}
+	return nil
+}
+
+func appendZero(tr *transcript.Transcript, p *ZeroProof) error {
+	if err := tr.AppendPoints("zero CA0", p.CA0); err != nil {
+		return err
+	}
+	if err := tr.AppendPoints("zero CBm", p.CBm); err != nil {
+		return err
+	}
+	return tr.AppendPoints("zero CD", p.CD...)
+}
+
+// proveSingleValue runs the single value product argument for the vector a
+// committed with randomness r, of at least two entries.
+//
+//nolint:funlen
+func proveSingleValue(suite Suite, ck *commitKey, tr *transcript.Transcript,
+	a []kyber.Scalar, r kyber.Scalar, rand cipher.Stream) (*SingleValueProof, error) {
+	n := len(a)
+
+	// b_i is the product of the first i+1 entries
+	b := make([]kyber.Scalar, n)
+	b[0] = a[0].Clone()
+	for i := 1; i < n; i++ {
+		b[i] = suite.Scalar().Mul(b[i-1], a[i])
+	}
+
+	d := randomScalars(suite, n, rand)
+	rd := suite.Scalar().Pick(rand)
+	delta := make([]kyber.Scalar, n)
+	delta[0] = d[0].Clone()
+	for i := 1; i < n-1; i++ {
+		delta[i] = suite.Scalar().Pick(rand)
+	}
+	delta[n-1] = suite.Scalar().Zero()
+	s1 := suite.Scalar().Pick(rand)
+	sx := suite.Scalar().Pick(rand)
+
+	dd := make([]kyber.Scalar, n-1)
+	dx := make([]kyber.Scalar, n-1)
+	for i := 0; i < n-1; i++ {
+		dd[i] = suite.Scalar().Mul(delta[i], d[i+1])
+		dd[i].Neg(dd[i])
+		dx[i] = suite.Scalar().Sub(delta[i+1], suite.Scalar().Mul(a[i+1], delta[i]))
+		dx[i].Sub(dx[i], suite.Scalar().Mul(b[i], d[i+1]))
+	}
+	prf := &SingleValueProof{
+		Cd:     ck.commit(suite, d, rd),
+		CDelta: ck.commit(suite, dd, s1),
+		CX:     ck.commit(suite, dx, sx),
+	}
+	if err := tr.AppendPoints("single value", prf.Cd, prf.CDelta, prf.CX); err != nil {
+		return nil, err
+	}
+	x := tr.ChallengeScalar("single value x")
+
+	prf.A = make([]kyber.Scalar, n)
+	prf.B = make([]kyber.Scalar, n)
+	for i := 0; i < n; i++ {
+		prf.A[i] = suite.Scalar().Mul(x, a[i])
+		prf.A[i].Add(prf.A[i], d[i])
+		prf.B[i] = suite.Scalar().Mul(x, b[i])
+		prf.B[i].Add(prf.B[i], delta[i])
+	}
+	prf.R = suite.Scalar().Mul(x, r)
+	prf.R.Add(prf.R, rd)
+	prf.S = suite.Scalar().Mul(x, sx)
+	prf.S.Add(prf.S, s1)
+	return prf, nil
+}
+
+func (p *SingleValueProof) verify(suite Suite, ck *commitKey, tr *transcript.Transcript,
+	ca kyber.Point, b kyber.Scalar) error {
+	n := len(ck.g)
+	if p.Cd == nil || p.CDelta == nil || p.CX == nil ||
+		!validScalars(p.A, n) || !validScalars(p.B, n) || p.R == nil || p.S == nil {
+		return fmt.Errorf("malformed single value product argument: %w", ErrInvalidProof)
+	}
+	if err := tr.AppendPoints("single value", p.Cd, p.CDelta, p.CX); err != nil {
+		return err
+	}
+	x := tr.ChallengeScalar("single value x")
+	one := suite.Scalar().One()
+
+	if !ck.opens(suite, []kyber.Scalar{x, one}, []kyber.Point{ca, p.Cd}, p.A, p.R) {
+		return fmt.Errorf("single value product argument: %w", ErrInvalidProof)
+	}
+	e := make([]kyber.Scalar, n-1)
+	for i := range e {
+		e[i] = suite.Scalar().Mul(x, p.B[i+1])
+		e[i].Sub(e[i], suite.Scalar().Mul(p.B[i], p.A[i+1]))
+	}
+	if !ck.opens(suite, []kyber.Scalar{x, one}, []kyber.Point{p.CX, p.CDelta}, e, p.S) {
+		return fmt.Errorf("single value product argument: %w", ErrInvalidProof)
+	}
+	if !p.B[0].Equal(p.A[0]) || !p.B[n-1].Equal(suite.Scalar().Mul(x, b)) {
+		return fmt.Errorf("single value product argument: %w", ErrInvalidProof)
+	}
+	return nil
+}
+
+// bilinear returns a *_y b = sum a_j*b_j*y^(j+1), yPows holding the y^(j+1).
+func bilinear(suite Suite, a, b, yPows []kyber.Scalar) kyber.Scalar {
+	res := suite.Scalar().Zero()
+	tmp := suite.Scalar()
+	for j := range a {
+		tmp.Mul(a[j], b[j])
+		res.Add(res, tmp.Mul(tmp, yPows[j]))
+	}
+	return res
+}
+
+func constant(suite Suite, n int, v int64) []kyber.Scalar {
+	res := make([]kyber.Scalar, n)
+	for i := range res {
+		res[i] = suite.Scalar().SetInt64(v)
+	}
+	return res
+}
+
+func reverse(s []kyber.Scalar) []kyber.Scalar {
+	res := make([]kyber.Scalar, len(s))
+	for i := range s {
+		res[len(s)-1-i] = s[i]
+	}
+	return res
+}
@@ -355,6 +355,9 @@ func Shuffle(group kyber.Group, G, H kyber.Point, X, Y []kyber.Point,
 	}
 
 	prover := func(ctx proof.ProverContext) error {
+		if err := bindStatement(ctx, group, G, H, X, Y, Xbar, Ybar); err != nil {
+			return err
+		}
 		return ps.Prove(pi, G, H, beta, X, Y, rand, ctx)
 	}
 	return Xbar, Ybar, prover
@@ -371,7 +374,23 @@ func Verifier(group kyber.Group, G, H kyber.Point, X, Y, Xbar, Ybar []kyber.Poin
 	ps := PairShuffle{}
 	ps.Init(group, len(X))
 	verifier := func(ctx proof.VerifierContext) error {
+		if err := bindStatement(ctx, group, G, H, X, Y, Xbar, Ybar); err != nil {
+			return err
+		}
 		return ps.Verify(G, H, X, Y, Xbar, Ybar, ctx)
 	}
 	return verifier
 }
+
+// bindStatement binds the input and output pairs of a shuffle to the
+// challenges of a non-interactive proof (see proof.BindContext).
+func bindStatement(ctx interface{}, group kyber.Group, G, H kyber.Point,
+	X, Y, Xbar, Ybar []kyber.Point) error {
+	if G == nil {
+		G = group.Point().Base()
+	}
+	if H == nil {
+		H = group.Point().Base()
+	}
+	return proof.BindContext(ctx, "shuffle", G, H, X, Y, Xbar, Ybar)
+}
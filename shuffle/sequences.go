@@ -8,6 +8,7 @@ import (
 
 	"go.dedis.ch/kyber/v4"
 	"go.dedis.ch/kyber/v4/proof"
+	"go.dedis.ch/kyber/v4/proof/transcript"
 	"go.dedis.ch/kyber/v4/util/random"
 )
 
@@ -113,7 +114,10 @@ func SequencesShuffle(
 				}
 			}
 
-			XUp, YUp, _, _ := GetSequenceVerifiable(group, X, Y, xBar, yBar, e)
+			XUp, YUp, XDown, YDown := GetSequenceVerifiable(group, X, Y, xBar, yBar, e)
+			if err := bindStatement(ctx, group, G, H, XUp, YUp, XDown, YDown); err != nil {
+				return err
+			}
 
 			return ps.Prove(pi, G, H, beta2, XUp, YUp, rand, ctx)
 		}, nil
@@ -122,6 +126,52 @@ func SequencesShuffle(
 	return xBar, yBar, getProver
 }
 
+// SequencesChallenge returns the scalars e used to consolidate the sequences
+// when the shuffle proof is made non-interactive. They are derived from a
+// transcript of the bases, the input and the output sequences, so that the
+// prover cannot choose them.
+func SequencesChallenge(suite Suite, G, H kyber.Point, X, Y, Xbar, Ybar [][]kyber.Point) ([]kyber.Scalar, error) {
+	if err := assertXY(X, Y); err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+	if err := assertXY(Xbar, Ybar); err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+	if len(Xbar) != len(X) || len(Xbar[0]) != len(X[0]) {
+		return nil, errors.New("input and output have different dimensions")
+	}
+	if G == nil {
+		G = suite.Point().Base()
+	}
+	if H == nil {
+		H = suite.Point().Base()
+	}
+
+	tr := transcript.New(suite, "shuffle sequences")
+	if err := tr.AppendPoints("G", G); err != nil {
+		return nil, err
+	}
+	if err := tr.AppendPoints("H", H); err != nil {
+		return nil, err
+	}
+	for _, in := range []struct {
+		label  string
+		points [][]kyber.Point
+	}{{"X", X}, {"Y", Y}, {"Xbar", Xbar}, {"Ybar", Ybar}} {
+		for _, seq := range in.points {
+			if err := tr.AppendPoints(in.label, seq...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	e := make([]kyber.Scalar, len(X))
+	for j := range e {
+		e[j] = tr.ChallengeScalar("e")
+	}
+	return e, nil
+}
+
 // assertXY checks that x, y have the same dimensions and at least one element
 func assertXY(X, Y [][]kyber.Point) error {
 	if len(X) == 0 || len(X[0]) == 0 {
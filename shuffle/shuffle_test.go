@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.dedis.ch/kyber/v4"
 	"go.dedis.ch/kyber/v4/group/edwards25519"
 	"go.dedis.ch/kyber/v4/proof"
@@ -219,3 +220,40 @@ func sequenceInvalidShuffleTest(t *testing.T, suite Suite, k, nq int) {
 	err = proof.HashVerify(suite, "PairShuffle", verifier, prf)
 	assert.Error(t, err)
 }
+
+func TestSequencesChallenge(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519WithRand(blake2xb.New(nil))
+	rand := suite.RandomStream()
+	h, c := setShuffleKeyPairs(rand, suite, k)
+	X, Y := generateAndEncryptRandomSequences(rand, suite, h, c, k)
+
+	XX, YY, getProver := SequencesShuffle(suite, nil, h, X, Y, rand)
+
+	e, err := SequencesChallenge(suite, nil, h, X, Y, XX, YY)
+	require.NoError(t, err)
+	require.Len(t, e, NQ)
+
+	prover, err := getProver(e)
+	require.NoError(t, err)
+	prf, err := proof.HashProve(suite, "SequencesShuffle", prover)
+	require.NoError(t, err)
+
+	// the verifier derives the same challenge
+	e2, err := SequencesChallenge(suite, nil, h, X, Y, XX, YY)
+	require.NoError(t, err)
+	for j := range e {
+		require.True(t, e[j].Equal(e2[j]))
+	}
+	XXUp, YYUp, XXDown, YYDown := GetSequenceVerifiable(suite, X, Y, XX, YY, e2)
+	verifier := Verifier(suite, nil, h, XXUp, YYUp, XXDown, YYDown)
+	require.NoError(t, proof.HashVerify(suite, "SequencesShuffle", verifier, prf))
+
+	// the challenge depends on the output
+	XX[0][0], XX[0][1] = XX[0][1], XX[0][0]
+	e3, err := SequencesChallenge(suite, nil, h, X, Y, XX, YY)
+	require.NoError(t, err)
+	require.False(t, e[0].Equal(e3[0]))
+
+	_, err = SequencesChallenge(suite, nil, h, X, Y, XX[:1], YY[:1])
+	require.Error(t, err)
+}
@@ -0,0 +1,348 @@
+package anon
+
+import (
+	"bytes"
+	"errors"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/internal/multiexp"
+	"go.dedis.ch/kyber/v4/proof/transcript"
+)
+
+// compact ring signature: a one-out-of-many proof over the padded ring
+// with m = len(CL) bits. The linkable variant adds Q and the tag.
+type cSig struct {
+	CL, CA, CB, CD []kyber.Point
+	F, ZA, ZB      []kyber.Scalar
+	ZD             kyber.Scalar
+}
+
+// compactBits returns the number of bits m of the ring positions, so that
+// the ring padded to 2^m members holds the anonymity set. It is at least 1,
+// since with no bit to hide the response would reveal the private key.
+func compactBits(n int) int {
+	m := 1
+	for 1<<m < n {
+		m++
+	}
+	return m
+}
+
+// compactBase returns the generator H of the bit commitments, whose
+// discrete logarithm in base G is unknown.
+func compactBase(suite Suite) kyber.Point {
+	return suite.Point().Pick(suite.XOF([]byte("anon compact ring signature generator")))
+}
+
+func compactTranscript(suite Suite, message []byte, L []kyber.Point,
+	linkScope []byte, linkTag kyber.Point) (*transcript.Transcript, error) {
+	tr := transcript.New(suite, "anon compact ring signature")
+	tr.AppendMessage("message", message)
+	tr.AppendUint64("ring size", uint64(len(L)))
+	if err := tr.AppendPoints("ring", L...); err != nil {
+		return nil, err
+	}
+	if linkScope != nil {
+		tr.AppendMessage("link scope", linkScope)
+		if err := tr.AppendPoint("link tag", linkTag); err != nil {
+			return nil, err
+		}
+	}
+	return tr, nil
+}
+
+// foldPadding returns the scalars of the ring members, adding the scalars
+// of the padding positions, which all repeat the last member, to it.
+func foldPadding(suite Suite, coefs []kyber.Scalar, n int) []kyber.Scalar {
+	res := make([]kyber.Scalar, n)
+	copy(res, coefs[:n])
+	res[n-1] = suite.Scalar().Set(res[n-1])
+	for _, c := range coefs[n:] {
+		res[n-1].Add(res[n-1], c)
+	}
+	return res
+}
+
+// SignCompact creates an optionally linkable anonymous signature, like Sign,
+// but whose size is logarithmic in the size of the anonymity set instead of
+// linear: with m the number of bits of the ring positions, it holds 4m group
+// elements and 3m+1 scalars, plus m group elements and the linkage tag if it
+// is linkable.
+//
+// The signature is a one-out-of-many proof of Groth and Kohlweiss, "One-out-
+// of-Many Proofs: Or How to Leak a Secret and Spend a Coin" at
+// https://eprint.iacr.org/2014/764, showing the knowledge of the private key
+// of a member of the anonymity set, made non-interactive with the message
+// in the transcript. The ring is padded to a power of two by repeating its
+// last member. The linkable variant proves with the same responses that the
+// linkage tag is the private key times the base point of the linkScope, as
+// in Triptych, https://eprint.iacr.org/2020/018: the linkage tags are the
+// same as the ones of Sign, and compact and regular signatures made with the
+// same key in the same linkScope are linked.
+//
+// Signing costs about m multi-exponentiations of the size of the anonymity
+// set, whose running time depends on secret values. SignCompact should not
+// be used where the signer's timing can be observed.
+func SignCompact(suite Suite, message []byte, anonymitySet Set,
+	linkScope []byte, mine int, privateKey kyber.Scalar) ([]byte, error) {
+	n := len(anonymitySet)
+	if mine < 0 || mine >= n {
+		return nil, errors.New("signer index out of range")
+	}
+	L := []kyber.Point(anonymitySet)
+	m := compactBits(n)
+	rand := suite.RandomStream()
+	H := compactBase(suite)
+
+	var linkBase, linkTag kyber.Point
+	if linkScope != nil {
+		linkBase = suite.Point().Pick(suite.XOF(linkScope))
+		linkTag = suite.Point().Mul(privateKey, linkBase)
+	}
+
+	// Commit to the bits l_j of our position, to random a_j,
+	// and to the products l_j a_j
+	sig := cSig{
+		CL: make([]kyber.Point, m),
+		CA: make([]kyber.Point, m),
+		CB: make([]kyber.Point, m),
+		CD: make([]kyber.Point, m),
+		F:  make([]kyber.Scalar, m),
+		ZA: make([]kyber.Scalar, m),
+		ZB: make([]kyber.Scalar, m),
+	}
+	l := make([]kyber.Scalar, m)
+	a := make([]kyber.Scalar, m)
+	r := make([]kyber.Scalar, m)
+	s := make([]kyber.Scalar, m)
+	t := make([]kyber.Scalar, m)
+	for j := 0; j < m; j++ {
+		l[j] = suite.Scalar().SetInt64(int64(mine >> j & 1))
+		a[j] = suite.Scalar().Pick(rand)
+		r[j] = suite.Scalar().Pick(rand)
+		s[j] = suite.Scalar().Pick(rand)
+		t[j] = suite.Scalar().Pick(rand)
+		la := suite.Scalar().Mul(l[j], a[j])
+		sig.CL[j] = suite.Point().Add(suite.Point().Mul(l[j], H), suite.Point().Mul(r[j], nil))
+		sig.CA[j] = suite.Point().Add(suite.Point().Mul(a[j], H), suite.Point().Mul(s[j], nil))
+		sig.CB[j] = suite.Point().Add(suite.Point().Mul(la, H), suite.Point().Mul(t[j], nil))
+	}
+
+	// The polynomial of position i is the product over the bits j of
+	// f_{j,1}(x) = l_j x + a_j or f_{j,0}(x) = (1 - l_j) x - a_j, depending
+	// on the bit j of i. Only the polynomial of our position has degree m:
+	// the coefficients of lower degree are committed to, blinded by rho_k.
+	poly := [][]kyber.Scalar{{suite.Scalar().One()}}
+	for j := 0; j < m; j++ {
+		f1 := []kyber.Scalar{a[j], l[j]}
+		f0 := []kyber.Scalar{
+			suite.Scalar().Neg(a[j]),
+			suite.Scalar().Sub(suite.Scalar().One(), l[j]),
+		}
+		next := make([][]kyber.Scalar, 2*len(poly))
+		for i, p := range poly {
+			next[i] = mulLinear(suite, p, f0)
+			next[i+len(poly)] = mulLinear(suite, p, f1)
+		}
+		poly = next
+	}
+	rho := make([]kyber.Scalar, m)
+	var Q []kyber.Point
+	if linkScope != nil {
+		Q = make([]kyber.Point, m)
+	}
+	coefs := make([]kyber.Scalar, len(poly))
+	for k := 0; k < m; k++ {
+		for i, p := range poly {
+			coefs[i] = p[k]
+		}
+		rho[k] = suite.Scalar().Pick(rand)
+		sig.CD[k] = multiexp.MultiExp(suite, foldPadding(suite, coefs, n), L)
+		sig.CD[k].Add(sig.CD[k], suite.Point().Mul(rho[k], nil))
+		if linkScope != nil {
+			Q[k] = suite.Point().Mul(rho[k], linkBase)
+		}
+	}
+
+	tr, err := compactTranscript(suite, message, L, linkScope, linkTag)
+	if err != nil {
+		return nil, err
+	}
+	x, err := compactChallenge(tr, &sig, Q)
+	if err != nil {
+		return nil, err
+	}
+
+	// f_j = l_j x + a_j, z_{a,j} = r_j x + s_j, z_{b,j} = r_j (x - f_j) + t_j
+	// and z_d = privateKey x^m - sum_k rho_k x^k
+	xk := suite.Scalar().One()
+	sig.ZD = suite.Scalar().Zero()
+	for j := 0; j < m; j++ {
+		sig.F[j] = suite.Scalar().Mul(l[j], x)
+		sig.F[j].Add(sig.F[j], a[j])
+		sig.ZA[j] = suite.Scalar().Mul(r[j], x)
+		sig.ZA[j].Add(sig.ZA[j], s[j])
+		sig.ZB[j] = suite.Scalar().Sub(x, sig.F[j])
+		sig.ZB[j].Mul(sig.ZB[j], r[j]).Add(sig.ZB[j], t[j])
+		sig.ZD.Sub(sig.ZD, suite.Scalar().Mul(rho[j], xk))
+		xk.Mul(xk, x)
+	}
+	sig.ZD.Add(sig.ZD, suite.Scalar().Mul(privateKey, xk))
+
+	buf := bytes.Buffer{}
+	if err := suite.Write(&buf, &sig); err != nil {
+		return nil, err
+	}
+	if linkScope != nil {
+		if err := suite.Write(&buf, Q, linkTag); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// mulLinear returns the coefficients of the polynomial p times the linear
+// polynomial f.
+func mulLinear(suite Suite, p, f []kyber.Scalar) []kyber.Scalar {
+	res := make([]kyber.Scalar, len(p)+1)
+	res[len(p)] = suite.Scalar().Zero()
+	for k := range p {
+		res[k] = suite.Scalar().Mul(p[k], f[0])
+	}
+	for k := range p {
+		res[k+1].Add(res[k+1], suite.Scalar().Mul(p[k], f[1]))
+	}
+	return res
+}
+
+func compactChallenge(tr *transcript.Transcript, sig *cSig, Q []kyber.Point) (kyber.Scalar, error) {
+	for _, c := range []struct {
+		label  string
+		points []kyber.Point
+	}{
+		{"CL", sig.CL}, {"CA", sig.CA}, {"CB", sig.CB}, {"CD", sig.CD}, {"Q", Q},
+	} {
+		if err := tr.AppendPoints(c.label, c.points...); err != nil {
+			return nil, err
+		}
+	}
+	return tr.ChallengeScalar("challenge"), nil
+}
+
+// VerifyCompact checks a signature generated by SignCompact, and returns
+// the linkage tag as Verify does.
+//
+// All the verification equations are combined with random weights into a
+// single multi-exponentiation of the size of the anonymity set.
+func VerifyCompact(suite Suite, message []byte, anonymitySet Set,
+	linkScope []byte, signatureBuffer []byte) ([]byte, error) {
+	n := len(anonymitySet)
+	if n == 0 {
+		return nil, errors.New("empty anonymity set")
+	}
+	L := []kyber.Point(anonymitySet)
+	m := compactBits(n)
+
+	// Decode the signature
+	buf := bytes.NewBuffer(signatureBuffer)
+	sig := cSig{
+		CL: make([]kyber.Point, m),
+		CA: make([]kyber.Point, m),
+		CB: make([]kyber.Point, m),
+		CD: make([]kyber.Point, m),
+		F:  make([]kyber.Scalar, m),
+		ZA: make([]kyber.Scalar, m),
+		ZB: make([]kyber.Scalar, m),
+	}
+	if err := suite.Read(buf, &sig); err != nil {
+		return nil, err
+	}
+	var linkBase, linkTag kyber.Point
+	var Q []kyber.Point
+	if linkScope != nil {
+		Q = make([]kyber.Point, m)
+		linkTag = suite.Point()
+		if err := suite.Read(buf, &Q, linkTag); err != nil {
+			return nil, err
+		}
+		linkBase = suite.Point().Pick(suite.XOF(linkScope))
+	}
+	if buf.Len() != 0 {
+		return nil, errors.New("invalid signature length")
+	}
+
+	tr, err := compactTranscript(suite, message, L, linkScope, linkTag)
+	if err != nil {
+		return nil, err
+	}
+	x, err := compactChallenge(tr, &sig, Q)
+	if err != nil {
+		return nil, err
+	}
+
+	// With random weights w1_j, w2_j, w3 and w4, check that the sum of
+	//   w1_j (x CL_j + CA_j - f_j H - z_{a,j} G)
+	//   w2_j ((x - f_j) CL_j + CB_j - z_{b,j} G)
+	//   w3 (sum_i p_i(x) L_i - sum_k x^k CD_k - z_d G)
+	//   w4 (x^m tag - sum_k x^k Q_k - z_d linkBase)
+	// is zero.
+	rand := suite.RandomStream()
+	gs := suite.Scalar().Zero()
+	hs := suite.Scalar().Zero()
+	var scalars []kyber.Scalar
+	var points []kyber.Point
+	w3 := suite.Scalar().Pick(rand)
+	w4 := suite.Scalar().Pick(rand)
+	xk := suite.Scalar().One()
+	for j := 0; j < m; j++ {
+		w1 := suite.Scalar().Pick(rand)
+		w2 := suite.Scalar().Pick(rand)
+		xf := suite.Scalar().Sub(x, sig.F[j])
+		cl := suite.Scalar().Mul(w1, x)
+		cl.Add(cl, suite.Scalar().Mul(w2, xf))
+		scalars = append(scalars, cl, w1, w2, suite.Scalar().Neg(suite.Scalar().Mul(w3, xk)))
+		points = append(points, sig.CL[j], sig.CA[j], sig.CB[j], sig.CD[j])
+		hs.Sub(hs, suite.Scalar().Mul(w1, sig.F[j]))
+		gs.Sub(gs, suite.Scalar().Mul(w1, sig.ZA[j]))
+		gs.Sub(gs, suite.Scalar().Mul(w2, sig.ZB[j]))
+		if linkScope != nil {
+			scalars = append(scalars, suite.Scalar().Neg(suite.Scalar().Mul(w4, xk)))
+			points = append(points, Q[j])
+		}
+		xk.Mul(xk, x)
+	}
+	gs.Sub(gs, suite.Scalar().Mul(w3, sig.ZD))
+	scalars = append(scalars, gs, hs)
+	points = append(points, suite.Point().Base(), compactBase(suite))
+	if linkScope != nil {
+		scalars = append(scalars, suite.Scalar().Mul(w4, xk),
+			suite.Scalar().Neg(suite.Scalar().Mul(w4, sig.ZD)))
+		points = append(points, linkTag, linkBase)
+	}
+
+	// p_i(x) is the product over the bits j of f_j or x - f_j,
+	// depending on the bit j of i.
+	p := []kyber.Scalar{w3}
+	for j := 0; j < m; j++ {
+		xf := suite.Scalar().Sub(x, sig.F[j])
+		next := make([]kyber.Scalar, 2*len(p))
+		for i, v := range p {
+			next[i] = suite.Scalar().Mul(v, xf)
+			next[i+len(p)] = suite.Scalar().Mul(v, sig.F[j])
+		}
+		p = next
+	}
+	scalars = append(scalars, foldPadding(suite, p, n)...)
+	points = append(points, L...)
+
+	if !multiexp.MultiExp(suite, scalars, points).Equal(suite.Point().Null()) {
+		return nil, ErrInvalidSignature
+	}
+
+	// Return the re-encoded linkage tag, for uniqueness checking
+	if linkScope != nil {
+		tag, _ := linkTag.MarshalBinary()
+		return tag, nil
+	}
+	return []byte{}, nil
+}
@@ -0,0 +1,128 @@
+package anon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+)
+
+func TestCompact(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	M := []byte("Hello World!")
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		X, x := benchGenKeys(suite, n)
+		for _, mine := range []int{0, n / 2, n - 1} {
+			X[0], X[mine] = X[mine], X[0]
+
+			sig, err := SignCompact(suite, M, Set(X), nil, mine, x)
+			require.NoError(t, err)
+			tag, err := VerifyCompact(suite, M, Set(X), nil, sig)
+			require.NoError(t, err)
+			require.NotNil(t, tag)
+			require.Len(t, tag, 0)
+
+			_, err = VerifyCompact(suite, []byte("Goodbye world!"), Set(X), nil, sig)
+			require.Error(t, err)
+			_, err = VerifyCompact(suite, M, Set(X), []byte("scope"), sig)
+			require.Error(t, err)
+			if n > 1 {
+				Y := append([]kyber.Point{}, X...)
+				Y[0], Y[n-1] = Y[n-1], Y[0]
+				_, err = VerifyCompact(suite, M, Set(Y), nil, sig)
+				require.Error(t, err)
+			}
+			// flip a bit of each element of the signature
+			for i := 0; i < len(sig); i += suite.ScalarLen() {
+				bad := append([]byte{}, sig...)
+				bad[i] ^= 1
+				_, err = VerifyCompact(suite, M, Set(X), nil, bad)
+				require.Error(t, err, "byte %d", i)
+			}
+
+			X[0], X[mine] = X[mine], X[0]
+		}
+	}
+
+	X, x := benchGenKeys(suite, 3)
+	_, err := SignCompact(suite, M, Set(X), nil, 3, x)
+	require.Error(t, err)
+	_, err = VerifyCompact(suite, M, Set{}, nil, []byte{})
+	require.Error(t, err)
+}
+
+func TestCompactLinkable(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	M := []byte("Hello World!")
+	S := []byte("My Linkage Scope")
+	X, x1 := benchGenKeys(suite, 7)
+	x2 := suite.Scalar().Pick(suite.RandomStream())
+	X[5] = suite.Point().Mul(x2, nil)
+
+	sign := func(mine int, x kyber.Scalar, scope []byte) []byte {
+		sig, err := SignCompact(suite, M, Set(X), scope, mine, x)
+		require.NoError(t, err)
+		tag, err := VerifyCompact(suite, M, Set(X), scope, sig)
+		require.NoError(t, err)
+		require.Len(t, tag, suite.PointLen())
+		_, err = VerifyCompact(suite, M, Set(X), nil, sig)
+		require.Error(t, err)
+		_, err = VerifyCompact(suite, M, Set(X), append(scope, '!'), sig)
+		require.Error(t, err)
+		return tag
+	}
+	tag1 := sign(0, x1, S)
+	require.Equal(t, tag1, sign(0, x1, S))
+	require.NotEqual(t, tag1, sign(5, x2, S))
+	require.NotEqual(t, tag1, sign(0, x1, []byte("other scope")))
+
+	// compact and regular signatures of the same signer are linked
+	tag, err := Verify(suite, M, Set(X), S, Sign(suite, M, Set(X), S, 0, x1))
+	require.NoError(t, err)
+	require.Equal(t, tag1, tag)
+
+	// a signature with a tag of another scope doesn't verify
+	sig, err := SignCompact(suite, M, Set(X), S, 0, x1)
+	require.NoError(t, err)
+	other, err := SignCompact(suite, M, Set(X), []byte("other scope"), 0, x1)
+	require.NoError(t, err)
+	pl := suite.PointLen()
+	copy(sig[len(sig)-pl:], other[len(other)-pl:])
+	_, err = VerifyCompact(suite, M, Set(X), S, sig)
+	require.Error(t, err)
+}
+
+func TestCompactSize(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	M := []byte("Hello World!")
+	X, x := benchGenKeys(suite, 1000)
+	sig, err := SignCompact(suite, M, Set(X), nil, 0, x)
+	require.NoError(t, err)
+	// m = 10 bits: 4m points and 3m+1 scalars
+	require.Len(t, sig, 40*suite.PointLen()+31*suite.ScalarLen())
+	_, err = VerifyCompact(suite, M, Set(X), nil, sig)
+	require.NoError(t, err)
+
+	sig, err = SignCompact(suite, M, Set(X), []byte("scope"), 0, x)
+	require.NoError(t, err)
+	require.Len(t, sig, 51*suite.PointLen()+31*suite.ScalarLen())
+}
+
+func BenchmarkSignCompact1000Ed25519(b *testing.B) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	X, x := benchGenKeys(suite, 1000)
+	for i := 0; i < b.N; i++ {
+		_, _ = SignCompact(suite, benchMessage, Set(X), nil, 0, x)
+	}
+}
+
+func BenchmarkVerifyCompact1000Ed25519(b *testing.B) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	X, x := benchGenKeys(suite, 1000)
+	sig, _ := SignCompact(suite, benchMessage, Set(X), nil, 0, x)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = VerifyCompact(suite, benchMessage, Set(X), nil, sig)
+	}
+}
@@ -0,0 +1,217 @@
+package anon
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature doesn't verify.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrMalformedSignature is returned when decoding a signature whose
+	// header or length is invalid, or when verifying a signature against an
+	// anonymity set or a linkScope that doesn't match its header.
+	ErrMalformedSignature = errors.New("anon: malformed signature")
+)
+
+// flags of the header of an encoded RingSignature
+const (
+	flagLinkable byte = 1 << iota
+	flagCompact
+)
+
+// headerLen is the length of the header of an encoded RingSignature: the
+// flags byte and the ring size as a big-endian uint32.
+const headerLen = 5
+
+// RingSignature is a signature created by Sign or SignCompact, along with
+// what it says about itself: whether it is linkable, whether it is compact,
+// and the size of its anonymity set.
+//
+// Its binary encoding starts with a header byte, whose bit 0 is set if the
+// signature is linkable and bit 1 if it is compact, followed by the ring size
+// as a big-endian uint32, and by the signature as returned by Sign or
+// SignCompact. UnmarshalRingSignature checks the length of the signature
+// against the header, so that applications can reject untrusted input, e.g.
+// with an unexpected ring size, before running the expensive verification.
+// The encoding doesn't name the suite, which is given to the functions
+// encoding and decoding the signature and to Verify, so RingSignature doesn't
+// implement encoding.BinaryMarshaler.
+type RingSignature struct {
+	flags    byte
+	ringSize int
+	tag      kyber.Point
+	sig      []byte
+}
+
+// SignRing creates a signature like Sign, and returns it as a RingSignature.
+func SignRing(suite Suite, message []byte, anonymitySet Set,
+	linkScope []byte, mine int, privateKey kyber.Scalar) (*RingSignature, error) {
+	if mine < 0 || mine >= len(anonymitySet) {
+		return nil, errors.New("signer index out of range")
+	}
+	sig := Sign(suite, message, anonymitySet, linkScope, mine, privateKey)
+	return newRingSignature(suite, 0, len(anonymitySet), linkScope != nil, sig)
+}
+
+// SignRingCompact creates a signature like SignCompact, and returns it as a
+// RingSignature.
+func SignRingCompact(suite Suite, message []byte, anonymitySet Set,
+	linkScope []byte, mine int, privateKey kyber.Scalar) (*RingSignature, error) {
+	sig, err := SignCompact(suite, message, anonymitySet, linkScope, mine, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return newRingSignature(suite, flagCompact, len(anonymitySet), linkScope != nil, sig)
+}
+
+func newRingSignature(suite Suite, flags byte, ringSize int, linkable bool, sig []byte) (*RingSignature, error) {
+	if linkable {
+		flags |= flagLinkable
+	}
+	return decodeRingSignature(suite, flags, ringSize, sig)
+}
+
+// Linkable tells if the signature is linkable.
+func (s *RingSignature) Linkable() bool {
+	return s.flags&flagLinkable != 0
+}
+
+// Compact tells if the signature was created by SignCompact.
+func (s *RingSignature) Compact() bool {
+	return s.flags&flagCompact != 0
+}
+
+// RingSize returns the size of the anonymity set of the signature.
+func (s *RingSignature) RingSize() int {
+	return s.ringSize
+}
+
+// LinkTag returns the linkage tag of a linkable signature, or nil if the
+// signature is unlinkable. The tag is only meaningful once the signature has
+// been verified, and only to compare with tags of the same linkScope.
+func (s *RingSignature) LinkTag() kyber.Point {
+	return s.tag
+}
+
+// Linked tells if the two signatures are linkable and have the same linkage
+// tag, i.e. if they were created by the same signer, provided that both
+// signatures were verified with the same linkScope.
+func Linked(a, b *RingSignature) bool {
+	if a.tag == nil || b.tag == nil {
+		return false
+	}
+	return a.tag.Equal(b.tag)
+}
+
+// Verify checks the signature with Verify or VerifyCompact. It returns
+// ErrMalformedSignature if the anonymity set, the linkScope or the suite
+// doesn't match the header.
+func (s *RingSignature) Verify(suite Suite, message []byte, anonymitySet Set, linkScope []byte) error {
+	if n, err := sigLen(suite, s.flags, s.ringSize); err != nil {
+		return err
+	} else if len(s.sig) != n {
+		return fmt.Errorf("%w: wrong length for the suite", ErrMalformedSignature)
+	}
+	if len(anonymitySet) != s.ringSize {
+		return fmt.Errorf("%w: ring of %d members for a signature of %d",
+			ErrMalformedSignature, len(anonymitySet), s.ringSize)
+	}
+	if (linkScope != nil) != s.Linkable() {
+		return fmt.Errorf("%w: linkability doesn't match the linkScope", ErrMalformedSignature)
+	}
+	var err error
+	if s.Compact() {
+		_, err = VerifyCompact(suite, message, anonymitySet, linkScope, s.sig)
+	} else {
+		_, err = Verify(suite, message, anonymitySet, linkScope, s.sig)
+	}
+	return err
+}
+
+// MarshalRingSignature returns the encoding of the signature, which must
+// have been created with the suite.
+func MarshalRingSignature(suite Suite, s *RingSignature) ([]byte, error) {
+	n, err := sigLen(suite, s.flags, s.ringSize)
+	if err != nil {
+		return nil, err
+	}
+	if s.sig == nil || len(s.sig) != n {
+		return nil, fmt.Errorf("%w: wrong length for the suite", ErrMalformedSignature)
+	}
+	buf := make([]byte, headerLen, headerLen+len(s.sig))
+	buf[0] = s.flags
+	binary.BigEndian.PutUint32(buf[1:], uint32(s.ringSize))
+	return append(buf, s.sig...), nil
+}
+
+// UnmarshalRingSignature decodes a signature encoded with
+// MarshalRingSignature and the suite.
+func UnmarshalRingSignature(suite Suite, buf []byte) (*RingSignature, error) {
+	if len(buf) < headerLen {
+		return nil, fmt.Errorf("%w: missing header", ErrMalformedSignature)
+	}
+	ringSize := binary.BigEndian.Uint32(buf[1:headerLen])
+	if uint64(ringSize) > uint64(maxInt) {
+		return nil, fmt.Errorf("%w: ring too large", ErrMalformedSignature)
+	}
+	sig := append([]byte{}, buf[headerLen:]...)
+	return decodeRingSignature(suite, buf[0], int(ringSize), sig)
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// sigLen returns the length of a signature with these flags and ring size.
+func sigLen(suite Suite, flags byte, ringSize int) (int, error) {
+	if flags&^(flagLinkable|flagCompact) != 0 {
+		return 0, fmt.Errorf("%w: unknown flags %#x", ErrMalformedSignature, flags)
+	}
+	if ringSize == 0 || uint64(ringSize) > math.MaxUint32 {
+		return 0, fmt.Errorf("%w: invalid ring size", ErrMalformedSignature)
+	}
+	linkable := flags&flagLinkable != 0
+	var points, scalars int
+	if flags&flagCompact != 0 {
+		// see cSig
+		m := compactBits(ringSize)
+		points, scalars = 4*m, 3*m+1
+		if linkable {
+			points += m + 1
+		}
+	} else {
+		// see uSig and lSig
+		scalars = 1 + ringSize
+		if linkable {
+			points = 1
+		}
+	}
+	n := uint64(points)*uint64(suite.PointLen()) + uint64(scalars)*uint64(suite.ScalarLen())
+	if n > uint64(maxInt) {
+		return 0, fmt.Errorf("%w: ring too large", ErrMalformedSignature)
+	}
+	return int(n), nil
+}
+
+// decodeRingSignature returns the signature after checking its length, and
+// decodes its linkage tag, which comes last in all the layouts.
+func decodeRingSignature(suite Suite, flags byte, ringSize int, sig []byte) (*RingSignature, error) {
+	n, err := sigLen(suite, flags, ringSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != n {
+		return nil, fmt.Errorf("%w: wrong length for the header", ErrMalformedSignature)
+	}
+	var tag kyber.Point
+	if flags&flagLinkable != 0 {
+		tag = suite.Point()
+		if err := tag.UnmarshalBinary(sig[len(sig)-suite.PointLen():]); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedSignature, err)
+		}
+	}
+	return &RingSignature{flags: flags, ringSize: ringSize, tag: tag, sig: sig}, nil
+}
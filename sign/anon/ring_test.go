@@ -0,0 +1,94 @@
+package anon
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/group/p256"
+)
+
+func TestRingSignature(t *testing.T) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	M := []byte("Hello World!")
+	S := []byte("My Linkage Scope")
+	X, x := benchGenKeys(suite, 5)
+
+	for _, sign := range []func(Suite, []byte, Set, []byte, int, kyber.Scalar) (*RingSignature, error){
+		SignRing, SignRingCompact,
+	} {
+		for _, scope := range [][]byte{nil, S} {
+			sig, err := sign(suite, M, Set(X), scope, 0, x)
+			require.NoError(t, err)
+			require.Equal(t, scope != nil, sig.Linkable())
+			require.Equal(t, 5, sig.RingSize())
+			require.Equal(t, scope != nil, sig.LinkTag() != nil)
+			require.NoError(t, sig.Verify(suite, M, Set(X), scope))
+			require.ErrorIs(t, sig.Verify(suite, []byte("Goodbye world!"), Set(X), scope), ErrInvalidSignature)
+			require.ErrorIs(t, sig.Verify(suite, M, Set(X[:4]), scope), ErrMalformedSignature)
+			wrong := S
+			if scope != nil {
+				wrong = nil
+			}
+			require.ErrorIs(t, sig.Verify(suite, M, Set(X), wrong), ErrMalformedSignature)
+
+			buf, err := MarshalRingSignature(suite, sig)
+			require.NoError(t, err)
+			require.Equal(t, uint32(5), binary.BigEndian.Uint32(buf[1:5]))
+
+			dec, err := UnmarshalRingSignature(suite, buf)
+			require.NoError(t, err)
+			require.Equal(t, sig.Compact(), dec.Compact())
+			require.Equal(t, sig.Linkable(), dec.Linkable())
+			require.NoError(t, dec.Verify(suite, M, Set(X), scope))
+			if scope != nil {
+				require.True(t, dec.LinkTag().Equal(sig.LinkTag()))
+				require.True(t, Linked(sig, dec))
+			} else {
+				require.False(t, Linked(sig, dec))
+			}
+
+			// the header is checked against the length
+			for _, bad := range [][]byte{
+				buf[:4],
+				buf[:len(buf)-1],
+				append(append([]byte{}, buf...), 0),
+				append([]byte{buf[0] ^ flagLinkable}, buf[1:]...),
+				append([]byte{buf[0] | 4}, buf[1:]...),
+				append([]byte{buf[0], 0, 0, 0, 9}, buf[5:]...),
+				append([]byte{buf[0], 0, 0, 0, 0}, buf[5:]...),
+				append([]byte{buf[0], 0xff, 0xff, 0xff, 0xff}, buf[5:]...),
+			} {
+				_, err = UnmarshalRingSignature(suite, bad)
+				require.ErrorIs(t, err, ErrMalformedSignature)
+			}
+		}
+	}
+
+	// signatures of different signers aren't linked
+	x2 := suite.Scalar().Pick(suite.RandomStream())
+	X[3] = suite.Point().Mul(x2, nil)
+	a, err := SignRing(suite, M, Set(X), S, 0, x)
+	require.NoError(t, err)
+	b, err := SignRingCompact(suite, M, Set(X), S, 0, x)
+	require.NoError(t, err)
+	c, err := SignRing(suite, M, Set(X), S, 3, x2)
+	require.NoError(t, err)
+	require.True(t, Linked(a, b))
+	require.False(t, Linked(a, c))
+
+	_, err = SignRing(suite, M, Set(X), S, 5, x)
+	require.Error(t, err)
+
+	// the signature doesn't match the lengths of another suite
+	other := p256.NewBlakeSHA256P256()
+	_, err = MarshalRingSignature(other, b)
+	require.ErrorIs(t, err, ErrMalformedSignature)
+	require.ErrorIs(t, b.Verify(other, M, Set(X), S), ErrMalformedSignature)
+	buf, err := MarshalRingSignature(suite, b)
+	require.NoError(t, err)
+	_, err = UnmarshalRingSignature(other, buf)
+	require.ErrorIs(t, err, ErrMalformedSignature)
+}
@@ -2,7 +2,6 @@ package anon
 
 import (
 	"bytes"
-	"errors"
 
 	"go.dedis.ch/kyber/v4"
 )
@@ -236,7 +235,7 @@ func Verify(suite Suite, message []byte, anonymitySet Set,
 		ci = signH1(suite, H1pre, PG, PH)
 	}
 	if !ci.Equal(sig.C0) {
-		return nil, errors.New("invalid signature")
+		return nil, ErrInvalidSignature
 	}
 
 	// Return the re-encoded linkage tag, for uniqueness checking
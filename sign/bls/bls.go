@@ -43,6 +43,17 @@ func NewSchemeOnG1(suite pairing.Suite) sign.Scheme {
 	}
 }
 
+// NewSchemeOnG1ByName is NewSchemeOnG1 with the pairing suite registered
+// under the name, such as "bls12381-kilic", see pairing.Find. The package of
+// the suite must be imported.
+func NewSchemeOnG1ByName(name string) (sign.Scheme, error) {
+	suite, err := pairing.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchemeOnG1(suite), nil
+}
+
 // NewSchemeOnG2 returns a sign.Scheme that uses G2 for its signature space and
 // G1 for its public key
 func NewSchemeOnG2(suite pairing.Suite) sign.Scheme {
@@ -58,6 +69,16 @@ func NewSchemeOnG2(suite pairing.Suite) sign.Scheme {
 	}
 }
 
+// NewSchemeOnG2ByName is NewSchemeOnG2 with the pairing suite registered
+// under the name, see NewSchemeOnG1ByName.
+func NewSchemeOnG2ByName(name string) (sign.Scheme, error) {
+	suite, err := pairing.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchemeOnG2(suite), nil
+}
+
 func (s *scheme) NewKeyPair(random cipher.Stream) (kyber.Scalar, kyber.Point) {
 	secret := s.keyGroup.Scalar().Pick(random)
 	public := s.keyGroup.Point().Mul(secret, nil)
@@ -1,10 +1,17 @@
 package bls
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/pairing"
+	_ "go.dedis.ch/kyber/v4/pairing/bls12381/circl"
+	_ "go.dedis.ch/kyber/v4/pairing/bls12381/kilic"
+	_ "go.dedis.ch/kyber/v4/pairing/bn254"
 	"go.dedis.ch/kyber/v4/pairing/bn256"
+	"go.dedis.ch/kyber/v4/sign"
 	"go.dedis.ch/kyber/v4/util/random"
 	"go.dedis.ch/kyber/v4/xof/blake2xb"
 )
@@ -80,3 +87,30 @@ func BenchmarkBLSSign(b *testing.B) {
 		require.Nil(b, err)
 	}
 }
+
+func TestBLSByName(t *testing.T) {
+	// the pairing suites are registered by their packages
+	for _, name := range []string{"bn256", "bn254", "bls12381-kilic", "BLS12381-circl"} {
+		suite, err := pairing.Find(name)
+		require.NoError(t, err)
+		require.Equal(t, strings.ToLower(name), suite.(fmt.Stringer).String())
+
+		msg := []byte("Hello Boneh-Lynn-Shacham")
+		newSchemes := []func(string) (sign.Scheme, error){NewSchemeOnG1ByName}
+		if strings.HasPrefix(suite.(fmt.Stringer).String(), "bls12381") {
+			// only BLS12-381 hashes to G2
+			newSchemes = append(newSchemes, NewSchemeOnG2ByName)
+		}
+		for _, newScheme := range newSchemes {
+			scheme, err := newScheme(name)
+			require.NoError(t, err)
+			private, public := scheme.NewKeyPair(random.New())
+			sig, err := scheme.Sign(private, msg)
+			require.NoError(t, err)
+			require.NoError(t, scheme.Verify(public, msg, sig))
+		}
+	}
+
+	_, err := NewSchemeOnG1ByName("bls12381")
+	require.ErrorIs(t, err, pairing.ErrUnknownSuite)
+}
@@ -69,6 +69,27 @@ func NewThresholdSchemeOnG2(suite pairing.Suite) sign.ThresholdScheme {
 	}
 }
 
+// NewThresholdSchemeOnG1ByName is NewThresholdSchemeOnG1 with the pairing
+// suite registered under the name, such as "bls12381-kilic", see
+// pairing.Find. The package of the suite must be imported.
+func NewThresholdSchemeOnG1ByName(name string) (sign.ThresholdScheme, error) {
+	suite, err := pairing.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewThresholdSchemeOnG1(suite), nil
+}
+
+// NewThresholdSchemeOnG2ByName is NewThresholdSchemeOnG2 with the pairing
+// suite registered under the name, see NewThresholdSchemeOnG1ByName.
+func NewThresholdSchemeOnG2ByName(name string) (sign.ThresholdScheme, error) {
+	suite, err := pairing.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return NewThresholdSchemeOnG2(suite), nil
+}
+
 // Sign creates a threshold BLS signature Si = xi * H(m) on the given message m
 // using the provided secret key share xi.
 func (s *scheme) Sign(private *share.PriShare, msg []byte) ([]byte, error) {
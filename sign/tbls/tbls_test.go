@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/kyber/v4/internal/test"
+	"go.dedis.ch/kyber/v4/pairing"
+	"go.dedis.ch/kyber/v4/pairing/bls12381/kilic"
 	"go.dedis.ch/kyber/v4/pairing/bn256"
 	"go.dedis.ch/kyber/v4/share"
 	"go.dedis.ch/kyber/v4/xof/blake2xb"
@@ -56,3 +58,16 @@ func TestBN256(t *testing.T) {
 	scheme := NewThresholdSchemeOnG1(suite)
 	test.ThresholdTest(t, suite.G2(), scheme)
 }
+
+func TestByName(t *testing.T) {
+	scheme, err := NewThresholdSchemeOnG1ByName("bls12381-kilic")
+	require.NoError(t, err)
+	test.ThresholdTest(t, kilic.NewBLS12381Suite().G2(), scheme)
+
+	scheme, err = NewThresholdSchemeOnG2ByName("bls12381-kilic")
+	require.NoError(t, err)
+	test.ThresholdTest(t, kilic.NewBLS12381Suite().G1(), scheme)
+
+	_, err = NewThresholdSchemeOnG1ByName("bls12381")
+	require.ErrorIs(t, err, pairing.ErrUnknownSuite)
+}
@@ -0,0 +1,170 @@
+package tecdsa
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"go.dedis.ch/kyber/v4/encrypt/paillier"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// MinModulusBits is the minimum bit length of the Paillier and ring-Pedersen
+// moduli. The MtA protocol needs a Paillier modulus above q⁵ for a curve of
+// order q, and 2048 bits keep the moduli out of reach of factorization.
+const MinModulusBits = 2048
+
+// ErrInvalidParams is returned when public parameters don't verify.
+var ErrInvalidParams = errors.New("tecdsa: invalid public parameters")
+
+// PreParams are the secret parameters of a signer, generated once and reused
+// for all the presignings: a Paillier key, and ring-Pedersen parameters
+// (Ñ, h1, h2) used by the other signers in their proofs to this signer.
+type PreParams struct {
+	Paillier *paillier.PrivateKey
+	NTilde   *big.Int
+	H1, H2   *big.Int
+	// Alpha is the discrete logarithm of H2 in base H1, and Beta the one of
+	// H1 in base H2.
+	Alpha, Beta *big.Int
+	// P and Q are the Sophie Germain primes of the safe primes 2P+1 and
+	// 2Q+1 whose product is NTilde.
+	P, Q *big.Int
+}
+
+// GeneratePreParams generates pre-parameters with moduli of the given bit
+// length. Generating the safe primes of the ring-Pedersen modulus takes from
+// seconds to minutes.
+func GeneratePreParams(rand cipher.Stream, bits int) (*PreParams, error) {
+	sk, err := paillier.GenerateKey(rand, bits)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p := safePrime(rand, (bits+1)/2)
+		q := safePrime(rand, bits-(bits+1)/2)
+		pp, err := NewPreParams(rand, sk.P, sk.Q, p, q)
+		if err == nil && pp.NTilde.BitLen() == bits {
+			return pp, nil
+		}
+	}
+}
+
+// NewPreParams returns the pre-parameters made of the primes of the Paillier
+// key, and of the two safe primes of the ring-Pedersen modulus.
+func NewPreParams(rand cipher.Stream, paillierP, paillierQ, safeP, safeQ *big.Int) (*PreParams, error) {
+	sk, err := paillier.NewPrivateKey(paillierP, paillierQ)
+	if err != nil {
+		return nil, err
+	}
+	if safeP.Cmp(safeQ) == 0 {
+		return nil, errors.New("tecdsa: equal safe primes")
+	}
+	p := new(big.Int).Rsh(safeP, 1)
+	q := new(big.Int).Rsh(safeQ, 1)
+	for _, v := range []*big.Int{safeP, safeQ, p, q} {
+		if !v.ProbablyPrime(20) {
+			return nil, errors.New("tecdsa: not a safe prime")
+		}
+	}
+	nTilde := new(big.Int).Mul(safeP, safeQ)
+	pq := new(big.Int).Mul(p, q)
+
+	// h1 generates the quadratic residues of Z*_Ñ, of order pq, with
+	// overwhelming probability
+	f := paillier.RandomUnit(rand, nTilde)
+	h1 := f.Mul(f, f).Mod(f, nTilde)
+	var alpha, beta *big.Int
+	for beta == nil {
+		alpha = random.Int(pq, rand)
+		beta = new(big.Int).ModInverse(alpha, pq)
+	}
+	return &PreParams{
+		Paillier: sk,
+		NTilde:   nTilde,
+		H1:       h1,
+		H2:       new(big.Int).Exp(h1, alpha, nTilde),
+		Alpha:    alpha,
+		Beta:     beta,
+		P:        p,
+		Q:        q,
+	}, nil
+}
+
+// safePrime returns a random safe prime of the given bit length.
+func safePrime(rand cipher.Stream, bits int) *big.Int {
+	p := new(big.Int)
+	for {
+		b := random.Bits(uint(bits-1), true, rand)
+		b[len(b)-1] |= 1
+		q := new(big.Int).SetBytes(b)
+		// q = 2 mod 3 is needed for 2q+1 not to be a multiple of 3
+		if new(big.Int).Mod(q, big.NewInt(3)).Int64() != 2 || !q.ProbablyPrime(1) {
+			continue
+		}
+		p.Lsh(q, 1).Add(p, one)
+		if p.ProbablyPrime(20) && q.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+// PublicParams are the public parameters of a signer, with the proofs of
+// their correctness. They are sent once to the other signers, which check
+// them with Verify.
+type PublicParams struct {
+	Paillier *paillier.PublicKey
+	NTilde   *big.Int
+	H1, H2   *big.Int
+	// KeyProof proves that the Paillier modulus is correct.
+	KeyProof []*big.Int
+	// DLNProof1 proves the knowledge of the discrete logarithm of H2 in base
+	// H1, and DLNProof2 of H1 in base H2.
+	DLNProof1, DLNProof2 *DLNProof
+}
+
+// Public returns the public parameters. The context binds the proofs to the
+// signer, e.g. its index and longterm public key, so that they can't be
+// replayed by another signer.
+func (p *PreParams) Public(rand cipher.Stream, context []byte) (*PublicParams, error) {
+	keyProof, err := p.Paillier.ProveKey(context)
+	if err != nil {
+		return nil, err
+	}
+	pq := new(big.Int).Mul(p.P, p.Q)
+	return &PublicParams{
+		Paillier:  &p.Paillier.PublicKey,
+		NTilde:    p.NTilde,
+		H1:        p.H1,
+		H2:        p.H2,
+		KeyProof:  keyProof,
+		DLNProof1: proveDLN(rand, context, p.NTilde, p.H1, p.H2, p.Alpha, pq),
+		DLNProof2: proveDLN(rand, context, p.NTilde, p.H2, p.H1, p.Beta, pq),
+	}, nil
+}
+
+// Verify verifies the public parameters for the context given to Public.
+func (p *PublicParams) Verify(context []byte) error {
+	if p.Paillier == nil || p.Paillier.N == nil || p.NTilde == nil || p.H1 == nil || p.H2 == nil {
+		return fmt.Errorf("%w: missing parameter", ErrInvalidParams)
+	}
+	if p.Paillier.N.BitLen() < MinModulusBits || p.NTilde.BitLen() < MinModulusBits {
+		return fmt.Errorf("%w: modulus too short", ErrInvalidParams)
+	}
+	if err := p.Paillier.VerifyKey(context, p.KeyProof); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidParams, err)
+	}
+	if p.H1.Cmp(p.H2) == 0 {
+		return fmt.Errorf("%w: equal ring-Pedersen bases", ErrInvalidParams)
+	}
+	if !p.DLNProof1.verify(context, p.NTilde, p.H1, p.H2) ||
+		!p.DLNProof2.verify(context, p.NTilde, p.H2, p.H1) {
+		return fmt.Errorf("%w: invalid ring-Pedersen proof", ErrInvalidParams)
+	}
+	return nil
+}
+
+func (p *PublicParams) ringPedersen() *ringPedersen {
+	return &ringPedersen{N: p.NTilde, H1: p.H1, H2: p.H2}
+}
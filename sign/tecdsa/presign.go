@@ -0,0 +1,470 @@
+package tecdsa
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/share"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// Config holds the parameters of a presigning.
+type Config struct {
+	Suite Suite
+	// Share is the key share of this signer.
+	Share DistKeyShare
+	// PreParams are the pre-parameters of this signer.
+	PreParams *PreParams
+	// Params holds the verified public parameters of the other signers, by
+	// share index.
+	Params map[uint32]*PublicParams
+	// Signers are the share indexes of the signers, this signer included. There
+	// must be at least as many signers as the threshold of the key.
+	Signers []uint32
+	// SessionID identifies the presigning. It must be unique, and the same for
+	// all the signers.
+	SessionID []byte
+}
+
+// Round1Message is sent by each signer to each other signer: its encrypted
+// share of the nonce, with a range proof, and its commitment to its share
+// of the nonce point.
+type Round1Message struct {
+	From, To   uint32
+	Commitment []byte
+	K          *big.Int
+	Proof      *RangeProof
+}
+
+// Round2Message is sent by each signer to each other signer: the responses
+// of the MtA protocols multiplying the share of the nonce of the recipient
+// with the blinding share and the key share of the sender.
+type Round2Message struct {
+	From, To   uint32
+	CGamma     *big.Int
+	ProofGamma *RespondProof
+	CW         *big.Int
+	ProofW     *RespondProof
+}
+
+// Round3Message is broadcast by each signer: its additive share of kγ.
+type Round3Message struct {
+	From  uint32
+	Delta kyber.Scalar
+}
+
+// Round4Message is broadcast by each signer: the opening of its commitment,
+// with a proof of knowledge of its blinding share.
+type Round4Message struct {
+	From  uint32
+	Gamma kyber.Point
+	Blind []byte
+	Proof []byte
+}
+
+// Round5Message is broadcast by each signer: the points it checks the
+// presignature with.
+type Round5Message struct {
+	From uint32
+	RBar kyber.Point
+	S    kyber.Point
+}
+
+// Presigner runs the presigning for a signer. Its rounds must be called in
+// order, each one with the messages of all the other signers from the
+// previous round.
+type Presigner struct {
+	c       *Config
+	suite   Suite
+	q       *big.Int
+	rand    cipher.Stream
+	index   uint32
+	others  []uint32
+	public  kyber.Point
+	pubPoly *share.PubPoly
+	round   int
+
+	k, gamma, w kyber.Scalar
+	bigGamma    kyber.Point
+	blind       []byte
+	kCipher     *big.Int
+
+	commitments map[uint32][]byte
+	kCiphers    map[uint32]*big.Int
+	betas, nus  map[uint32]kyber.Scalar
+	delta       kyber.Scalar
+	sigma       kyber.Scalar
+	r           kyber.Point
+}
+
+// NewPresigner returns the presigner of this signer.
+func NewPresigner(c *Config) (*Presigner, error) {
+	if c.PreParams == nil || c.Share == nil || len(c.SessionID) == 0 {
+		return nil, errors.New("tecdsa: incomplete config")
+	}
+	commits := c.Share.Commitments()
+	index := c.Share.PriShare().I
+	signers := append([]uint32{}, c.Signers...)
+	sort.Slice(signers, func(i, j int) bool { return signers[i] < signers[j] })
+	var others []uint32
+	found := false
+	for i, s := range signers {
+		if i > 0 && signers[i-1] == s {
+			return nil, errors.New("tecdsa: duplicate signer")
+		}
+		if s == index {
+			found = true
+			continue
+		}
+		if c.Params[s] == nil {
+			return nil, fmt.Errorf("tecdsa: missing public parameters of signer %d", s)
+		}
+		others = append(others, s)
+	}
+	if !found {
+		return nil, errors.New("tecdsa: own index not in the signers")
+	}
+	if len(signers) < len(commits) {
+		return nil, fmt.Errorf("tecdsa: %d signers for a threshold of %d", len(signers), len(commits))
+	}
+	return &Presigner{
+		c:       c,
+		suite:   c.Suite,
+		q:       c.Suite.Params().N,
+		rand:    c.Suite.RandomStream(),
+		index:   index,
+		others:  others,
+		public:  commits[0],
+		pubPoly: share.NewPubPoly(c.Suite, nil, commits),
+	}, nil
+}
+
+// Round1 starts the presigning, and returns the messages for the other
+// signers.
+func (p *Presigner) Round1() ([]*Round1Message, error) {
+	if err := p.nextRound(1); err != nil {
+		return nil, err
+	}
+	s := p.suite
+	signers := append([]uint32{p.index}, p.others...)
+	p.k = s.Scalar().Pick(p.rand)
+	p.gamma = s.Scalar().Pick(p.rand)
+	p.w = lagrange(s, p.index, signers)
+	p.w.Mul(p.w, p.c.Share.PriShare().V)
+	p.bigGamma = s.Point().Mul(p.gamma, nil)
+	p.blind = make([]byte, 32)
+	random.Bytes(p.blind, p.rand)
+	commitment, err := p.commitment(p.index, p.bigGamma, p.blind)
+	if err != nil {
+		return nil, err
+	}
+
+	own := &p.c.PreParams.Paillier.PublicKey
+	k := scalarInt(p.k)
+	var r *big.Int
+	p.kCipher, r, err = own.Encrypt(p.rand, k)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]*Round1Message, 0, len(p.others))
+	for _, j := range p.others {
+		msgs = append(msgs, &Round1Message{
+			From:       p.index,
+			To:         j,
+			Commitment: commitment,
+			K:          p.kCipher,
+			Proof: proveRange(p.rand, p.q, p.context("range", p.index, j), own,
+				p.c.Params[j].ringPedersen(), p.kCipher, k, r),
+		})
+	}
+	return msgs, nil
+}
+
+// Round2 processes the messages of the first round, and returns the
+// responses of the MtA protocols for the other signers.
+func (p *Presigner) Round2(msgs []*Round1Message) ([]*Round2Message, error) {
+	if err := p.nextRound(2); err != nil {
+		return nil, err
+	}
+	p.commitments = make(map[uint32][]byte)
+	p.kCiphers = make(map[uint32]*big.Int)
+	own := p.ownRingPedersen()
+	err := p.collect(len(msgs), func(i int) (uint32, uint32) { return msgs[i].From, msgs[i].To },
+		func(i int) error {
+			m := msgs[i]
+			if !m.Proof.verify(p.q, p.context("range", m.From, p.index), p.c.Params[m.From].Paillier,
+				own, m.K) {
+				return errors.New("invalid range proof")
+			}
+			p.commitments[m.From] = m.Commitment
+			p.kCiphers[m.From] = m.K
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	s := p.suite
+	p.betas = make(map[uint32]kyber.Scalar)
+	p.nus = make(map[uint32]kyber.Scalar)
+	w := s.Point().Mul(p.w, nil)
+	out := make([]*Round2Message, 0, len(p.others))
+	for _, j := range p.others {
+		m := &Round2Message{From: p.index, To: j}
+		m.CGamma, m.ProofGamma, p.betas[j] = p.respond(j, "gamma", p.gamma, nil)
+		m.CW, m.ProofW, p.nus[j] = p.respond(j, "w", p.w, w)
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// respond runs the MtA protocol as the responder, for the product of the
+// nonce share of the signer j and the secret x, with the public X = xG if
+// it isn't nil. It returns the response, its proof, and the additive share
+// of the responder.
+func (p *Presigner) respond(j uint32, label string, x kyber.Scalar, X kyber.Point) (
+	*big.Int, *RespondProof, kyber.Scalar) {
+	params := p.c.Params[j]
+	pk := params.Paillier
+	xi := scalarInt(x)
+	betaPrm := random.Int(pow(p.q, 5), p.rand)
+	// c = K^x (N+1)^β' r^N mod N²
+	c, r, _ := pk.Encrypt(p.rand, betaPrm) //nolint:errcheck // β' < q⁵ < N
+	c = pk.Add(c, pk.Mul(p.kCiphers[j], xi))
+	proof := proveRespond(p.rand, p.suite, p.q, p.context(label, p.index, j), pk,
+		params.ringPedersen(), p.kCiphers[j], c, X, xi, betaPrm, r)
+	beta := toScalar(p.suite, betaPrm)
+	return c, proof, beta.Neg(beta)
+}
+
+// Round3 processes the responses of the MtA protocols, and returns the
+// additive share of kγ of this signer.
+func (p *Presigner) Round3(msgs []*Round2Message) (*Round3Message, error) {
+	if err := p.nextRound(3); err != nil {
+		return nil, err
+	}
+	s := p.suite
+	sk := p.c.PreParams.Paillier
+	own := p.ownRingPedersen()
+	p.delta = s.Scalar().Mul(p.k, p.gamma)
+	p.sigma = s.Scalar().Mul(p.k, p.w)
+	signers := append([]uint32{p.index}, p.others...)
+	err := p.collect(len(msgs), func(i int) (uint32, uint32) { return msgs[i].From, msgs[i].To },
+		func(i int) error {
+			m := msgs[i]
+			if !m.ProofGamma.verify(s, p.q, p.context("gamma", m.From, p.index), &sk.PublicKey, own,
+				p.kCipher, m.CGamma, nil) {
+				return errors.New("invalid MtA proof")
+			}
+			w := p.pubPoly.Eval(m.From).V
+			w.Mul(lagrange(s, m.From, signers), w)
+			if !m.ProofW.verify(s, p.q, p.context("w", m.From, p.index), &sk.PublicKey, own,
+				p.kCipher, m.CW, w) {
+				return errors.New("invalid MtAwc proof")
+			}
+			alpha, err := sk.Decrypt(m.CGamma)
+			if err != nil {
+				return err
+			}
+			mu, err := sk.Decrypt(m.CW)
+			if err != nil {
+				return err
+			}
+			p.delta.Add(p.delta, toScalar(s, alpha))
+			p.delta.Add(p.delta, p.betas[m.From])
+			p.sigma.Add(p.sigma, toScalar(s, mu))
+			p.sigma.Add(p.sigma, p.nus[m.From])
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &Round3Message{From: p.index, Delta: p.delta}, nil
+}
+
+// Round4 processes the shares of kγ, and returns the opening of the
+// commitment of this signer.
+func (p *Presigner) Round4(msgs []*Round3Message) (*Round4Message, error) {
+	if err := p.nextRound(4); err != nil {
+		return nil, err
+	}
+	delta := p.suite.Scalar().Set(p.delta)
+	err := p.collect(len(msgs), func(i int) (uint32, uint32) { return msgs[i].From, p.index },
+		func(i int) error {
+			if msgs[i].Delta == nil {
+				return errors.New("missing share")
+			}
+			delta.Add(delta, msgs[i].Delta)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	if delta.Equal(p.suite.Scalar().Zero()) {
+		return nil, fmt.Errorf("%w: kγ is zero", ErrInconsistent)
+	}
+	p.delta = delta
+	proof, err := schnorr.Sign(p.suite, p.gamma, p.context("pok", p.index, p.index))
+	if err != nil {
+		return nil, err
+	}
+	return &Round4Message{From: p.index, Gamma: p.bigGamma, Blind: p.blind, Proof: proof}, nil
+}
+
+// Round5 processes the openings of the commitments, computes the nonce
+// point, and returns the points the presignature is checked with.
+func (p *Presigner) Round5(msgs []*Round4Message) (*Round5Message, error) {
+	if err := p.nextRound(5); err != nil {
+		return nil, err
+	}
+	s := p.suite
+	bigGamma := s.Point().Set(p.bigGamma)
+	err := p.collect(len(msgs), func(i int) (uint32, uint32) { return msgs[i].From, p.index },
+		func(i int) error {
+			m := msgs[i]
+			if m.Gamma == nil {
+				return errors.New("missing point")
+			}
+			commitment, err := p.commitment(m.From, m.Gamma, m.Blind)
+			if err != nil {
+				return err
+			}
+			if string(commitment) != string(p.commitments[m.From]) {
+				return errors.New("invalid opening")
+			}
+			if err := schnorr.Verify(s, m.Gamma, p.context("pok", m.From, m.From), m.Proof); err != nil {
+				return err
+			}
+			bigGamma.Add(bigGamma, m.Gamma)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	p.r = s.Point().Mul(s.Scalar().Inv(p.delta), bigGamma)
+	if _, _, err := coordinates(s, p.r); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInconsistent, err)
+	}
+	return &Round5Message{
+		From: p.index,
+		RBar: s.Point().Mul(p.k, p.r),
+		S:    s.Point().Mul(p.sigma, p.r),
+	}, nil
+}
+
+// Presignature processes the points of the other signers, checks that they
+// are consistent with the public key, and returns the presignature of this
+// signer.
+func (p *Presigner) Presignature(msgs []*Round5Message) (*Presignature, error) {
+	if err := p.nextRound(6); err != nil {
+		return nil, err
+	}
+	s := p.suite
+	ps := &Presignature{
+		suite:     s,
+		Index:     p.index,
+		R:         p.r,
+		PublicKey: p.public,
+		RBar:      map[uint32]kyber.Point{p.index: s.Point().Mul(p.k, p.r)},
+		S:         map[uint32]kyber.Point{p.index: s.Point().Mul(p.sigma, p.r)},
+		k:         p.k,
+		sigma:     p.sigma,
+	}
+	err := p.collect(len(msgs), func(i int) (uint32, uint32) { return msgs[i].From, p.index },
+		func(i int) error {
+			if msgs[i].RBar == nil || msgs[i].S == nil {
+				return errors.New("missing point")
+			}
+			ps.RBar[msgs[i].From] = msgs[i].RBar
+			ps.S[msgs[i].From] = msgs[i].S
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	// Σ k_i R = kR = G and Σ σ_i R = kxR = Y
+	rBar, sum := s.Point().Null(), s.Point().Null()
+	for _, j := range ps.signers() {
+		rBar.Add(rBar, ps.RBar[j])
+		sum.Add(sum, ps.S[j])
+	}
+	if !rBar.Equal(s.Point().Base()) {
+		return nil, fmt.Errorf("%w: nonce points don't sum to the base point", ErrInconsistent)
+	}
+	if !sum.Equal(p.public) {
+		return nil, fmt.Errorf("%w: key points don't sum to the public key", ErrInconsistent)
+	}
+	return ps, nil
+}
+
+// nextRound moves to the round, which must follow the current one.
+func (p *Presigner) nextRound(round int) error {
+	if p.round != round-1 {
+		return fmt.Errorf("tecdsa: round %d called after round %d", round, p.round)
+	}
+	p.round = round
+	return nil
+}
+
+// collect checks that there is one message from each other signer, sent to
+// this signer, and processes them.
+func (p *Presigner) collect(n int, route func(int) (uint32, uint32), process func(int) error) error {
+	seen := make(map[uint32]bool)
+	expected := make(map[uint32]bool)
+	for _, j := range p.others {
+		expected[j] = true
+	}
+	for i := 0; i < n; i++ {
+		from, to := route(i)
+		if !expected[from] || to != p.index {
+			return fmt.Errorf("%w: unexpected message from %d to %d", ErrInvalidMessage, from, to)
+		}
+		if seen[from] {
+			return fmt.Errorf("%w: duplicate message from %d", ErrInvalidMessage, from)
+		}
+		seen[from] = true
+		if err := process(i); err != nil {
+			return fmt.Errorf("%w: from %d: %v", ErrInvalidMessage, from, err)
+		}
+	}
+	if len(seen) != len(expected) {
+		return fmt.Errorf("%w: %d messages for %d signers", ErrInvalidMessage, len(seen), len(expected))
+	}
+	return nil
+}
+
+// context binds a proof to the session, its purpose, and its prover and
+// verifier.
+func (p *Presigner) context(label string, from, to uint32) []byte {
+	buf := make([]byte, 0, len(p.c.SessionID)+len(label)+12)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(p.c.SessionID)))
+	buf = append(buf, p.c.SessionID...)
+	buf = append(buf, label...)
+	buf = binary.BigEndian.AppendUint32(buf, from)
+	return binary.BigEndian.AppendUint32(buf, to)
+}
+
+// commitment returns the commitment of the signer to its blinding point.
+func (p *Presigner) commitment(from uint32, gamma kyber.Point, blind []byte) ([]byte, error) {
+	buf, err := gamma.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	_, _ = h.Write(p.context("commitment", from, from))
+	_, _ = h.Write(buf)
+	_, _ = h.Write(blind)
+	return h.Sum(nil), nil
+}
+
+func (p *Presigner) ownRingPedersen() *ringPedersen {
+	pp := p.c.PreParams
+	return &ringPedersen{N: pp.NTilde, H1: pp.H1, H2: pp.H2}
+}
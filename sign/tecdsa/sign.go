@@ -0,0 +1,142 @@
+package tecdsa
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+// Presignature is the output of the presigning for a signer. Its public part
+// is the same for all the signers: the nonce point R, and the points k_i R
+// and σ_i R of each signer i, which the partial signatures are verified with.
+type Presignature struct {
+	suite Suite
+	// Index is the share index of the signer.
+	Index uint32
+	// R is the nonce point of the signature.
+	R kyber.Point
+	// RBar and S hold the points k_i R and σ_i R of each signer i.
+	RBar, S map[uint32]kyber.Point
+	// PublicKey is the public key of the distributed key.
+	PublicKey kyber.Point
+
+	k, sigma kyber.Scalar
+}
+
+// PartialSignature is the share of a signature of a signer. It must be sent
+// to the combiners.
+type PartialSignature struct {
+	Index uint32
+	S     kyber.Scalar
+}
+
+// Sign returns the partial signature of the hash of the message. The secret
+// part of the presignature is erased, so that it can't be used twice.
+func (p *Presignature) Sign(hash []byte) (*PartialSignature, error) {
+	if p.k == nil {
+		return nil, ErrPresignatureUsed
+	}
+	r, err := p.rScalar()
+	if err != nil {
+		return nil, err
+	}
+	m := toScalar(p.suite, hashToInt(hash, p.suite))
+	// s_i = m k_i + r σ_i
+	s := p.suite.Scalar().Mul(m, p.k)
+	s.Add(s, r.Mul(r, p.sigma))
+	p.k, p.sigma = nil, nil
+	return &PartialSignature{Index: p.Index, S: s}, nil
+}
+
+// VerifyPartial verifies the partial signature of the hash, against the
+// points of its signer.
+func (p *Presignature) VerifyPartial(hash []byte, ps *PartialSignature) error {
+	if ps == nil {
+		return fmt.Errorf("%w: nil partial signature", ErrInvalidPartial)
+	}
+	rBar, ok := p.RBar[ps.Index]
+	if !ok || ps.S == nil {
+		return fmt.Errorf("%w: unknown signer %d", ErrInvalidPartial, ps.Index)
+	}
+	r, err := p.rScalar()
+	if err != nil {
+		return err
+	}
+	m := toScalar(p.suite, hashToInt(hash, p.suite))
+	// s_i R = m k_i R + r σ_i R
+	left := p.suite.Point().Mul(ps.S, p.R)
+	right := p.suite.Point().Mul(r, p.S[ps.Index])
+	right.Add(right, p.suite.Point().Mul(m, rBar))
+	if !left.Equal(right) {
+		return fmt.Errorf("%w: from signer %d", ErrInvalidPartial, ps.Index)
+	}
+	return nil
+}
+
+// Combine verifies the partial signatures of the hash, one from each signer,
+// and returns the signature. The signature is normalized to a low S, as
+// required by Bitcoin and Ethereum.
+func (p *Presignature) Combine(hash []byte, partials []*PartialSignature) (*Signature, error) {
+	seen := make(map[uint32]bool)
+	s := p.suite.Scalar().Zero()
+	for _, ps := range partials {
+		if ps == nil {
+			return nil, fmt.Errorf("%w: nil partial signature", ErrInvalidPartial)
+		}
+		if seen[ps.Index] {
+			return nil, fmt.Errorf("%w: duplicate from signer %d", ErrInvalidPartial, ps.Index)
+		}
+		if err := p.VerifyPartial(hash, ps); err != nil {
+			return nil, err
+		}
+		seen[ps.Index] = true
+		s.Add(s, ps.S)
+	}
+	if len(seen) != len(p.RBar) {
+		return nil, fmt.Errorf("%w: %d partial signatures for %d signers",
+			ErrInvalidPartial, len(seen), len(p.RBar))
+	}
+	r, err := p.rScalar()
+	if err != nil {
+		return nil, err
+	}
+	sig := &Signature{R: scalarInt(r), S: scalarInt(s)}
+	n := p.suite.Params().N
+	if sig.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		sig.S.Sub(n, sig.S)
+	}
+	pub, err := ecdsaPublicKey(p.suite, p.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ecdsa.Verify(pub, hash, sig.R, sig.S) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidPartial)
+	}
+	return sig, nil
+}
+
+// rScalar returns the x-coordinate of R modulo the order, as a scalar.
+func (p *Presignature) rScalar() (kyber.Scalar, error) {
+	x, _, err := coordinates(p.suite, p.R)
+	if err != nil {
+		return nil, err
+	}
+	r := toScalar(p.suite, x)
+	if r.Equal(p.suite.Scalar().Zero()) {
+		return nil, fmt.Errorf("%w: r is zero", ErrInconsistent)
+	}
+	return r, nil
+}
+
+// signers returns the share indexes of the signers, in order.
+func (p *Presignature) signers() []uint32 {
+	signers := make([]uint32, 0, len(p.RBar))
+	for i := range p.RBar {
+		signers = append(signers, i)
+	}
+	sort.Slice(signers, func(i, j int) bool { return signers[i] < signers[j] })
+	return signers
+}
@@ -0,0 +1,171 @@
+// Package tecdsa implements threshold ECDSA signatures, following the
+// protocol of Gennaro and Goldfeder, "Fast Multiparty Threshold ECDSA with
+// Fast Trustless Setup" (GG18), with the presigning and the consistency
+// checks of "One Round Threshold ECDSA with Identifiable Abort" (GG20).
+// The signatures verify with crypto/ecdsa, on secp256k1 (group/secp256k1) and
+// P-256 (group/p256).
+//
+// The key is generated with the share/dkg/pedersen package: each node ends up
+// with a DistKeyShare, and the ECDSA public key is the public key of the DKG.
+// Each node also generates PreParams once, and sends the PublicParams to the
+// other nodes, which verify them.
+//
+// A signature is then issued by any set of at least threshold signers, in
+// two steps:
+//   - the presigning, independent of the message, where the signers run the
+//     rounds of a Presigner. The MtA (multiplicative-to-additive) sub-protocol
+//     turns the products of their secrets into additive shares, with Paillier
+//     encryption and range proofs. Each signer ends up with a Presignature.
+//   - the signing, where each signer issues a PartialSignature of the hash
+//     with its presignature, and anyone holding the presignature of a signer
+//     combines them into the signature.
+//
+// A presignature must only be used once, or the private key leaks. The
+// messages of the presigning must be sent on authenticated channels. The
+// protocol aborts if a check fails, but the proofs identifying the culprit
+// of GG20 are not implemented: the presignature consistency checks tell that
+// a signer misbehaved, and only the partial signatures are verified one by
+// one.
+package tecdsa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/share"
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Suite represents the functionalities needed by the tecdsa package: a group
+// which is also an elliptic.Curve, as the suites of group/secp256k1 and
+// group/p256.
+type Suite interface {
+	kyber.Group
+	kyber.HashFactory
+	kyber.Random
+	elliptic.Curve
+}
+
+// DistKeyShare is the key share of a node, as generated by the
+// share/dkg/pedersen package.
+type DistKeyShare interface {
+	PriShare() *share.PriShare
+	Commitments() []kyber.Point
+}
+
+var (
+	// ErrInvalidMessage is returned when a message of the presigning is
+	// invalid.
+	ErrInvalidMessage = errors.New("tecdsa: invalid message")
+	// ErrInconsistent is returned when the consistency checks of the
+	// presigning fail: a signer misbehaved.
+	ErrInconsistent = errors.New("tecdsa: inconsistent presignature")
+	// ErrPresignatureUsed is returned when a presignature is used twice.
+	ErrPresignatureUsed = errors.New("tecdsa: presignature already used")
+	// ErrInvalidPartial is returned when a partial signature is invalid.
+	ErrInvalidPartial = errors.New("tecdsa: invalid partial signature")
+)
+
+// Signature is an ECDSA signature.
+type Signature struct {
+	R, S *big.Int
+}
+
+// MarshalASN1 returns the ASN.1 DER encoding of the signature, as accepted by
+// ecdsa.VerifyASN1.
+func (s *Signature) MarshalASN1() ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1BigInt(s.R)
+		b.AddASN1BigInt(s.S)
+	})
+	return b.Bytes()
+}
+
+// PublicKey returns the ECDSA public key of the distributed key.
+func PublicKey(s Suite, key DistKeyShare) (*ecdsa.PublicKey, error) {
+	return ecdsaPublicKey(s, key.Commitments()[0])
+}
+
+func ecdsaPublicKey(s Suite, p kyber.Point) (*ecdsa.PublicKey, error) {
+	x, y, err := coordinates(s, p)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: ecdsaCurve(s), X: x, Y: y}, nil
+}
+
+// ecdsaCurve returns the curve of the standard library for the NIST curves,
+// so that the public keys are the ones of crypto/ecdsa.
+func ecdsaCurve(s Suite) elliptic.Curve {
+	for _, c := range []elliptic.Curve{elliptic.P224(), elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		if s.Params() == c.Params() {
+			return c
+		}
+	}
+	return s
+}
+
+// coordinates returns the affine coordinates of the point, from its
+// uncompressed ANSI X9.62 encoding.
+func coordinates(s Suite, p kyber.Point) (*big.Int, *big.Int, error) {
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := (s.Params().BitSize + 7) / 8
+	if len(buf) != 1+2*size || buf[0] != 4 {
+		return nil, nil, errors.New("tecdsa: unsupported point encoding")
+	}
+	x := new(big.Int).SetBytes(buf[1 : 1+size])
+	y := new(big.Int).SetBytes(buf[1+size:])
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, nil, errors.New("tecdsa: point at infinity")
+	}
+	return x, y, nil
+}
+
+// hashToInt converts a hash to an integer as crypto/ecdsa does: it keeps the
+// leftmost bits of the hash, as many as the bit length of the order.
+func hashToInt(hash []byte, c elliptic.Curve) *big.Int {
+	orderBits := c.Params().N.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	ret := new(big.Int).SetBytes(hash)
+	excess := len(hash)*8 - orderBits
+	if excess > 0 {
+		ret.Rsh(ret, uint(excess))
+	}
+	return ret
+}
+
+// scalarInt returns the value of the scalar.
+func scalarInt(s kyber.Scalar) *big.Int {
+	buf, err := s.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+// lagrange returns the Lagrange coefficient at 0 of the share index i, for
+// the set of share indexes.
+func lagrange(g kyber.Group, i uint32, indexes []uint32) kyber.Scalar {
+	num, den := g.Scalar().One(), g.Scalar().One()
+	xi := g.Scalar().SetInt64(int64(i) + 1)
+	for _, j := range indexes {
+		if j == i {
+			continue
+		}
+		xj := g.Scalar().SetInt64(int64(j) + 1)
+		num.Mul(num, xj)
+		den.Mul(den, xj.Sub(xj, xi))
+	}
+	return num.Div(num, den)
+}
@@ -0,0 +1,347 @@
+package tecdsa
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/p256"
+	"go.dedis.ch/kyber/v4/group/secp256k1"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+)
+
+// testPreParams are built from safe primes, whose generation is too slow for
+// the tests.
+var testPreParams []*PreParams
+
+// testParams are the public parameters matching testPreParams, by share
+// index.
+var testParams = make(map[uint32]*PublicParams)
+
+func paramsContext(i uint32) []byte {
+	return binary.BigEndian.AppendUint32([]byte("tecdsa test"), i)
+}
+
+func TestMain(m *testing.M) {
+	f, err := os.Open("testdata/safeprimes.txt")
+	if err != nil {
+		panic(err)
+	}
+	var primes []*big.Int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		p, ok := new(big.Int).SetString(scanner.Text(), 16)
+		if !ok {
+			panic("invalid prime")
+		}
+		primes = append(primes, p)
+	}
+	f.Close()
+	suite := secp256k1.NewBlakeSHA256Secp256k1()
+	for i := 0; i+4 <= len(primes); i += 4 {
+		pp, err := NewPreParams(suite.RandomStream(), primes[i], primes[i+1], primes[i+2], primes[i+3])
+		if err != nil {
+			panic(err)
+		}
+		index := uint32(len(testPreParams))
+		testPreParams = append(testPreParams, pp)
+		testParams[index], err = pp.Public(suite.RandomStream(), paramsContext(index))
+		if err != nil {
+			panic(err)
+		}
+	}
+	os.Exit(m.Run())
+}
+
+// runDKG runs a pedersen DKG without faults, and returns the key shares.
+func runDKG(t *testing.T, suite dkg.Suite, n, thr int) []*dkg.DistKeyShare {
+	privates := make([]kyber.Scalar, n)
+	nodes := make([]dkg.Node, n)
+	for i := range nodes {
+		privates[i] = suite.Scalar().Pick(suite.RandomStream())
+		nodes[i] = dkg.Node{Index: uint32(i), Public: suite.Point().Mul(privates[i], nil)}
+	}
+	nonce := dkg.GetNonce()
+	gens := make([]*dkg.DistKeyGenerator, n)
+	var deals []*dkg.DealBundle
+	for i := range gens {
+		var err error
+		gens[i], err = dkg.NewDistKeyHandler(&dkg.Config{
+			Suite:     suite,
+			Longterm:  privates[i],
+			NewNodes:  nodes,
+			Threshold: thr,
+			Auth:      schnorr.NewScheme(suite),
+			Nonce:     nonce,
+		})
+		require.NoError(t, err)
+		d, err := gens[i].Deals()
+		require.NoError(t, err)
+		deals = append(deals, d)
+	}
+	for _, g := range gens {
+		resp, err := g.ProcessDeals(deals)
+		require.NoError(t, err)
+		require.Nil(t, resp)
+	}
+	shares := make([]*dkg.DistKeyShare, n)
+	for i, g := range gens {
+		res, _, err := g.ProcessResponses(nil)
+		require.NoError(t, err)
+		shares[i] = res.Key
+	}
+	return shares
+}
+
+// presign runs the presigning for the signers. The tamper function, if not
+// nil, can modify the messages of each round before they are delivered.
+func presign(t *testing.T, suite Suite, shares []*dkg.DistKeyShare, signers []uint32,
+	tamper func(msg interface{})) ([]*Presignature, error) {
+	ps := make([]*Presigner, len(signers))
+	for i, s := range signers {
+		var err error
+		ps[i], err = NewPresigner(&Config{
+			Suite:     suite,
+			Share:     shares[s],
+			PreParams: testPreParams[s],
+			Params:    testParams,
+			Signers:   signers,
+			SessionID: []byte("session"),
+		})
+		require.NoError(t, err)
+	}
+	if tamper == nil {
+		tamper = func(interface{}) {}
+	}
+
+	var r1 []*Round1Message
+	for _, p := range ps {
+		msgs, err := p.Round1()
+		require.NoError(t, err)
+		r1 = append(r1, msgs...)
+	}
+	var r2 []*Round2Message
+	for _, m := range r1 {
+		tamper(m)
+	}
+	for _, p := range ps {
+		msgs, err := p.Round2(filter(r1, func(m *Round1Message) bool { return m.To == p.index }))
+		if err != nil {
+			return nil, err
+		}
+		r2 = append(r2, msgs...)
+	}
+	for _, m := range r2 {
+		tamper(m)
+	}
+	var r3 []*Round3Message
+	for _, p := range ps {
+		msg, err := p.Round3(filter(r2, func(m *Round2Message) bool { return m.To == p.index }))
+		if err != nil {
+			return nil, err
+		}
+		tamper(msg)
+		r3 = append(r3, msg)
+	}
+	var r4 []*Round4Message
+	for _, p := range ps {
+		msg, err := p.Round4(filter(r3, func(m *Round3Message) bool { return m.From != p.index }))
+		if err != nil {
+			return nil, err
+		}
+		tamper(msg)
+		r4 = append(r4, msg)
+	}
+	var r5 []*Round5Message
+	for _, p := range ps {
+		msg, err := p.Round5(filter(r4, func(m *Round4Message) bool { return m.From != p.index }))
+		if err != nil {
+			return nil, err
+		}
+		tamper(msg)
+		r5 = append(r5, msg)
+	}
+	var presigs []*Presignature
+	for _, p := range ps {
+		presig, err := p.Presignature(filter(r5, func(m *Round5Message) bool { return m.From != p.index }))
+		if err != nil {
+			return nil, err
+		}
+		presigs = append(presigs, presig)
+	}
+	return presigs, nil
+}
+
+// filter returns the messages to keep.
+func filter[M any](msgs []M, keep func(M) bool) []M {
+	var res []M
+	for _, m := range msgs {
+		if keep(m) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// testSuite is a suite for both the DKG and the signatures.
+type testSuite interface {
+	Suite
+	kyber.XOFFactory
+}
+
+func testSign(t *testing.T, suite testSuite, n, thr int, signers []uint32) {
+	shares := runDKG(t, suite, n, thr)
+	presigs, err := presign(t, suite, shares, signers, nil)
+	require.NoError(t, err)
+	pub, err := PublicKey(suite, shares[0])
+	require.NoError(t, err)
+	require.True(t, pub.Curve.IsOnCurve(pub.X, pub.Y))
+
+	hash := sha256.Sum256([]byte("threshold ecdsa"))
+	var partials []*PartialSignature
+	for _, p := range presigs {
+		ps, err := p.Sign(hash[:])
+		require.NoError(t, err)
+		partials = append(partials, ps)
+	}
+	for _, p := range presigs {
+		sig, err := p.Combine(hash[:], partials)
+		require.NoError(t, err)
+		require.True(t, ecdsa.Verify(pub, hash[:], sig.R, sig.S))
+		require.LessOrEqual(t, sig.S.Cmp(new(big.Int).Rsh(suite.Params().N, 1)), 0)
+		der, err := sig.MarshalASN1()
+		require.NoError(t, err)
+		require.True(t, ecdsa.VerifyASN1(pub, hash[:], der))
+	}
+
+	// a presignature is used once
+	_, err = presigs[0].Sign(hash[:])
+	require.ErrorIs(t, err, ErrPresignatureUsed)
+
+	// invalid partial signatures
+	other := sha256.Sum256([]byte("other message"))
+	require.ErrorIs(t, presigs[0].VerifyPartial(other[:], partials[1]), ErrInvalidPartial)
+	bad := &PartialSignature{Index: partials[1].Index, S: suite.Scalar().Add(partials[1].S, suite.Scalar().One())}
+	_, err = presigs[0].Combine(hash[:], append([]*PartialSignature{bad}, partials[2:]...))
+	require.ErrorIs(t, err, ErrInvalidPartial)
+	_, err = presigs[0].Combine(hash[:], partials[1:])
+	require.ErrorIs(t, err, ErrInvalidPartial)
+	require.ErrorIs(t, presigs[0].VerifyPartial(hash[:], nil), ErrInvalidPartial)
+	_, err = presigs[0].Combine(hash[:], append([]*PartialSignature{nil}, partials[1:]...))
+	require.ErrorIs(t, err, ErrInvalidPartial)
+}
+
+func TestSignSecp256k1(t *testing.T) {
+	suite := secp256k1.NewBlakeSHA256Secp256k1()
+	testSign(t, suite, 3, 2, []uint32{0, 2})
+}
+
+func TestSignP256(t *testing.T) {
+	suite := p256.NewBlakeSHA256P256()
+	testSign(t, suite, 3, 2, []uint32{0, 1, 2})
+	pub, err := PublicKey(suite, runDKG(t, suite, 2, 2)[0])
+	require.NoError(t, err)
+	require.Equal(t, elliptic.P256(), pub.Curve)
+}
+
+func TestParams(t *testing.T) {
+	for i, p := range testParams {
+		require.NoError(t, p.Verify(paramsContext(i)))
+		require.ErrorIs(t, p.Verify(paramsContext(i+1)), ErrInvalidParams)
+	}
+	p := *testParams[0]
+	p.H2 = new(big.Int).Add(p.H2, big.NewInt(1))
+	require.ErrorIs(t, p.Verify(paramsContext(0)), ErrInvalidParams)
+	p = *testParams[0]
+	p.DLNProof2 = p.DLNProof1
+	require.ErrorIs(t, p.Verify(paramsContext(0)), ErrInvalidParams)
+	p = *testParams[0]
+	p.NTilde = new(big.Int).Rsh(p.NTilde, 1024)
+	require.ErrorIs(t, p.Verify(paramsContext(0)), ErrInvalidParams)
+}
+
+func TestPresignMisbehavior(t *testing.T) {
+	suite := secp256k1.NewBlakeSHA256Secp256k1()
+	shares := runDKG(t, suite, 3, 2)
+	signers := []uint32{0, 1}
+	one := big.NewInt(1)
+	for name, c := range map[string]struct {
+		tamper func(msg interface{})
+		err    error
+	}{
+		"range proof": {func(msg interface{}) {
+			if m, ok := msg.(*Round1Message); ok && m.From == 1 {
+				m.K = testParams[1].Paillier.Add(m.K, m.K)
+			}
+		}, ErrInvalidMessage},
+		"MtA": {func(msg interface{}) {
+			if m, ok := msg.(*Round2Message); ok && m.From == 1 {
+				m.CGamma = new(big.Int).Add(m.CGamma, one)
+			}
+		}, ErrInvalidMessage},
+		"MtAwc": {func(msg interface{}) {
+			if m, ok := msg.(*Round2Message); ok && m.From == 0 {
+				m.ProofW.S1 = new(big.Int).Add(m.ProofW.S1, one)
+			}
+		}, ErrInvalidMessage},
+		"opening": {func(msg interface{}) {
+			if m, ok := msg.(*Round4Message); ok && m.From == 1 {
+				m.Gamma = suite.Point().Pick(suite.RandomStream())
+			}
+		}, ErrInvalidMessage},
+		"delta": {func(msg interface{}) {
+			if m, ok := msg.(*Round3Message); ok && m.From == 1 {
+				m.Delta = suite.Scalar().Add(m.Delta, suite.Scalar().One())
+			}
+		}, ErrInconsistent},
+		"key point": {func(msg interface{}) {
+			if m, ok := msg.(*Round5Message); ok && m.From == 0 {
+				m.S = suite.Point().Add(m.S, suite.Point().Base())
+			}
+		}, ErrInconsistent},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := presign(t, suite, shares, signers, c.tamper)
+			require.ErrorIs(t, err, c.err)
+		})
+	}
+}
+
+func TestPresignerConfig(t *testing.T) {
+	suite := secp256k1.NewBlakeSHA256Secp256k1()
+	shares := runDKG(t, suite, 3, 2)
+	c := &Config{
+		Suite:     suite,
+		Share:     shares[0],
+		PreParams: testPreParams[0],
+		Params:    testParams,
+		Signers:   []uint32{0},
+		SessionID: []byte("session"),
+	}
+	_, err := NewPresigner(c)
+	require.Error(t, err, "not enough signers")
+	c.Signers = []uint32{1, 2}
+	_, err = NewPresigner(c)
+	require.Error(t, err, "not a signer")
+	c.Signers = []uint32{0, 1, 1}
+	_, err = NewPresigner(c)
+	require.Error(t, err, "duplicate signer")
+
+	c.Signers = []uint32{0, 1}
+	p, err := NewPresigner(c)
+	require.NoError(t, err)
+	_, err = p.Round2(nil)
+	require.Error(t, err, "round out of order")
+	_, err = p.Round1()
+	require.NoError(t, err)
+	_, err = p.Round2(nil)
+	require.ErrorIs(t, err, ErrInvalidMessage)
+}
@@ -0,0 +1,312 @@
+package tecdsa
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/encrypt/paillier"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+// The zero-knowledge proofs of the MtA protocol follow the appendix A of
+// "Fast Multiparty Threshold ECDSA with Fast Trustless Setup" (GG18). They
+// are made non-interactive with the Fiat-Shamir transform, and use the
+// ring-Pedersen parameters (Ñ, h1, h2) of the verifier.
+
+var one = big.NewInt(1)
+
+// ringPedersen are the ring-Pedersen parameters of a verifier.
+type ringPedersen struct {
+	N, H1, H2 *big.Int
+}
+
+// commit returns h1^x h2^r mod Ñ.
+func (rp *ringPedersen) commit(x, r *big.Int) *big.Int {
+	c := new(big.Int).Exp(rp.H1, x, rp.N)
+	c.Mul(c, new(big.Int).Exp(rp.H2, r, rp.N))
+	return c.Mod(c, rp.N)
+}
+
+// challenge hashes the values to a challenge in [0, q).
+func challenge(q *big.Int, context []byte, values ...*big.Int) *big.Int {
+	e := new(big.Int).SetBytes(hashInts(context, values...))
+	return e.Mod(e, q)
+}
+
+// hashInts hashes the context and the length-prefixed values.
+func hashInts(context []byte, values ...*big.Int) []byte {
+	h := sha256.New()
+	_ = binary.Write(h, binary.BigEndian, uint32(len(context)))
+	_, _ = h.Write(context)
+	for _, v := range values {
+		b := v.Bytes()
+		_ = binary.Write(h, binary.BigEndian, uint32(len(b)))
+		_, _ = h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// inUnits tells if all the values are in Z*_n.
+func inUnits(n *big.Int, values ...*big.Int) bool {
+	gcd := new(big.Int)
+	for _, v := range values {
+		if v == nil || v.Sign() <= 0 || v.Cmp(n) >= 0 || gcd.GCD(nil, nil, v, n).Cmp(one) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// inRange tells if all the values are in [0, bound].
+func inRange(bound *big.Int, values ...*big.Int) bool {
+	for _, v := range values {
+		if v == nil || v.Sign() < 0 || v.Cmp(bound) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func pow(q *big.Int, e int64) *big.Int {
+	return new(big.Int).Exp(q, big.NewInt(e), nil)
+}
+
+// RangeProof proves that the plaintext of a Paillier ciphertext is in
+// [0, q³], where q is the order of the curve.
+type RangeProof struct {
+	Z, U, W, S, S1, S2 *big.Int
+}
+
+// proveRange proves that c = (N+1)^m r^N mod N² with m < q.
+func proveRange(rand cipher.Stream, q *big.Int, context []byte, pk *paillier.PublicKey,
+	rp *ringPedersen, c, m, r *big.Int) *RangeProof {
+	q3 := pow(q, 3)
+	alpha := random.Int(q3, rand)
+	beta := paillier.RandomUnit(rand, pk.N)
+	gamma := random.Int(new(big.Int).Mul(q3, rp.N), rand)
+	rho := random.Int(new(big.Int).Mul(q, rp.N), rand)
+
+	z := rp.commit(m, rho)
+	u, _ := pk.EncryptWithNonce(alpha, beta) //nolint:errcheck // alpha < q³ < N
+	w := rp.commit(alpha, gamma)
+	e := challenge(q, context, pk.N, rp.N, c, z, u, w)
+
+	s := new(big.Int).Exp(r, e, pk.N)
+	s.Mul(s, beta).Mod(s, pk.N)
+	s1 := new(big.Int).Mul(e, m)
+	s1.Add(s1, alpha)
+	s2 := new(big.Int).Mul(e, rho)
+	s2.Add(s2, gamma)
+	return &RangeProof{Z: z, U: u, W: w, S: s, S1: s1, S2: s2}
+}
+
+// verify verifies the proof for the ciphertext c.
+func (p *RangeProof) verify(q *big.Int, context []byte, pk *paillier.PublicKey,
+	rp *ringPedersen, c *big.Int) bool {
+	n2 := pk.N2()
+	if p == nil || !inUnits(rp.N, p.Z, p.W) || !inUnits(n2, p.U, c) || !inUnits(pk.N, p.S) ||
+		!inRange(pow(q, 3), p.S1) || !inRange(new(big.Int).Mul(pow(q, 4), rp.N), p.S2) {
+		return false
+	}
+	e := challenge(q, context, pk.N, rp.N, c, p.Z, p.U, p.W)
+
+	// u = (N+1)^s1 s^N c^-e mod N²
+	u, err := pk.EncryptWithNonce(new(big.Int).Mod(p.S1, pk.N), p.S)
+	if err != nil {
+		return false
+	}
+	ce := new(big.Int).Exp(c, e, n2)
+	u.Mul(u, ce.ModInverse(ce, n2)).Mod(u, n2)
+	if u.Cmp(p.U) != 0 {
+		return false
+	}
+
+	// w = h1^s1 h2^s2 z^-e mod Ñ
+	w := rp.commit(p.S1, p.S2)
+	ze := new(big.Int).Exp(p.Z, e, rp.N)
+	w.Mul(w, ze.ModInverse(ze, rp.N)).Mod(w, rp.N)
+	return w.Cmp(p.W) == 0
+}
+
+// RespondProof proves that a Paillier ciphertext c2 = c1^x (N+1)^y r^N mod N²
+// with x < q and y < q⁵, and optionally that x is the discrete logarithm of
+// a point X.
+type RespondProof struct {
+	Z, ZPrm, T, V, W, S, S1, S2, T1, T2 *big.Int
+	// U is αG, only set when the proof is about X.
+	U kyber.Point
+}
+
+// proveRespond proves the statement of a RespondProof. X is nil for a proof
+// without the check.
+func proveRespond(rand cipher.Stream, g kyber.Group, q *big.Int, context []byte, pk *paillier.PublicKey,
+	rp *ringPedersen, c1, c2 *big.Int, X kyber.Point, x, y, r *big.Int) *RespondProof {
+	q3 := pow(q, 3)
+	qN := new(big.Int).Mul(q, rp.N)
+	q3N := new(big.Int).Mul(q3, rp.N)
+	n2 := pk.N2()
+
+	alpha := random.Int(q3, rand)
+	rho := random.Int(qN, rand)
+	sigma := random.Int(qN, rand)
+	tau := random.Int(q3N, rand)
+	rhoPrm := random.Int(q3N, rand)
+	beta := paillier.RandomUnit(rand, pk.N)
+	gamma := random.Int(pow(q, 7), rand)
+
+	p := &RespondProof{
+		Z:    rp.commit(x, rho),
+		ZPrm: rp.commit(alpha, rhoPrm),
+		T:    rp.commit(y, sigma),
+		W:    rp.commit(gamma, tau),
+	}
+	// v = c1^α (N+1)^γ β^N mod N²
+	p.V, _ = pk.EncryptWithNonce(new(big.Int).Mod(gamma, pk.N), beta) //nolint:errcheck // reduced
+	p.V.Mul(p.V, new(big.Int).Exp(c1, alpha, n2)).Mod(p.V, n2)
+	if X != nil {
+		p.U = g.Point().Mul(toScalar(g, alpha), nil)
+	}
+
+	e := respondChallenge(q, context, pk, rp, c1, c2, X, p)
+	p.S = new(big.Int).Exp(r, e, pk.N)
+	p.S.Mul(p.S, beta).Mod(p.S, pk.N)
+	p.S1 = new(big.Int).Mul(e, x)
+	p.S1.Add(p.S1, alpha)
+	p.S2 = new(big.Int).Mul(e, rho)
+	p.S2.Add(p.S2, rhoPrm)
+	p.T1 = new(big.Int).Mul(e, y)
+	p.T1.Add(p.T1, gamma)
+	p.T2 = new(big.Int).Mul(e, sigma)
+	p.T2.Add(p.T2, tau)
+	return p
+}
+
+// verify verifies the proof for the ciphertexts c1 and c2, and the point X
+// if it isn't nil.
+func (p *RespondProof) verify(g kyber.Group, q *big.Int, context []byte, pk *paillier.PublicKey,
+	rp *ringPedersen, c1, c2 *big.Int, X kyber.Point) bool {
+	n2 := pk.N2()
+	if p == nil || !inUnits(rp.N, p.Z, p.ZPrm, p.T, p.W) || !inUnits(n2, p.V, c1, c2) ||
+		!inUnits(pk.N, p.S) || !inRange(pow(q, 3), p.S1) || !inRange(pow(q, 7), p.T1) ||
+		!inRange(new(big.Int).Mul(pow(q, 4), rp.N), p.S2, p.T2) || (X != nil) != (p.U != nil) {
+		return false
+	}
+	e := respondChallenge(q, context, pk, rp, c1, c2, X, p)
+
+	// s1 G = e X + u
+	if X != nil {
+		left := g.Point().Mul(toScalar(g, p.S1), nil)
+		right := g.Point().Mul(toScalar(g, e), X)
+		if !left.Equal(right.Add(right, p.U)) {
+			return false
+		}
+	}
+
+	// h1^s1 h2^s2 = z^e z' mod Ñ
+	right := new(big.Int).Exp(p.Z, e, rp.N)
+	right.Mul(right, p.ZPrm).Mod(right, rp.N)
+	if rp.commit(p.S1, p.S2).Cmp(right) != 0 {
+		return false
+	}
+
+	// h1^t1 h2^t2 = t^e w mod Ñ
+	right.Exp(p.T, e, rp.N)
+	right.Mul(right, p.W).Mod(right, rp.N)
+	if rp.commit(p.T1, p.T2).Cmp(right) != 0 {
+		return false
+	}
+
+	// c1^s1 s^N (N+1)^t1 = c2^e v mod N²
+	left, err := pk.EncryptWithNonce(new(big.Int).Mod(p.T1, pk.N), p.S)
+	if err != nil {
+		return false
+	}
+	left.Mul(left, new(big.Int).Exp(c1, p.S1, n2)).Mod(left, n2)
+	right.Exp(c2, e, n2)
+	right.Mul(right, p.V).Mod(right, n2)
+	return left.Cmp(right) == 0
+}
+
+func respondChallenge(q *big.Int, context []byte, pk *paillier.PublicKey,
+	rp *ringPedersen, c1, c2 *big.Int, X kyber.Point, p *RespondProof) *big.Int {
+	values := []*big.Int{pk.N, rp.N, c1, c2, p.Z, p.ZPrm, p.T, p.V, p.W}
+	if X != nil {
+		values = append(values, pointInt(X), pointInt(p.U))
+	}
+	return challenge(q, context, values...)
+}
+
+// dlnIterations is the number of binary challenges of a DLNProof.
+const dlnIterations = 128
+
+// DLNProof proves the knowledge of the discrete logarithm x of h2 in base
+// h1 modulo Ñ, with binary challenges.
+type DLNProof struct {
+	Alpha, T []*big.Int
+}
+
+// proveDLN proves that h2 = h1^x mod Ñ, where the order of h1 divides pq.
+func proveDLN(rand cipher.Stream, context []byte, n, h1, h2, x, pq *big.Int) *DLNProof {
+	p := &DLNProof{
+		Alpha: make([]*big.Int, dlnIterations),
+		T:     make([]*big.Int, dlnIterations),
+	}
+	a := make([]*big.Int, dlnIterations)
+	for i := range a {
+		a[i] = random.Int(pq, rand)
+		p.Alpha[i] = new(big.Int).Exp(h1, a[i], n)
+	}
+	c := dlnChallenge(context, n, h1, h2, p.Alpha)
+	for i := range a {
+		p.T[i] = new(big.Int).Set(a[i])
+		if c.Bit(i) == 1 {
+			p.T[i].Add(p.T[i], x).Mod(p.T[i], pq)
+		}
+	}
+	return p
+}
+
+func (p *DLNProof) verify(context []byte, n, h1, h2 *big.Int) bool {
+	if p == nil || len(p.Alpha) != dlnIterations || len(p.T) != dlnIterations ||
+		!inUnits(n, h1, h2) || h1.Cmp(one) == 0 || h2.Cmp(one) == 0 {
+		return false
+	}
+	if !inUnits(n, p.Alpha...) || !inRange(n, p.T...) {
+		return false
+	}
+	c := dlnChallenge(context, n, h1, h2, p.Alpha)
+	left, right := new(big.Int), new(big.Int)
+	for i := range p.T {
+		left.Exp(h1, p.T[i], n)
+		right.Set(p.Alpha[i])
+		if c.Bit(i) == 1 {
+			right.Mul(right, h2).Mod(right, n)
+		}
+		if left.Cmp(right) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func dlnChallenge(context []byte, n, h1, h2 *big.Int, alpha []*big.Int) *big.Int {
+	values := append([]*big.Int{n, h1, h2}, alpha...)
+	return new(big.Int).SetBytes(hashInts(context, values...)[:dlnIterations/8])
+}
+
+// toScalar returns v mod q as a scalar of g.
+func toScalar(g kyber.Group, v *big.Int) kyber.Scalar {
+	return g.Scalar().SetBytes(v.Bytes())
+}
+
+// pointInt returns the encoding of the point as an integer, for hashing.
+func pointInt(p kyber.Point) *big.Int {
+	b, err := p.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return new(big.Int).SetBytes(b)
+}
@@ -3,6 +3,7 @@ package suites
 import (
 	"go.dedis.ch/kyber/v4/group/edwards25519"
 	"go.dedis.ch/kyber/v4/group/p256"
+	"go.dedis.ch/kyber/v4/group/secp256k1"
 	"go.dedis.ch/kyber/v4/pairing/bls12381/circl"
 	"go.dedis.ch/kyber/v4/pairing/bls12381/kilic"
 	"go.dedis.ch/kyber/v4/pairing/bn254"
@@ -12,16 +13,23 @@ import (
 func init() {
 	// Those are variable time suites that shouldn't be used
 	// in production environment when possible
-	register(p256.NewBlakeSHA256P256())
-	register(p256.NewBlakeSHA256QR512())
-	register(bn256.NewSuiteG1())
-	register(bn256.NewSuiteG2())
-	register(bn256.NewSuiteGT())
-	register(bn256.NewSuiteBn256())
-	register(bn254.NewSuite())
-	register(circl.NewSuiteBLS12381())
-	register(kilic.NewSuiteBLS12381())
+	primeOrder := Capabilities{PrimeOrder: true}
+	register(func() Suite { return p256.NewBlakeSHA256P256() }, primeOrder)
+	register(func() Suite { return p256.NewBlakeSHA256QR512() }, primeOrder)
+	register(func() Suite { return secp256k1.NewBlakeSHA256Secp256k1() }, primeOrder)
+
+	pairing := Capabilities{PairingFriendly: true, PrimeOrder: true}
+	hashablePairing := Capabilities{PairingFriendly: true, PrimeOrder: true, HashToCurve: true}
+	register(func() Suite { return bn256.NewSuiteG1() }, hashablePairing)
+	register(func() Suite { return bn256.NewSuiteG2() }, pairing)
+	register(func() Suite { return bn256.NewSuiteGT() }, pairing)
+	register(func() Suite { return bn256.NewSuiteBn256() }, pairing)
+	register(func() Suite { return bn254.NewSuite() },
+		Capabilities{PairingFriendly: true, PrimeOrder: true, PairingGroupsOnly: true})
+	register(func() Suite { return circl.NewSuiteBLS12381() }, hashablePairing)
+	register(func() Suite { return kilic.NewSuiteBLS12381() }, hashablePairing)
 	// This is a constant time implementation that should be
-	// used as much as possible
-	register(edwards25519.NewBlakeSHA256Ed25519())
+	// used as much as possible. The curve has a cofactor of 8.
+	register(func() Suite { return edwards25519.NewBlakeSHA256Ed25519() },
+		Capabilities{ConstantTime: true, HashToCurve: true})
 }
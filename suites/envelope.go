@@ -0,0 +1,175 @@
+package suites
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+// EnvelopeVersion is the version of the envelope encoding.
+const EnvelopeVersion = 1
+
+// Kind is the kind of object held by an envelope.
+type Kind byte
+
+// The kinds of objects an envelope can hold.
+const (
+	KindPoint Kind = iota + 1
+	KindScalar
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindPoint:
+		return "point"
+	case KindScalar:
+		return "scalar"
+	default:
+		return fmt.Sprintf("kind(%d)", byte(k))
+	}
+}
+
+// ErrInvalidEnvelope is returned when decoding a malformed envelope.
+var ErrInvalidEnvelope = errors.New("suites: invalid envelope")
+
+// An envelope is a self-describing encoding of a point or a scalar: it starts
+// with the version byte, the kind byte and the name of the suite prefixed by
+// its length in one byte, followed by the MarshalBinary encoding of the
+// object. Decoding looks the suite up with Find, so that the object can be
+// decoded without knowing its group in advance.
+//
+// The points of a pairing suite are the ones of the group returned by its
+// Point method, such as G2 for the BLS12-381 suites: the points of the other
+// groups of the pairing are rejected by MarshalEnvelope, since they would be
+// decoded as points of that group. The suites whose capabilities have
+// PairingGroupsOnly, such as "bn254", have no envelopes.
+
+// marshalID is implemented by the points and scalars with an 8-byte
+// identifier of their type, such as the ones of edwards25519.
+type marshalID interface {
+	MarshalID() [8]byte
+}
+
+// MarshalEnvelope returns the envelope of the point or scalar of the suite.
+// The suite must be registered, so that the envelope can be decoded.
+func MarshalEnvelope(s Suite, obj kyber.Marshaling) ([]byte, error) {
+	var kind Kind
+	switch obj.(type) {
+	case kyber.Point:
+		kind = KindPoint
+	case kyber.Scalar:
+		kind = KindScalar
+	default:
+		return nil, fmt.Errorf("%w: unsupported object %T", ErrInvalidEnvelope, obj)
+	}
+	name := strings.ToLower(s.String())
+	r, ok := lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSuite, s.String())
+	}
+	if len(name) > 255 {
+		return nil, fmt.Errorf("%w: suite name too long", ErrInvalidEnvelope)
+	}
+	ref, err := newObject(s, r.capabilities, kind)
+	if err != nil {
+		return nil, err
+	}
+	// catch objects of another group: of another type, such as the points of
+	// the other groups of a pairing, or with another marshalling ID
+	if reflect.TypeOf(obj) != reflect.TypeOf(ref) {
+		return nil, fmt.Errorf("%w: %s of another group than %s", ErrInvalidEnvelope, kind, s)
+	}
+	if id, ok := obj.(marshalID); ok {
+		if refID, ok := ref.(marshalID); ok && refID.MarshalID() != id.MarshalID() {
+			return nil, fmt.Errorf("%w: %s of another group than %s", ErrInvalidEnvelope, kind, s)
+		}
+	}
+	payload, err := obj.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, 3+len(name)+len(payload))
+	buf = append(buf, EnvelopeVersion, byte(kind), byte(len(name)))
+	buf = append(buf, name...)
+	return append(buf, payload...), nil
+}
+
+// UnmarshalEnvelope decodes an envelope encoded with MarshalEnvelope. It
+// returns the suite, the kind of the object, and the object, which is a
+// kyber.Point or a kyber.Scalar of the suite.
+func UnmarshalEnvelope(buf []byte) (Suite, Kind, kyber.Marshaling, error) {
+	if len(buf) < 3 || len(buf) < 3+int(buf[2]) {
+		return nil, 0, nil, fmt.Errorf("%w: too short", ErrInvalidEnvelope)
+	}
+	if buf[0] != EnvelopeVersion {
+		return nil, 0, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, buf[0])
+	}
+	kind := Kind(buf[1])
+	end := 3 + int(buf[2])
+	name := string(buf[3:end])
+	s, err := Find(name)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	c, err := CapabilitiesOf(name)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	obj, err := newObject(s, c, kind)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	payload := buf[end:]
+	if len(payload) != obj.MarshalSize() {
+		return nil, 0, nil, fmt.Errorf("%w: wrong %s length", ErrInvalidEnvelope, kind)
+	}
+	if err := obj.UnmarshalBinary(payload); err != nil {
+		return nil, 0, nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	return s, kind, obj, nil
+}
+
+// newObject returns a point or a scalar of the suite with these
+// capabilities.
+func newObject(s Suite, c Capabilities, kind Kind) (kyber.Marshaling, error) {
+	if c.PairingGroupsOnly {
+		return nil, fmt.Errorf("%w: suite %s only has elements in the groups of its pairing",
+			ErrInvalidEnvelope, s)
+	}
+	switch kind {
+	case KindPoint:
+		return s.Point(), nil
+	case KindScalar:
+		return s.Scalar(), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown %s", ErrInvalidEnvelope, kind)
+	}
+}
+
+// UnmarshalPoint decodes the envelope of a point.
+func UnmarshalPoint(buf []byte) (Suite, kyber.Point, error) {
+	s, kind, obj, err := UnmarshalEnvelope(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if kind != KindPoint {
+		return nil, nil, fmt.Errorf("%w: %s instead of point", ErrInvalidEnvelope, kind)
+	}
+	return s, obj.(kyber.Point), nil //nolint:errcheck // checked by the kind
+}
+
+// UnmarshalScalar decodes the envelope of a scalar.
+func UnmarshalScalar(buf []byte) (Suite, kyber.Scalar, error) {
+	s, kind, obj, err := UnmarshalEnvelope(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if kind != KindScalar {
+		return nil, nil, fmt.Errorf("%w: %s instead of scalar", ErrInvalidEnvelope, kind)
+	}
+	return s, obj.(kyber.Scalar), nil //nolint:errcheck // checked by the kind
+}
@@ -0,0 +1,93 @@
+package suites
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/pairing"
+	"go.dedis.ch/kyber/v4/pairing/bn256"
+)
+
+func TestEnvelope(t *testing.T) {
+	for _, name := range []string{"ed25519", "P256", "Residue512", "bn256.G1", "bn256.G2", "bn256.GT",
+		"bn256.adapter", "bls12381-circl", "bls12381-kilic"} {
+		s := MustFind(name)
+		p := s.Point().Pick(s.RandomStream())
+		x := s.Scalar().Pick(s.RandomStream())
+
+		buf, err := MarshalEnvelope(s, p)
+		require.NoError(t, err)
+		s2, p2, err := UnmarshalPoint(buf)
+		require.NoError(t, err, name)
+		require.Equal(t, s.String(), s2.String())
+		require.True(t, p.Equal(p2))
+		_, _, err = UnmarshalScalar(buf)
+		require.ErrorIs(t, err, ErrInvalidEnvelope)
+
+		buf, err = MarshalEnvelope(s, x)
+		require.NoError(t, err)
+		s2, kind, obj, err := UnmarshalEnvelope(buf)
+		require.NoError(t, err)
+		require.Equal(t, KindScalar, kind)
+		require.Equal(t, s.String(), s2.String())
+		require.True(t, x.Equal(obj.(kyber.Scalar)))
+		_, _, err = UnmarshalPoint(buf)
+		require.ErrorIs(t, err, ErrInvalidEnvelope)
+
+		_, _, _, err = UnmarshalEnvelope(buf[:len(buf)-1])
+		require.ErrorIs(t, err, ErrInvalidEnvelope)
+		_, _, _, err = UnmarshalEnvelope(append(buf, 0))
+		require.ErrorIs(t, err, ErrInvalidEnvelope)
+	}
+}
+
+func TestEnvelopeInvalid(t *testing.T) {
+	s := MustFind("ed25519")
+	buf, err := MarshalEnvelope(s, s.Point().Base())
+	require.NoError(t, err)
+
+	for _, bad := range [][]byte{
+		nil,
+		buf[:2],
+		buf[:5],
+		append([]byte{2}, buf[1:]...),
+		append([]byte{1, 3}, buf[2:]...),
+	} {
+		_, _, _, err = UnmarshalEnvelope(bad)
+		require.ErrorIs(t, err, ErrInvalidEnvelope)
+	}
+	_, _, _, err = UnmarshalEnvelope(append([]byte{1, 1, 3, 'f', 'o', 'o'}, buf[10:]...))
+	require.ErrorIs(t, err, ErrUnknownSuite)
+
+	// some pairing suites only have points in their groups
+	_, _, _, err = UnmarshalEnvelope(append([]byte{1, 1, 5, 'b', 'n', '2', '5', '4'}, buf[10:]...))
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+	bn254 := MustFind("bn254")
+	_, err = MarshalEnvelope(bn254, bn254.(pairing.Suite).G1().Point())
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	// the points of a pairing suite are the ones of its Point method
+	kilic := MustFind("bls12381-kilic")
+	for _, g := range []kyber.Group{kilic.(pairing.Suite).G1(), kilic.(pairing.Suite).GT()} {
+		_, err = MarshalEnvelope(kilic, g.Point().Null())
+		require.ErrorIs(t, err, ErrInvalidEnvelope)
+	}
+
+	// the object must be of the group of the suite
+	_, err = MarshalEnvelope(s, bn256.NewSuiteG1().Point())
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+	_, err = MarshalEnvelope(s, nil)
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+	_, err = MarshalEnvelope(edwards25519.NewBlakeSHA256Ed25519WithRand(nil), s.Point())
+	require.NoError(t, err)
+
+	RequireConstantTime()
+	defer func() { requireConstTime = false }()
+	p256 := suites["p256"].factory()
+	buf, err = MarshalEnvelope(p256, p256.Point().Base())
+	require.NoError(t, err)
+	_, _, err = UnmarshalPoint(buf)
+	require.Error(t, err)
+}
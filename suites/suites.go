@@ -1,12 +1,29 @@
 // Package suites allows callers to look up Kyber suites by name.
 //
-// Currently, only the "ed25519" suite is available with a constant
+// Suites are registered with Register, along with their capabilities, so
+// that third-party suites can be looked up like the ones of Kyber, and
+// callers can select suites by their capabilities with Names.
+//
+// It also provides envelopes, self-describing encodings of points and
+// scalars naming their suite, which can be decoded without knowing the group
+// in advance.
+//
+// The BLS12-381 suites are named after their backend, "bls12381-circl" and
+// "bls12381-kilic", like in the registry of the pairing package. Their former
+// names "circl.adapter" and "kilic.adapter" are still accepted as aliases by
+// Find and CapabilitiesOf, so that envelopes and keystores encoded with them
+// can be decoded, but are not returned by Names.
+//
+// Currently, only the "ed25519" suite of Kyber is available with a constant
 // time implementation and the other ones use variable time algorithms.
 package suites
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"go.dedis.ch/kyber/v4"
 )
@@ -20,31 +37,105 @@ type Suite interface {
 	kyber.Random
 }
 
-var suites = map[string]Suite{}
+// Capabilities are the properties of a registered suite.
+type Capabilities struct {
+	// ConstantTime tells whether the implementation of the group uses
+	// constant time algorithms for secret values.
+	ConstantTime bool
+	// PairingFriendly tells whether the suite is part of a pairing.
+	PairingFriendly bool
+	// PrimeOrder tells whether the group has a prime order, with no cofactor
+	// to clear.
+	PrimeOrder bool
+	// HashToCurve tells whether messages can be hashed to the points of the
+	// suite.
+	HashToCurve bool
+	// PairingGroupsOnly tells whether the suite only provides points and
+	// scalars through the groups G1, G2 and GT of its pairing, so that its
+	// Point and Scalar methods panic and it has no envelopes.
+	PairingGroupsOnly bool
+}
 
-var requireConstTime = false
+// Factory creates a suite. It is called by Find for every lookup.
+type Factory func() Suite
 
-// register is called by suites to make themselves known to Kyber.
-func register(s Suite) {
-	suites[strings.ToLower(s.String())] = s
+type registration struct {
+	factory      Factory
+	capabilities Capabilities
 }
 
-// ErrUnknownSuite indicates that the suite was not one of the
-// registered suites.
-var ErrUnknownSuite = errors.New("unknown suite")
+var (
+	mu               sync.RWMutex
+	suites           = map[string]registration{}
+	requireConstTime = false
+)
+
+// aliases maps the former names of suites to their current name.
+var aliases = map[string]string{
+	"circl.adapter": "bls12381-circl",
+	"kilic.adapter": "bls12381-kilic",
+}
+
+var (
+	// ErrUnknownSuite indicates that the suite was not one of the
+	// registered suites.
+	ErrUnknownSuite = errors.New("unknown suite")
+	// ErrDuplicateSuite is returned when registering a suite under a name
+	// which is already registered.
+	ErrDuplicateSuite = errors.New("suite already registered")
+	// ErrNotConstantTime is returned by Find when the suite exists but is not
+	// constant time, while suites.RequireConstantTime was called.
+	ErrNotConstantTime = errors.New("requested suite exists but is not implemented " +
+		"with constant time algorithms as required by suites.RequireConstantTime")
+)
+
+// Register makes the suite created by factory known under the name, which
+// is case-insensitive, with its capabilities. Names must be unique. The name
+// should be the one returned by the String method of the suite, which is the
+// one used by envelopes.
+func Register(name string, factory Factory, capabilities Capabilities) error {
+	if name == "" || factory == nil {
+		return errors.New("suites: empty name or nil factory")
+	}
+	name = strings.ToLower(name)
+	mu.Lock()
+	defer mu.Unlock()
+	_, alias := aliases[name]
+	if _, ok := suites[name]; ok || alias {
+		return fmt.Errorf("%w: %s", ErrDuplicateSuite, name)
+	}
+	suites[name] = registration{factory: factory, capabilities: capabilities}
+	return nil
+}
+
+// register is called by the suites of Kyber to make themselves known.
+func register(factory Factory, capabilities Capabilities) {
+	if err := Register(factory().String(), factory, capabilities); err != nil {
+		panic(err)
+	}
+}
+
+func lookup(name string) (registration, bool) {
+	name = strings.ToLower(name)
+	if n, ok := aliases[name]; ok {
+		name = n
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := suites[name]
+	return r, ok
+}
 
 // Find looks up a suite by name.
 func Find(name string) (Suite, error) {
-	if s, ok := suites[strings.ToLower(name)]; ok {
-		if requireConstTime && strings.ToLower(s.String()) != "ed25519" {
-			return nil, errors.New(
-				"requested suite exists but is not implemented " +
-					"with constant time algorithms as required by " +
-					"suites.RequireConstantTime")
-		}
-		return s, nil
+	r, ok := lookup(name)
+	if !ok {
+		return nil, ErrUnknownSuite
+	}
+	if requireConstTime && !r.capabilities.ConstantTime {
+		return nil, ErrNotConstantTime
 	}
-	return nil, ErrUnknownSuite
+	return r.factory(), nil
 }
 
 // MustFind looks up a suite by name and panics if it is not found.
@@ -56,6 +147,35 @@ func MustFind(name string) Suite {
 	return s
 }
 
+// CapabilitiesOf returns the capabilities of a registered suite.
+func CapabilitiesOf(name string) (Capabilities, error) {
+	r, ok := lookup(name)
+	if !ok {
+		return Capabilities{}, ErrUnknownSuite
+	}
+	return r.capabilities, nil
+}
+
+// Names returns the sorted names of the registered suites whose
+// capabilities satisfy the filter, or of all of them if the filter is nil.
+// With suites.RequireConstantTime, only the constant time suites are
+// returned.
+func Names(filter func(Capabilities) bool) []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var names []string
+	for name, r := range suites {
+		if requireConstTime && !r.capabilities.ConstantTime {
+			continue
+		}
+		if filter == nil || filter(r.capabilities) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // RequireConstantTime causes all future calls to Find and MustFind to only
 // search for suites where the implementation is constant time.
 // It should be called in an init() function for the main package
@@ -63,7 +183,7 @@ func MustFind(name string) Suite {
 // Once constant time implementations are required, there is no way to
 // turn it back off (by design).
 //
-// At this time, the only constant time crypto suite is "Ed25519".
+// At this time, the only constant time crypto suite of Kyber is "Ed25519".
 func RequireConstantTime() {
 	requireConstTime = true
 }
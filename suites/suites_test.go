@@ -1,9 +1,12 @@
 package suites
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/secp256k1"
 )
 
 func TestSuites_Find(t *testing.T) {
@@ -14,6 +17,7 @@ func TestSuites_Find(t *testing.T) {
 		"bn256.GT",
 		"P256",
 		"Residue512",
+		"secp256k1",
 	}
 
 	for _, name := range ss {
@@ -38,3 +42,106 @@ func TestSuites_ConstTime(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, s)
 }
+
+func TestSuites_Secp256k1(t *testing.T) {
+	// the scalar multiplication of secp256k1 is variable time
+	c, err := CapabilitiesOf("secp256k1")
+	require.NoError(t, err)
+	require.Equal(t, Capabilities{PrimeOrder: true}, c)
+}
+
+func TestSuites_Register(t *testing.T) {
+	factory := func() Suite { return secp256k1.NewBlakeSHA256Secp256k1() }
+	require.NoError(t, Register("Test-secp256k1", factory, Capabilities{PrimeOrder: true}))
+	defer func() {
+		mu.Lock()
+		delete(suites, "test-secp256k1")
+		mu.Unlock()
+	}()
+
+	s, err := Find("TEST-SECP256K1")
+	require.NoError(t, err)
+	require.Equal(t, "secp256k1", s.String())
+	require.Contains(t, Names(nil), "test-secp256k1")
+
+	err = Register("test-secp256k1", factory, Capabilities{})
+	require.ErrorIs(t, err, ErrDuplicateSuite)
+	err = Register("secp256k1", factory, Capabilities{})
+	require.ErrorIs(t, err, ErrDuplicateSuite)
+	err = Register("ED25519", factory, Capabilities{})
+	require.ErrorIs(t, err, ErrDuplicateSuite)
+	err = Register("circl.adapter", factory, Capabilities{})
+	require.ErrorIs(t, err, ErrDuplicateSuite)
+	require.Error(t, Register("", factory, Capabilities{}))
+	require.Error(t, Register("nil", nil, Capabilities{}))
+
+	// a third-party suite declared constant time can be found with
+	// RequireConstantTime
+	require.NoError(t, Register("ct-secp256k1", factory, Capabilities{ConstantTime: true}))
+	defer func() {
+		mu.Lock()
+		delete(suites, "ct-secp256k1")
+		mu.Unlock()
+	}()
+	RequireConstantTime()
+	defer func() { requireConstTime = false }()
+	_, err = Find("secp256k1")
+	require.ErrorIs(t, err, ErrNotConstantTime)
+	_, err = Find("ct-secp256k1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ct-secp256k1", "ed25519"}, Names(nil))
+}
+
+func TestSuites_Aliases(t *testing.T) {
+	for alias, name := range map[string]string{
+		"circl.adapter": "bls12381-circl",
+		"Kilic.Adapter": "bls12381-kilic",
+	} {
+		s, err := Find(alias)
+		require.NoError(t, err)
+		require.Equal(t, name, s.String())
+		caps, err := CapabilitiesOf(alias)
+		require.NoError(t, err)
+		require.True(t, caps.PairingFriendly)
+		require.NotContains(t, Names(nil), strings.ToLower(alias))
+	}
+
+	// envelopes encoded under the former names still decode
+	s := MustFind("bls12381-circl")
+	p := s.Point().Pick(s.RandomStream())
+	buf, err := MarshalEnvelope(s, p)
+	require.NoError(t, err)
+	old := append([]byte{buf[0], buf[1], byte(len("circl.adapter"))}, "circl.adapter"...)
+	old = append(old, buf[3+buf[2]:]...)
+	s2, _, obj, err := UnmarshalEnvelope(old)
+	require.NoError(t, err)
+	require.Equal(t, "bls12381-circl", s2.String())
+	require.True(t, p.Equal(obj.(kyber.Point)))
+}
+
+func TestSuites_Capabilities(t *testing.T) {
+	caps, err := CapabilitiesOf("Ed25519")
+	require.NoError(t, err)
+	require.Equal(t, Capabilities{ConstantTime: true, HashToCurve: true}, caps)
+	_, err = CapabilitiesOf("unknown")
+	require.ErrorIs(t, err, ErrUnknownSuite)
+
+	pairing := Names(func(c Capabilities) bool { return c.PairingFriendly })
+	require.Equal(t, []string{
+		"bls12381-circl", "bls12381-kilic",
+		"bn254", "bn256.adapter", "bn256.g1", "bn256.g2", "bn256.gt",
+	}, pairing)
+	hashable := Names(func(c Capabilities) bool { return c.HashToCurve && c.PrimeOrder })
+	require.Equal(t, []string{"bls12381-circl", "bls12381-kilic", "bn256.g1"}, hashable)
+
+	// the declared hash to curve matches the points of the suites
+	for _, name := range Names(func(c Capabilities) bool { return c.HashToCurve }) {
+		switch MustFind(name).Point().(type) {
+		case kyber.HashablePoint, interface {
+			Hash([]byte, string) kyber.Point
+		}:
+		default:
+			t.Errorf("%s points can't be hashed to", name)
+		}
+	}
+}
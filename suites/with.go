@@ -0,0 +1,71 @@
+package suites
+
+import (
+	"crypto/cipher"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/util/key"
+)
+
+// WithXOF returns a suite using the given XOF constructor instead of the one
+// of s, for instance k12.New or a closure over cshake.New128, to speed up the
+// hashing of proofs and shuffles. Everything else is delegated to s, and key
+// generation uses s if it implements key.Generator. Methods of s outside of
+// Suite, such as the ones of pairing suites, aren't available on the result.
+//
+// Note that the XOF is part of protocols: parties must agree on it, and the
+// outputs of the result aren't interoperable with the ones of s.
+func WithXOF(s Suite, newXOF func(seed []byte) kyber.XOF) Suite {
+	w := wrap(s)
+	w.newXOF = newXOF
+	return w
+}
+
+// WithRandom returns a suite whose RandomStream is random instead of the one
+// of s, for instance a DRBG of util/random. Everything else is delegated to s
+// as with WithXOF. The stream must be safe for concurrent use if the suite is
+// used concurrently.
+func WithRandom(s Suite, random cipher.Stream) Suite {
+	w := wrap(s)
+	w.random = random
+	return w
+}
+
+// wrapped overrides the XOF or the random stream of a suite.
+type wrapped struct {
+	Suite
+	newXOF func(seed []byte) kyber.XOF
+	random cipher.Stream
+}
+
+// wrap returns a copy of s if it is already wrapped, so that the overrides
+// compose, or a new wrapper otherwise.
+func wrap(s Suite) *wrapped {
+	if w, ok := s.(*wrapped); ok {
+		c := *w
+		return &c
+	}
+	return &wrapped{Suite: s}
+}
+
+func (w *wrapped) XOF(seed []byte) kyber.XOF {
+	if w.newXOF == nil {
+		return w.Suite.XOF(seed)
+	}
+	return w.newXOF(seed)
+}
+
+func (w *wrapped) RandomStream() cipher.Stream {
+	if w.random == nil {
+		return w.Suite.RandomStream()
+	}
+	return w.random
+}
+
+// NewKey implements key.Generator.
+func (w *wrapped) NewKey(random cipher.Stream) kyber.Scalar {
+	if g, ok := w.Suite.(key.Generator); ok {
+		return g.NewKey(random)
+	}
+	return w.Scalar().Pick(random)
+}
@@ -0,0 +1,72 @@
+package suites
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/proof/dleq"
+	"go.dedis.ch/kyber/v4/util/key"
+	"go.dedis.ch/kyber/v4/util/random"
+	"go.dedis.ch/kyber/v4/xof/cshake"
+	"go.dedis.ch/kyber/v4/xof/k12"
+)
+
+func TestWithXOF(t *testing.T) {
+	ed25519 := MustFind("Ed25519")
+	cshakeXOF := func(seed []byte) kyber.XOF { return cshake.New256(seed, []byte("test")) }
+	for _, newXOF := range []func([]byte) kyber.XOF{k12.New, cshakeXOF} {
+		s := WithXOF(ed25519, newXOF)
+		require.Equal(t, ed25519.String(), s.String())
+
+		expected := make([]byte, 32)
+		newXOF([]byte("seed")).Read(expected)
+		out := make([]byte, 32)
+		s.XOF([]byte("seed")).Read(out)
+		require.Equal(t, expected, out)
+
+		// Fiat-Shamir proofs use the XOF of the suite
+		x := s.Scalar().Pick(s.RandomStream())
+		G, H := s.Point().Base(), s.Point().Pick(s.RandomStream())
+		proof, xG, xH, err := dleq.NewDLEQProof(s, G, H, x)
+		require.NoError(t, err)
+		require.NoError(t, proof.Verify(s, G, H, xG, xH))
+		points := func(p kyber.Point) []kyber.Point { return []kyber.Point{p} }
+		c, err := dleq.Challenge(s, points(G), points(H), points(xG), points(xH), points(proof.VG), points(proof.VH))
+		require.NoError(t, err)
+		require.True(t, c.Equal(proof.C))
+		c, err = dleq.Challenge(ed25519, points(G), points(H), points(xG), points(xH), points(proof.VG), points(proof.VH))
+		require.NoError(t, err)
+		require.False(t, c.Equal(proof.C))
+
+		// key generation is the one of the underlying suite
+		require.Implements(t, (*key.Generator)(nil), s)
+		pair := key.NewKeyPair(s)
+		require.True(t, pair.Public.Equal(s.Point().Mul(pair.Private, nil)))
+	}
+}
+
+func TestWithRandom(t *testing.T) {
+	ed25519 := MustFind("Ed25519")
+	newDRBG := func() *random.DRBG {
+		d, err := random.NewHMACDRBG(&random.DRBGConfig{Entropy: bytes.NewReader(make([]byte, 1024))})
+		require.NoError(t, err)
+		return d
+	}
+
+	// the key pairs are deterministic with a DRBG seeded deterministically
+	s1 := WithRandom(ed25519, newDRBG())
+	s2 := WithXOF(WithRandom(ed25519, newDRBG()), k12.New)
+	pair1, pair2 := key.NewKeyPair(s1), key.NewKeyPair(s2)
+	require.True(t, pair1.Public.Equal(pair2.Public))
+	require.True(t, pair1.Public.Equal(s1.Point().Mul(pair1.Private, nil)))
+
+	// the overrides compose
+	out := make([]byte, 32)
+	s2.XOF(nil).Read(out)
+	expected := make([]byte, 32)
+	k12.New(nil).Read(expected)
+	require.Equal(t, expected, out)
+	require.NotEqual(t, ed25519.RandomStream(), s1.RandomStream())
+}
@@ -1,5 +1,9 @@
 // Package encoding package provides helper functions to encode/decode a Point/Scalar in
 // hexadecimal.
+//
+// It also encodes the keys of some suites in standard text formats: PEM with
+// PKIX and PKCS #8 for Ed25519 and P-256, JWK for Ed25519 and P-256, and
+// multibase with multicodec for BLS12-381 public keys.
 package encoding
 
 import (
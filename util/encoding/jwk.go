@@ -0,0 +1,171 @@
+package encoding
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/suites"
+)
+
+// JWK is a JSON Web Key (RFC 7517): an OKP key (RFC 8037) for the "Ed25519"
+// suite, or an EC key (RFC 7518) for the "P256" suite. The members are
+// base64url encoded without padding. D is the private key, which is the seed
+// for Ed25519.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// PointToJWK returns the JSON encoding of the public key as a JWK.
+func PointToJWK(s suites.Suite, p kyber.Point) ([]byte, error) {
+	key, err := stdPublicKey(s, p)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(publicJWK(key))
+}
+
+// ScalarToJWK returns the JSON encoding of the private key as a JWK, with its
+// public key. It returns ErrEd25519Seed for Ed25519, see Ed25519SeedToJWK.
+func ScalarToJWK(s suites.Suite, x kyber.Scalar) ([]byte, error) {
+	key, err := stdP256PrivateKey(s, x)
+	if err != nil {
+		return nil, err
+	}
+	jwk := publicJWK(&key.PublicKey)
+	jwk.D = b64.EncodeToString(key.D.FillBytes(make([]byte, 32)))
+	return json.Marshal(jwk)
+}
+
+// Ed25519SeedToJWK returns the JSON encoding as a JWK of the Ed25519 private
+// key with the given seed, with its public key.
+func Ed25519SeedToJWK(seed []byte) ([]byte, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: wrong seed size", ErrInvalidKey)
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+	jwk := publicJWK(key.Public())
+	jwk.D = b64.EncodeToString(seed)
+	return json.Marshal(jwk)
+}
+
+func publicJWK(key interface{}) *JWK {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: b64.EncodeToString(k)}
+	case *ecdsa.PublicKey:
+		return &JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   b64.EncodeToString(k.X.FillBytes(make([]byte, 32))),
+			Y:   b64.EncodeToString(k.Y.FillBytes(make([]byte, 32))),
+		}
+	default:
+		panic("unreachable")
+	}
+}
+
+// JWKToPoint decodes the public key of a JWK of the suite.
+func JWKToPoint(s suites.Suite, data []byte) (kyber.Point, error) {
+	pub, _, err := parseJWK(s, data)
+	if err != nil {
+		return nil, err
+	}
+	return fromStdPublicKey(s, pub)
+}
+
+// JWKToScalar decodes the private key of a JWK of the suite. It checks that
+// the public key of the JWK matches the private key.
+func JWKToScalar(s suites.Suite, data []byte) (kyber.Scalar, error) {
+	pub, priv, err := parseJWK(s, data)
+	if err != nil {
+		return nil, err
+	}
+	if priv == nil {
+		return nil, fmt.Errorf("%w: no private key", ErrInvalidKey)
+	}
+	x, err := fromStdPrivateKey(s, priv)
+	if err != nil {
+		return nil, err
+	}
+	p, err := fromStdPublicKey(s, pub)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Equal(s.Point().Mul(x, nil)) {
+		return nil, fmt.Errorf("%w: public key doesn't match the private key", ErrInvalidKey)
+	}
+	return x, nil
+}
+
+// parseJWK returns the public key and the private key, if any, of the JWK as
+// keys of the standard library.
+func parseJWK(s suites.Suite, data []byte) (interface{}, interface{}, error) {
+	t, err := suiteKeyType(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	var jwk JWK
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	x, err := decodeMember(jwk.X, "x")
+	if err != nil {
+		return nil, nil, err
+	}
+	var d []byte
+	if jwk.D != "" {
+		if d, err = decodeMember(jwk.D, "d"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	switch {
+	case t == keyEd25519 && jwk.Kty == "OKP" && jwk.Crv == "Ed25519":
+		if len(x) != ed25519.PublicKeySize || jwk.Y != "" || (d != nil && len(d) != ed25519.SeedSize) {
+			return nil, nil, fmt.Errorf("%w: wrong member size", ErrInvalidKey)
+		}
+		if d == nil {
+			return ed25519.PublicKey(x), nil, nil
+		}
+		return ed25519.PublicKey(x), ed25519.NewKeyFromSeed(d), nil
+	case t == keyP256 && jwk.Kty == "EC" && jwk.Crv == "P-256":
+		y, err := decodeMember(jwk.Y, "y")
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(x) != 32 || len(y) != 32 || (d != nil && len(d) != 32) {
+			return nil, nil, fmt.Errorf("%w: wrong member size", ErrInvalidKey)
+		}
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if d == nil {
+			return pub, nil, nil
+		}
+		return pub, &ecdsa.PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(d)}, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s key on curve %q for suite %s", ErrInvalidKey, jwk.Kty, jwk.Crv, s)
+	}
+}
+
+func decodeMember(v, name string) ([]byte, error) {
+	buf, err := b64.DecodeString(v)
+	if err != nil || len(buf) == 0 {
+		return nil, fmt.Errorf("%w: invalid member %q", ErrInvalidKey, name)
+	}
+	return buf, nil
+}
@@ -0,0 +1,79 @@
+package encoding
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/suites"
+)
+
+func TestJWKEd25519(t *testing.T) {
+	suite := suites.MustFind("Ed25519")
+	// RFC 8037, appendix A.1
+	jwk := `{"kty":"OKP","crv":"Ed25519",` +
+		`"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",` +
+		`"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	x, err := JWKToScalar(suite, []byte(jwk))
+	require.NoError(t, err)
+	p, err := JWKToPoint(suite, []byte(jwk))
+	require.NoError(t, err)
+	require.True(t, p.Equal(suite.Point().Mul(x, nil)))
+	pb, err := p.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a", hex.EncodeToString(pb))
+
+	seed, err := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	require.NoError(t, err)
+	data, err := Ed25519SeedToJWK(seed)
+	require.NoError(t, err)
+	require.JSONEq(t, jwk, string(data))
+
+	data, err = PointToJWK(suite, p)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`, string(data))
+	_, err = JWKToScalar(suite, data)
+	require.ErrorIs(t, err, ErrInvalidKey)
+	_, err = ScalarToJWK(suite, x)
+	require.ErrorIs(t, err, ErrEd25519Seed)
+
+	// the public key must match the private key
+	var bad JWK
+	require.NoError(t, json.Unmarshal([]byte(jwk), &bad))
+	bad.X = "2FqYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	data, err = json.Marshal(bad)
+	require.NoError(t, err)
+	_, err = JWKToScalar(suite, data)
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestJWKP256(t *testing.T) {
+	suite := suites.MustFind("P256")
+	// RFC 7517, appendix A.2
+	jwk := `{"kty":"EC","crv":"P-256",` +
+		`"x":"MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4",` +
+		`"y":"4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM",` +
+		`"d":"870MB6gfuTJ4HtUnUvYMyJpr5eUZNP4Bk43bVdj3eAE"}`
+	x, err := JWKToScalar(suite, []byte(jwk))
+	require.NoError(t, err)
+	data, err := ScalarToJWK(suite, x)
+	require.NoError(t, err)
+	require.JSONEq(t, jwk, string(data))
+
+	p, err := JWKToPoint(suite, []byte(jwk))
+	require.NoError(t, err)
+	data, err = PointToJWK(suite, p)
+	require.NoError(t, err)
+	p2, err := JWKToPoint(suite, data)
+	require.NoError(t, err)
+	require.True(t, p.Equal(p2))
+
+	// keys of another suite are rejected
+	_, err = JWKToPoint(suites.MustFind("Ed25519"), data)
+	require.ErrorIs(t, err, ErrInvalidKey)
+	_, err = JWKToPoint(suite, []byte(`{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}`))
+	require.ErrorIs(t, err, ErrInvalidKey)
+	_, err = JWKToPoint(suite, []byte(`{`))
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,174 @@
+package encoding
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/suites"
+)
+
+var (
+	// ErrUnsupportedSuite is returned when encoding keys of a suite which the
+	// encoding doesn't support.
+	ErrUnsupportedSuite = errors.New("encoding: unsupported suite")
+	// ErrInvalidKey is returned when decoding a key which is malformed, or
+	// of another type than the suite.
+	ErrInvalidKey = errors.New("encoding: invalid key")
+	// ErrEd25519Seed is returned when encoding an Ed25519 private key given
+	// as a scalar: the standard encodings hold the 32-byte seed the scalar is
+	// derived from, which can't be recovered from the scalar. Use the
+	// functions taking the seed instead.
+	ErrEd25519Seed = errors.New("encoding: Ed25519 private keys are encoded as seeds")
+)
+
+// keyType is the type of the keys of a suite supported by the standard
+// library.
+type keyType int
+
+const (
+	keyEd25519 keyType = iota + 1
+	keyP256
+)
+
+func suiteKeyType(s suites.Suite) (keyType, error) {
+	switch strings.ToLower(s.String()) {
+	case "ed25519":
+		return keyEd25519, nil
+	case "p256":
+		return keyP256, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedSuite, s.String())
+	}
+}
+
+// stdPublicKey returns the key of the standard library of the point, an
+// ed25519.PublicKey or an *ecdsa.PublicKey.
+func stdPublicKey(s suites.Suite, p kyber.Point) (interface{}, error) {
+	t, err := suiteKeyType(s)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if t == keyEd25519 {
+		return ed25519.PublicKey(buf), nil
+	}
+	// uncompressed ANSI X9.62 encoding, with the point at infinity as zeros
+	if len(buf) != 65 || buf[0] != 4 {
+		return nil, fmt.Errorf("%w: unexpected P-256 point encoding", ErrInvalidKey)
+	}
+	x, y := new(big.Int).SetBytes(buf[1:33]), new(big.Int).SetBytes(buf[33:])
+	if !elliptic.P256().IsOnCurve(x, y) {
+		return nil, fmt.Errorf("%w: point at infinity", ErrInvalidKey)
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+// fromStdPublicKey returns the point of the key of the standard library,
+// which must be of the type of the suite.
+func fromStdPublicKey(s suites.Suite, key interface{}) (kyber.Point, error) {
+	t, err := suiteKeyType(s)
+	if err != nil {
+		return nil, err
+	}
+	var buf []byte
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		if t != keyEd25519 {
+			return nil, fmt.Errorf("%w: Ed25519 key for suite %s", ErrInvalidKey, s)
+		}
+		buf = k
+	case *ecdsa.PublicKey:
+		if t != keyP256 || k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ECDSA key for suite %s", ErrInvalidKey, s)
+		}
+		buf = make([]byte, 65)
+		buf[0] = 4
+		k.X.FillBytes(buf[1:33])
+		k.Y.FillBytes(buf[33:])
+	default:
+		return nil, fmt.Errorf("%w: %T for suite %s", ErrInvalidKey, key, s)
+	}
+	p := s.Point()
+	if err := p.UnmarshalBinary(buf); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return p, nil
+}
+
+// stdP256PrivateKey returns the ECDSA private key of the scalar.
+func stdP256PrivateKey(s suites.Suite, x kyber.Scalar) (*ecdsa.PrivateKey, error) {
+	t, err := suiteKeyType(s)
+	if err != nil {
+		return nil, err
+	}
+	if t == keyEd25519 {
+		return nil, ErrEd25519Seed
+	}
+	buf, err := x.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := stdPublicKey(s, s.Point().Mul(x, nil))
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PrivateKey{
+		PublicKey: *pub.(*ecdsa.PublicKey), //nolint:errcheck // P-256 suite
+		D:         new(big.Int).SetBytes(buf),
+	}, nil
+}
+
+// fromStdPrivateKey returns the scalar of the key of the standard library,
+// which must be of the type of the suite. The scalar of an Ed25519 key is
+// derived from its seed.
+func fromStdPrivateKey(s suites.Suite, key interface{}) (kyber.Scalar, error) {
+	t, err := suiteKeyType(s)
+	if err != nil {
+		return nil, err
+	}
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		if t != keyEd25519 {
+			return nil, fmt.Errorf("%w: Ed25519 key for suite %s", ErrInvalidKey, s)
+		}
+		return ed25519Scalar(s, k.Seed())
+	case *ecdsa.PrivateKey:
+		if t != keyP256 || k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ECDSA key for suite %s", ErrInvalidKey, s)
+		}
+		buf := make([]byte, s.ScalarLen())
+		k.D.FillBytes(buf)
+		x := s.Scalar()
+		if err := x.UnmarshalBinary(buf); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+		return x, nil
+	default:
+		return nil, fmt.Errorf("%w: %T for suite %s", ErrInvalidKey, key, s)
+	}
+}
+
+// ed25519Scalar returns the private scalar derived from an Ed25519 seed, as
+// edwards25519 does.
+func ed25519Scalar(s suites.Suite, seed []byte) (kyber.Scalar, error) {
+	g, ok := s.(interface {
+		NewKeyAndSeedWithInput([]byte) (kyber.Scalar, []byte, []byte)
+	})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s can't derive keys from seeds", ErrUnsupportedSuite, s)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: wrong seed size", ErrInvalidKey)
+	}
+	x, _, _ := g.NewKeyAndSeedWithInput(seed)
+	return x, nil
+}
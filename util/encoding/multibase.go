@@ -0,0 +1,165 @@
+package encoding
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/suites"
+)
+
+// The multibase encodings of BLS12-381 public keys are the compressed points
+// of G1 or G2, prefixed by their multicodec code as an unsigned varint, and
+// encoded in a multibase: base58btc ('z') for encoding, as in the did:key
+// method, and also base16 ('f') and base64url ('u') for decoding. See
+// https://github.com/multiformats/multicodec and
+// https://github.com/multiformats/multibase.
+
+// multicodec codes of the BLS12-381 public keys
+const (
+	codecBLS12381G1 = 0xea
+	codecBLS12381G2 = 0xeb
+)
+
+// sizes of the compressed BLS12-381 points
+const (
+	blsG1Size = 48
+	blsG2Size = 96
+)
+
+// pairingGroups is implemented by the BLS12-381 suites, whose Point is in G2.
+type pairingGroups interface {
+	G1() kyber.Group
+	G2() kyber.Group
+}
+
+// blsGroups returns the groups G1 and G2 of a BLS12-381 suite.
+func blsGroups(s suites.Suite) (kyber.Group, kyber.Group, error) {
+	p, ok := s.(pairingGroups)
+	if !ok || p.G1().PointLen() != blsG1Size || p.G2().PointLen() != blsG2Size {
+		return nil, nil, fmt.Errorf("%w: %s is not a BLS12-381 suite", ErrUnsupportedSuite, s)
+	}
+	return p.G1(), p.G2(), nil
+}
+
+// PointToMultibase returns the base58btc multibase encoding of the public
+// key of G1 or G2 of a BLS12-381 suite.
+func PointToMultibase(s suites.Suite, p kyber.Point) (string, error) {
+	if _, _, err := blsGroups(s); err != nil {
+		return "", err
+	}
+	buf, err := p.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	var codec uint64
+	switch len(buf) {
+	case blsG1Size:
+		codec = codecBLS12381G1
+	case blsG2Size:
+		codec = codecBLS12381G2
+	default:
+		return "", fmt.Errorf("%w: not a BLS12-381 point", ErrInvalidKey)
+	}
+	data := binary.AppendUvarint(nil, codec)
+	return "z" + base58Encode(append(data, buf...)), nil
+}
+
+// MultibaseToPoint decodes the multibase encoding of a public key of G1 or
+// G2 of a BLS12-381 suite.
+func MultibaseToPoint(s suites.Suite, str string) (kyber.Point, error) {
+	g1, g2, err := blsGroups(s)
+	if err != nil {
+		return nil, err
+	}
+	if str == "" {
+		return nil, fmt.Errorf("%w: empty multibase", ErrInvalidKey)
+	}
+	var data []byte
+	switch str[0] {
+	case 'z':
+		data, err = base58Decode(str[1:])
+	case 'f':
+		data, err = hex.DecodeString(str[1:])
+	case 'u':
+		data, err = base64.RawURLEncoding.DecodeString(str[1:])
+	default:
+		return nil, fmt.Errorf("%w: unsupported multibase %q", ErrInvalidKey, str[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	codec, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: invalid multicodec", ErrInvalidKey)
+	}
+	var p kyber.Point
+	switch codec {
+	case codecBLS12381G1:
+		p = g1.Point()
+	case codecBLS12381G2:
+		p = g2.Point()
+	default:
+		return nil, fmt.Errorf("%w: unsupported multicodec %#x", ErrInvalidKey, codec)
+	}
+	if len(data[n:]) != p.MarshalSize() {
+		return nil, fmt.Errorf("%w: wrong key size", ErrInvalidKey)
+	}
+	if err := p.UnmarshalBinary(data[n:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return p, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var big58 = big.NewInt(58)
+
+// base58Encode returns the base58btc encoding of buf: leading zero bytes are
+// encoded as '1', and the rest as a big-endian number.
+func base58Encode(buf []byte) string {
+	zeros := 0
+	for zeros < len(buf) && buf[zeros] == 0 {
+		zeros++
+	}
+	n := new(big.Int).SetBytes(buf)
+	var out []byte
+	mod := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, big58, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// base58Decode decodes a base58btc string.
+func base58Decode(str string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(str) && str[zeros] == '1' {
+		zeros++
+	}
+	n := new(big.Int)
+	for i := zeros; i < len(str); i++ {
+		d := -1
+		for j := 0; j < len(base58Alphabet); j++ {
+			if base58Alphabet[j] == str[i] {
+				d = j
+				break
+			}
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", str[i])
+		}
+		n.Mul(n, big58).Add(n, big.NewInt(int64(d)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,76 @@
+package encoding
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/pairing"
+	"go.dedis.ch/kyber/v4/suites"
+)
+
+func TestMultibase(t *testing.T) {
+	for _, name := range []string{"bls12381-kilic", "bls12381-circl"} {
+		suite := suites.MustFind(name)
+		ps := suite.(pairing.Suite)
+		for _, g := range []kyber.Group{ps.G1(), ps.G2()} {
+			p := g.Point().Pick(suite.RandomStream())
+			str, err := PointToMultibase(suite, p)
+			require.NoError(t, err)
+			require.Equal(t, byte('z'), str[0])
+			p2, err := MultibaseToPoint(suite, str)
+			require.NoError(t, err)
+			require.True(t, p.Equal(p2))
+
+			// the points of G1 and G2 of the two implementations are the same
+			other := suites.MustFind("bls12381-kilic")
+			if name == "bls12381-kilic" {
+				other = suites.MustFind("bls12381-circl")
+			}
+			p3, err := MultibaseToPoint(other, str)
+			require.NoError(t, err)
+			buf, err := p.MarshalBinary()
+			require.NoError(t, err)
+			buf3, err := p3.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, buf, buf3)
+
+			codec := "ea01"
+			if len(buf) == 96 {
+				codec = "eb01"
+			}
+			p2, err = MultibaseToPoint(suite, "f"+codec+hex.EncodeToString(buf))
+			require.NoError(t, err)
+			require.True(t, p.Equal(p2))
+			_, err = MultibaseToPoint(suite, "f"+codec+hex.EncodeToString(buf[1:]))
+			require.ErrorIs(t, err, ErrInvalidKey)
+			_, err = MultibaseToPoint(suite, "fed01"+hex.EncodeToString(buf))
+			require.ErrorIs(t, err, ErrInvalidKey)
+			_, err = MultibaseToPoint(suite, "x"+str[1:])
+			require.ErrorIs(t, err, ErrInvalidKey)
+		}
+	}
+	_, err := PointToMultibase(suites.MustFind("bn256.adapter"), nil)
+	require.ErrorIs(t, err, ErrUnsupportedSuite)
+}
+
+func TestBase58(t *testing.T) {
+	for _, v := range []struct{ hex, b58 string }{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"00000000000000000000", "1111111111"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	} {
+		buf, err := hex.DecodeString(v.hex)
+		require.NoError(t, err)
+		require.Equal(t, v.b58, base58Encode(buf))
+		dec, err := base58Decode(v.b58)
+		require.NoError(t, err)
+		require.Equal(t, buf, dec)
+	}
+	_, err := base58Decode("0OIl")
+	require.Error(t, err)
+}
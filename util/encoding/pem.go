@@ -0,0 +1,129 @@
+package encoding
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/suites"
+)
+
+// The PKIX, PKCS #8 and PEM encodings are the ones of crypto/x509, for the
+// keys of the "Ed25519" and "P256" suites. Ed25519 private keys are encoded
+// as their seed, following RFC 8410, and decode to the scalar derived from
+// the seed.
+
+// PEM block types
+const (
+	pemPublicKey  = "PUBLIC KEY"
+	pemPrivateKey = "PRIVATE KEY"
+)
+
+// PointToPKIX returns the DER encoding of the public key as a PKIX
+// SubjectPublicKeyInfo.
+func PointToPKIX(s suites.Suite, p kyber.Point) ([]byte, error) {
+	key, err := stdPublicKey(s, p)
+	if err != nil {
+		return nil, err
+	}
+	return x509.MarshalPKIXPublicKey(key)
+}
+
+// PKIXToPoint decodes a public key of the suite encoded as a PKIX
+// SubjectPublicKeyInfo.
+func PKIXToPoint(s suites.Suite, der []byte) (kyber.Point, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return fromStdPublicKey(s, key)
+}
+
+// ScalarToPKCS8 returns the DER encoding of the private key in PKCS #8. It
+// returns ErrEd25519Seed for Ed25519, see Ed25519SeedToPKCS8.
+func ScalarToPKCS8(s suites.Suite, x kyber.Scalar) ([]byte, error) {
+	key, err := stdP256PrivateKey(s, x)
+	if err != nil {
+		return nil, err
+	}
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// Ed25519SeedToPKCS8 returns the DER encoding in PKCS #8 of the Ed25519
+// private key with the given seed.
+func Ed25519SeedToPKCS8(seed []byte) ([]byte, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%w: wrong seed size", ErrInvalidKey)
+	}
+	return x509.MarshalPKCS8PrivateKey(ed25519.NewKeyFromSeed(seed))
+}
+
+// PKCS8ToScalar decodes a private key of the suite encoded in PKCS #8.
+func PKCS8ToScalar(s suites.Suite, der []byte) (kyber.Scalar, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return fromStdPrivateKey(s, key)
+}
+
+// PointToPEM returns the public key as a PEM "PUBLIC KEY" block.
+func PointToPEM(s suites.Suite, p kyber.Point) ([]byte, error) {
+	der, err := PointToPKIX(s, p)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}), nil
+}
+
+// PEMToPoint decodes a public key of the suite from a PEM "PUBLIC KEY" block.
+func PEMToPoint(s suites.Suite, data []byte) (kyber.Point, error) {
+	der, err := decodePEM(data, pemPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return PKIXToPoint(s, der)
+}
+
+// ScalarToPEM returns the private key as a PEM "PRIVATE KEY" block. It
+// returns ErrEd25519Seed for Ed25519, see Ed25519SeedToPEM.
+func ScalarToPEM(s suites.Suite, x kyber.Scalar) ([]byte, error) {
+	der, err := ScalarToPKCS8(s, x)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), nil
+}
+
+// Ed25519SeedToPEM returns the Ed25519 private key with the given seed as a
+// PEM "PRIVATE KEY" block.
+func Ed25519SeedToPEM(seed []byte) ([]byte, error) {
+	der, err := Ed25519SeedToPKCS8(seed)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), nil
+}
+
+// PEMToScalar decodes a private key of the suite from a PEM "PRIVATE KEY"
+// block.
+func PEMToScalar(s suites.Suite, data []byte) (kyber.Scalar, error) {
+	der, err := decodePEM(data, pemPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return PKCS8ToScalar(s, der)
+}
+
+func decodePEM(data []byte, blockType string) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block", ErrInvalidKey)
+	}
+	if block.Type != blockType {
+		return nil, fmt.Errorf("%w: PEM block %q instead of %q", ErrInvalidKey, block.Type, blockType)
+	}
+	return block.Bytes, nil
+}
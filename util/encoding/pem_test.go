@@ -0,0 +1,103 @@
+package encoding
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/suites"
+)
+
+func TestPEMEd25519(t *testing.T) {
+	suite := suites.MustFind("Ed25519")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// keys of crypto/x509 decode to the kyber keys
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	p, err := PKIXToPoint(suite, der)
+	require.NoError(t, err)
+	pb, err := p.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, []byte(pub), pb)
+
+	der, err = x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	x, err := PKCS8ToScalar(suite, der)
+	require.NoError(t, err)
+	require.True(t, p.Equal(suite.Point().Mul(x, nil)))
+
+	// and the other way around
+	data, err := PointToPEM(suite, p)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "-----BEGIN PUBLIC KEY-----")
+	p2, err := PEMToPoint(suite, data)
+	require.NoError(t, err)
+	require.True(t, p.Equal(p2))
+	der, err = PointToPKIX(suite, p)
+	require.NoError(t, err)
+	key, err := x509.ParsePKIXPublicKey(der)
+	require.NoError(t, err)
+	require.Equal(t, pub, key)
+
+	data, err = Ed25519SeedToPEM(priv.Seed())
+	require.NoError(t, err)
+	x2, err := PEMToScalar(suite, data)
+	require.NoError(t, err)
+	require.True(t, x.Equal(x2))
+
+	_, err = ScalarToPEM(suite, x)
+	require.ErrorIs(t, err, ErrEd25519Seed)
+	_, err = PEMToScalar(suite, []byte("not a PEM block"))
+	require.ErrorIs(t, err, ErrInvalidKey)
+	pubPEM, err := PointToPEM(suite, p)
+	require.NoError(t, err)
+	_, err = PEMToScalar(suite, pubPEM)
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestPEMP256(t *testing.T) {
+	suite := suites.MustFind("P256")
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	x, err := PKCS8ToScalar(suite, der)
+	require.NoError(t, err)
+	der, err = x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+	p, err := PKIXToPoint(suite, der)
+	require.NoError(t, err)
+	require.True(t, p.Equal(suite.Point().Mul(x, nil)))
+
+	data, err := ScalarToPEM(suite, x)
+	require.NoError(t, err)
+	x2, err := PEMToScalar(suite, data)
+	require.NoError(t, err)
+	require.True(t, x.Equal(x2))
+	der, err = ScalarToPKCS8(suite, x)
+	require.NoError(t, err)
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	require.NoError(t, err)
+	require.True(t, priv.Equal(key))
+
+	data, err = PointToPEM(suite, p)
+	require.NoError(t, err)
+	p2, err := PEMToPoint(suite, data)
+	require.NoError(t, err)
+	require.True(t, p.Equal(p2))
+
+	// keys of another suite are rejected
+	_, err = PEMToPoint(suites.MustFind("Ed25519"), data)
+	require.ErrorIs(t, err, ErrInvalidKey)
+	_, err = PointToPEM(suites.MustFind("bn256.G1"), p)
+	require.ErrorIs(t, err, ErrUnsupportedSuite)
+	_, err = PointToPEM(suite, suite.Point().Null())
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,179 @@
+package hd
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/group/edwards25519"
+	"go.dedis.ch/kyber/v4/group/p256"
+	"go.dedis.ch/kyber/v4/group/secp256k1"
+)
+
+// The seeds of BIP-32 and SLIP-0010 have between 128 and 512 bits.
+const (
+	minSeedSize = 16
+	maxSeedSize = 64
+)
+
+// bip32 is BIP-32 on secp256k1, or SLIP-0010 on the other curves. The
+// schemes differ on Ed25519, which only has hardened children whose private
+// keys are the Ed25519 seeds, and on the handling of invalid keys: BIP-32
+// rejects them while SLIP-0010 derives another candidate.
+type bip32 struct {
+	g kyber.Group
+	// curve is nil for Ed25519
+	curve elliptic.Curve
+	// hmacKey is the key used to derive the master key from the seed
+	hmacKey string
+	// retry tells whether invalid keys are replaced as in SLIP-0010
+	retry bool
+}
+
+var (
+	ed25519Scheme = &bip32{
+		g:       edwards25519.NewBlakeSHA256Ed25519(),
+		hmacKey: "ed25519 seed",
+	}
+	secp256k1Scheme = &bip32{
+		g:       secp256k1.NewBlakeSHA256Secp256k1(),
+		curve:   secp256k1.S256(),
+		hmacKey: "Bitcoin seed",
+	}
+	p256Scheme = &bip32{
+		g:       p256.NewBlakeSHA256P256(),
+		curve:   elliptic.P256(),
+		hmacKey: "Nist256p1 seed",
+		retry:   true,
+	}
+)
+
+// NewEd25519 returns the SLIP-0010 master key of the seed on Ed25519, in the
+// group of edwards25519.
+func NewEd25519(seed []byte) (*Key, error) {
+	return ed25519Scheme.master(seed)
+}
+
+// NewSecp256k1 returns the BIP-32 master key of the seed on secp256k1, in the
+// group of group/secp256k1.
+func NewSecp256k1(seed []byte) (*Key, error) {
+	return secp256k1Scheme.master(seed)
+}
+
+// NewP256 returns the SLIP-0010 master key of the seed on NIST P-256, in the
+// group of group/p256.
+func NewP256(seed []byte) (*Key, error) {
+	return p256Scheme.master(seed)
+}
+
+func (b *bip32) master(seed []byte) (*Key, error) {
+	if len(seed) < minSeedSize || len(seed) > maxSeedSize {
+		return nil, fmt.Errorf("%w: %d bytes instead of %d to %d", ErrInvalidSeed, len(seed), minSeedSize, maxSeedSize)
+	}
+	I := hmacSHA512([]byte(b.hmacKey), seed)
+	for b.curve != nil && !b.valid(I[:32]) {
+		if !b.retry {
+			return nil, fmt.Errorf("%w: invalid master key", ErrInvalidSeed)
+		}
+		I = hmacSHA512([]byte(b.hmacKey), I)
+	}
+	return newKey(b, I[:32], I[32:]), nil
+}
+
+func (b *bip32) group() kyber.Group {
+	return b.g
+}
+
+func (b *bip32) scalar(private []byte) kyber.Scalar {
+	if b.curve == nil {
+		x, _, _ := b.g.(*edwards25519.SuiteEd25519).NewKeyAndSeedWithInput(private) //nolint:errcheck // Ed25519 suite
+		return x
+	}
+	return b.g.Scalar().SetBytes(private)
+}
+
+// valid tells whether the 32 bytes are a valid private key.
+func (b *bip32) valid(k []byte) bool {
+	n := new(big.Int).SetBytes(k)
+	return n.Sign() > 0 && n.Cmp(b.curve.Params().N) < 0
+}
+
+func (b *bip32) child(k *Key, index uint32) ([]byte, []byte, error) {
+	var data []byte
+	if index >= HardenedOffset {
+		data = append([]byte{0}, k.private...)
+	} else {
+		if b.curve == nil {
+			return nil, nil, ErrHardenedOnly
+		}
+		data = compress(k.public)
+	}
+	I := hmacSHA512(k.chainCode, binary.BigEndian.AppendUint32(data, index))
+	if b.curve == nil {
+		return I[:32], I[32:], nil
+	}
+
+	N := b.curve.Params().N
+	for {
+		IL := new(big.Int).SetBytes(I[:32])
+		child := new(big.Int).Add(IL, new(big.Int).SetBytes(k.private))
+		child.Mod(child, N)
+		if IL.Cmp(N) < 0 && child.Sign() != 0 {
+			return child.FillBytes(make([]byte, 32)), I[32:], nil
+		}
+		if !b.retry {
+			return nil, nil, ErrInvalidChild
+		}
+		I = b.next(k, I, index)
+	}
+}
+
+func (b *bip32) publicChild(k *Key, index uint32) (kyber.Point, []byte, error) {
+	if index >= HardenedOffset {
+		return nil, nil, ErrPublicDerivation
+	}
+	if b.curve == nil {
+		return nil, nil, ErrHardenedOnly
+	}
+	I := hmacSHA512(k.chainCode, binary.BigEndian.AppendUint32(compress(k.public), index))
+	null := b.g.Point().Null()
+	for {
+		child := b.g.Point().Null()
+		if b.valid(I[:32]) {
+			child.Mul(b.g.Scalar().SetBytes(I[:32]), nil).Add(child, k.public)
+		}
+		if !child.Equal(null) {
+			return child, I[32:], nil
+		}
+		if !b.retry {
+			return nil, nil, ErrInvalidChild
+		}
+		I = b.next(k, I, index)
+	}
+}
+
+// next returns the next candidate of SLIP-0010 after an invalid key.
+func (b *bip32) next(k *Key, I []byte, index uint32) []byte {
+	data := append([]byte{1}, I[32:]...)
+	return hmacSHA512(k.chainCode, binary.BigEndian.AppendUint32(data, index))
+}
+
+// compress returns the SEC 1 compressed encoding of a point, which the groups
+// of the curves marshal uncompressed.
+func compress(p kyber.Point) []byte {
+	buf, _ := p.MarshalBinary() //nolint:errcheck // never fails for these groups
+	out := make([]byte, 33)
+	out[0] = 2 | buf[64]&1
+	copy(out[1:], buf[1:33])
+	return out
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,95 @@
+package hd
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/hkdf"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+// EIP-2333 derives the BLS12-381 private keys through Lamport keys, which
+// only the parent private key can compute: every child is hardened, and the
+// index only selects the child. See https://eips.ethereum.org/EIPS/eip-2333.
+
+// the order of the groups of BLS12-381
+var blsOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+const (
+	// minimum size of the seed
+	eip2333SeedSize = 32
+	// number of chunks of a Lamport key
+	lamportChunks = 255
+	// size of the output of HKDF_mod_r
+	hkdfModRSize = 48
+)
+
+type eip2333 struct {
+	g kyber.Group
+}
+
+// NewBLS12381 returns the EIP-2333 master key of the seed on BLS12-381, with
+// the public keys in g, which is G1 or G2 of a BLS12-381 suite.
+func NewBLS12381(g kyber.Group, seed []byte) (*Key, error) {
+	if len(seed) < eip2333SeedSize {
+		return nil, fmt.Errorf("%w: %d bytes instead of at least %d", ErrInvalidSeed, len(seed), eip2333SeedSize)
+	}
+	return newKey(&eip2333{g: g}, hkdfModR(seed), nil), nil
+}
+
+func (e *eip2333) group() kyber.Group {
+	return e.g
+}
+
+func (e *eip2333) scalar(private []byte) kyber.Scalar {
+	return e.g.Scalar().SetBytes(private)
+}
+
+func (e *eip2333) child(k *Key, index uint32) ([]byte, []byte, error) {
+	return hkdfModR(lamportPublicKey(k.private, index)), nil, nil
+}
+
+func (e *eip2333) publicChild(*Key, uint32) (kyber.Point, []byte, error) {
+	return nil, nil, ErrPublicDerivation
+}
+
+// hkdfModR is HKDF_mod_r of EIP-2333, returning the key as 32 big-endian
+// bytes.
+func hkdfModR(ikm []byte) []byte {
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	ikm = append(append([]byte{}, ikm...), 0)
+	info := binary.BigEndian.AppendUint16(nil, hkdfModRSize)
+	okm := make([]byte, hkdfModRSize)
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+		_, _ = io.ReadFull(hkdf.New(sha256.New, ikm, salt, info), okm)
+		sk.SetBytes(okm).Mod(sk, blsOrder)
+	}
+	return sk.FillBytes(make([]byte, 32))
+}
+
+// lamportPublicKey is parent_SK_to_lamport_PK of EIP-2333: the compressed
+// Lamport public key derived from the parent private key and the index.
+func lamportPublicKey(parent []byte, index uint32) []byte {
+	salt := binary.BigEndian.AppendUint32(nil, index)
+	notParent := make([]byte, len(parent))
+	for i, b := range parent {
+		notParent[i] = ^b
+	}
+	h := sha256.New()
+	for _, ikm := range [][]byte{parent, notParent} {
+		lamport := make([]byte, lamportChunks*sha256.Size)
+		_, _ = io.ReadFull(hkdf.New(sha256.New, ikm, salt, nil), lamport)
+		for i := 0; i < lamportChunks; i++ {
+			chunk := sha256.Sum256(lamport[i*sha256.Size : (i+1)*sha256.Size])
+			h.Write(chunk[:])
+		}
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,209 @@
+// Package hd derives hierarchical deterministic key pairs from a seed.
+//
+// Three derivation schemes are supported, each on a fixed curve:
+//
+//   - SLIP-0010 for Ed25519, with hardened derivation only;
+//   - BIP-32 for secp256k1 and SLIP-0010 for NIST P-256, with both hardened
+//     and non-hardened derivation, and public derivation of the non-hardened
+//     children of a public key;
+//   - EIP-2333 for BLS12-381, in which every child is derived from the parent
+//     private key, with the public keys in G1 or G2 of either BLS12-381
+//     backend.
+//
+// The key pairs are the ones of the corresponding kyber groups, so they can be
+// used with the sign/* schemes of these groups: sign/eddsa and sign/schnorr
+// for Ed25519, sign/schnorr or ECDSA for secp256k1 and P-256, and sign/bls for
+// BLS12-381.
+//
+// Paths are written as in BIP-32, such as "m/44'/0'/0'/0/1", where "'", "h"
+// or "H" marks a hardened index.
+package hd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/util/key"
+)
+
+// HardenedOffset is the first index of the hardened children.
+const HardenedOffset uint32 = 1 << 31
+
+var (
+	// ErrInvalidSeed is returned when the seed is too short or too long for
+	// the scheme.
+	ErrInvalidSeed = errors.New("hd: invalid seed")
+	// ErrInvalidPath is returned when parsing a malformed derivation path.
+	ErrInvalidPath = errors.New("hd: invalid path")
+	// ErrHardenedOnly is returned when deriving a non-hardened child on a
+	// curve which only supports hardened derivation.
+	ErrHardenedOnly = errors.New("hd: only hardened derivation is supported")
+	// ErrPublicDerivation is returned when deriving from a public key a child
+	// which requires the private key.
+	ErrPublicDerivation = errors.New("hd: child requires the private key")
+	// ErrInvalidChild is returned by BIP-32 for the negligible fraction of
+	// indices whose child key is invalid; the next index should be used
+	// instead.
+	ErrInvalidChild = errors.New("hd: invalid child key")
+	// ErrNoPrivateKey is returned when asking for the private key of a public
+	// key.
+	ErrNoPrivateKey = errors.New("hd: no private key")
+)
+
+// Key is a node of a derivation tree: a private key, or only a public key,
+// with the chain code used to derive its children.
+type Key struct {
+	scheme    scheme
+	private   []byte // nil for public keys
+	public    kyber.Point
+	chainCode []byte
+	depth     int
+	index     uint32
+}
+
+// scheme is a derivation scheme on a given curve.
+type scheme interface {
+	group() kyber.Group
+	// scalar returns the private scalar of the private key bytes.
+	scalar(private []byte) kyber.Scalar
+	// child returns the private key and chain code of the child of a private
+	// key.
+	child(k *Key, index uint32) ([]byte, []byte, error)
+	// publicChild returns the public key and chain code of the child of a
+	// public key.
+	publicChild(k *Key, index uint32) (kyber.Point, []byte, error)
+}
+
+func newKey(s scheme, private, chainCode []byte) *Key {
+	return &Key{
+		scheme:    s,
+		private:   private,
+		public:    s.group().Point().Mul(s.scalar(private), nil),
+		chainCode: chainCode,
+	}
+}
+
+// Child returns the child of the key with the given index, which is hardened
+// if it is at least HardenedOffset.
+func (k *Key) Child(index uint32) (*Key, error) {
+	child := &Key{scheme: k.scheme, depth: k.depth + 1, index: index}
+	var err error
+	if k.private == nil {
+		child.public, child.chainCode, err = k.scheme.publicChild(k, index)
+		if err != nil {
+			return nil, err
+		}
+		return child, nil
+	}
+	child.private, child.chainCode, err = k.scheme.child(k, index)
+	if err != nil {
+		return nil, err
+	}
+	child.public = k.scheme.group().Point().Mul(k.scheme.scalar(child.private), nil)
+	return child, nil
+}
+
+// Derive returns the descendant of the key at the given path, which is
+// relative to the key.
+func (k *Key) Derive(path string) (*Key, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indices {
+		if k, err = k.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// Public returns the public key of the key, from which only the non-hardened
+// children can be derived.
+func (k *Key) Public() *Key {
+	return &Key{
+		scheme:    k.scheme,
+		public:    k.public,
+		chainCode: k.chainCode,
+		depth:     k.depth,
+		index:     k.index,
+	}
+}
+
+// IsPrivate tells whether the key holds a private key.
+func (k *Key) IsPrivate() bool {
+	return k.private != nil
+}
+
+// Pair returns the key pair of the key.
+func (k *Key) Pair() (*key.Pair, error) {
+	if k.private == nil {
+		return nil, ErrNoPrivateKey
+	}
+	return &key.Pair{
+		Public:  k.public.Clone(),
+		Private: k.scheme.scalar(k.private),
+	}, nil
+}
+
+// PublicKey returns the public key.
+func (k *Key) PublicKey() kyber.Point {
+	return k.public.Clone()
+}
+
+// PrivateKey returns the private key as specified by the scheme: the seed of
+// the Ed25519 private key for SLIP-0010 on Ed25519, and the 32-byte big-endian
+// private scalar otherwise.
+func (k *Key) PrivateKey() ([]byte, error) {
+	if k.private == nil {
+		return nil, ErrNoPrivateKey
+	}
+	return append([]byte{}, k.private...), nil
+}
+
+// ChainCode returns the chain code of the key, which is empty for EIP-2333.
+func (k *Key) ChainCode() []byte {
+	return append([]byte{}, k.chainCode...)
+}
+
+// Depth returns the depth of the key in the tree, 0 for a master key.
+func (k *Key) Depth() int {
+	return k.depth
+}
+
+// Index returns the index of the key in its parent, 0 for a master key.
+func (k *Key) Index() uint32 {
+	return k.index
+}
+
+// ParsePath parses a derivation path, such as "m/44'/0'/0'/0/1", into the
+// indices of the successive children. The leading "m" is optional, and the
+// hardened indices are marked with "'", "h" or "H".
+func ParsePath(path string) ([]uint32, error) {
+	if path == "" || path == "m" || path == "M" {
+		return nil, nil
+	}
+	parts := strings.Split(path, "/")
+	if parts[0] == "m" || parts[0] == "M" {
+		parts = parts[1:]
+	}
+	indices := make([]uint32, len(parts))
+	for i, p := range parts {
+		hardened := strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h") || strings.HasSuffix(p, "H")
+		if hardened {
+			p = p[:len(p)-1]
+		}
+		n, err := strconv.ParseUint(p, 10, 31)
+		if err != nil || (len(p) > 1 && p[0] == '0') {
+			return nil, fmt.Errorf("%w: invalid index %q", ErrInvalidPath, parts[i])
+		}
+		indices[i] = uint32(n)
+		if hardened {
+			indices[i] += HardenedOffset
+		}
+	}
+	return indices, nil
+}
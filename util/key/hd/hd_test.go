@@ -0,0 +1,266 @@
+package hd
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"go.dedis.ch/kyber/v4/group/secp256k1"
+	"go.dedis.ch/kyber/v4/pairing/bls12381/kilic"
+	"go.dedis.ch/kyber/v4/sign/bls"
+	"go.dedis.ch/kyber/v4/sign/eddsa"
+	"go.dedis.ch/kyber/v4/sign/schnorr"
+	"go.dedis.ch/kyber/v4/util/random"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	buf, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return buf
+}
+
+type vector struct {
+	path, chainCode, private, public string
+}
+
+// checkVectors checks the keys derived from the master key against the test
+// vectors, with the public keys in SEC 1 compressed form, or prefixed by 00
+// for Ed25519.
+func checkVectors(t *testing.T, master *Key, vectors []vector) {
+	for _, v := range vectors {
+		k, err := master.Derive(v.path)
+		require.NoError(t, err, v.path)
+		require.Equal(t, v.chainCode, hex.EncodeToString(k.ChainCode()), v.path)
+		private, err := k.PrivateKey()
+		require.NoError(t, err)
+		require.Equal(t, v.private, hex.EncodeToString(private), v.path)
+
+		var public []byte
+		if master.scheme == ed25519Scheme {
+			buf, err := k.PublicKey().MarshalBinary()
+			require.NoError(t, err)
+			public = append([]byte{0}, buf...)
+		} else {
+			public = compress(k.PublicKey())
+		}
+		require.Equal(t, v.public, hex.EncodeToString(public), v.path)
+	}
+}
+
+const seed1 = "000102030405060708090a0b0c0d0e0f"
+
+// Test vector 1 of SLIP-0010 for ed25519.
+func TestEd25519Vectors(t *testing.T) {
+	master, err := NewEd25519(unhex(t, seed1))
+	require.NoError(t, err)
+	checkVectors(t, master, []vector{
+		{
+			"m",
+			"90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+			"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			"00a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+		},
+		{
+			"m/0H",
+			"8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69",
+			"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			"008c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+		},
+	})
+
+	_, err = master.Child(0)
+	require.ErrorIs(t, err, ErrHardenedOnly)
+	_, err = master.Public().Child(HardenedOffset)
+	require.ErrorIs(t, err, ErrPublicDerivation)
+}
+
+// Test vector 1 of BIP-32.
+func TestSecp256k1Vectors(t *testing.T) {
+	master, err := NewSecp256k1(unhex(t, seed1))
+	require.NoError(t, err)
+	checkVectors(t, master, []vector{
+		{
+			"m",
+			"873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508",
+			"e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+			"0339a36013301597daef41fbe593a02cc513d0b55527ec2df1050e2e8ff49c85c2",
+		},
+		{
+			"m/0H",
+			"47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141",
+			"edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+			"035a784662a4a20a65bf6aab9ae98a6c068a81c52e4b032c0fb5400c706cfccc56",
+		},
+		{
+			"m/0H/1",
+			"2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19",
+			"3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+			"03501e454bf00751f24b1b489aa925215d66af2234e3891c3b21a52bedb3cd711c",
+		},
+	})
+}
+
+// Test vector 1 of SLIP-0010 for nist256p1.
+func TestP256Vectors(t *testing.T) {
+	master, err := NewP256(unhex(t, seed1))
+	require.NoError(t, err)
+	checkVectors(t, master, []vector{
+		{
+			"m",
+			"beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea",
+			"612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2",
+			"0266874dc6ade47b3ecd096745ca09bcd29638dd52c2c12117b11ed3e458cfa9e8",
+		},
+		{
+			"m/0H",
+			"3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11",
+			"6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c",
+			"0384610f5ecffe8fda089363a41f56a5c7ffc1d81b59a612d0d649b2d22355590c",
+		},
+	})
+}
+
+// Test cases 0 and 1 of EIP-2333.
+func TestBLS12381Vectors(t *testing.T) {
+	suite := kilic.NewSuiteBLS12381()
+	for _, v := range []struct {
+		seed, master, child string
+		index               uint32
+	}{
+		{
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+			"6083874454709270928345386274498605044986640685124978867557563392430687146096",
+			"20397789859736650942317412262472558107875392172444076792671091975210932703118",
+			0,
+		},
+		{
+			"3141592653589793238462643383279502884197169399375105820974944592",
+			"29757020647961307431480504535336562678282505419141012933316116377660817309383",
+			"25457201688850691947727629385191704516744796114925897962676248250929345014287",
+			3141592653,
+		},
+	} {
+		master, err := NewBLS12381(suite.G1(), unhex(t, v.seed))
+		require.NoError(t, err)
+		private, err := master.PrivateKey()
+		require.NoError(t, err)
+		require.Equal(t, v.master, new(big.Int).SetBytes(private).String())
+
+		child, err := master.Child(v.index)
+		require.NoError(t, err)
+		private, err = child.PrivateKey()
+		require.NoError(t, err)
+		require.Equal(t, v.child, new(big.Int).SetBytes(private).String())
+	}
+}
+
+func TestPublicDerivation(t *testing.T) {
+	seed := random.Bits(256, false, random.New())
+	for _, newMaster := range []func([]byte) (*Key, error){NewSecp256k1, NewP256} {
+		master, err := newMaster(seed)
+		require.NoError(t, err)
+		account, err := master.Derive("m/44'/0'/0'")
+		require.NoError(t, err)
+
+		private, err := account.Derive("0/7")
+		require.NoError(t, err)
+		public, err := account.Public().Derive("0/7")
+		require.NoError(t, err)
+		require.False(t, public.IsPrivate())
+		require.True(t, private.PublicKey().Equal(public.PublicKey()))
+		require.Equal(t, private.ChainCode(), public.ChainCode())
+		require.Equal(t, 5, public.Depth())
+		require.Equal(t, uint32(7), public.Index())
+
+		_, err = account.Public().Child(HardenedOffset)
+		require.ErrorIs(t, err, ErrPublicDerivation)
+		_, err = public.Pair()
+		require.ErrorIs(t, err, ErrNoPrivateKey)
+	}
+
+	master, err := NewBLS12381(kilic.NewSuiteBLS12381().G2(), seed)
+	require.NoError(t, err)
+	_, err = master.Public().Child(0)
+	require.ErrorIs(t, err, ErrPublicDerivation)
+}
+
+func TestSignatures(t *testing.T) {
+	seed := random.Bits(256, false, random.New())
+	msg := []byte("hierarchical deterministic")
+
+	// Ed25519 private keys are seeds, which sign/eddsa uses directly
+	k, err := NewEd25519(seed)
+	require.NoError(t, err)
+	k, err = k.Derive("m/44'/501'/0'")
+	require.NoError(t, err)
+	private, err := k.PrivateKey()
+	require.NoError(t, err)
+	sig := ed25519.Sign(ed25519.NewKeyFromSeed(private), msg)
+	require.NoError(t, eddsa.Verify(k.PublicKey(), msg, sig))
+	pair, err := k.Pair()
+	require.NoError(t, err)
+	sig, err = schnorr.Sign(ed25519Scheme.g.(schnorr.Suite), pair.Private, msg)
+	require.NoError(t, err)
+	require.NoError(t, schnorr.Verify(ed25519Scheme.g, pair.Public, msg, sig))
+
+	// secp256k1 key pairs are ECDSA keys
+	k, err = NewSecp256k1(seed)
+	require.NoError(t, err)
+	k, err = k.Derive("m/44'/0'/0'/0/0")
+	require.NoError(t, err)
+	private, err = k.PrivateKey()
+	require.NoError(t, err)
+	ecdsaKey := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(private)}
+	ecdsaKey.Curve = secp256k1.S256()
+	ecdsaKey.X, ecdsaKey.Y = elliptic.Unmarshal(secp256k1.S256(), mustMarshal(t, k))
+	digest := sha256.Sum256(msg)
+	r, s, err := ecdsa.Sign(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+	require.True(t, ecdsa.Verify(&ecdsaKey.PublicKey, digest[:], r, s))
+
+	// BLS12-381 key pairs with public keys in G2 and signatures in G1
+	suite := kilic.NewSuiteBLS12381()
+	k, err = NewBLS12381(suite.G2(), seed)
+	require.NoError(t, err)
+	k, err = k.Derive("m/12381/3600/0/0/0")
+	require.NoError(t, err)
+	pair, err = k.Pair()
+	require.NoError(t, err)
+	scheme := bls.NewSchemeOnG1(suite)
+	sig, err = scheme.Sign(pair.Private, msg)
+	require.NoError(t, err)
+	require.NoError(t, scheme.Verify(pair.Public, msg, sig))
+}
+
+func mustMarshal(t *testing.T, k *Key) []byte {
+	buf, err := k.PublicKey().MarshalBinary()
+	require.NoError(t, err)
+	return buf
+}
+
+func TestParsePath(t *testing.T) {
+	indices, err := ParsePath("m/44'/0h/1H/2")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{44 + HardenedOffset, HardenedOffset, 1 + HardenedOffset, 2}, indices)
+
+	indices, err = ParsePath("m")
+	require.NoError(t, err)
+	require.Empty(t, indices)
+
+	for _, path := range []string{"m/", "m/x", "m/-1", "m/2147483648", "m/01", "m//1", "m/1''"} {
+		_, err := ParsePath(path)
+		require.ErrorIs(t, err, ErrInvalidPath, path)
+	}
+
+	_, err = NewSecp256k1(make([]byte, 15))
+	require.ErrorIs(t, err, ErrInvalidSeed)
+	_, err = NewBLS12381(kilic.NewSuiteBLS12381().G1(), make([]byte, 31))
+	require.ErrorIs(t, err, ErrInvalidSeed)
+}
@@ -0,0 +1,427 @@
+// Package keystore stores private keys encrypted with a password, in a JSON
+// format in the style of the EIP-2335 keystores of Ethereum
+// (https://eips.ethereum.org/EIPS/eip-2335).
+//
+// A keystore holds a key.Pair, a share.PriShare or a pedersen DistKeyShare,
+// along with the name of its suite, the kind of the key and its public part.
+// The key is encrypted with ChaCha20-Poly1305 under a key derived from the
+// password with scrypt or Argon2id, and the other fields of the keystore are
+// authenticated as additional data: a keystore whose metadata was modified
+// doesn't decrypt.
+//
+// The JSON schema is stable: all the binary values are hex encoded, and a
+// keystore encoded with encoding/json always has its fields in the same
+// order. Given the same random stream, the Encrypt functions produce the same
+// keystore.
+package keystore
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v4"
+	"go.dedis.ch/kyber/v4/share"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/suites"
+	"go.dedis.ch/kyber/v4/util/key"
+	"go.dedis.ch/kyber/v4/util/random"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version is the version of the keystore format.
+const Version = 1
+
+// The kinds of keys a keystore holds.
+const (
+	KindKeyPair      = "key-pair"
+	KindPriShare     = "pri-share"
+	KindDistKeyShare = "dist-key-share"
+)
+
+// The key derivation functions.
+const (
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+)
+
+// The largest KDF parameters a keystore may use. They bound the memory a
+// keystore from an untrusted source makes the KDF allocate, to about 1 GiB
+// for both functions, and the time it takes.
+const (
+	MaxScryptN       = 1 << 20
+	MaxScryptRP      = 8
+	MaxArgon2Time    = 16
+	MaxArgon2Memory  = 1 << 20 // in KiB
+	MaxArgon2Threads = 64
+)
+
+// CipherChaCha20Poly1305 is the name of the only cipher.
+const CipherChaCha20Poly1305 = "chacha20-poly1305"
+
+var (
+	// ErrInvalidKeystore is returned when a keystore is malformed, or holds
+	// another kind of key than requested.
+	ErrInvalidKeystore = errors.New("keystore: invalid keystore")
+	// ErrDecryption is returned when the password is wrong or the keystore
+	// was modified.
+	ErrDecryption = errors.New("keystore: wrong password or modified keystore")
+)
+
+// Keystore is an encrypted key. It is encoded in JSON with encoding/json.
+type Keystore struct {
+	Version     int    `json:"version"`
+	UUID        string `json:"uuid"`
+	Description string `json:"description"`
+	Suite       string `json:"suite"`
+	Kind        string `json:"kind"`
+	// Public is the public key of a key pair, the public share of a share,
+	// or the encoding of the commitments of a DistKeyShare.
+	Public string `json:"pubkey"`
+	Crypto Crypto `json:"crypto"`
+}
+
+// Crypto holds the parameters of the encryption and the ciphertext.
+type Crypto struct {
+	KDF    KDF    `json:"kdf"`
+	Cipher Cipher `json:"cipher"`
+}
+
+// KDF is a key derivation function and its parameters. The derived key is
+// 32 bytes long.
+type KDF struct {
+	Function string    `json:"function"`
+	Params   KDFParams `json:"params"`
+}
+
+// KDFParams are the parameters of the key derivation functions. N, R and P
+// are the ones of scrypt, and Time, Memory (in KiB) and Threads the ones of
+// Argon2id. They are bounded by the Max constants above.
+type KDFParams struct {
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	Salt    string `json:"salt"`
+}
+
+// Cipher is the AEAD encrypting the key, its nonce and the ciphertext.
+type Cipher struct {
+	Function string       `json:"function"`
+	Params   CipherParams `json:"params"`
+	Message  string       `json:"message"`
+}
+
+// CipherParams are the parameters of the cipher.
+type CipherParams struct {
+	Nonce string `json:"nonce"`
+}
+
+// Options are the options of the encryption. The zero value uses scrypt
+// with the parameters of EIP-2335.
+type Options struct {
+	// KDF is KDFScrypt or KDFArgon2id.
+	KDF string
+	// Params are the parameters of the KDF, without the salt. The zero
+	// value takes the defaults: N = 2^18, R = 8 and P = 1 for scrypt, and
+	// Time = 3, Memory = 64 MiB and Threads = 4 for Argon2id.
+	Params KDFParams
+	// Description is stored in clear in the keystore.
+	Description string
+	// Rand is the source of the salt, the nonce and the UUID. It defaults
+	// to random.New().
+	Rand cipher.Stream
+}
+
+const (
+	keyLen  = 32
+	saltLen = 32
+)
+
+// EncryptKeyPair returns the keystore of the key pair of the suite.
+func EncryptKeyPair(s suites.Suite, p *key.Pair, password []byte, opts *Options) (*Keystore, error) {
+	priv, err := p.Private.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := p.Public.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return encrypt(s, KindKeyPair, pub, priv, password, opts)
+}
+
+// EncryptPriShare returns the keystore of the share of the suite. The public
+// key of the keystore is the share times the base point.
+func EncryptPriShare(s suites.Suite, sh *share.PriShare, password []byte, opts *Options) (*Keystore, error) {
+	pub, err := s.Point().Mul(sh.V, nil).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	priv, err := encodePriShare(sh)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt(s, KindPriShare, pub, priv, password, opts)
+}
+
+// EncryptDistKeyShare returns the keystore of the DistKeyShare of the suite.
+// The public key of the keystore is the concatenation of the commitments,
+// which are stored in clear.
+func EncryptDistKeyShare(s suites.Suite, d *dkg.DistKeyShare, password []byte, opts *Options) (*Keystore, error) {
+	if d.Share == nil || len(d.Commits) == 0 {
+		return nil, fmt.Errorf("%w: incomplete DistKeyShare", ErrInvalidKeystore)
+	}
+	var pub []byte
+	for _, c := range d.Commits {
+		buf, err := c.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		pub = append(pub, buf...)
+	}
+	priv, err := encodePriShare(d.Share)
+	if err != nil {
+		return nil, err
+	}
+	return encrypt(s, KindDistKeyShare, pub, priv, password, opts)
+}
+
+// encodePriShare returns the index of the share as a big-endian uint32,
+// followed by the share.
+func encodePriShare(sh *share.PriShare) ([]byte, error) {
+	buf, err := sh.V.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(binary.BigEndian.AppendUint32(nil, sh.I), buf...), nil
+}
+
+func encrypt(s suites.Suite, kind string, pub, priv, password []byte, opts *Options) (*Keystore, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	rand := opts.Rand
+	if rand == nil {
+		rand = random.New()
+	}
+	kdf := KDF{Function: opts.KDF, Params: opts.Params}
+	switch kdf.Function {
+	case "", KDFScrypt:
+		kdf.Function = KDFScrypt
+		if kdf.Params.N == 0 {
+			kdf.Params.N, kdf.Params.R, kdf.Params.P = 1<<18, 8, 1
+		}
+	case KDFArgon2id:
+		if kdf.Params.Time == 0 {
+			kdf.Params.Time, kdf.Params.Memory, kdf.Params.Threads = 3, 64*1024, 4
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown KDF %q", ErrInvalidKeystore, kdf.Function)
+	}
+	salt := random.Bits(saltLen*8, false, rand)
+	kdf.Params.Salt = hex.EncodeToString(salt)
+
+	nonce := random.Bits(chacha20poly1305.NonceSize*8, false, rand)
+	ks := &Keystore{
+		Version:     Version,
+		UUID:        newUUID(rand),
+		Description: opts.Description,
+		Suite:       s.String(),
+		Kind:        kind,
+		Public:      hex.EncodeToString(pub),
+		Crypto: Crypto{
+			KDF: kdf,
+			Cipher: Cipher{
+				Function: CipherChaCha20Poly1305,
+				Params:   CipherParams{Nonce: hex.EncodeToString(nonce)},
+			},
+		},
+	}
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := ks.additionalData()
+	if err != nil {
+		return nil, err
+	}
+	ks.Crypto.Cipher.Message = hex.EncodeToString(aead.Seal(nil, nonce, priv, ad))
+	return ks, nil
+}
+
+// newUUID returns a random UUID of version 4.
+func newUUID(rand cipher.Stream) string {
+	b := random.Bits(128, false, rand)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// aead returns the cipher keyed by the password, after checking the
+// parameters of the KDF.
+func (ks *Keystore) aead(password []byte) (cipher.AEAD, error) {
+	p := ks.Crypto.KDF.Params
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil || len(salt) < 16 {
+		return nil, fmt.Errorf("%w: invalid salt", ErrInvalidKeystore)
+	}
+	var k []byte
+	switch ks.Crypto.KDF.Function {
+	case KDFScrypt:
+		if p.N <= 1 || p.N > MaxScryptN || p.R <= 0 || p.P <= 0 || p.R*p.P > MaxScryptRP {
+			return nil, fmt.Errorf("%w: invalid scrypt parameters", ErrInvalidKeystore)
+		}
+		k, err = scrypt.Key(password, salt, p.N, p.R, p.P, keyLen)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKeystore, err)
+		}
+	case KDFArgon2id:
+		if p.Time == 0 || p.Time > MaxArgon2Time || p.Memory == 0 || p.Memory > MaxArgon2Memory ||
+			p.Threads == 0 || p.Threads > MaxArgon2Threads {
+			return nil, fmt.Errorf("%w: invalid Argon2id parameters", ErrInvalidKeystore)
+		}
+		k = argon2.IDKey(password, salt, p.Time, p.Memory, p.Threads, keyLen)
+	default:
+		return nil, fmt.Errorf("%w: unknown KDF %q", ErrInvalidKeystore, ks.Crypto.KDF.Function)
+	}
+	if ks.Crypto.Cipher.Function != CipherChaCha20Poly1305 {
+		return nil, fmt.Errorf("%w: unknown cipher %q", ErrInvalidKeystore, ks.Crypto.Cipher.Function)
+	}
+	return chacha20poly1305.New(k)
+}
+
+// additionalData returns the data authenticated along with the key: the
+// JSON encoding of the keystore without the ciphertext.
+func (ks *Keystore) additionalData() ([]byte, error) {
+	c := *ks
+	c.Crypto.Cipher.Message = ""
+	return json.Marshal(&c)
+}
+
+// decrypt returns the private part of the key, after checking the kind and
+// looking the suite up with suites.Find.
+func (ks *Keystore) decrypt(kind string, password []byte) (suites.Suite, []byte, []byte, error) {
+	if ks.Version != Version {
+		return nil, nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidKeystore, ks.Version)
+	}
+	if ks.Kind != kind {
+		return nil, nil, nil, fmt.Errorf("%w: %s instead of %s", ErrInvalidKeystore, ks.Kind, kind)
+	}
+	s, err := suites.Find(ks.Suite)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pub, err1 := hex.DecodeString(ks.Public)
+	nonce, err2 := hex.DecodeString(ks.Crypto.Cipher.Params.Nonce)
+	ct, err3 := hex.DecodeString(ks.Crypto.Cipher.Message)
+	if err := errors.Join(err1, err2, err3); err != nil || len(nonce) != chacha20poly1305.NonceSize {
+		return nil, nil, nil, fmt.Errorf("%w: invalid encoding", ErrInvalidKeystore)
+	}
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ad, err := ks.additionalData()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	priv, err := aead.Open(nil, nonce, ct, ad)
+	if err != nil {
+		return nil, nil, nil, ErrDecryption
+	}
+	return s, pub, priv, nil
+}
+
+// DecryptKeyPair decrypts a keystore created by EncryptKeyPair.
+func (ks *Keystore) DecryptKeyPair(password []byte) (*key.Pair, error) {
+	s, pub, priv, err := ks.decrypt(KindKeyPair, password)
+	if err != nil {
+		return nil, err
+	}
+	x := s.Scalar()
+	if err := x.UnmarshalBinary(priv); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeystore, err)
+	}
+	p, err := checkPublic(s, x, pub)
+	if err != nil {
+		return nil, err
+	}
+	return &key.Pair{Public: p, Private: x}, nil
+}
+
+// DecryptPriShare decrypts a keystore created by EncryptPriShare.
+func (ks *Keystore) DecryptPriShare(password []byte) (*share.PriShare, error) {
+	s, pub, priv, err := ks.decrypt(KindPriShare, password)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := decodePriShare(s, priv)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkPublic(s, sh.V, pub); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// DecryptDistKeyShare decrypts a keystore created by EncryptDistKeyShare.
+func (ks *Keystore) DecryptDistKeyShare(password []byte) (*dkg.DistKeyShare, error) {
+	s, pub, priv, err := ks.decrypt(KindDistKeyShare, password)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := decodePriShare(s, priv)
+	if err != nil {
+		return nil, err
+	}
+	pl := s.PointLen()
+	if len(pub) == 0 || len(pub)%pl != 0 {
+		return nil, fmt.Errorf("%w: invalid commitments", ErrInvalidKeystore)
+	}
+	commits := make([]kyber.Point, len(pub)/pl)
+	for i := range commits {
+		commits[i] = s.Point()
+		if err := commits[i].UnmarshalBinary(pub[i*pl : (i+1)*pl]); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKeystore, err)
+		}
+	}
+	// the share must be on the public polynomial
+	pubShare := share.NewPubPoly(s, nil, commits).Eval(sh.I)
+	if !pubShare.V.Equal(s.Point().Mul(sh.V, nil)) {
+		return nil, fmt.Errorf("%w: share not on the public polynomial", ErrInvalidKeystore)
+	}
+	return &dkg.DistKeyShare{Commits: commits, Share: sh}, nil
+}
+
+func decodePriShare(s suites.Suite, priv []byte) (*share.PriShare, error) {
+	if len(priv) < 4 {
+		return nil, fmt.Errorf("%w: share too short", ErrInvalidKeystore)
+	}
+	sh := &share.PriShare{I: binary.BigEndian.Uint32(priv), V: s.Scalar()}
+	if err := sh.V.UnmarshalBinary(priv[4:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeystore, err)
+	}
+	return sh, nil
+}
+
+// checkPublic decodes the public key and checks that it is the private key
+// times the base point.
+func checkPublic(s suites.Suite, x kyber.Scalar, pub []byte) (kyber.Point, error) {
+	p := s.Point()
+	if err := p.UnmarshalBinary(pub); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeystore, err)
+	}
+	if !p.Equal(s.Point().Mul(x, nil)) {
+		return nil, fmt.Errorf("%w: public key doesn't match", ErrInvalidKeystore)
+	}
+	return p, nil
+}
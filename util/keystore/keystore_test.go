@@ -0,0 +1,238 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v4/share"
+	dkg "go.dedis.ch/kyber/v4/share/dkg/pedersen"
+	"go.dedis.ch/kyber/v4/suites"
+	"go.dedis.ch/kyber/v4/util/key"
+	"go.dedis.ch/kyber/v4/xof/blake2xb"
+)
+
+var testPassword = []byte("testpassword🔑")
+
+// fast parameters for the tests
+var (
+	testScrypt = &Options{Params: KDFParams{N: 1 << 10, R: 8, P: 1}}
+	testArgon2 = &Options{KDF: KDFArgon2id, Params: KDFParams{Time: 1, Memory: 64, Threads: 1}}
+)
+
+func TestKeyPair(t *testing.T) {
+	for _, name := range []string{"Ed25519", "P256", "bn256.G1"} {
+		s := suites.MustFind(name)
+		p := key.NewKeyPair(s)
+		for _, opts := range []*Options{testScrypt, testArgon2} {
+			ks, err := EncryptKeyPair(s, p, testPassword, opts)
+			require.NoError(t, err)
+			data, err := json.Marshal(ks)
+			require.NoError(t, err)
+
+			var dec Keystore
+			require.NoError(t, json.Unmarshal(data, &dec))
+			p2, err := dec.DecryptKeyPair(testPassword)
+			require.NoError(t, err)
+			// edwards25519 compares unreduced scalars in Equal
+			require.Equal(t, must(p.Private.MarshalBinary()), must(p2.Private.MarshalBinary()))
+			require.True(t, p.Public.Equal(p2.Public))
+
+			_, err = dec.DecryptKeyPair([]byte("wrong"))
+			require.ErrorIs(t, err, ErrDecryption)
+			_, err = dec.DecryptPriShare(testPassword)
+			require.ErrorIs(t, err, ErrInvalidKeystore)
+		}
+	}
+}
+
+func TestPriShare(t *testing.T) {
+	s := suites.MustFind("Ed25519")
+	sh := &share.PriShare{I: 7, V: s.Scalar().Pick(s.RandomStream())}
+	ks, err := EncryptPriShare(s, sh, testPassword, testScrypt)
+	require.NoError(t, err)
+	sh2, err := ks.DecryptPriShare(testPassword)
+	require.NoError(t, err)
+	require.Equal(t, sh.I, sh2.I)
+	require.True(t, sh.V.Equal(sh2.V))
+	_, err = ks.DecryptKeyPair(testPassword)
+	require.ErrorIs(t, err, ErrInvalidKeystore)
+}
+
+func TestDistKeyShare(t *testing.T) {
+	s := suites.MustFind("Ed25519")
+	priPoly := share.NewPriPoly(s, 3, nil, s.RandomStream())
+	_, commits := priPoly.Commit(nil).Info()
+	d := &dkg.DistKeyShare{Commits: commits, Share: priPoly.Shares(5)[2]}
+	ks, err := EncryptDistKeyShare(s, d, testPassword, testArgon2)
+	require.NoError(t, err)
+	d2, err := ks.DecryptDistKeyShare(testPassword)
+	require.NoError(t, err)
+	require.True(t, d.Public().Equal(d2.Public()))
+	require.Equal(t, d.Share.I, d2.Share.I)
+	require.True(t, d.Share.V.Equal(d2.Share.V))
+	_, err = dkg.MarshalDistKeyShare(s, d2)
+	require.NoError(t, err)
+
+	// the share must be on the public polynomial
+	d.Share = priPoly.Shares(5)[3]
+	d.Share.I = 2
+	ks, err = EncryptDistKeyShare(s, d, testPassword, testArgon2)
+	require.NoError(t, err)
+	_, err = ks.DecryptDistKeyShare(testPassword)
+	require.ErrorIs(t, err, ErrInvalidKeystore)
+}
+
+func TestMetadataAuthenticated(t *testing.T) {
+	s := suites.MustFind("Ed25519")
+	p := key.NewKeyPair(s)
+	for _, modify := range []func(ks *Keystore){
+		func(ks *Keystore) { ks.Description = "modified" },
+		func(ks *Keystore) { ks.UUID = "00000000-0000-4000-8000-000000000000" },
+		func(ks *Keystore) { ks.Public = hex.EncodeToString(must(s.Point().Base().MarshalBinary())) },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.R = 1 },
+		func(ks *Keystore) { ks.Crypto.Cipher.Message = ks.Crypto.Cipher.Message[2:] },
+	} {
+		ks, err := EncryptKeyPair(s, p, testPassword, testScrypt)
+		require.NoError(t, err)
+		modify(ks)
+		_, err = ks.DecryptKeyPair(testPassword)
+		require.ErrorIs(t, err, ErrDecryption)
+	}
+
+	ks, err := EncryptKeyPair(s, p, testPassword, testScrypt)
+	require.NoError(t, err)
+	ks.Suite = "unknown"
+	_, err = ks.DecryptKeyPair(testPassword)
+	require.ErrorIs(t, err, suites.ErrUnknownSuite)
+	ks.Suite = "Ed25519"
+	ks.Version = 2
+	_, err = ks.DecryptKeyPair(testPassword)
+	require.ErrorIs(t, err, ErrInvalidKeystore)
+
+	_, err = EncryptKeyPair(s, p, testPassword, &Options{KDF: "pbkdf2"})
+	require.ErrorIs(t, err, ErrInvalidKeystore)
+}
+
+func TestKDFLimits(t *testing.T) {
+	s := suites.MustFind("Ed25519")
+	p := key.NewKeyPair(s)
+	for _, modify := range []func(ks *Keystore){
+		func(ks *Keystore) { ks.Crypto.KDF.Params.N = 1 << 40 },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.N = MaxScryptN * 2 },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.R = 1 << 20 },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.P = MaxScryptRP },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.P = -1 },
+	} {
+		ks, err := EncryptKeyPair(s, p, testPassword, testScrypt)
+		require.NoError(t, err)
+		modify(ks)
+		_, err = ks.DecryptKeyPair(testPassword)
+		require.ErrorIs(t, err, ErrInvalidKeystore)
+	}
+	for _, modify := range []func(ks *Keystore){
+		func(ks *Keystore) { ks.Crypto.KDF.Params.Memory = 4294967295 },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.Memory = MaxArgon2Memory + 1 },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.Time = MaxArgon2Time + 1 },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.Threads = MaxArgon2Threads + 1 },
+		func(ks *Keystore) { ks.Crypto.KDF.Params.Threads = 0 },
+	} {
+		ks, err := EncryptKeyPair(s, p, testPassword, testArgon2)
+		require.NoError(t, err)
+		modify(ks)
+		_, err = ks.DecryptKeyPair(testPassword)
+		require.ErrorIs(t, err, ErrInvalidKeystore)
+	}
+
+	_, err := EncryptKeyPair(s, p, testPassword, &Options{Params: KDFParams{N: MaxScryptN * 2, R: 8, P: 1}})
+	require.ErrorIs(t, err, ErrInvalidKeystore)
+}
+
+func must(b []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+type vector struct {
+	Password string          `json:"password"`
+	Secret   string          `json:"secret"`
+	Keystore json.RawMessage `json:"keystore"`
+}
+
+// testVectors returns keystores of keys derived from a fixed seed, with the
+// same seed for the salts, nonces and UUIDs.
+func testVectors(t *testing.T) []vector {
+	rand := blake2xb.New([]byte("keystore test vectors"))
+	var res []vector
+	add := func(ks *Keystore, err error, secret []byte) {
+		require.NoError(t, err)
+		data, err := json.Marshal(ks)
+		require.NoError(t, err)
+		res = append(res, vector{string(testPassword), hex.EncodeToString(secret), data})
+	}
+
+	ed := suites.MustFind("Ed25519")
+	p := &key.Pair{Private: ed.Scalar().Pick(rand)}
+	p.Public = ed.Point().Mul(p.Private, nil)
+	ks, err := EncryptKeyPair(ed, p, testPassword, &Options{
+		Params: testScrypt.Params, Description: "Ed25519 key pair", Rand: rand})
+	add(ks, err, must(p.Private.MarshalBinary()))
+
+	p256 := suites.MustFind("P256")
+	sh := &share.PriShare{I: 3, V: p256.Scalar().Pick(rand)}
+	ks, err = EncryptPriShare(p256, sh, testPassword, &Options{
+		KDF: KDFArgon2id, Params: testArgon2.Params, Description: "P256 share", Rand: rand})
+	add(ks, err, must(sh.V.MarshalBinary()))
+
+	priPoly := share.NewPriPoly(ed, 2, nil, rand)
+	_, commits := priPoly.Commit(nil).Info()
+	d := &dkg.DistKeyShare{Commits: commits, Share: priPoly.Shares(3)[1]}
+	ks, err = EncryptDistKeyShare(ed, d, testPassword, &Options{
+		Params: testScrypt.Params, Description: "Ed25519 DistKeyShare", Rand: rand})
+	add(ks, err, must(d.Share.V.MarshalBinary()))
+	return res
+}
+
+// Set KEYSTORE_UPDATE=1 to regenerate testdata/vectors.json after a change
+// of the format.
+func TestVectors(t *testing.T) {
+	vectors := testVectors(t)
+	if os.Getenv("KEYSTORE_UPDATE") != "" {
+		data, err := json.MarshalIndent(vectors, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile("testdata/vectors.json", append(data, '\n'), 0o644))
+	}
+	data, err := os.ReadFile("testdata/vectors.json")
+	require.NoError(t, err)
+	var expected []vector
+	require.NoError(t, json.Unmarshal(data, &expected))
+	require.Len(t, expected, len(vectors))
+
+	for i, v := range expected {
+		// the encryption is deterministic given the random stream
+		require.JSONEq(t, string(v.Keystore), string(vectors[i].Keystore))
+
+		var ks Keystore
+		require.NoError(t, json.Unmarshal(v.Keystore, &ks))
+		var secret []byte
+		switch ks.Kind {
+		case KindKeyPair:
+			p, err := ks.DecryptKeyPair([]byte(v.Password))
+			require.NoError(t, err)
+			secret = must(p.Private.MarshalBinary())
+		case KindPriShare:
+			sh, err := ks.DecryptPriShare([]byte(v.Password))
+			require.NoError(t, err)
+			secret = must(sh.V.MarshalBinary())
+		case KindDistKeyShare:
+			d, err := ks.DecryptDistKeyShare([]byte(v.Password))
+			require.NoError(t, err)
+			secret = must(d.Share.V.MarshalBinary())
+		}
+		require.Equal(t, v.Secret, hex.EncodeToString(secret))
+	}
+}
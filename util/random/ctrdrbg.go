@@ -0,0 +1,99 @@
+package random
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+)
+
+const (
+	ctrKeySize  = 32
+	ctrSeedSize = ctrKeySize + aes.BlockSize
+)
+
+// ctrDRBG is CTR_DRBG of SP 800-90A, section 10.2.1, with AES-256 and
+// without derivation function: the entropy input must have full entropy, and
+// the personalization string and additional inputs are at most as long as
+// the seed.
+type ctrDRBG struct {
+	block cipher.Block
+	v     [aes.BlockSize]byte
+}
+
+// NewCTRDRBG returns a CTR_DRBG with AES-256 without derivation function. It
+// is instantiated with 48 bytes of entropy input from the entropy source,
+// which must have full entropy, and no nonce. The personalization string and
+// additional inputs are at most 48 bytes long.
+func NewCTRDRBG(config *DRBGConfig) (*DRBG, error) {
+	return newDRBG(&ctrDRBG{}, config)
+}
+
+func (c *ctrDRBG) entropySize() int { return ctrSeedSize }
+
+func (c *ctrDRBG) nonceSize() int { return 0 }
+
+func (c *ctrDRBG) maxInputSize() int { return ctrSeedSize }
+
+func (c *ctrDRBG) instantiate(entropy, _, personalization []byte) {
+	c.setKey(make([]byte, ctrKeySize))
+	c.v = [aes.BlockSize]byte{}
+	c.reseed(entropy, personalization)
+}
+
+// reseed updates the state with the entropy input XORed with the additional
+// input, padded with zeros.
+func (c *ctrDRBG) reseed(entropy, additional []byte) {
+	seed := make([]byte, ctrSeedSize)
+	copy(seed, entropy)
+	for i, b := range additional {
+		seed[i] ^= b
+	}
+	c.update(seed)
+}
+
+func (c *ctrDRBG) setKey(key []byte) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic("random: " + err.Error())
+	}
+	c.block = block
+}
+
+// increment increments V as a 128-bit big-endian counter.
+func (c *ctrDRBG) increment() {
+	for i := len(c.v) - 1; i >= 0; i-- {
+		c.v[i]++
+		if c.v[i] != 0 {
+			return
+		}
+	}
+}
+
+// keystream fills out with the encryptions of the successive values of V.
+func (c *ctrDRBG) keystream(out []byte) {
+	var block [aes.BlockSize]byte
+	for len(out) > 0 {
+		c.increment()
+		c.block.Encrypt(block[:], c.v[:])
+		out = out[copy(out, block[:]):]
+	}
+}
+
+// update is CTR_DRBG_Update, with the provided data of at most a seed,
+// padded with zeros.
+func (c *ctrDRBG) update(provided []byte) {
+	temp := make([]byte, ctrSeedSize)
+	c.keystream(temp)
+	for i, b := range provided {
+		temp[i] ^= b
+	}
+	c.setKey(temp[:ctrKeySize])
+	copy(c.v[:], temp[ctrKeySize:])
+}
+
+func (c *ctrDRBG) generate(out, additional []byte) {
+	if len(additional) > 0 {
+		c.update(additional)
+	}
+	c.keystream(out)
+	c.update(additional)
+}
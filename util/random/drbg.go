@@ -0,0 +1,201 @@
+package random
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// The deterministic random bit generators (DRBG) of NIST SP 800-90A Rev. 1,
+// HMAC_DRBG with SHA-256 and CTR_DRBG with AES-256 without derivation
+// function, both at the security strength of 256 bits. They are seeded from
+// an entropy source, crypto/rand by default, and reseeded from it after a
+// number of requests, or before every request with prediction resistance.
+
+const (
+	// maxReseedInterval is the maximum number of requests between reseeds
+	maxReseedInterval = 1 << 48
+	// maxRequestSize is the maximum number of bytes of a request
+	maxRequestSize = 1 << 16
+)
+
+var (
+	// ErrEntropy is returned when the entropy source of a DRBG fails.
+	ErrEntropy = errors.New("random: entropy source failure")
+	// ErrInputTooLong is returned when a personalization string or an
+	// additional input is longer than the DRBG allows.
+	ErrInputTooLong = errors.New("random: DRBG input too long")
+	// ErrRequestTooLong is returned when more than 64 KiB are requested at
+	// once from a DRBG.
+	ErrRequestTooLong = errors.New("random: DRBG request too long")
+)
+
+// DRBGConfig configures a DRBG. The zero value is a DRBG seeded from
+// crypto/rand, without personalization string nor prediction resistance.
+type DRBGConfig struct {
+	// Entropy is the entropy source, crypto/rand by default.
+	Entropy io.Reader
+	// Personalization is the personalization string mixed in the seed.
+	Personalization []byte
+	// ReseedInterval is the number of requests after which the DRBG is
+	// reseeded from the entropy source, 2^48 by default and at most.
+	ReseedInterval uint64
+	// PredictionResistance reseeds the DRBG before every request.
+	PredictionResistance bool
+}
+
+// mechanism is a DRBG mechanism of SP 800-90A, without the reseed counter.
+type mechanism interface {
+	// entropySize and nonceSize are the numbers of bytes of entropy input
+	// and nonce read from the entropy source to instantiate the mechanism.
+	entropySize() int
+	nonceSize() int
+	// maxInputSize is the maximum size of the personalization string and the
+	// additional inputs.
+	maxInputSize() int
+	instantiate(entropy, nonce, personalization []byte)
+	reseed(entropy, additional []byte)
+	generate(out, additional []byte)
+}
+
+// DRBG is a deterministic random bit generator of NIST SP 800-90A. It is a
+// cipher.Stream, so it can be used as the random stream of kyber suites, and
+// an io.Reader. It is safe for concurrent use.
+type DRBG struct {
+	mu                   sync.Mutex
+	m                    mechanism
+	entropy              io.Reader
+	reseedCounter        uint64
+	reseedInterval       uint64
+	predictionResistance bool
+}
+
+func newDRBG(m mechanism, config *DRBGConfig) (*DRBG, error) {
+	if config == nil {
+		config = &DRBGConfig{}
+	}
+	d := &DRBG{
+		m:                    m,
+		entropy:              config.Entropy,
+		reseedInterval:       config.ReseedInterval,
+		predictionResistance: config.PredictionResistance,
+	}
+	if d.entropy == nil {
+		d.entropy = rand.Reader
+	}
+	if d.reseedInterval == 0 || d.reseedInterval > maxReseedInterval {
+		d.reseedInterval = maxReseedInterval
+	}
+	if len(config.Personalization) > m.maxInputSize() {
+		return nil, fmt.Errorf("%w: personalization string", ErrInputTooLong)
+	}
+	entropy, err := d.read(m.entropySize())
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := d.read(m.nonceSize())
+	if err != nil {
+		return nil, err
+	}
+	m.instantiate(entropy, nonce, config.Personalization)
+	d.reseedCounter = 1
+	return d, nil
+}
+
+func (d *DRBG) read(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(d.entropy, buf); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEntropy, err)
+	}
+	return buf, nil
+}
+
+// Reseed reseeds the DRBG from its entropy source, with an optional
+// additional input.
+func (d *DRBG) Reseed(additional []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reseed(additional)
+}
+
+func (d *DRBG) reseed(additional []byte) error {
+	if len(additional) > d.m.maxInputSize() {
+		return fmt.Errorf("%w: additional input", ErrInputTooLong)
+	}
+	entropy, err := d.read(d.m.entropySize())
+	if err != nil {
+		return err
+	}
+	d.m.reseed(entropy, additional)
+	d.reseedCounter = 1
+	return nil
+}
+
+// Generate fills out with random bytes, with an optional additional input.
+// It's a single request of SP 800-90A, so out can't be longer than the
+// maximum of 64 KiB, in which case ErrRequestTooLong is returned.
+func (d *DRBG) Generate(out, additional []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(additional) > d.m.maxInputSize() {
+		return fmt.Errorf("%w: additional input", ErrInputTooLong)
+	}
+	if len(out) > maxRequestSize {
+		return ErrRequestTooLong
+	}
+	return d.generate(out, additional)
+}
+
+// stream fills out with random bytes, split into requests of at most 64 KiB.
+func (d *DRBG) stream(out []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for len(out) > 0 {
+		n := min(len(out), maxRequestSize)
+		if err := d.generate(out[:n], nil); err != nil {
+			return err
+		}
+		out = out[n:]
+	}
+	return nil
+}
+
+func (d *DRBG) generate(out, additional []byte) error {
+	if d.predictionResistance || d.reseedCounter > d.reseedInterval {
+		// the additional input is used by the reseed
+		if err := d.reseed(additional); err != nil {
+			return err
+		}
+		additional = nil
+	}
+	d.m.generate(out, additional)
+	d.reseedCounter++
+	return nil
+}
+
+// Read implements io.Reader. Unlike Generate, it accepts buffers longer than
+// 64 KiB, and fills them with consecutive requests.
+func (d *DRBG) Read(p []byte) (int, error) {
+	if err := d.stream(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// XORKeyStream implements cipher.Stream. Like Read, it splits the key stream
+// into requests of at most 64 KiB. It panics if the entropy source fails when
+// reseeding.
+func (d *DRBG) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("XORKeyStream: dst too short")
+	}
+	key := make([]byte, len(src))
+	if err := d.stream(key); err != nil {
+		panic(err.Error())
+	}
+	for i := range src {
+		dst[i] = src[i] ^ key[i]
+	}
+}
@@ -0,0 +1,279 @@
+package random
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	buf, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return buf
+}
+
+// The CTR_DRBG vector of the NIST ACVP server, AES-256 without derivation
+// function, with reseed and additional inputs: ctrDRBG-1.0/prompt.json of
+// https://github.com/usnistgov/ACVP-Server at fb44dce, lines 4447-4482.
+func TestCTRDRBGVector(t *testing.T) {
+	entropy := unhex(t, "9FCBB4CCC0135C484BDED061DA9FD70748682FE84166B97FF53F9AA1909B2E95D3D529C0F453B3AC575D12AA441CC5CD")
+	personalization := unhex(t, "2C9FED0B39556CDBE699EBCA2A0EC7EECB287E8744475050C572FA8AE9ED0A4A7D6F1CABF1C4278532FB20AF7D64BD32")
+	reseedEntropy := unhex(t, "913C0DA19B010EDDD55A7A4F3F713EEF5B1534D34360A7EC376AE71A6B340043CC7726F762CB853453F399B3A645062A")
+	reseedAdditional := unhex(t, "2D9D4EC141A22E6CD2F6EE4F6719CF6BDF95CFE50B8D5EA6C87D38B4B872706FFF80B0380BB90E9C42D11D6526E56C29")
+	additional1 := unhex(t, "A642F06D327828F3E84564A3E37D60C157073B95864CA07981B0189668A0D978CD5DC68F06801CEFF0DC839A312B028E")
+	additional2 := unhex(t, "9DB14BABFA9107C88BA92073C0B4A65E89147EA06D74B894142979482F452915B35B5636F9B8A951759735ADE7C8D5D1")
+	returned := unhex(t, "F10C645683FF0131254052ED4C698122B46B563654C29D728AC191CA4AAEFE649EEFE4C6FC33B25BB739294DD5CF5780"+
+		"99F856C98D98000CBF971F1E6EA900822FF8C110118F6520471744D3F8A3F5C7D568494240E57F5488AF9C9F9F4E7322F56CCD843C0DBFCE"+
+		"9170C02E205389420527F23EDB3369D9FCC5E34901B5BA4EB71B973FC7982FFE0899FF7FE53EE0C4F51A3EF93EF9C6D4D279DD7536F8776B"+
+		"E94AAA05E89EF6E6AEE8832B4B42FFCA5FB91EC0273F9EF945865512889B0C5EE141D1B38DF827D2A694835561628C6F9B093A01A835F07A"+
+		"DBB9E03FEBF93389E8F3B86E1E0ABF1F9958FA286AD995289C2F606D1A9043A166C1AFE8D00769C712650819C9068A4BD22717C98338395A"+
+		"7BA6E95B5178BFBF4EFB0F05A91713BA8BF2127A6BA1EDFA6D1CAB05C03EE0D2AFE1DA4EB8F2C579EC872FF4B602027EF4BDCF2F4B01423F"+
+		"8E600A13D7CACB6AB83263BA58F907694AF614A6724FD0E4C627A0D91DDC6716C697FACE6F4808A4F37B731DE4E0CD4766CEADAAAF479925"+
+		"05299C72AC1A6E9A8335B8D7E501B3841188D0DA4DE5267674444DC2B0CF9F010756FA865A25CA3F1B24C34E845B2259926B6A867A7684DE"+
+		"68A6137C4FB0F47A2E54AE9E6455BEBA0B0A9629644FE9E378EE95386443BA977124FFD1192E9F460684C7B09FA99F5F93F04F56FD7955E0"+
+		"42187887CE696F1934017E458B16B5C9")
+
+	d, err := NewCTRDRBG(&DRBGConfig{
+		Entropy:         bytes.NewReader(append(entropy, reseedEntropy...)),
+		Personalization: personalization,
+	})
+	require.NoError(t, err)
+	require.NoError(t, d.Reseed(reseedAdditional))
+	out := make([]byte, len(returned))
+	require.NoError(t, d.Generate(out, additional1))
+	require.NoError(t, d.Generate(out, additional2))
+	require.Equal(t, returned, out)
+}
+
+// The deterministic ECDSA nonces of RFC 6979 are the first outputs of an
+// HMAC_DRBG with SHA-256 seeded with the private key and the hash of the
+// message: appendix A.2.5, for P-256 with SHA-256.
+func TestHMACDRBGVector(t *testing.T) {
+	x := unhex(t, "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721")
+	for _, v := range []struct {
+		message, k string
+	}{
+		{"sample", "A6E3C57DD01ABE90086538398355DD4C3B17AA873382B0F24D6129493D8AAD60"},
+		{"test", "D16B6AE827F17175E040871A1C7EC3500192C4C92677336EC2537ACAEE0008E0"},
+	} {
+		// the hashes are smaller than the order of P-256, so they are their
+		// own reduction
+		h := sha256.Sum256([]byte(v.message))
+		// the seed is x || h, split into the entropy input, the nonce and the
+		// personalization string
+		d, err := NewHMACDRBG(&DRBGConfig{
+			Entropy:         bytes.NewReader(append(x, h[:16]...)),
+			Personalization: h[16:],
+		})
+		require.NoError(t, err)
+		k := make([]byte, 32)
+		require.NoError(t, d.Generate(k, nil))
+		require.Equal(t, unhex(t, v.k), k, v.message)
+	}
+}
+
+// The CAVP vectors of SP 800-90A, from drbgtestvectors.zip of
+// https://csrc.nist.gov/Projects/Cryptographic-Algorithm-Validation-Program/Random-Number-Generators,
+// as transcribed in test/recipes/30-test_evp_data/evprand.txt of
+// https://github.com/openssl/openssl at 8cf17aaeb459, where each one is the
+// first COUNT of its section.
+//
+// HMAC_DRBG.rsp of drbgvectors_no_reseed with SHA-256, with personalization
+// string and additional inputs: evprand.txt lines 41865-41874.
+func TestHMACDRBGCAVP(t *testing.T) {
+	entropy := unhex(t, "5d3286bc53a258a53ba781e2c4dcd79a790e43bbe0e89fb3eed39086be34174b")
+	nonce := unhex(t, "c5422294b7318952ace7055ab7570abf")
+	personalization := unhex(t, "2dba094d008e150d51c4135bb2f03dcde9cbf3468a12908a1b025c120c985b9d")
+	additional1 := unhex(t, "793a7ef8f6f0482beac542bb785c10f8b7b406a4de92667ab168ecc2cf7573c6")
+	additional2 := unhex(t, "2238cdb4e23d629fe0c2a83dd8d5144ce1a6229ef41dabe2a99ff722e510b530")
+	returned := unhex(t, "d04678198ae7e1aeb435b45291458ffde0891560748b43330eaf866b5a6385e74c6fa5a5a44bdb284d436e98d2440"+
+		"18d6acedcdfa2e9f499d8089e4db86ae89a6ab2d19cb705e2f048f97fb597f04106a1fa6a1416ad3d859118e079a0c319eb95686f4cb"+
+		"cce3b5101c7a0b010ef029c4ef6d06cdfac97efb9773891688c37cf")
+
+	d, err := NewHMACDRBG(&DRBGConfig{
+		Entropy:         bytes.NewReader(append(entropy, nonce...)),
+		Personalization: personalization,
+	})
+	require.NoError(t, err)
+	out := make([]byte, len(returned))
+	require.NoError(t, d.Generate(out, additional1))
+	require.NoError(t, d.Generate(out, additional2))
+	require.Equal(t, returned, out)
+}
+
+// HMAC_DRBG.rsp of drbgvectors_pr_false with SHA-256, with personalization
+// string and a reseed, without additional input: TestNIST1 in
+// hmacdrbg/hmacdrbg_test.go of https://github.com/hashicorp/go-hmac-drbg at
+// a6e5a68489f6, lines 59-63.
+func TestHMACDRBGCAVPReseed(t *testing.T) {
+	entropy := unhex(t, "fa0ee1fe39c7c390aa94159d0de97564342b591777f3e5f6a4ba2aea342ec840")
+	nonce := unhex(t, "dd0820655cb2ffdb0da9e9310a67c9e5")
+	personalization := unhex(t, "f2e58fe60a3afc59dad37595415ffd318ccf69d67780f6fa0797dc9aa43e144c")
+	reseedEntropy := unhex(t, "e0629b6d7975ddfa96a399648740e60f1f9557dc58b3d7415f9ba9d4dbb501f6")
+	returned := unhex(t, "f92d4cf99a535b20222a52a68db04c5af6f5ffc7b66a473a37a256bd8d298f9b4aa4af7e8d181e02367903f93bd"+
+		"b744c6c2f3f3472626b40ce9bd6a70e7b8f93992a16a76fab6b5f162568e08ee6c3e804aefd952ddd3acb791c50f2ad69e9a04028a06"+
+		"a9c01d3a62aca2aaf6efe69ed97a016213a2dd642b4886764072d9cbe")
+
+	var seed []byte
+	seed = append(seed, entropy...)
+	seed = append(seed, nonce...)
+	seed = append(seed, reseedEntropy...)
+	d, err := NewHMACDRBG(&DRBGConfig{
+		Entropy:         bytes.NewReader(seed),
+		Personalization: personalization,
+	})
+	require.NoError(t, err)
+	require.NoError(t, d.Reseed(nil))
+	out := make([]byte, len(returned))
+	require.NoError(t, d.Generate(out, nil))
+	require.NoError(t, d.Generate(out, nil))
+	require.Equal(t, returned, out)
+}
+
+// With prediction resistance, every request reseeds from the entropy source
+// with the additional input. HMAC_DRBG.rsp of drbgvectors_pr_true with
+// SHA-256, with personalization string and additional inputs: evprand.txt
+// lines 71817-71828.
+func TestHMACDRBGCAVPPredictionResistance(t *testing.T) {
+	entropy := unhex(t, "4294671d493dc085b5184607d7de2ff2b6aceb734a1b026f6cfee7c5a90f03da")
+	nonce := unhex(t, "d071544e599235d5eb38b64b551d2a6e")
+	personalization := unhex(t, "63bc769ae1d95a98bde870e4db7776297041d37c8a5c688d4e024b78d83f4d78")
+	additional1 := unhex(t, "28848becd3f47696f124f4b14853a456156f69be583a7d4682cff8d44b39e1d3")
+	entropyPR1 := unhex(t, "db9b4790b62336fbb9a684b82947065393eeef8f57bd2477141ad17e776dac34")
+	additional2 := unhex(t, "8bfce0b7132661c3cd78175d83926f643e36f7608eec2c5dac3ddcbacc8c2182")
+	entropyPR2 := unhex(t, "4a9abe80f6f522f29878bedf8245b27940a76471006fb4a4110beb4decb6c341")
+	returned := unhex(t, "e580dc969194b2b18a97478aef9d1a72390aff14562747bf080d741527a6655ce7fc135325b457483a9f9c70f91"+
+		"165a811cf4524b50d51199a0df3bd60d12abac27d0bf6618e6b114e05420352e23f3603dfe8a225dc19b3d1fff1dc245dc6b1df24c7417"+
+		"44bec3f9437dbbf222df84881a457a589e7815ef132f686b760f012")
+
+	var seed []byte
+	for _, b := range [][]byte{entropy, nonce, entropyPR1, entropyPR2} {
+		seed = append(seed, b...)
+	}
+	d, err := NewHMACDRBG(&DRBGConfig{
+		Entropy:              bytes.NewReader(seed),
+		Personalization:      personalization,
+		PredictionResistance: true,
+	})
+	require.NoError(t, err)
+	out := make([]byte, len(returned))
+	require.NoError(t, d.Generate(out, additional1))
+	require.NoError(t, d.Generate(out, additional2))
+	require.Equal(t, returned, out)
+}
+
+// CTR_DRBG.rsp of drbgvectors_pr_true with AES-256 without derivation
+// function, with personalization string and additional inputs: evprand.txt
+// lines 55528-55538.
+func TestCTRDRBGCAVPPredictionResistance(t *testing.T) {
+	entropy := unhex(t, "c54805274bde00aa5289e0513579019707666d2fa7a1c8908865891c87c0c652335a4d3cc415bc30742b164647f8820f")
+	personalization := unhex(t, "d63fb5afa2101fa4b8a6c3b89d9c250ac728fc1ddad0e7585b5d54728ed20c2f940e89155596e3b963635b6d6088164b")
+	additional1 := unhex(t, "744bfae3c23a5cc9a3b373b6c50795068d35eb8a339746ac810d16f864e880061082edf9d2687c211960aa83400f85f9")
+	entropyPR1 := unhex(t, "b2ad31d1f20dcf30dd526ec9156c07f270216bdb59197325bab180675929888ab699c54fb21819b7d921d6346bff2f7f")
+	additional2 := unhex(t, "ad55c682962aa4fe9ebc227c9402e79b0aa7874844d33eaee7e2d15baf81d9d33936e4d93f28ad109657b512aee115a5")
+	entropyPR2 := unhex(t, "eca449048d26fd38f8ca435237dce66eadec7069ee5dd0b70084b819a711c0820a7556bbd0ae20f06e5169278b593b71")
+	returned := unhex(t, "f08fdfc1775b6feb5a4177110bf29d7c3ab715dfdc4b27200359288c0624bd5c"+
+		"1028acc9914d88a82b09f5eaafdc3bca8547b98481df39b86504314221cbdc3c")
+
+	var seed []byte
+	for _, b := range [][]byte{entropy, entropyPR1, entropyPR2} {
+		seed = append(seed, b...)
+	}
+	d, err := NewCTRDRBG(&DRBGConfig{
+		Entropy:              bytes.NewReader(seed),
+		Personalization:      personalization,
+		PredictionResistance: true,
+	})
+	require.NoError(t, err)
+	out := make([]byte, len(returned))
+	require.NoError(t, d.Generate(out, additional1))
+	require.NoError(t, d.Generate(out, additional2))
+	require.Equal(t, returned, out)
+}
+
+// countingReader counts the bytes read from an entropy source.
+type countingReader struct {
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.n += len(p)
+	return len(p), nil
+}
+
+func TestDRBGReseed(t *testing.T) {
+	for _, newDRBG := range []func(*DRBGConfig) (*DRBG, error){NewHMACDRBG, NewCTRDRBG} {
+		entropy := &countingReader{}
+		d, err := newDRBG(&DRBGConfig{Entropy: entropy, ReseedInterval: 2})
+		require.NoError(t, err)
+		instantiated := entropy.n
+
+		out := make([]byte, 16)
+		require.NoError(t, d.Generate(out, nil))
+		require.NoError(t, d.Generate(out, nil))
+		require.Equal(t, instantiated, entropy.n)
+		// the third request reseeds
+		require.NoError(t, d.Generate(out, nil))
+		reseeded := entropy.n
+		require.Greater(t, reseeded, instantiated)
+
+		// with prediction resistance, every request reseeds
+		entropy = &countingReader{}
+		d, err = newDRBG(&DRBGConfig{Entropy: entropy, PredictionResistance: true})
+		require.NoError(t, err)
+		require.NoError(t, d.Generate(out, nil))
+		require.NoError(t, d.Generate(out, nil))
+		require.Equal(t, instantiated+2*(reseeded-instantiated), entropy.n)
+
+		// a failing entropy source
+		_, err = newDRBG(&DRBGConfig{Entropy: bytes.NewReader(nil)})
+		require.ErrorIs(t, err, ErrEntropy)
+		d, err = newDRBG(&DRBGConfig{Entropy: io.LimitReader(entropy, int64(instantiated)), PredictionResistance: true})
+		require.NoError(t, err)
+		require.ErrorIs(t, d.Generate(out, nil), ErrEntropy)
+		require.Panics(t, func() { d.XORKeyStream(out, out) })
+	}
+
+	_, err := NewCTRDRBG(&DRBGConfig{Personalization: make([]byte, ctrSeedSize+1)})
+	require.ErrorIs(t, err, ErrInputTooLong)
+}
+
+func TestDRBGStream(t *testing.T) {
+	for _, newDRBG := range []func(*DRBGConfig) (*DRBG, error){NewHMACDRBG, NewCTRDRBG} {
+		seed := bytes.Repeat([]byte{1}, 1024)
+		d1, err := newDRBG(&DRBGConfig{Entropy: bytes.NewReader(seed), Personalization: []byte("kyber")})
+		require.NoError(t, err)
+		d2, err := newDRBG(&DRBGConfig{Entropy: bytes.NewReader(seed), Personalization: []byte("kyber")})
+		require.NoError(t, err)
+		d3, err := newDRBG(&DRBGConfig{Entropy: bytes.NewReader(seed)})
+		require.NoError(t, err)
+
+		// the key stream is the output, split into requests
+		src := make([]byte, maxRequestSize+100)
+		copy(src, "hello")
+		dst := make([]byte, len(src))
+		d1.XORKeyStream(dst, src)
+		out := make([]byte, len(src))
+		_, err = io.ReadFull(d2, out)
+		require.NoError(t, err)
+		for i := range out {
+			out[i] ^= src[i]
+		}
+		require.Equal(t, out, dst)
+
+		other := make([]byte, len(src))
+		d3.XORKeyStream(other, src)
+		require.NotEqual(t, dst, other)
+
+		// the stream can be used by the package
+		require.Len(t, Bits(256, true, d1), 32)
+
+		// a single request is at most 64 KiB
+		require.ErrorIs(t, d1.Generate(src, nil), ErrRequestTooLong)
+		require.NoError(t, d1.Generate(src[:maxRequestSize], nil))
+	}
+}
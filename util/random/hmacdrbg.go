@@ -0,0 +1,74 @@
+package random
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math"
+)
+
+// hmacDRBG is HMAC_DRBG of SP 800-90A, section 10.1.2, with SHA-256.
+type hmacDRBG struct {
+	k, v []byte
+}
+
+// NewHMACDRBG returns an HMAC_DRBG with SHA-256. It is instantiated with 32
+// bytes of entropy input and 16 bytes of nonce from the entropy source.
+func NewHMACDRBG(config *DRBGConfig) (*DRBG, error) {
+	return newDRBG(&hmacDRBG{}, config)
+}
+
+func (h *hmacDRBG) entropySize() int { return sha256.Size }
+
+func (h *hmacDRBG) nonceSize() int { return sha256.Size / 2 }
+
+// maxInputSize is 2^32 bytes in SP 800-90A, capped to the size of an int
+// on 32-bit platforms.
+func (h *hmacDRBG) maxInputSize() int { return math.MaxInt32 }
+
+func (h *hmacDRBG) instantiate(entropy, nonce, personalization []byte) {
+	h.k = make([]byte, sha256.Size)
+	h.v = make([]byte, sha256.Size)
+	for i := range h.v {
+		h.v[i] = 1
+	}
+	h.update(entropy, nonce, personalization)
+}
+
+func (h *hmacDRBG) reseed(entropy, additional []byte) {
+	h.update(entropy, additional)
+}
+
+// hmac returns HMAC(K, data...).
+func (h *hmacDRBG) hmac(data ...[]byte) []byte {
+	m := hmac.New(sha256.New, h.k)
+	for _, d := range data {
+		m.Write(d)
+	}
+	return m.Sum(nil)
+}
+
+// update is HMAC_DRBG_Update, with the provided data given in parts.
+func (h *hmacDRBG) update(provided ...[]byte) {
+	size := 0
+	for _, p := range provided {
+		size += len(p)
+	}
+	h.k = h.hmac(append([][]byte{h.v, {0}}, provided...)...)
+	h.v = h.hmac(h.v)
+	if size == 0 {
+		return
+	}
+	h.k = h.hmac(append([][]byte{h.v, {1}}, provided...)...)
+	h.v = h.hmac(h.v)
+}
+
+func (h *hmacDRBG) generate(out, additional []byte) {
+	if len(additional) > 0 {
+		h.update(additional)
+	}
+	for len(out) > 0 {
+		h.v = h.hmac(h.v)
+		out = out[copy(out, h.v):]
+	}
+	h.update(additional)
+}
@@ -0,0 +1,103 @@
+// Package cshake provides implementations of kyber.XOF based on the
+// cSHAKE128 and cSHAKE256 functions of NIST SP 800-185, which are SHAKE with
+// a customization string separating the domains of their applications.
+package cshake
+
+import (
+	"go.dedis.ch/kyber/v4"
+	"golang.org/x/crypto/sha3"
+)
+
+type xof struct {
+	newHash       func(N, S []byte) sha3.ShakeHash
+	customization []byte
+	sh            sha3.ShakeHash
+	seed          []byte
+	// key is here to not make excess garbage during repeated calls
+	// to XORKeyStream.
+	key []byte
+}
+
+// New128 creates a new XOF using cSHAKE128 with the given customization
+// string, which may be empty.
+func New128(seed, customization []byte) kyber.XOF {
+	return newXOF(sha3.NewCShake128, seed, customization)
+}
+
+// New256 creates a new XOF using cSHAKE256 with the given customization
+// string, which may be empty.
+func New256(seed, customization []byte) kyber.XOF {
+	return newXOF(sha3.NewCShake256, seed, customization)
+}
+
+func newXOF(newHash func(N, S []byte) sha3.ShakeHash, seed, customization []byte) kyber.XOF {
+	x := &xof{
+		newHash:       newHash,
+		customization: append([]byte{}, customization...),
+		seed:          append([]byte{}, seed...),
+	}
+	x.Reset()
+	return x
+}
+
+func (x *xof) Clone() kyber.XOF {
+	return &xof{
+		newHash:       x.newHash,
+		customization: x.customization,
+		sh:            x.sh.Clone(),
+		seed:          x.seed,
+	}
+}
+
+func (x *xof) Reseed() {
+	if len(x.key) < 128 {
+		x.key = make([]byte, 128)
+	} else {
+		x.key = x.key[0:128]
+	}
+	_, err := x.Read(x.key)
+	if err != nil {
+		panic("xof error getting key: " + err.Error())
+	}
+	x.sh = x.newHash(nil, x.customization)
+	_, err = x.sh.Write(x.key)
+	if err != nil {
+		panic("xof error writing key: " + err.Error())
+	}
+}
+
+func (x *xof) Reset() {
+	x.sh = x.newHash(nil, x.customization)
+	x.sh.Write(x.seed)
+}
+
+func (x *xof) Read(dst []byte) (int, error) {
+	return x.sh.Read(dst)
+}
+
+func (x *xof) Write(src []byte) (int, error) {
+	return x.sh.Write(src)
+}
+
+func (x *xof) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("dst too short")
+	}
+	if len(x.key) < len(src) {
+		x.key = make([]byte, len(src))
+	} else {
+		x.key = x.key[0:len(src)]
+	}
+
+	n, err := x.Read(x.key)
+	if err != nil {
+		panic("xof error getting key: " + err.Error())
+	}
+	if n != len(src) {
+		panic("short read on key")
+	}
+
+	for i := range src {
+		dst[i] = src[i] ^ x.key[i]
+	}
+}
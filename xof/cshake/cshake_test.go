@@ -0,0 +1,38 @@
+package cshake
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	buf, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	require.NoError(t, err)
+	return buf
+}
+
+// Samples #2 and #4 of the NIST cSHAKE examples, with an empty function name.
+func TestVectors(t *testing.T) {
+	customization := []byte("Email Signature")
+	data := []byte{0, 1, 2, 3}
+
+	out := make([]byte, 32)
+	New128(data, customization).Read(out)
+	require.Equal(t, unhex(t, "C1 C3 69 25 B6 40 9A 04 F1 B5 04 FC BC A9 D8 2B"+
+		"40 17 27 7C B5 ED 2B 20 65 FC 1D 38 14 D5 AA F5"), out)
+
+	out = make([]byte, 64)
+	New256(data, customization).Read(out)
+	require.Equal(t, unhex(t, "D0 08 82 8E 2B 80 AC 9D 22 18 FF EE 1D 07 0C 48"+
+		"B8 E4 C8 7B FF 32 C9 69 9D 5B 68 96 EE E0 ED D1"+
+		"64 02 0E 2B E0 56 08 58 D9 C0 0C 03 7E 34 A9 69"+
+		"37 C5 61 A7 4C 41 2B B4 C7 46 46 95 27 28 1C 8C"), out)
+
+	// the customization separates the domains
+	other := make([]byte, 64)
+	New256(data, []byte("Email Signature!")).Read(other)
+	require.NotEqual(t, out, other)
+}
@@ -0,0 +1,136 @@
+// Package k12 provides implementations of kyber.XOF based on TurboSHAKE and
+// KangarooTwelve (RFC 9861), which use the Keccak permutation reduced to 12
+// rounds, about twice as fast as SHAKE. KangarooTwelve additionally hashes
+// long inputs as a tree of TurboSHAKE128 leaves, and takes a customization
+// string.
+package k12
+
+import (
+	"io"
+
+	"go.dedis.ch/kyber/v4"
+)
+
+const (
+	rounds = 12
+	// rates of TurboSHAKE128 and TurboSHAKE256
+	rate128 = 168
+	rate256 = 136
+	// default domain separation byte of TurboSHAKE
+	domainTurboSHAKE = 0x1f
+)
+
+// state is the hashing state of an XOF.
+type state interface {
+	io.Writer
+	io.Reader
+	clone() state
+}
+
+func (s *sponge) clone() state {
+	c := *s
+	return &c
+}
+
+type xof struct {
+	newState func() state
+	st       state
+	seed     []byte
+	// key is here to not make excess garbage during repeated calls
+	// to XORKeyStream.
+	key []byte
+}
+
+// NewTurboSHAKE128 creates a new XOF using TurboSHAKE128 with the default
+// domain separation byte.
+func NewTurboSHAKE128(seed []byte) kyber.XOF {
+	return newXOF(func() state {
+		s := newSponge(rate128, rounds, domainTurboSHAKE)
+		return &s
+	}, seed)
+}
+
+// NewTurboSHAKE256 creates a new XOF using TurboSHAKE256 with the default
+// domain separation byte.
+func NewTurboSHAKE256(seed []byte) kyber.XOF {
+	return newXOF(func() state {
+		s := newSponge(rate256, rounds, domainTurboSHAKE)
+		return &s
+	}, seed)
+}
+
+// New creates a new XOF using KangarooTwelve (KT128) with an empty
+// customization string.
+func New(seed []byte) kyber.XOF {
+	return NewCustom(seed, nil)
+}
+
+// NewCustom creates a new XOF using KangarooTwelve (KT128) with the given
+// customization string.
+func NewCustom(seed, customization []byte) kyber.XOF {
+	customization = append([]byte{}, customization...)
+	return newXOF(func() state { return newTree(customization) }, seed)
+}
+
+func newXOF(newState func() state, seed []byte) kyber.XOF {
+	x := &xof{newState: newState, seed: append([]byte{}, seed...)}
+	x.Reset()
+	return x
+}
+
+func (x *xof) Clone() kyber.XOF {
+	return &xof{newState: x.newState, st: x.st.clone(), seed: x.seed}
+}
+
+func (x *xof) Reseed() {
+	if len(x.key) < 128 {
+		x.key = make([]byte, 128)
+	} else {
+		x.key = x.key[0:128]
+	}
+	_, err := x.Read(x.key)
+	if err != nil {
+		panic("xof error getting key: " + err.Error())
+	}
+	x.st = x.newState()
+	_, err = x.st.Write(x.key)
+	if err != nil {
+		panic("xof error writing key: " + err.Error())
+	}
+}
+
+func (x *xof) Reset() {
+	x.st = x.newState()
+	x.st.Write(x.seed)
+}
+
+func (x *xof) Read(dst []byte) (int, error) {
+	return x.st.Read(dst)
+}
+
+func (x *xof) Write(src []byte) (int, error) {
+	return x.st.Write(src)
+}
+
+func (x *xof) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("dst too short")
+	}
+	if len(x.key) < len(src) {
+		x.key = make([]byte, len(src))
+	} else {
+		x.key = x.key[0:len(src)]
+	}
+
+	n, err := x.Read(x.key)
+	if err != nil {
+		panic("xof error getting key: " + err.Error())
+	}
+	if n != len(src) {
+		panic("short read on key")
+	}
+
+	for i := range src {
+		dst[i] = src[i] ^ x.key[i]
+	}
+}
@@ -0,0 +1,116 @@
+package k12
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+// ptn is the pattern of the test vectors of RFC 9861: the bytes 00 to FA,
+// repeated.
+func ptn(n int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i % 251)
+	}
+	return buf
+}
+
+func unhex(t *testing.T, s string) []byte {
+	buf, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	require.NoError(t, err)
+	return buf
+}
+
+// With the 24 rounds of Keccak-f, the sponge is SHAKE.
+func TestSHAKE(t *testing.T) {
+	for _, size := range []int{0, 1, rate128 - 1, rate128, rate128 + 1, 1000} {
+		s := newSponge(rate128, 24, domainTurboSHAKE)
+		s.Write(ptn(size))
+		out := make([]byte, 500)
+		s.Read(out)
+
+		expected := make([]byte, len(out))
+		sha3.ShakeSum128(expected, ptn(size))
+		require.Equal(t, expected, out, size)
+	}
+}
+
+func TestTurboSHAKEVectors(t *testing.T) {
+	out := make([]byte, 32)
+	NewTurboSHAKE128(nil).Read(out)
+	require.Equal(t, unhex(t, "1E 41 5F 1C 59 83 AF F2 16 92 17 27 7D 17 BB 53"+
+		"8C D9 45 A3 97 DD EC 54 1F 1C E4 1A F2 C1 B7 4C"), out)
+
+	out = make([]byte, 64)
+	NewTurboSHAKE256(nil).Read(out)
+	require.Equal(t, unhex(t, "36 7A 32 9D AF EA 87 1C 78 02 EC 67 F9 05 AE 13"+
+		"C5 76 95 DC 2C 66 63 C6 10 35 F5 9A 18 F8 E7 DB"+
+		"11 ED C0 E1 2E 91 EA 60 EB 6B 32 DF 06 DD 7F 00"+
+		"2F BA FA BB 6E 13 EC 1C C2 0D 99 55 47 60 0D B0"), out)
+}
+
+func TestKangarooTwelveVectors(t *testing.T) {
+	for _, v := range []struct {
+		message, customization []byte
+		expected               string
+	}{
+		{nil, nil, "1A C2 D4 50 FC 3B 42 05 D1 9D A7 BF CA 1B 37 51" +
+			"3C 08 03 57 7A C7 16 7F 06 FE 2C E1 F0 EF 39 E5"},
+		{ptn(17), nil, "6B F7 5F A2 23 91 98 DB 47 72 E3 64 78 F8 E1 9B" +
+			"0F 37 12 05 F6 A9 A9 3A 27 3F 51 DF 37 12 28 88"},
+		{ptn(17 * 17), nil, "0C 31 5E BC DE DB F6 14 26 DE 7D CF 8F B7 25 D1" +
+			"E7 46 75 D7 F5 32 7A 50 67 F3 67 B1 08 EC B6 7C"},
+		{ptn(17 * 17 * 17), nil, "CB 55 2E 2E C7 7D 99 10 70 1D 57 8B 45 7D DF 77" +
+			"2C 12 E3 22 E4 EE 7F E4 17 F9 2C 75 8F 0D 59 D0"},
+		{ptn(17 * 17 * 17 * 17), nil, "87 01 04 5E 22 20 53 45 FF 4D DA 05 55 5C BB 5C" +
+			"3A F1 A7 71 C2 B8 9B AE F3 7D B4 3D 99 98 B9 FE"},
+		{ptn(17 * 17 * 17 * 17 * 17), nil, "84 4D 61 09 33 B1 B9 96 3C BD EB 5A E3 B6 B0 5C" +
+			"C7 CB D6 7C EE DF 88 3E B6 78 A0 A8 E0 37 16 82"},
+		{nil, ptn(1), "FA B6 58 DB 63 E9 4A 24 61 88 BF 7A F6 9A 13 30" +
+			"45 F4 6E E9 84 C5 6E 3C 33 28 CA AF 1A A1 A5 83"},
+		{[]byte{0xff}, ptn(41), "D8 48 C5 06 8C ED 73 6F 44 62 15 9B 98 67 FD 4C" +
+			"20 B8 08 AC C3 D5 BC 48 E0 B0 6B A0 A3 76 2E C4"},
+		{bytes.Repeat([]byte{0xff}, 3), ptn(41 * 41), "C3 89 E5 00 9A E5 71 20 85 4C 2E 8C 64 67 0A C0" +
+			"13 58 CF 4C 1B AF 89 44 7A 72 42 34 DC 7C ED 74"},
+		{bytes.Repeat([]byte{0xff}, 7), ptn(41 * 41 * 41), "75 D2 F8 6A 2E 64 45 66 72 6B 4F BC FC 56 57 B9" +
+			"DB CF 07 0C 7B 0D CA 06 45 0A B2 91 D7 44 3B CF"},
+	} {
+		out := make([]byte, 32)
+		NewCustom(v.message, v.customization).Read(out)
+		require.Equal(t, unhex(t, v.expected), out, "message %d, customization %d", len(v.message), len(v.customization))
+	}
+
+	// the last 32 bytes of 10032 bytes of output
+	out := make([]byte, 10032)
+	New(nil).Read(out)
+	require.Equal(t, unhex(t, "E8 DC 56 36 42 F7 22 8C 84 68 4C 89 84 05 D3 A8"+
+		"34 79 91 58 C0 79 B1 28 80 27 7A 1D 28 E2 FF 6D"), out[10000:])
+}
+
+// Writing in pieces gives the same output as writing at once, around the
+// chunk boundaries.
+func TestKangarooTwelveWrites(t *testing.T) {
+	msg := ptn(3*chunkSize + 1)
+	for _, size := range []int{chunkSize - 1, chunkSize, chunkSize + 1, 2 * chunkSize, len(msg)} {
+		expected := make([]byte, 64)
+		New(msg[:size]).Read(expected)
+
+		for _, step := range []int{1, 100, chunkSize - 1, chunkSize} {
+			x := New(nil)
+			for i := 0; i < size; i += step {
+				x.Write(msg[i:min(i+step, size)])
+			}
+			c := x.Clone()
+			out := make([]byte, 64)
+			x.Read(out)
+			require.Equal(t, expected, out, "size %d, step %d", size, step)
+			c.Read(out)
+			require.Equal(t, expected, out, "size %d, step %d", size, step)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package k12
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// round constants of Keccak-f[1600], of which Keccak-p[1600, 12] uses the
+// last 12
+var roundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// rotation offsets of the lanes, indexed by x+5y
+var rotations = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// keccakP applies the last rounds of Keccak-f[1600] to the state, which is
+// Keccak-p[1600, 12] for 12 rounds.
+func keccakP(a *[25]uint64, rounds int) {
+	var c [5]uint64
+	var b [25]uint64
+	for r := 24 - rounds; r < 24; r++ {
+		// θ
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d := c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				a[y+x] ^= d
+			}
+		}
+		// ρ and π
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(a[x+5*y], rotations[x+5*y])
+			}
+		}
+		// χ
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				a[y+x] = b[y+x] ^ (^b[y+(x+1)%5] & b[y+(x+2)%5])
+			}
+		}
+		// ι
+		a[0] ^= roundConstants[r]
+	}
+}
+
+// sponge is a Keccak sponge with the padding of TurboSHAKE: the domain
+// separation byte followed by zeros and a final bit.
+type sponge struct {
+	a      [25]uint64
+	rate   int
+	rounds int
+	domain byte
+	// pos is the position in the current block of the rate
+	pos       int
+	squeezing bool
+}
+
+func newSponge(rate, rounds int, domain byte) sponge {
+	return sponge{rate: rate, rounds: rounds, domain: domain}
+}
+
+// xorByte adds b to the state at the byte position i.
+func (s *sponge) xorByte(i int, b byte) {
+	s.a[i/8] ^= uint64(b) << (8 * (i % 8))
+}
+
+func (s *sponge) Write(p []byte) (int, error) {
+	if s.squeezing {
+		panic("k12: Write after Read")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		if s.pos == 0 && len(p) >= s.rate {
+			// full blocks are absorbed a lane at a time
+			for i := 0; i < s.rate/8; i++ {
+				s.a[i] ^= binary.LittleEndian.Uint64(p[8*i:])
+			}
+			p = p[s.rate:]
+			keccakP(&s.a, s.rounds)
+			continue
+		}
+		s.xorByte(s.pos, p[0])
+		p = p[1:]
+		s.pos++
+		if s.pos == s.rate {
+			keccakP(&s.a, s.rounds)
+			s.pos = 0
+		}
+	}
+	return n, nil
+}
+
+// pad terminates the absorbing phase.
+func (s *sponge) pad() {
+	s.xorByte(s.pos, s.domain)
+	s.xorByte(s.rate-1, 0x80)
+	keccakP(&s.a, s.rounds)
+	s.pos = 0
+	s.squeezing = true
+}
+
+func (s *sponge) Read(p []byte) (int, error) {
+	if !s.squeezing {
+		s.pad()
+	}
+	n := len(p)
+	for len(p) > 0 {
+		if s.pos == s.rate {
+			keccakP(&s.a, s.rounds)
+			s.pos = 0
+		}
+		p[0] = byte(s.a[s.pos/8] >> (8 * (s.pos % 8)))
+		p = p[1:]
+		s.pos++
+	}
+	return n, nil
+}
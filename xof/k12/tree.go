@@ -0,0 +1,130 @@
+package k12
+
+const (
+	// size of the chunks of the input of KangarooTwelve
+	chunkSize = 8192
+	// size of the chaining values of the leaves
+	cvSize = 32
+	// domain separation bytes of KangarooTwelve
+	domainSingle = 0x07
+	domainFinal  = 0x06
+	domainLeaf   = 0x0b
+)
+
+// tree is the state of KangarooTwelve. The input S = M || C ||
+// length_encode(|C|) is hashed by TurboSHAKE128 directly if it fits in a
+// chunk. Otherwise, the first chunk starts the final node, and the chaining
+// values of the following chunks, hashed as leaves, are appended to it.
+// Chunks are only closed when more input arrives, since the hashing of the
+// first one depends on whether it is the last.
+type tree struct {
+	customization []byte
+	// first holds the first chunk until the input exceeds a chunk
+	first []byte
+	// final is the final node, once the input exceeds a chunk
+	final *sponge
+	// leaf is the current leaf, and leafSize its size
+	leaf     *sponge
+	leafSize int
+	// leaves is the number of leaves appended to the final node
+	leaves uint64
+	// out is the output once the input is terminated
+	out *sponge
+}
+
+func newTree(customization []byte) *tree {
+	return &tree{customization: customization}
+}
+
+func (t *tree) clone() state {
+	c := *t
+	c.first = append([]byte{}, t.first...)
+	if t.final != nil {
+		final := *t.final
+		c.final = &final
+	}
+	if t.leaf != nil {
+		leaf := *t.leaf
+		c.leaf = &leaf
+	}
+	if t.out != nil {
+		out := *t.out
+		c.out = &out
+	}
+	return &c
+}
+
+func (t *tree) Write(p []byte) (int, error) {
+	if t.out != nil {
+		panic("k12: Write after Read")
+	}
+	t.write(p)
+	return len(p), nil
+}
+
+func (t *tree) write(p []byte) {
+	for len(p) > 0 {
+		if t.final == nil {
+			if len(t.first) < chunkSize {
+				n := min(chunkSize-len(t.first), len(p))
+				t.first = append(t.first, p[:n]...)
+				p = p[n:]
+				continue
+			}
+			// the first chunk isn't the last one
+			final := newSponge(rate128, rounds, domainFinal)
+			final.Write(t.first)
+			final.Write([]byte{3, 0, 0, 0, 0, 0, 0, 0})
+			t.final, t.first = &final, nil
+		}
+		if t.leaf != nil && t.leafSize == chunkSize {
+			t.closeLeaf()
+		}
+		if t.leaf == nil {
+			leaf := newSponge(rate128, rounds, domainLeaf)
+			t.leaf, t.leafSize = &leaf, 0
+		}
+		n := min(chunkSize-t.leafSize, len(p))
+		t.leaf.Write(p[:n])
+		t.leafSize += n
+		p = p[n:]
+	}
+}
+
+// closeLeaf appends the chaining value of the current leaf to the final
+// node.
+func (t *tree) closeLeaf() {
+	var cv [cvSize]byte
+	t.leaf.Read(cv[:])
+	t.final.Write(cv[:])
+	t.leaf = nil
+	t.leaves++
+}
+
+func (t *tree) Read(p []byte) (int, error) {
+	if t.out == nil {
+		t.write(t.customization)
+		t.write(lengthEncode(uint64(len(t.customization))))
+		if t.final == nil {
+			out := newSponge(rate128, rounds, domainSingle)
+			out.Write(t.first)
+			t.out, t.first = &out, nil
+		} else {
+			t.closeLeaf()
+			t.final.Write(lengthEncode(t.leaves))
+			t.final.Write([]byte{0xff, 0xff})
+			t.out, t.final = t.final, nil
+		}
+	}
+	return t.out.Read(p)
+}
+
+// lengthEncode is length_encode of KangarooTwelve: the big-endian bytes of x
+// without leading zeros, followed by their number.
+func lengthEncode(x uint64) []byte {
+	var buf []byte
+	for ; x > 0; x >>= 8 {
+		buf = append([]byte{byte(x)}, buf...)
+	}
+	return append(buf, byte(len(buf)))
+}
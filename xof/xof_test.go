@@ -10,6 +10,8 @@ import (
 	"go.dedis.ch/kyber/v4"
 	"go.dedis.ch/kyber/v4/xof/blake2xb"
 	"go.dedis.ch/kyber/v4/xof/blake2xs"
+	"go.dedis.ch/kyber/v4/xof/cshake"
+	"go.dedis.ch/kyber/v4/xof/k12"
 	"go.dedis.ch/kyber/v4/xof/keccak"
 )
 
@@ -25,7 +27,30 @@ type keccakF struct{}
 
 func (b *keccakF) XOF(seed []byte) kyber.XOF { return keccak.New(seed) }
 
-var impls = []kyber.XOFFactory{&blake2xbF{}, &blake2xsF{}, &keccakF{}}
+type cshake128F struct{}
+
+func (b *cshake128F) XOF(seed []byte) kyber.XOF { return cshake.New128(seed, []byte("test")) }
+
+type cshake256F struct{}
+
+func (b *cshake256F) XOF(seed []byte) kyber.XOF { return cshake.New256(seed, []byte("test")) }
+
+type turboSHAKE128F struct{}
+
+func (b *turboSHAKE128F) XOF(seed []byte) kyber.XOF { return k12.NewTurboSHAKE128(seed) }
+
+type turboSHAKE256F struct{}
+
+func (b *turboSHAKE256F) XOF(seed []byte) kyber.XOF { return k12.NewTurboSHAKE256(seed) }
+
+type k12F struct{}
+
+func (b *k12F) XOF(seed []byte) kyber.XOF { return k12.NewCustom(seed, []byte("test")) }
+
+var impls = []kyber.XOFFactory{
+	&blake2xbF{}, &blake2xsF{}, &keccakF{},
+	&cshake128F{}, &cshake256F{}, &turboSHAKE128F{}, &turboSHAKE256F{}, &k12F{},
+}
 
 func TestEncDec(t *testing.T) {
 	lengths := []int{0, 1, 16, 1024, 8192}